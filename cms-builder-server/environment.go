@@ -23,6 +23,18 @@ type ConfigKeys struct {
 	SMTPPassword       string `json:"smtpPassword"`
 	SMTPSender         string `json:"smtpSender"`         // Server port
 	CsrfToken          string `json:"csrfToken"`          // CSRF token
+	AuthProvider       string `json:"authProvider"`       // Auth provider, firebase by default
+	AuthJwtSecret      string `json:"authJwtSecret"`      // Key local access tokens are signed with
+	AuthAccessTTL      string `json:"authAccessTtl"`      // Local access token lifetime in minutes
+	AuthRefreshTTL     string `json:"authRefreshTtl"`     // Local refresh token lifetime in hours
+	OIDCIssuer         string `json:"oidcIssuer"`         // OIDC issuer URL
+	OIDCClientId       string `json:"oidcClientId"`       // OIDC client id, expected audience of ID tokens
+	OIDCClientSecret   string `json:"oidcClientSecret"`   // OIDC client secret
+	OIDCRoleClaim      string `json:"oidcRoleClaim"`      // Claim holding roles or groups, e.g. realm_access.roles
+	OIDCRoleMap        string `json:"oidcRoleMap"`        // Claim values mapped to roles, e.g. cms-admins:admin,staff:editor
+	MfaRequiredRoles   string `json:"mfaRequiredRoles"`   // Roles only granted to sessions with a second factor, admin by default with local auth, none to disable
+	RequireVerified    string `json:"requireVerified"`    // Block users from protected routes until they verify their email, api keys are exempt
+	AdminUrl           string `json:"adminUrl"`           // URL of the admin app, password reset and verification emails link to it
 	FirebaseSecret     string `json:"firebaseSecret"`     // Firebase secret
 	FirebaseApiKey     string `json:"firebaseApiKey"`     // Firebase API key
 	GodToken           string `json:"godToken"`           // God token
@@ -36,6 +48,9 @@ type ConfigKeys struct {
 	AwsAccessKeyId     string `json:"awsAccessKeyId"`     // AWS access key id
 	BaseUrl            string `json:"baseUrl"`            // where the app is running
 	RunScheduler       string `json:"runScheduler"`       // Run scheduler
+	DefaultLocale      string `json:"defaultLocale"`      // Locale stored in the model fields
+	SupportedLocales   string `json:"supportedLocales"`   // Comma separated locales content can be delivered in
+	LocaleFallbacks    string `json:"localeFallbacks"`    // Comma separated locale:fallback pairs, e.g. es-AR:es
 }
 
 // EnvKeys are the keys used in the configuration file
@@ -57,6 +72,18 @@ var EnvKeys = ConfigKeys{
 	ServerHost:         "SERVER_HOST",
 	ServerPort:         "SERVER_PORT",
 	CsrfToken:          "CSRF_TOKEN",
+	AuthProvider:       "AUTH_PROVIDER",
+	AuthJwtSecret:      "AUTH_JWT_SECRET",
+	AuthAccessTTL:      "AUTH_ACCESS_TOKEN_TTL",
+	AuthRefreshTTL:     "AUTH_REFRESH_TOKEN_TTL",
+	OIDCIssuer:         "OIDC_ISSUER",
+	OIDCClientId:       "OIDC_CLIENT_ID",
+	OIDCClientSecret:   "OIDC_CLIENT_SECRET",
+	OIDCRoleClaim:      "OIDC_ROLE_CLAIM",
+	OIDCRoleMap:        "OIDC_ROLE_MAP",
+	MfaRequiredRoles:   "MFA_REQUIRED_ROLES",
+	RequireVerified:    "AUTH_REQUIRE_VERIFIED_EMAIL",
+	AdminUrl:           "ADMIN_URL",
 	FirebaseSecret:     "FIREBASE_SECRET",
 	FirebaseApiKey:     "FIREBASE_API_KEY",
 	SMTPHost:           "SMTP_HOST",
@@ -74,4 +101,7 @@ var EnvKeys = ConfigKeys{
 	AwsAccessKeyId:     "AWS_ACCESS_KEY_ID",
 	BaseUrl:            "BASE_URL",
 	RunScheduler:       "RUN_SCHEDULER",
+	DefaultLocale:      "DEFAULT_LOCALE",
+	SupportedLocales:   "SUPPORTED_LOCALES",
+	LocaleFallbacks:    "LOCALE_FALLBACKS",
 }
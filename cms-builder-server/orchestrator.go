@@ -1,12 +1,23 @@
 package orchestrator
 
 import (
+	"context"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
 	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authProviders "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/providers"
 	auth "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/resources"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
 	cliPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/clients"
+	commentResources "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/comment/resources"
 	configPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/config"
+	dashboardPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/dashboard"
 	dbPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database"
 	dbResources "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/resources"
 	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
@@ -15,11 +26,15 @@ import (
 	file "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/file/resources"
 	loggerPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/logger"
 	loggerTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/logger/types"
+	notificationPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/notification"
+	notificationResources "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/notification/resources"
 	rlResources "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/request-logger/resources"
 	rmPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
 	schPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/scheduler"
 	schResources "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/scheduler/resources"
 	svrPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server"
+	svrMiddlewares "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/middlewares"
 	svrTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/types"
 	storePkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/store"
 	storeConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/store/constants"
@@ -28,6 +43,9 @@ import (
 
 const orchestratorVersion = "1.6.63"
 
+// Permissions changed by other instances are picked up within this interval
+const permissionCacheTTL = time.Minute
+
 type OrchestratorUsers struct {
 	God       *authModels.User
 	Admin     *authModels.User
@@ -38,13 +56,14 @@ type OrchestratorUsers struct {
 type Orchestrator struct {
 	Config          *configPkg.ConfigReader
 	DB              *dbTypes.DatabaseConnection
-	FirebaseClient  *cliPkg.FirebaseManager
+	AuthProvider    authTypes.AuthProvider
 	Logger          *loggerTypes.Logger
 	LoggerConfig    *loggerTypes.LoggerConfig
 	ResourceManager *rmPkg.ResourceManager
 	Scheduler       *schPkg.Scheduler
 	SMTPConfig      *emailTypes.SMTPConfig
 	EmailSender     *emailPkg.EmailSender
+	Inbox           *notificationPkg.Inbox
 	Server          *svrTypes.Server
 	Store           storeTypes.Store
 	Users           *OrchestratorUsers
@@ -65,17 +84,20 @@ func (o *Orchestrator) init() error {
 		o.InitConfigReader,
 		o.InitLogger,
 		o.InitDatabase,
-		o.InitFirebase,
+		o.InitAuthProvider,
 		o.InitResourceManager,
+		o.InitSMTPConfig,
 		o.InitAuth,
 		o.InitDatabaseLogger,
 		o.InitRequestLogger,
 		o.InitUsers,
 		o.InitServer,
 		o.InitStore,
+		o.InitNotifications,
 		o.InitFiles,
 		o.InitScheduler,
-		o.InitSMTPConfig,
+		o.InitDashboard,
+		o.InitComments,
 	}
 
 	for _, init := range initializers {
@@ -137,24 +159,102 @@ func (o *Orchestrator) InitDatabase() error {
 	return nil
 }
 
-func (o *Orchestrator) InitFirebase() error {
-	cfg := &cliPkg.FirebaseConfig{
-		Secret: o.Config.GetString(EnvKeys.FirebaseSecret),
+// InitAuthProvider sets up the provider users authenticate with, Firebase unless configured otherwise.
+func (o *Orchestrator) InitAuthProvider() error {
+	providerType := o.Config.GetString(EnvKeys.AuthProvider)
+	if providerType == "" {
+		providerType = string(authConstants.AuthProviderFirebase)
 	}
-	fb, err := cliPkg.NewFirebaseAdmin(cfg)
-	if err != nil {
-		return fmt.Errorf("error initializing firebase: %w", err)
+
+	switch providerType {
+	case string(authConstants.AuthProviderFirebase):
+		cfg := &cliPkg.FirebaseConfig{
+			Secret: o.Config.GetString(EnvKeys.FirebaseSecret),
+		}
+		provider, err := authProviders.NewFirebaseProvider(cfg)
+		if err != nil {
+			return fmt.Errorf("error initializing firebase: %w", err)
+		}
+		o.AuthProvider = provider
+	case string(authConstants.AuthProviderLocal):
+		cfg := authProviders.LocalProviderConfig{
+			Secret:          o.Config.GetString(EnvKeys.AuthJwtSecret),
+			Issuer:          o.Config.GetString(EnvKeys.AppName),
+			AccessTokenTTL:  time.Duration(o.Config.GetInt64(EnvKeys.AuthAccessTTL)) * time.Minute,
+			RefreshTokenTTL: time.Duration(o.Config.GetInt64(EnvKeys.AuthRefreshTTL)) * time.Hour,
+		}
+		provider, err := authProviders.NewLocalProvider(o.DB, cfg)
+		if err != nil {
+			return fmt.Errorf("error initializing local auth: %w", err)
+		}
+		o.AuthProvider = provider
+	case string(authConstants.AuthProviderOIDC):
+		cfg := authProviders.OIDCProviderConfig{
+			Issuer:       o.Config.GetString(EnvKeys.OIDCIssuer),
+			ClientId:     o.Config.GetString(EnvKeys.OIDCClientId),
+			ClientSecret: o.Config.GetString(EnvKeys.OIDCClientSecret),
+			RoleClaim:    o.Config.GetString(EnvKeys.OIDCRoleClaim),
+			RoleMap:      map[string]authTypes.Role{},
+		}
+		for _, pair := range splitConfigList(o.Config.GetString(EnvKeys.OIDCRoleMap)) {
+			value, role, ok := strings.Cut(pair, ":")
+			if !ok {
+				return fmt.Errorf("invalid oidc role mapping %s, expected value:role", pair)
+			}
+			cfg.RoleMap[strings.TrimSpace(value)] = authTypes.Role(strings.TrimSpace(role))
+		}
+		provider, err := authProviders.NewOIDCProvider(context.Background(), cfg)
+		if err != nil {
+			return fmt.Errorf("error initializing oidc: %w", err)
+		}
+		o.AuthProvider = provider
+	case string(authConstants.AuthProviderNone):
+		o.AuthProvider = authProviders.NewNoneProvider()
+	default:
+		return fmt.Errorf("unknown auth provider: %s", providerType)
 	}
-	o.FirebaseClient = fb
 
-	o.Logger.Info().Msg("Firebase initialized")
+	o.Logger.Info().Str("provider", providerType).Msg("Auth provider initialized")
 	return nil
 }
 
 func (o *Orchestrator) InitResourceManager() error {
 	o.Logger.Info().Msg("Initializing resource manager")
 	o.ResourceManager = rmPkg.NewResourceManager(o.DB, o.Logger)
-	return nil
+
+	defaultLocale := o.Config.GetString(EnvKeys.DefaultLocale)
+	if defaultLocale == "" {
+		return nil
+	}
+
+	locales := rmTypes.NewLocaleConfig(defaultLocale)
+	for _, locale := range splitConfigList(o.Config.GetString(EnvKeys.SupportedLocales)) {
+		if !locales.IsSupported(locale) {
+			locales.Supported = append(locales.Supported, locale)
+		}
+	}
+
+	for _, pair := range splitConfigList(o.Config.GetString(EnvKeys.LocaleFallbacks)) {
+		locale, fallback, ok := strings.Cut(pair, ":")
+		if !ok {
+			return fmt.Errorf("invalid locale fallback %s, expected locale:fallback", pair)
+		}
+		locales.Fallbacks[strings.TrimSpace(locale)] = strings.TrimSpace(fallback)
+	}
+
+	return o.ResourceManager.SetLocales(locales)
+}
+
+// splitConfigList splits a comma separated config value, skipping empty items.
+func splitConfigList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
 
 func (o *Orchestrator) InitAuth() error {
@@ -163,9 +263,92 @@ func (o *Orchestrator) InitAuth() error {
 		return o.Users.System
 	}
 
-	resourceConfig := auth.SetupUserResource(o.FirebaseClient, o.DB, o.Logger, getSystemUser)
+	resourceConfig := auth.SetupUserResource(o.AuthProvider, o.DB, o.Logger, getSystemUser)
 	_, err := o.ResourceManager.AddResource(resourceConfig)
-	return err
+	if err != nil {
+		return err
+	}
+
+	err = o.DB.DB.AutoMigrate(&authModels.UserSession{}, &authModels.SessionRevocation{})
+	if err != nil {
+		return err
+	}
+
+	apiKeyConfig := auth.SetupApiKeyResource(o.ResourceManager, o.DB, o.Logger)
+	_, err = o.ResourceManager.AddResource(apiKeyConfig)
+	if err != nil {
+		return err
+	}
+
+	impersonationConfig := auth.SetupImpersonationSessionResource(o.Logger)
+	_, err = o.ResourceManager.AddResource(impersonationConfig)
+	if err != nil {
+		return err
+	}
+
+	roleConfigs := []*rmTypes.ResourceConfig{
+		auth.SetupRoleResource(o.Logger),
+		auth.SetupPermissionResource(o.ResourceManager, o.Logger),
+		auth.SetupUserRoleResource(o.Logger),
+	}
+	for _, config := range roleConfigs {
+		_, err = o.ResourceManager.AddResource(config)
+		if err != nil {
+			return err
+		}
+	}
+
+	permissionStore, err := authUtils.NewPermissionStore(o.DB, permissionCacheTTL, o.Logger)
+	if err != nil {
+		return fmt.Errorf("error initializing permission store: %w", err)
+	}
+	authUtils.SetPermissionStore(permissionStore)
+
+	// Only local sessions can carry a second factor out of the box, other providers opt in
+	mfaRoles := []authTypes.Role{}
+	if o.AuthProvider != nil && o.AuthProvider.Type() == authConstants.AuthProviderLocal {
+		mfaRoles = append(mfaRoles, authConstants.AdminRole)
+	}
+	if value := o.Config.GetString(EnvKeys.MfaRequiredRoles); value != "" {
+		mfaRoles = []authTypes.Role{}
+		for _, role := range splitConfigList(value) {
+			if role != "none" {
+				mfaRoles = append(mfaRoles, authTypes.Role(role))
+			}
+		}
+	}
+	authUtils.SetMfaRequiredRoles(mfaRoles...)
+
+	svrMiddlewares.SetRequireVerifiedEmail(o.Config.GetBool(EnvKeys.RequireVerified))
+
+	// Login routes are only served by providers the server talks to on behalf of clients
+	routes := []svrTypes.Route{}
+	switch provider := o.AuthProvider.(type) {
+	case *authProviders.LocalProvider:
+		linkUrl := o.Config.GetString(EnvKeys.AdminUrl)
+		if linkUrl == "" {
+			linkUrl = o.Config.GetString(EnvKeys.BaseUrl)
+		}
+		mailer := &authUtils.AccountMailer{
+			Sender:  o.EmailSender,
+			AppName: o.Config.GetString(EnvKeys.AppName),
+			LinkUrl: linkUrl,
+		}
+		routes = auth.SetupLocalAuthRoutes(provider)
+		routes = append(routes, auth.SetupAccountRoutes(provider, mailer)...)
+	case *authProviders.OIDCProvider:
+		routes = auth.SetupOIDCRoutes(provider, o.DB)
+	}
+
+	routes = append(routes, auth.SetupSessionRoutes(o.DB)...)
+
+	for _, route := range routes {
+		if err := o.ResourceManager.AddRoute(route); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func (o *Orchestrator) InitDatabaseLogger() error {
@@ -185,7 +368,7 @@ func (o *Orchestrator) InitFiles() error {
 	storeConfig := o.Store.GetConfig()
 	o.Logger.Info().Interface("storeConfig", storeConfig).Msg("Initializing store")
 
-	fileConfig := file.SetupFileResource(o.ResourceManager, o.DB, o.Store, o.Logger, o.Config.GetString(EnvKeys.BaseUrl))
+	fileConfig := file.SetupFileResource(o.ResourceManager, o.DB, o.Store, o.Logger, o.Config.GetString(EnvKeys.BaseUrl), o.Inbox)
 	_, err := o.ResourceManager.AddResource(fileConfig)
 	return err
 }
@@ -194,6 +377,14 @@ func (o *Orchestrator) InitUsers() error {
 	if err := o.SetupOrchestratorUsers(); err != nil {
 		return fmt.Errorf("error setting up orchestrator users: %w", err)
 	}
+
+	requestId := "automated::" + uuid.New().String()
+	err := authUtils.SeedRoles(context.Background(), o.DB, o.Users.System, requestId, o.Logger,
+		authConstants.AdminRole, authConstants.VisitorRole, authConstants.SchedulerRole)
+	if err != nil {
+		return fmt.Errorf("error seeding roles: %w", err)
+	}
+
 	o.Logger.Info().Msg("Users initialized successfully")
 	return nil
 }
@@ -208,7 +399,7 @@ func (o *Orchestrator) InitServer() error {
 		GodToken:       o.Config.GetString(EnvKeys.GodToken),
 		GodUser:        o.Users.God,
 		SystemUser:     o.Users.System,
-		Firebase:       o.FirebaseClient,
+		AuthProvider:   o.AuthProvider,
 		LoggerConfig:   o.LoggerConfig,
 	}
 
@@ -285,6 +476,7 @@ func (o *Orchestrator) InitScheduler() error {
 	if err != nil {
 		return fmt.Errorf("error initializing scheduler: %w", err)
 	}
+	sch.Notifier = o.Inbox
 	o.Scheduler = sch
 
 	jobConfig := schResources.SetupSchedulerJobDefinitionResource(o.ResourceManager, o.DB, sch.JobRegistry, runScheduler)
@@ -295,6 +487,11 @@ func (o *Orchestrator) InitScheduler() error {
 	return nil
 }
 
+func (o *Orchestrator) InitDashboard() error {
+	route := dashboardPkg.SetupDashboardRoute(o.ResourceManager, o.DB)
+	return o.ResourceManager.AddRoute(route)
+}
+
 func (o *Orchestrator) InitSMTPConfig() error {
 	o.Logger.Info().Msg("Initializing SMTP Config")
 	o.SMTPConfig = &emailTypes.SMTPConfig{
@@ -309,13 +506,49 @@ func (o *Orchestrator) InitSMTPConfig() error {
 	return nil
 }
 
+// InitNotifications adds the Notification resource and the Inbox other packages notify users with.
+// Notifications are emailed with the EmailSender to the users who opted in.
+func (o *Orchestrator) InitNotifications() error {
+	inbox, err := notificationPkg.NewInbox(o.DB, o.EmailSender, o.Users.System, o.Logger)
+	if err != nil {
+		return fmt.Errorf("error initializing notification inbox: %w", err)
+	}
+	o.Inbox = inbox
+
+	notificationConfig := notificationResources.SetupNotificationResource(o.ResourceManager, o.DB, o.Logger)
+	_, err = o.ResourceManager.AddResource(notificationConfig)
+	return err
+}
+
+// InitComments adds the Comment resource, mentioned users are notified through the Inbox.
+func (o *Orchestrator) InitComments() error {
+	commentConfig := commentResources.SetupCommentResource(o.ResourceManager, o.DB, o.Inbox, o.Logger)
+	_, err := o.ResourceManager.AddResource(commentConfig)
+	return err
+}
+
+// InitSingletons creates the record of every singleton resource that has none yet.
+// It runs right before the server starts, once the application registered its resources.
+func (o *Orchestrator) InitSingletons() error {
+	requestId := "automated::" + uuid.New().String()
+	return o.ResourceManager.EnsureSingletons(context.Background(), o.Users.System, requestId)
+}
+
 func (o *Orchestrator) Run() error {
+	if err := o.InitSingletons(); err != nil {
+		return fmt.Errorf("error initializing singletons: %w", err)
+	}
+
 	o.Logger.Info().Msg("Starting Server")
 
 	return svrPkg.RunServer(o.Server, o.ResourceManager.GetRoutes, nil)
 }
 
 func (o *Orchestrator) RunWithCertificate(cert *svrTypes.TLSCertificateConfig) error {
+	if err := o.InitSingletons(); err != nil {
+		return fmt.Errorf("error initializing singletons: %w", err)
+	}
+
 	o.Logger.Info().Msg("Starting Server")
 
 	return svrPkg.RunServer(o.Server, o.ResourceManager.GetRoutes, cert)
@@ -42,9 +42,9 @@ func TestNewOrchestrator(t *testing.T) {
 	db := o.DB
 	assert.NotNil(t, db)
 
-	// Firebase
-	firebase := o.FirebaseClient
-	assert.NotNil(t, firebase)
+	// Auth Provider
+	provider := o.AuthProvider
+	assert.NotNil(t, provider)
 
 	// ResourceManager
 	resourceManager := o.ResourceManager
@@ -28,6 +28,14 @@ const (
 	SchedulerRole authTypes.Role = "scheduler"
 )
 
+// Supported auth providers.
+const (
+	AuthProviderFirebase authTypes.AuthProviderType = "firebase"
+	AuthProviderLocal    authTypes.AuthProviderType = "local"
+	AuthProviderOIDC     authTypes.AuthProviderType = "oidc"
+	AuthProviderNone     authTypes.AuthProviderType = "none"
+)
+
 const GodTokenHeader = "X-God-Token"
 
 const RolesParamKey = "roles"
@@ -0,0 +1,251 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	authProviders "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/providers"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	svrMiddlewares "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/middlewares"
+	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
+)
+
+const (
+	accountEmailsPerAddress = 3
+	accountEmailsPerIp      = 10
+	accountEmailsWindow     = time.Hour
+)
+
+// accountEmailLimiter throttles the account emails sent to an address and requested from an IP,
+// so the flows can't flood inboxes or be used to probe for accounts.
+type accountEmailLimiter struct {
+	byEmail *svrMiddlewares.RateLimiter
+	byIp    *svrMiddlewares.RateLimiter
+}
+
+func newAccountEmailLimiter() *accountEmailLimiter {
+	return &accountEmailLimiter{
+		byEmail: svrMiddlewares.NewRateLimiter(accountEmailsPerAddress, accountEmailsWindow),
+		byIp:    svrMiddlewares.NewRateLimiter(accountEmailsPerIp, accountEmailsWindow),
+	}
+}
+
+// Allow counts the request against both limits, so clients cycling through emails are throttled too.
+func (l *accountEmailLimiter) Allow(r *http.Request, email string) bool {
+	ipAllowed := l.byIp.Allow(svrUtils.GetRequestIp(r))
+	emailAllowed := l.byEmail.Allow(strings.ToLower(strings.TrimSpace(email)))
+	return ipAllowed && emailAllowed
+}
+
+type AccountEmailInput struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordInput struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type VerifyEmailInput struct {
+	Token string `json:"token"`
+}
+
+// ForgotPasswordHandler emails a link to reset the password to the given email.
+// The response is the same whether the email belongs to an account or not, and is sent before the email.
+// Requests are throttled by email and IP.
+func ForgotPasswordHandler(provider *authProviders.LocalProvider, mailer *authUtils.AccountMailer) http.HandlerFunc {
+	limiter := newAccountEmailLimiter()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := svrUtils.GetRequestLogger(r)
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodPost)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		// 2. Parse Request Body
+		input := AccountEmailInput{}
+		bodyBytes, err := svrUtils.ReadRequestBody(r)
+		if err == nil {
+			err = json.Unmarshal(bodyBytes, &input)
+		}
+		if err != nil || input.Email == "" {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, "Email is required")
+			return
+		}
+
+		// 3. Throttle Requests
+		if !limiter.Allow(r, input.Email) {
+			svrUtils.SendJsonResponse(w, http.StatusTooManyRequests, nil, "Too many requests, try again later")
+			return
+		}
+
+		// 4. Email the Link
+		mailer.Go(func() {
+			token, err := provider.PasswordResetToken(context.Background(), input.Email)
+			if err == nil {
+				err = mailer.SendPasswordReset(token.User, token.Token, token.ExpiresAt)
+			}
+			if err != nil && !errors.Is(err, authTypes.ErrProviderUserNotFound) {
+				log.Error().Err(err).Msg("Error sending password reset email")
+			}
+		})
+
+		svrUtils.SendJsonResponse(w, http.StatusAccepted, nil, "If the email belongs to an account, a link to reset the password has been sent")
+	}
+}
+
+// ResetPasswordHandler sets a new password with the token of a reset link.
+// Every session of the user is revoked, and the email is marked as verified as the user received the link.
+func ResetPasswordHandler(provider *authProviders.LocalProvider, db *dbTypes.DatabaseConnection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestCtx := svrUtils.GetRequestContext(r)
+		log := requestCtx.Logger
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodPost)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		// 2. Parse Request Body
+		input := ResetPasswordInput{}
+		bodyBytes, err := svrUtils.ReadRequestBody(r)
+		if err == nil {
+			err = json.Unmarshal(bodyBytes, &input)
+		}
+		if err != nil || input.Token == "" || input.Password == "" {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, "Token and password are required")
+			return
+		}
+
+		// 3. Reset Password
+		user, err := provider.ResetPassword(r.Context(), input.Token, input.Password)
+		if errors.Is(err, authProviders.ErrInvalidAccountToken) {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, authProviders.ErrInvalidAccountToken.Error())
+			return
+		}
+		if err != nil {
+			log.Error().Err(err).Msg("Error resetting password")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error resetting password")
+			return
+		}
+
+		// 4. Revoke Sessions, they may have been opened with the previous password.
+		// The reset is not reported as done otherwise, the user can request a new link and retry.
+		err = authUtils.RevokeUserSessions(r.Context(), db, user, user, "password reset", requestCtx.RequestId, log)
+		if err != nil {
+			log.Error().Err(err).Uint("user", user.ID).Msg("Error revoking sessions")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error resetting password")
+			return
+		}
+
+		err = authUtils.MarkEmailVerified(r.Context(), db, user, user, requestCtx.RequestId, log)
+		if err != nil {
+			log.Error().Err(err).Uint("user", user.ID).Msg("Error marking email as verified")
+		}
+
+		svrUtils.SendJsonResponse(w, http.StatusOK, nil, "Password has been reset")
+	}
+}
+
+// SendVerificationHandler emails a link to verify the given email.
+// The response is the same whether the email belongs to an account or not, and is sent before the email.
+// Requests are throttled by email and IP.
+func SendVerificationHandler(provider *authProviders.LocalProvider, mailer *authUtils.AccountMailer) http.HandlerFunc {
+	limiter := newAccountEmailLimiter()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := svrUtils.GetRequestLogger(r)
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodPost)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		// 2. Parse Request Body
+		input := AccountEmailInput{}
+		bodyBytes, err := svrUtils.ReadRequestBody(r)
+		if err == nil {
+			err = json.Unmarshal(bodyBytes, &input)
+		}
+		if err != nil || input.Email == "" {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, "Email is required")
+			return
+		}
+
+		// 3. Throttle Requests
+		if !limiter.Allow(r, input.Email) {
+			svrUtils.SendJsonResponse(w, http.StatusTooManyRequests, nil, "Too many requests, try again later")
+			return
+		}
+
+		// 4. Email the Link
+		mailer.Go(func() {
+			token, err := provider.EmailVerificationToken(context.Background(), input.Email)
+			if err == nil {
+				err = mailer.SendEmailVerification(token.User, token.Token, token.ExpiresAt)
+			}
+			if err != nil && !errors.Is(err, authTypes.ErrProviderUserNotFound) && !errors.Is(err, authProviders.ErrEmailAlreadyVerified) {
+				log.Error().Err(err).Msg("Error sending verification email")
+			}
+		})
+
+		svrUtils.SendJsonResponse(w, http.StatusAccepted, nil, "If the email belongs to an unverified account, a verification link has been sent")
+	}
+}
+
+// VerifyEmailHandler marks the email of the user as verified with the token of a verification link.
+func VerifyEmailHandler(provider *authProviders.LocalProvider, db *dbTypes.DatabaseConnection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestCtx := svrUtils.GetRequestContext(r)
+		log := requestCtx.Logger
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodPost)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		// 2. Parse Request Body
+		input := VerifyEmailInput{}
+		bodyBytes, err := svrUtils.ReadRequestBody(r)
+		if err == nil {
+			err = json.Unmarshal(bodyBytes, &input)
+		}
+		if err != nil || input.Token == "" {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, "Token is required")
+			return
+		}
+
+		// 3. Verify Email
+		user, err := provider.VerifyEmailToken(r.Context(), input.Token)
+		if errors.Is(err, authProviders.ErrInvalidAccountToken) {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, authProviders.ErrInvalidAccountToken.Error())
+			return
+		}
+		if err == nil {
+			err = authUtils.MarkEmailVerified(r.Context(), db, user, user, requestCtx.RequestId, log)
+		}
+		if err != nil {
+			log.Error().Err(err).Msg("Error verifying email")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error verifying email")
+			return
+		}
+
+		svrUtils.SendJsonResponse(w, http.StatusOK, nil, "Email has been verified")
+	}
+}
@@ -0,0 +1,223 @@
+package auth_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authHandlers "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/handlers"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authProviders "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/providers"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	testPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/testing"
+)
+
+var linkPattern = regexp.MustCompile(`href="(https://admin\.test\.com[^"]+)"`)
+
+// linkToken returns the path and token of the link in the last email sent to the recipient.
+func linkToken(t *testing.T, smtp *testPkg.SmtpServer, recipient string) (string, string) {
+	messages := smtp.Messages()
+	for i := len(messages) - 1; i >= 0; i-- {
+		if len(messages[i].To) == 0 || messages[i].To[0] != recipient {
+			continue
+		}
+
+		match := linkPattern.FindStringSubmatch(messages[i].Body())
+		if !assert.Len(t, match, 2, "email has no link") {
+			return "", ""
+		}
+
+		link, err := url.Parse(strings.ReplaceAll(match[1], "&amp;", "&"))
+		assert.NoError(t, err)
+		return link.Path, link.Query().Get("token")
+	}
+
+	t.Fatalf("no email sent to %s", recipient)
+	return "", ""
+}
+
+func setupAccountHandlers(t *testing.T) (testPkg.TestUtils, *authProviders.LocalProvider, *authUtils.AccountMailer, *testPkg.SmtpServer) {
+	bed := testPkg.SetupAuthTestBed()
+
+	provider, err := authProviders.NewLocalProvider(bed.Db, authProviders.LocalProviderConfig{Secret: "test-secret"})
+	assert.NoError(t, err)
+
+	smtp, err := testPkg.StartSmtpServer()
+	assert.NoError(t, err)
+	t.Cleanup(func() { smtp.Close() })
+
+	mailer := &authUtils.AccountMailer{
+		Sender:  smtp.Sender(),
+		AppName: "CMS",
+		LinkUrl: "https://admin.test.com/",
+	}
+
+	return bed, provider, mailer, smtp
+}
+
+func createLocalUser(t *testing.T, bed testPkg.TestUtils, provider *authProviders.LocalProvider) *authModels.User {
+	user, err := authUtils.CreateUserWithRole(authTypes.RegisterUserInput{
+		FirstName:        "Jane",
+		Email:            strings.ToLower(testPkg.RandomEmail()),
+		Password:         "secret-password",
+		Roles:            []authTypes.Role{authConstants.VisitorRole},
+		RegisterFirebase: true,
+	}, provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+	assert.NoError(t, err)
+	return user
+}
+
+func TestPasswordResetHandlers(t *testing.T) {
+	bed, provider, mailer, smtp := setupAccountHandlers(t)
+	user := createLocalUser(t, bed, provider)
+
+	// Forgot Password
+	req := testPkg.CreateTestRequest(t, http.MethodPost, "/auth/forgot-password", `{}`, false, nil, bed.Logger)
+	rr := testPkg.ExecuteHandler(t, authHandlers.ForgotPasswordHandler(provider, mailer), req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	// Unknown emails get the same response, without an email
+	req = testPkg.CreateTestRequest(t, http.MethodPost, "/auth/forgot-password", `{"email": "missing@example.com"}`, false, nil, bed.Logger)
+	rr = testPkg.ExecuteHandler(t, authHandlers.ForgotPasswordHandler(provider, mailer), req)
+	assert.Equal(t, http.StatusAccepted, rr.Code)
+	mailer.Wait()
+	assert.Empty(t, smtp.Messages())
+
+	body := fmt.Sprintf(`{"email": "%s"}`, user.Email)
+	req = testPkg.CreateTestRequest(t, http.MethodPost, "/auth/forgot-password", body, false, nil, bed.Logger)
+	rr = testPkg.ExecuteHandler(t, authHandlers.ForgotPasswordHandler(provider, mailer), req)
+	assert.Equal(t, http.StatusAccepted, rr.Code)
+	mailer.Wait()
+	assert.Len(t, smtp.Messages(), 1)
+	assert.Contains(t, smtp.Messages()[0].Data, "Subject: Reset your CMS password")
+
+	path, token := linkToken(t, smtp, user.Email)
+	assert.Equal(t, authUtils.PasswordResetPath, path)
+	assert.NotEmpty(t, token)
+
+	// Reset Password
+	req = testPkg.CreateTestRequest(t, http.MethodPost, "/auth/reset-password", `{"token": "invalid", "password": "new-password"}`, false, nil, bed.Logger)
+	rr = testPkg.ExecuteHandler(t, authHandlers.ResetPasswordHandler(provider, bed.Db), req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	body = fmt.Sprintf(`{"token": "%s", "password": "new-password"}`, token)
+	req = testPkg.CreateTestRequest(t, http.MethodPost, "/auth/reset-password", body, false, nil, bed.Logger)
+	rr = testPkg.ExecuteHandler(t, authHandlers.ResetPasswordHandler(provider, bed.Db), req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	req = testPkg.CreateTestRequest(t, http.MethodPost, "/auth/reset-password", body, false, nil, bed.Logger)
+	rr = testPkg.ExecuteHandler(t, authHandlers.ResetPasswordHandler(provider, bed.Db), req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	// Sessions are revoked, and the email is verified as the user received the link
+	revocations := []authModels.SessionRevocation{}
+	assert.NoError(t, bed.Db.DB.Where("user_id = ?", user.ID).Find(&revocations).Error)
+	assert.Len(t, revocations, 1)
+
+	stored := authModels.User{}
+	assert.NoError(t, bed.Db.DB.First(&stored, user.ID).Error)
+	assert.True(t, stored.IsEmailVerified())
+
+	body = fmt.Sprintf(`{"email": "%s", "password": "new-password"}`, user.Email)
+	req = testPkg.CreateTestRequest(t, http.MethodPost, "/auth/login", body, false, nil, bed.Logger)
+	rr = testPkg.ExecuteHandler(t, authHandlers.LoginHandler(provider), req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestPasswordResetHandlers_RevokeSessionsFails(t *testing.T) {
+	bed, provider, mailer, smtp := setupAccountHandlers(t)
+	user := createLocalUser(t, bed, provider)
+	failRevocations(bed)
+
+	body := fmt.Sprintf(`{"email": "%s"}`, user.Email)
+	req := testPkg.CreateTestRequest(t, http.MethodPost, "/auth/forgot-password", body, false, nil, bed.Logger)
+	rr := testPkg.ExecuteHandler(t, authHandlers.ForgotPasswordHandler(provider, mailer), req)
+	assert.Equal(t, http.StatusAccepted, rr.Code)
+	mailer.Wait()
+
+	_, token := linkToken(t, smtp, user.Email)
+
+	// The reset isn't reported as done while the previous sessions are still valid
+	body = fmt.Sprintf(`{"token": "%s", "password": "new-password"}`, token)
+	req = testPkg.CreateTestRequest(t, http.MethodPost, "/auth/reset-password", body, false, nil, bed.Logger)
+	rr = testPkg.ExecuteHandler(t, authHandlers.ResetPasswordHandler(provider, bed.Db), req)
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	assert.NotContains(t, rr.Body.String(), "Password has been reset")
+}
+
+func TestEmailVerificationHandlers(t *testing.T) {
+	bed, provider, mailer, smtp := setupAccountHandlers(t)
+	user := createLocalUser(t, bed, provider)
+	assert.False(t, user.IsEmailVerified())
+
+	// Send Verification
+	body := fmt.Sprintf(`{"email": "%s"}`, user.Email)
+	req := testPkg.CreateTestRequest(t, http.MethodPost, "/auth/verify-email/send", body, false, nil, bed.Logger)
+	rr := testPkg.ExecuteHandler(t, authHandlers.SendVerificationHandler(provider, mailer), req)
+	assert.Equal(t, http.StatusAccepted, rr.Code)
+	mailer.Wait()
+	assert.Len(t, smtp.Messages(), 1)
+
+	path, token := linkToken(t, smtp, user.Email)
+	assert.Equal(t, authUtils.EmailVerificationPath, path)
+
+	// Verify Email
+	req = testPkg.CreateTestRequest(t, http.MethodPost, "/auth/verify-email", `{"token": "invalid"}`, false, nil, bed.Logger)
+	rr = testPkg.ExecuteHandler(t, authHandlers.VerifyEmailHandler(provider, bed.Db), req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	body = fmt.Sprintf(`{"token": "%s"}`, token)
+	req = testPkg.CreateTestRequest(t, http.MethodPost, "/auth/verify-email", body, false, nil, bed.Logger)
+	rr = testPkg.ExecuteHandler(t, authHandlers.VerifyEmailHandler(provider, bed.Db), req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	stored := authModels.User{}
+	assert.NoError(t, bed.Db.DB.First(&stored, user.ID).Error)
+	assert.True(t, stored.IsEmailVerified())
+
+	// Verified users aren't emailed again
+	body = fmt.Sprintf(`{"email": "%s"}`, user.Email)
+	req = testPkg.CreateTestRequest(t, http.MethodPost, "/auth/verify-email/send", body, false, nil, bed.Logger)
+	rr = testPkg.ExecuteHandler(t, authHandlers.SendVerificationHandler(provider, mailer), req)
+	assert.Equal(t, http.StatusAccepted, rr.Code)
+	mailer.Wait()
+	assert.Len(t, smtp.Messages(), 1)
+}
+
+func TestAccountEmailHandlers_Throttling(t *testing.T) {
+	bed, provider, mailer, smtp := setupAccountHandlers(t)
+	user := createLocalUser(t, bed, provider)
+
+	forgotPassword := authHandlers.ForgotPasswordHandler(provider, mailer)
+	send := func(email string, ip string) int {
+		body := fmt.Sprintf(`{"email": "%s"}`, email)
+		req := testPkg.CreateTestRequest(t, http.MethodPost, "/auth/forgot-password", body, false, nil, bed.Logger)
+		req.RemoteAddr = ip + ":12345"
+		return testPkg.ExecuteHandler(t, forgotPassword, req).Code
+	}
+
+	// Emails are limited whatever IP they are requested from
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusAccepted, send(user.Email, fmt.Sprintf("10.0.0.%d", i)))
+	}
+	assert.Equal(t, http.StatusTooManyRequests, send(user.Email, "10.0.0.100"))
+
+	mailer.Wait()
+	assert.Len(t, smtp.Messages(), 3)
+
+	// And so are IPs, whatever email they ask for
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, http.StatusAccepted, send(testPkg.RandomEmail(), "10.0.1.1"))
+	}
+	assert.Equal(t, http.StatusTooManyRequests, send(testPkg.RandomEmail(), "10.0.1.1"))
+	assert.Equal(t, http.StatusAccepted, send(testPkg.RandomEmail(), "10.0.1.2"))
+
+	mailer.Wait()
+}
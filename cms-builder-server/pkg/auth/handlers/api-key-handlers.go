@@ -0,0 +1,227 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	dbQueries "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/queries"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	rmPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
+	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
+	"github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/utils"
+)
+
+type ApiKeyInput struct {
+	Name       string           `json:"name"`
+	UserID     uint             `json:"userId"`
+	Roles      []authTypes.Role `json:"roles"`
+	Scopes     []string         `json:"scopes"` // resource:operation, e.g. Post:read
+	AllowedIps []string         `json:"allowedIps"`
+	ExpiresAt  *time.Time       `json:"expiresAt"`
+}
+
+// ApiKeyCreated is returned once on creation, it is the only time the key is shown.
+type ApiKeyCreated struct {
+	*authModels.ApiKey
+	Key string `json:"key"`
+}
+
+// ApiKeyCreateHandler creates an api key for a service user. Scopes are checked against the
+// permissions of the resources of the manager.
+func ApiKeyCreateHandler(mgr *rmPkg.ResourceManager) rmTypes.ApiFunction {
+	return func(a *rmTypes.Resource, db *dbTypes.DatabaseConnection) http.HandlerFunc {
+		return apiKeyCreateHandler(mgr, a, db)
+	}
+}
+
+func apiKeyCreateHandler(mgr *rmPkg.ResourceManager, a *rmTypes.Resource, db *dbTypes.DatabaseConnection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestCtx := svrUtils.GetRequestContext(r)
+		log := requestCtx.Logger
+		user := requestCtx.User
+		requestId := requestCtx.RequestId
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodPost)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		// 2. Check Permissions
+		if !authUtils.UserIsAllowed(a.Permissions, user.GetRoles(), authConstants.OperationCreate, a.ResourceNames.Singular, log) {
+			svrUtils.SendJsonResponse(w, http.StatusForbidden, nil, "User is not allowed to create this resource")
+			return
+		}
+
+		// 3. Parse Request Body
+		bodyBytes, err := svrUtils.ReadRequestBody(r)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, "Invalid request body")
+			return
+		}
+
+		input := ApiKeyInput{}
+		err = json.Unmarshal(bodyBytes, &input)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, "Invalid request body")
+			return
+		}
+
+		// 4. Validate Input
+		if input.Name == "" || input.UserID == 0 {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, "Name and user are required")
+			return
+		}
+
+		for _, scope := range input.Scopes {
+			if !authUtils.ValidScope(scope, authConstants.AllAllowedAccess) {
+				svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, "Invalid scope "+scope+", expected resource:operation")
+				return
+			}
+		}
+
+		if input.ExpiresAt != nil && input.ExpiresAt.Before(time.Now()) {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, "Expiration must be in the future")
+			return
+		}
+
+		serviceUser := authModels.User{}
+		err = dbQueries.FindOne(r.Context(), log, db, &serviceUser, map[string]interface{}{"id": input.UserID}, []string{})
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, "User not found")
+			return
+		}
+
+		// Keys can't do more than their user, nor carry roles requiring a second factor
+		// the creator's session didn't pass, as requests made with keys skip the MFA policy
+		authUtils.LoadUserRoles(r.Context(), db, &serviceUser, log)
+		for _, role := range input.Roles {
+			if !serviceUser.HasRole(role) {
+				svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, "User doesn't hold role "+string(role))
+				return
+			}
+		}
+
+		keyRoles := input.Roles
+		if len(keyRoles) == 0 {
+			keyRoles = serviceUser.GetRoles()
+		}
+
+		for _, role := range keyRoles {
+			if authUtils.RoleRequiresMfa(role) && !user.HasRole(role) {
+				svrUtils.SendJsonResponse(w, http.StatusForbidden, nil, "Granting role "+string(role)+" requires holding it with a second factor")
+				return
+			}
+		}
+
+		// Scopes only restrict the roles of the key, each must be allowed by them
+		for _, scope := range input.Scopes {
+			resourceName, operation, _ := strings.Cut(scope, ":")
+			scoped, err := mgr.GetResourceByName(resourceName)
+			if err != nil {
+				svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, "Unknown resource in scope "+scope)
+				return
+			}
+
+			if !authUtils.UserIsAllowed(scoped.Permissions, keyRoles, authTypes.CrudOperation(operation), scoped.ResourceNames.Singular, log) {
+				svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, "User is not allowed to "+operation+" "+resourceName+", it can't be scoped to it")
+				return
+			}
+		}
+
+		// 5. Generate Key
+		key, prefix, hash, err := authUtils.GenerateApiKey()
+		if err != nil {
+			log.Error().Err(err).Msg("Error generating api key")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error generating api key")
+			return
+		}
+
+		apiKey := &authModels.ApiKey{
+			Name:       input.Name,
+			Prefix:     prefix,
+			KeyHash:    hash,
+			UserID:     serviceUser.ID,
+			Roles:      authUtils.FormatRoles(input.Roles),
+			Scopes:     strings.Join(input.Scopes, ","),
+			AllowedIps: strings.Join(input.AllowedIps, ","),
+			ExpiresAt:  input.ExpiresAt,
+		}
+		apiKey.CreatedByID = user.ID
+		apiKey.UpdatedByID = user.ID
+
+		// 6. Create Instance in Database
+		err = dbQueries.Create(r.Context(), log, db, apiKey, user, requestId)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error creating resource")
+			return
+		}
+
+		svrUtils.SendJsonResponse(w, http.StatusCreated, &ApiKeyCreated{ApiKey: apiKey, Key: key}, a.ResourceNames.Singular+" has been created, the key won't be shown again")
+	}
+}
+
+// ApiKeyRevokeHandler revokes an api key, requests made with it are rejected from then on.
+func ApiKeyRevokeHandler(mgr *rmPkg.ResourceManager, db *dbTypes.DatabaseConnection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestCtx := svrUtils.GetRequestContext(r)
+		log := requestCtx.Logger
+		user := requestCtx.User
+		requestId := requestCtx.RequestId
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodPut)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		// 2. Check Permissions
+		a, err := mgr.GetResource(authModels.ApiKey{})
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, err.Error())
+			return
+		}
+
+		if !authUtils.UserIsAllowed(a.Permissions, user.GetRoles(), authConstants.OperationUpdate, a.ResourceNames.Singular, log) {
+			svrUtils.SendJsonResponse(w, http.StatusForbidden, nil, "User is not allowed to update this resource")
+			return
+		}
+
+		// 3. Find Instance
+		apiKey := authModels.ApiKey{}
+		err = dbQueries.FindOne(r.Context(), log, db, &apiKey, map[string]interface{}{"id": svrUtils.GetUrlParam("id", r)}, []string{})
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusNotFound, nil, "Instance not found")
+			return
+		}
+
+		if apiKey.RevokedAt != nil {
+			svrUtils.SendJsonResponse(w, http.StatusOK, &apiKey, a.ResourceNames.Singular+" is already revoked")
+			return
+		}
+
+		// 4. Update State
+		previousState := apiKey
+		now := time.Now()
+		apiKey.RevokedAt = &now
+		apiKey.UpdatedByID = user.ID
+
+		differences := utils.CompareInterfaces(previousState, apiKey)
+		err = dbQueries.Update(r.Context(), log, db, &apiKey, user, differences, requestId)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error revoking api key")
+			return
+		}
+
+		svrUtils.SendJsonResponse(w, http.StatusOK, &apiKey, a.ResourceNames.Singular+" has been revoked")
+	}
+}
@@ -0,0 +1,200 @@
+package auth_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authHandlers "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/handlers"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authResources "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/resources"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	testPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/testing"
+)
+
+type apiKeyResponse struct {
+	Data authHandlers.ApiKeyCreated `json:"data"`
+}
+
+func TestApiKeyCreateHandler(t *testing.T) {
+	bed := testPkg.SetupAuthTestBed()
+
+	resource, err := bed.Mgr.AddResource(authResources.SetupApiKeyResource(bed.Mgr, bed.Db, bed.Logger))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		method         string
+		requestBody    string
+		user           *authModels.User
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Invalid Method",
+			method:         http.MethodGet,
+			requestBody:    `{}`,
+			user:           bed.AdminUser,
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedBody:   "Method not allowed",
+		},
+		{
+			name:           "Visitors are not allowed",
+			method:         http.MethodPost,
+			requestBody:    fmt.Sprintf(`{"name": "ci", "userId": %d}`, bed.VisitorUser.ID),
+			user:           bed.VisitorUser,
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "User is not allowed to create this resource",
+		},
+		{
+			name:           "Missing user",
+			method:         http.MethodPost,
+			requestBody:    `{"name": "ci"}`,
+			user:           bed.AdminUser,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Name and user are required",
+		},
+		{
+			name:           "Invalid scope",
+			method:         http.MethodPost,
+			requestBody:    fmt.Sprintf(`{"name": "ci", "userId": %d, "scopes": ["Post:publish"]}`, bed.VisitorUser.ID),
+			user:           bed.AdminUser,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid scope",
+		},
+		{
+			name:           "Scope on an unknown resource",
+			method:         http.MethodPost,
+			requestBody:    fmt.Sprintf(`{"name": "ci", "userId": %d, "scopes": ["Post:read"]}`, bed.VisitorUser.ID),
+			user:           bed.AdminUser,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Unknown resource in scope Post:read",
+		},
+		{
+			name:           "Scopes the user isn't allowed",
+			method:         http.MethodPost,
+			requestBody:    fmt.Sprintf(`{"name": "ci", "userId": %d, "scopes": ["User:read", "ApiKey:create"]}`, bed.VisitorUser.ID),
+			user:           bed.AdminUser,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "User is not allowed to create ApiKey, it can't be scoped to it",
+		},
+		{
+			name:           "Roles the user doesn't hold",
+			method:         http.MethodPost,
+			requestBody:    fmt.Sprintf(`{"name": "ci", "userId": %d, "roles": ["admin"]}`, bed.VisitorUser.ID),
+			user:           bed.AdminUser,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "User doesn't hold role admin",
+		},
+		{
+			name:           "Unknown user",
+			method:         http.MethodPost,
+			requestBody:    `{"name": "ci", "userId": 999999}`,
+			user:           bed.AdminUser,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "User not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := testPkg.CreateTestRequest(t, tt.method, "/api/api-keys/new", tt.requestBody, true, tt.user, bed.Logger)
+			rr := testPkg.ExecuteHandler(t, authHandlers.ApiKeyCreateHandler(bed.Mgr)(resource, bed.Db), req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestApiKeyCreateHandler_MfaRequiredRoles(t *testing.T) {
+	bed := testPkg.SetupAuthTestBed()
+
+	resource, err := bed.Mgr.AddResource(authResources.SetupApiKeyResource(bed.Mgr, bed.Db, bed.Logger))
+	assert.NoError(t, err)
+
+	authUtils.SetMfaRequiredRoles(authConstants.SchedulerRole)
+	t.Cleanup(func() { authUtils.SetMfaRequiredRoles() })
+
+	scheduler := testPkg.CreateSchedulerUser()
+	assert.NoError(t, bed.Db.DB.Create(scheduler).Error)
+
+	create := func(user *authModels.User, body string) *httptest.ResponseRecorder {
+		req := testPkg.CreateTestRequest(t, http.MethodPost, "/api/api-keys/new", body, true, user, bed.Logger)
+		return testPkg.ExecuteHandler(t, authHandlers.ApiKeyCreateHandler(bed.Mgr)(resource, bed.Db), req)
+	}
+
+	// Keys skip the MFA policy, so only creators holding the role with a second factor can grant it,
+	// whether explicitly or through the roles of the user, scoped or not
+	for _, body := range []string{
+		fmt.Sprintf(`{"name": "ci", "userId": %d, "roles": ["scheduler"]}`, scheduler.ID),
+		fmt.Sprintf(`{"name": "ci", "userId": %d}`, scheduler.ID),
+		fmt.Sprintf(`{"name": "ci", "userId": %d, "scopes": ["User:read"]}`, scheduler.ID),
+	} {
+		rr := create(bed.AdminUser, body)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Granting role scheduler requires holding it with a second factor")
+	}
+
+	creator := testPkg.CreateUser("Creator", authConstants.AdminRole.S()+","+authConstants.SchedulerRole.S())
+	rr := create(creator, fmt.Sprintf(`{"name": "ci", "userId": %d, "roles": ["scheduler"]}`, scheduler.ID))
+	assert.Equal(t, http.StatusCreated, rr.Code)
+}
+
+func TestApiKeyLifecycle(t *testing.T) {
+	bed := testPkg.SetupAuthTestBed()
+
+	resource, err := bed.Mgr.AddResource(authResources.SetupApiKeyResource(bed.Mgr, bed.Db, bed.Logger))
+	assert.NoError(t, err)
+
+	// Create
+	body := fmt.Sprintf(`{"name": "ci", "userId": %d, "scopes": ["User:read"], "allowedIps": ["127.0.0.1"]}`, bed.VisitorUser.ID)
+	req := testPkg.CreateTestRequest(t, http.MethodPost, "/api/api-keys/new", body, true, bed.AdminUser, bed.Logger)
+	rr := testPkg.ExecuteHandler(t, authHandlers.ApiKeyCreateHandler(bed.Mgr)(resource, bed.Db), req)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+
+	created := apiKeyResponse{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+	assert.True(t, strings.HasPrefix(created.Data.Key, created.Data.Prefix))
+	assert.NotContains(t, rr.Body.String(), authUtils.HashApiKey(created.Data.Key))
+
+	_, err = authUtils.VerifyApiKey(created.Data.Key, "127.0.0.1", bed.Db, bed.Logger)
+	assert.NoError(t, err)
+
+	// Revoke
+	router := mux.NewRouter()
+	router.HandleFunc("/api/api-keys/{id}/revoke", authHandlers.ApiKeyRevokeHandler(bed.Mgr, bed.Db))
+
+	path := fmt.Sprintf("/api/api-keys/%d/revoke", created.Data.ID)
+	req = testPkg.CreateTestRequest(t, http.MethodPut, path, "", true, bed.VisitorUser, bed.Logger)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	req = testPkg.CreateTestRequest(t, http.MethodPut, path, "", true, bed.AdminUser, bed.Logger)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	_, err = authUtils.VerifyApiKey(created.Data.Key, "127.0.0.1", bed.Db, bed.Logger)
+	assert.ErrorIs(t, err, authUtils.ErrApiKeyInactive)
+
+	// Keys can't be updated, so revocation can't be undone
+	router.HandleFunc("/api/api-keys/{id}", resource.Api.Update(resource, bed.Db))
+
+	body = fmt.Sprintf(`{"name": "ci", "userId": %d, "roles": "admin", "revokedAt": null}`, bed.VisitorUser.ID)
+	req = testPkg.CreateTestRequest(t, http.MethodPut, fmt.Sprintf("/api/api-keys/%d", created.Data.ID), body, true, bed.AdminUser, bed.Logger)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	_, err = authUtils.VerifyApiKey(created.Data.Key, "127.0.0.1", bed.Db, bed.Logger)
+	assert.ErrorIs(t, err, authUtils.ErrApiKeyInactive)
+}
@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	dbQueries "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/queries"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
+)
+
+type ImpersonateInput struct {
+	Reason string `json:"reason"`
+}
+
+// ImpersonationStarted is returned once on creation, it is the only time the token is shown.
+type ImpersonationStarted struct {
+	Session   *authModels.ImpersonationSession `json:"session"`
+	Token     string                           `json:"token"`
+	ExpiresAt time.Time                        `json:"expiresAt"`
+}
+
+// ImpersonateHandler lets an admin act as another user, the returned token is sent as
+// "Authorization: Impersonate <token>". Requests made with it are logged with both users.
+func ImpersonateHandler(db *dbTypes.DatabaseConnection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestCtx := svrUtils.GetRequestContext(r)
+		log := requestCtx.Logger
+		user := requestCtx.User
+		requestId := requestCtx.RequestId
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodPost)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		// 2. Check Permissions
+		if user == nil || user.ImpersonatedBy != nil || !user.HasRole(authConstants.AdminRole) {
+			svrUtils.SendJsonResponse(w, http.StatusForbidden, nil, "Only admins can impersonate users")
+			return
+		}
+
+		// 3. Parse Request Body
+		input := ImpersonateInput{}
+		bodyBytes, err := svrUtils.ReadRequestBody(r)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, "Invalid request body")
+			return
+		}
+
+		if len(bodyBytes) > 0 {
+			err = json.Unmarshal(bodyBytes, &input)
+			if err != nil {
+				svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, "Invalid request body")
+				return
+			}
+		}
+
+		// 4. Find Target User
+		target := authModels.User{}
+		err = dbQueries.FindOne(r.Context(), log, db, &target, map[string]interface{}{"id": svrUtils.GetUrlParam("id", r)}, []string{})
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusNotFound, nil, "User not found")
+			return
+		}
+
+		authUtils.LoadUserRoles(r.Context(), db, &target, log)
+		if target.ID == user.ID || target.HasRole(authConstants.AdminRole) {
+			svrUtils.SendJsonResponse(w, http.StatusForbidden, nil, "Admins can't be impersonated")
+			return
+		}
+
+		// 5. Start Session
+		session, token, err := authUtils.StartImpersonation(r.Context(), user, &target, input.Reason, db, requestId, log)
+		if err != nil {
+			log.Error().Err(err).Msg("Error starting impersonation")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error starting impersonation")
+			return
+		}
+
+		log.Info().Uint("admin", user.ID).Uint("user", target.ID).Msg("Impersonation started")
+
+		output := &ImpersonationStarted{
+			Session:   session,
+			Token:     token,
+			ExpiresAt: session.ExpiresAt,
+		}
+
+		svrUtils.SendJsonResponse(w, http.StatusCreated, output, "Impersonation has started, the token won't be shown again")
+	}
+}
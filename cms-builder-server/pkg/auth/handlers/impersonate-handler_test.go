@@ -0,0 +1,106 @@
+package auth_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	authHandlers "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/handlers"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	testPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/testing"
+)
+
+type impersonationResponse struct {
+	Data authHandlers.ImpersonationStarted `json:"data"`
+}
+
+func TestImpersonateHandler(t *testing.T) {
+	bed := testPkg.SetupAuthTestBed()
+	assert.NoError(t, bed.Db.DB.AutoMigrate(&authModels.ImpersonationSession{}, &authModels.UserRole{}))
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/users/{id}/impersonate", authHandlers.ImpersonateHandler(bed.Db))
+
+	impersonating := *bed.VisitorUser
+	impersonating.ImpersonatedBy = bed.AdminUser
+
+	tests := []struct {
+		name           string
+		method         string
+		target         uint
+		user           *authModels.User
+		expectedStatus int
+	}{
+		{
+			name:           "Invalid Method",
+			method:         http.MethodGet,
+			target:         bed.VisitorUser.ID,
+			user:           bed.AdminUser,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:           "Visitors are not allowed",
+			method:         http.MethodPost,
+			target:         bed.NoRoleUser.ID,
+			user:           bed.VisitorUser,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Impersonations can't be nested",
+			method:         http.MethodPost,
+			target:         bed.NoRoleUser.ID,
+			user:           &impersonating,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Admins can't be impersonated",
+			method:         http.MethodPost,
+			target:         bed.AdminUser.ID,
+			user:           bed.AdminUser,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "User not found",
+			method:         http.MethodPost,
+			target:         0,
+			user:           bed.AdminUser,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Success",
+			method:         http.MethodPost,
+			target:         bed.VisitorUser.ID,
+			user:           bed.AdminUser,
+			expectedStatus: http.StatusCreated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := fmt.Sprintf("/api/users/%d/impersonate", tt.target)
+			req := testPkg.CreateTestRequest(t, tt.method, path, `{"reason": "support ticket"}`, true, tt.user, bed.Logger)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+			assert.Equal(t, tt.expectedStatus, rr.Code, rr.Body.String())
+
+			if tt.expectedStatus != http.StatusCreated {
+				return
+			}
+
+			response := impersonationResponse{}
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+			assert.Equal(t, "support ticket", response.Data.Session.Reason)
+
+			user, err := authUtils.VerifyImpersonation(response.Data.Token, bed.Db, bed.Logger)
+			assert.NoError(t, err)
+			assert.Equal(t, bed.VisitorUser.ID, user.ID)
+			assert.Equal(t, bed.AdminUser.ID, user.ImpersonatedBy.ID)
+		})
+	}
+}
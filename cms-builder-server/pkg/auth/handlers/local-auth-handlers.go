@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	authProviders "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/providers"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
+)
+
+type LoginInput struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Code     string `json:"code"` // TOTP or recovery code, required once MFA is enrolled
+}
+
+type RefreshInput struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// LoginHandler starts a session for the user with the given email and password.
+func LoginHandler(provider *authProviders.LocalProvider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := svrUtils.GetRequestLogger(r)
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodPost)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		// 2. Parse Request Body
+		bodyBytes, err := svrUtils.ReadRequestBody(r)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, "Invalid request body")
+			return
+		}
+
+		input := LoginInput{}
+		err = json.Unmarshal(bodyBytes, &input)
+		if err != nil || input.Email == "" || input.Password == "" {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, "Email and password are required")
+			return
+		}
+
+		// 3. Login
+		session, err := provider.Login(r.Context(), input.Email, input.Password, input.Code)
+		if errors.Is(err, authProviders.ErrInvalidCredentials) || errors.Is(err, authUtils.ErrMfaRequired) || errors.Is(err, authUtils.ErrInvalidMfaCode) {
+			svrUtils.SendJsonResponse(w, http.StatusUnauthorized, nil, err.Error())
+			return
+		}
+		if errors.Is(err, authUtils.ErrMfaLocked) {
+			svrUtils.SendJsonResponse(w, http.StatusTooManyRequests, nil, err.Error())
+			return
+		}
+		if err != nil {
+			log.Error().Err(err).Msg("Error logging in")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error logging in")
+			return
+		}
+
+		svrUtils.SendJsonResponse(w, http.StatusOK, session, "Logged in successfully")
+	}
+}
+
+// RefreshHandler rotates the refresh token, returning a new access token along with the next refresh token.
+func RefreshHandler(provider *authProviders.LocalProvider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := svrUtils.GetRequestLogger(r)
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodPost)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		// 2. Parse Request Body
+		input, err := readRefreshInput(r)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, err.Error())
+			return
+		}
+
+		// 3. Refresh
+		session, err := provider.Refresh(r.Context(), input.RefreshToken)
+		if errors.Is(err, authProviders.ErrInvalidRefreshToken) {
+			svrUtils.SendJsonResponse(w, http.StatusUnauthorized, nil, err.Error())
+			return
+		}
+		if err != nil {
+			log.Error().Err(err).Msg("Error refreshing session")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error refreshing session")
+			return
+		}
+
+		svrUtils.SendJsonResponse(w, http.StatusOK, session, "Session refreshed successfully")
+	}
+}
+
+// LogoutHandler revokes the session of the given refresh token.
+// Access tokens already issued stay valid until they expire.
+func LogoutHandler(provider *authProviders.LocalProvider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := svrUtils.GetRequestLogger(r)
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodPost)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		// 2. Parse Request Body
+		input, err := readRefreshInput(r)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, err.Error())
+			return
+		}
+
+		// 3. Logout
+		err = provider.Logout(r.Context(), input.RefreshToken)
+		if errors.Is(err, authProviders.ErrInvalidRefreshToken) {
+			svrUtils.SendJsonResponse(w, http.StatusUnauthorized, nil, err.Error())
+			return
+		}
+		if err != nil {
+			log.Error().Err(err).Msg("Error logging out")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error logging out")
+			return
+		}
+
+		svrUtils.SendJsonResponse(w, http.StatusOK, nil, "Logged out successfully")
+	}
+}
+
+func readRefreshInput(r *http.Request) (*RefreshInput, error) {
+	bodyBytes, err := svrUtils.ReadRequestBody(r)
+	if err != nil {
+		return nil, errors.New("invalid request body")
+	}
+
+	input := RefreshInput{}
+	err = json.Unmarshal(bodyBytes, &input)
+	if err != nil || input.RefreshToken == "" {
+		return nil, errors.New("refresh token is required")
+	}
+
+	return &input, nil
+}
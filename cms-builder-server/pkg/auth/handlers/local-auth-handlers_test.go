@@ -0,0 +1,93 @@
+package auth_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authHandlers "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/handlers"
+	authProviders "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/providers"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	testPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/testing"
+)
+
+type sessionResponse struct {
+	Data authProviders.Session `json:"data"`
+}
+
+func TestLocalAuthHandlers(t *testing.T) {
+	bed := testPkg.SetupAuthTestBed()
+
+	provider, err := authProviders.NewLocalProvider(bed.Db, authProviders.LocalProviderConfig{Secret: "test-secret"})
+	assert.NoError(t, err)
+
+	email := strings.ToLower(testPkg.RandomEmail())
+	_, err = authUtils.CreateUserWithRole(authTypes.RegisterUserInput{
+		FirstName:        "Jane",
+		Email:            email,
+		Password:         "secret-password",
+		Roles:            []authTypes.Role{authConstants.VisitorRole},
+		RegisterFirebase: true,
+	}, provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+	assert.NoError(t, err)
+
+	// Login
+	body := fmt.Sprintf(`{"email": "%s", "password": "wrong"}`, email)
+	req := testPkg.CreateTestRequest(t, http.MethodPost, "/auth/login", body, false, nil, bed.Logger)
+	rr := testPkg.ExecuteHandler(t, authHandlers.LoginHandler(provider), req)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	body = fmt.Sprintf(`{"email": "%s", "password": "secret-password"}`, email)
+	req = testPkg.CreateTestRequest(t, http.MethodPost, "/auth/login", body, false, nil, bed.Logger)
+	rr = testPkg.ExecuteHandler(t, authHandlers.LoginHandler(provider), req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	login := sessionResponse{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &login))
+	assert.NotEmpty(t, login.Data.AccessToken)
+
+	// Refresh
+	body = fmt.Sprintf(`{"refreshToken": "%s"}`, login.Data.RefreshToken)
+	req = testPkg.CreateTestRequest(t, http.MethodPost, "/auth/refresh", body, false, nil, bed.Logger)
+	rr = testPkg.ExecuteHandler(t, authHandlers.RefreshHandler(provider), req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	refreshed := sessionResponse{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &refreshed))
+	assert.NotEqual(t, login.Data.RefreshToken, refreshed.Data.RefreshToken)
+
+	// Logout
+	body = fmt.Sprintf(`{"refreshToken": "%s"}`, refreshed.Data.RefreshToken)
+	req = testPkg.CreateTestRequest(t, http.MethodPost, "/auth/logout", body, false, nil, bed.Logger)
+	rr = testPkg.ExecuteHandler(t, authHandlers.LogoutHandler(provider), req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	req = testPkg.CreateTestRequest(t, http.MethodPost, "/auth/refresh", body, false, nil, bed.Logger)
+	rr = testPkg.ExecuteHandler(t, authHandlers.RefreshHandler(provider), req)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+}
+
+func TestLocalAuthHandlers_Errors(t *testing.T) {
+	bed := testPkg.SetupAuthTestBed()
+
+	provider, err := authProviders.NewLocalProvider(bed.Db, authProviders.LocalProviderConfig{Secret: "test-secret"})
+	assert.NoError(t, err)
+
+	req := testPkg.CreateTestRequest(t, http.MethodGet, "/auth/login", "", false, nil, bed.Logger)
+	rr := testPkg.ExecuteHandler(t, authHandlers.LoginHandler(provider), req)
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+
+	req = testPkg.CreateTestRequest(t, http.MethodPost, "/auth/login", `{"email": ""}`, false, nil, bed.Logger)
+	rr = testPkg.ExecuteHandler(t, authHandlers.LoginHandler(provider), req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	req = testPkg.CreateTestRequest(t, http.MethodPost, "/auth/refresh", `{}`, false, nil, bed.Logger)
+	rr = testPkg.ExecuteHandler(t, authHandlers.RefreshHandler(provider), req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
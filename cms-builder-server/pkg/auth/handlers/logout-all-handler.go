@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"net/http"
+
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
+)
+
+// LogoutAllHandler signs the current user out of every device, the user has to sign in again.
+func LogoutAllHandler(db *dbTypes.DatabaseConnection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestCtx := svrUtils.GetRequestContext(r)
+		log := requestCtx.Logger
+		user := requestCtx.User
+		requestId := requestCtx.RequestId
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodPost)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		if user == nil || user.ID == 0 {
+			svrUtils.SendJsonResponse(w, http.StatusUnauthorized, nil, "User is not authenticated")
+			return
+		}
+
+		// Admins impersonating the user can't sign them out
+		if user.ImpersonatedBy != nil {
+			svrUtils.SendJsonResponse(w, http.StatusForbidden, nil, "Sessions can't be revoked while impersonating")
+			return
+		}
+
+		// 2. Revoke Sessions
+		err = authUtils.RevokeUserSessions(r.Context(), db, user, user, "logout all devices", requestId, log)
+		if err != nil {
+			log.Error().Err(err).Msg("Error revoking sessions")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error logging out")
+			return
+		}
+
+		svrUtils.SendJsonResponse(w, http.StatusOK, nil, "Logged out of every device")
+	}
+}
@@ -0,0 +1,142 @@
+package auth_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authHandlers "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/handlers"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	testPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/testing"
+)
+
+func countRevocations(bed testPkg.TestUtils, user *authModels.User) int64 {
+	var count int64
+	bed.Db.DB.Model(&authModels.SessionRevocation{}).Where("user_id = ?", user.ID).Count(&count)
+	return count
+}
+
+// failRevocations makes every session revocation stored through the connection of the bed fail.
+func failRevocations(bed testPkg.TestUtils) {
+	bed.Db.DB.Callback().Create().Before("gorm:create").Register("test:fail_revocations", func(tx *gorm.DB) {
+		if tx.Statement.Table == "session_revocations" {
+			_ = tx.AddError(errors.New("revocation failed"))
+		}
+	})
+}
+
+func TestLogoutAllHandler(t *testing.T) {
+	bed := testPkg.SetupAuthTestBed()
+	handler := authHandlers.LogoutAllHandler(bed.Db)
+
+	impersonating := *bed.VisitorUser
+	impersonating.ImpersonatedBy = bed.AdminUser
+
+	tests := []struct {
+		name                string
+		method              string
+		user                *authModels.User
+		expectedStatus      int
+		expectedRevocations int64
+	}{
+		{
+			name:           "Invalid Method",
+			method:         http.MethodGet,
+			user:           bed.VisitorUser,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:           "Anonymous",
+			method:         http.MethodPost,
+			user:           &authModels.User{},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Impersonating",
+			method:         http.MethodPost,
+			user:           &impersonating,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:                "Success",
+			method:              http.MethodPost,
+			user:                bed.VisitorUser,
+			expectedStatus:      http.StatusOK,
+			expectedRevocations: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := testPkg.CreateTestRequest(t, tt.method, "/auth/logout-all", "", true, tt.user, bed.Logger)
+			rr := testPkg.ExecuteHandler(t, handler, req)
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Equal(t, tt.expectedRevocations, countRevocations(bed, bed.VisitorUser))
+		})
+	}
+}
+
+func TestUserHandlers_RevokeSessions(t *testing.T) {
+	bed := testPkg.SetupAuthTestBed()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/user/{id}", authHandlers.UserUpdateHandler(bed.Src, bed.Db)).Methods(http.MethodPut)
+	router.HandleFunc("/user/{id}", authHandlers.UserDeleteHandler(bed.Src, bed.Db)).Methods(http.MethodDelete)
+
+	instance := testPkg.CreateVisitorUser()
+	assert.NoError(t, bed.Db.DB.Create(instance).Error)
+	path := "/user/" + instance.StringID()
+
+	send := func(method string, body string) {
+		req := testPkg.CreateTestRequest(t, method, path, body, true, bed.AdminUser, bed.Logger)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	// Other changes keep the sessions
+	send(http.MethodPut, fmt.Sprintf(`{"firstName": "Renamed", "email": "%s", "roles": "%s"}`, instance.Email, authConstants.VisitorRole))
+	assert.Equal(t, int64(0), countRevocations(bed, instance))
+
+	// Role changes and deletion revoke them
+	send(http.MethodPut, fmt.Sprintf(`{"firstName": "Renamed", "email": "%s", "roles": "%s"}`, instance.Email, authConstants.AdminRole))
+	assert.Equal(t, int64(1), countRevocations(bed, instance))
+
+	send(http.MethodDelete, "")
+	assert.Equal(t, int64(2), countRevocations(bed, instance))
+}
+
+func TestUserHandlers_RevokeSessionsFails(t *testing.T) {
+	bed := testPkg.SetupAuthTestBed()
+	failRevocations(bed)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/user/{id}", authHandlers.UserUpdateHandler(bed.Src, bed.Db)).Methods(http.MethodPut)
+	router.HandleFunc("/user/{id}", authHandlers.UserDeleteHandler(bed.Src, bed.Db)).Methods(http.MethodDelete)
+
+	instance := testPkg.CreateVisitorUser()
+	assert.NoError(t, bed.Db.DB.Create(instance).Error)
+	path := "/user/" + instance.StringID()
+
+	send := func(method string, body string) {
+		req := testPkg.CreateTestRequest(t, method, path, body, true, bed.AdminUser, bed.Logger)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusInternalServerError, rr.Code, rr.Body.String())
+	}
+
+	// Neither the role change nor the deletion is stored while the old sessions are still valid
+	send(http.MethodPut, fmt.Sprintf(`{"firstName": "Renamed", "email": "%s", "roles": "%s"}`, instance.Email, authConstants.AdminRole))
+	send(http.MethodDelete, "")
+
+	stored := authModels.User{}
+	assert.NoError(t, bed.Db.DB.First(&stored, instance.ID).Error)
+	assert.Equal(t, instance.Roles, stored.Roles)
+}
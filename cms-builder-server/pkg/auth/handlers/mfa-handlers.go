@@ -0,0 +1,185 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
+)
+
+type MfaCodeInput struct {
+	Code string `json:"code"`
+}
+
+// MfaEnrollment is returned when enrolling, the secret is added to an authenticator app,
+// usually by scanning the otpauth URI as a QR code.
+type MfaEnrollment struct {
+	Secret string `json:"secret"`
+	Uri    string `json:"otpauthUri"`
+}
+
+type MfaRecoveryCodes struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// MfaEnrollHandler starts the MFA enrollment of the current user, it has to be confirmed with a first code.
+func MfaEnrollHandler(db *dbTypes.DatabaseConnection, issuer string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestCtx := svrUtils.GetRequestContext(r)
+		log := requestCtx.Logger
+		user := requestCtx.User
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodPost)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		if !canManageMfa(w, user) {
+			return
+		}
+
+		// 2. Enroll
+		enrollment, err := authUtils.EnrollMfa(r.Context(), db, user, requestCtx.RequestId, log)
+		if errors.Is(err, authUtils.ErrMfaAlreadyEnrolled) {
+			svrUtils.SendJsonResponse(w, http.StatusConflict, nil, err.Error())
+			return
+		}
+		if err != nil {
+			log.Error().Err(err).Msg("Error enrolling mfa")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error enrolling mfa")
+			return
+		}
+
+		output := &MfaEnrollment{
+			Secret: enrollment.Secret,
+			Uri:    authUtils.TotpUri(issuer, user.Email, enrollment.Secret),
+		}
+
+		svrUtils.SendJsonResponse(w, http.StatusCreated, output, "Confirm the enrollment with a code of your authenticator")
+	}
+}
+
+// MfaConfirmHandler confirms the enrollment of the current user, returning the recovery codes.
+// Codes are required to sign in from then on.
+func MfaConfirmHandler(db *dbTypes.DatabaseConnection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestCtx := svrUtils.GetRequestContext(r)
+		log := requestCtx.Logger
+		user := requestCtx.User
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodPost)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		if !canManageMfa(w, user) {
+			return
+		}
+
+		// 2. Parse Request Body
+		input, err := readMfaCodeInput(r)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, err.Error())
+			return
+		}
+
+		// 3. Confirm
+		codes, err := authUtils.ConfirmMfa(r.Context(), db, user, input.Code, requestCtx.RequestId, log)
+		if err != nil {
+			sendMfaError(w, r, err, "Error confirming mfa")
+			return
+		}
+
+		svrUtils.SendJsonResponse(w, http.StatusOK, &MfaRecoveryCodes{RecoveryCodes: codes}, "Mfa has been enabled, the recovery codes won't be shown again")
+	}
+}
+
+// MfaDisableHandler removes the MFA enrollment of the current user, after checking a code.
+func MfaDisableHandler(db *dbTypes.DatabaseConnection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestCtx := svrUtils.GetRequestContext(r)
+		log := requestCtx.Logger
+		user := requestCtx.User
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodPost)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		if !canManageMfa(w, user) {
+			return
+		}
+
+		// 2. Parse Request Body
+		input, err := readMfaCodeInput(r)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, err.Error())
+			return
+		}
+
+		// 3. Disable
+		err = authUtils.DisableMfa(r.Context(), db, user, input.Code, requestCtx.RequestId, log)
+		if err != nil {
+			sendMfaError(w, r, err, "Error disabling mfa")
+			return
+		}
+
+		svrUtils.SendJsonResponse(w, http.StatusOK, nil, "Mfa has been disabled")
+	}
+}
+
+// canManageMfa rejects anonymous requests, and admins acting as the user.
+func canManageMfa(w http.ResponseWriter, user *authModels.User) bool {
+	if user == nil || user.ID == 0 {
+		svrUtils.SendJsonResponse(w, http.StatusUnauthorized, nil, "User is not authenticated")
+		return false
+	}
+
+	if user.ImpersonatedBy != nil {
+		svrUtils.SendJsonResponse(w, http.StatusForbidden, nil, "Mfa can't be managed while impersonating")
+		return false
+	}
+
+	return true
+}
+
+func sendMfaError(w http.ResponseWriter, r *http.Request, err error, msg string) {
+	switch {
+	case errors.Is(err, authUtils.ErrInvalidMfaCode):
+		svrUtils.SendJsonResponse(w, http.StatusUnauthorized, nil, err.Error())
+	case errors.Is(err, authUtils.ErrMfaLocked):
+		svrUtils.SendJsonResponse(w, http.StatusTooManyRequests, nil, err.Error())
+	case errors.Is(err, authUtils.ErrMfaNotEnrolled):
+		svrUtils.SendJsonResponse(w, http.StatusNotFound, nil, err.Error())
+	case errors.Is(err, authUtils.ErrMfaAlreadyEnrolled):
+		svrUtils.SendJsonResponse(w, http.StatusConflict, nil, err.Error())
+	default:
+		svrUtils.GetRequestLogger(r).Error().Err(err).Msg(msg)
+		svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, msg)
+	}
+}
+
+func readMfaCodeInput(r *http.Request) (*MfaCodeInput, error) {
+	bodyBytes, err := svrUtils.ReadRequestBody(r)
+	if err != nil {
+		return nil, errors.New("invalid request body")
+	}
+
+	input := MfaCodeInput{}
+	err = json.Unmarshal(bodyBytes, &input)
+	if err != nil || input.Code == "" {
+		return nil, errors.New("code is required")
+	}
+
+	return &input, nil
+}
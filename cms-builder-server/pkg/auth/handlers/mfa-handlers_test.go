@@ -0,0 +1,112 @@
+package auth_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authHandlers "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/handlers"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authProviders "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/providers"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	testPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/testing"
+)
+
+type mfaEnrollmentResponse struct {
+	Data authHandlers.MfaEnrollment `json:"data"`
+}
+
+type mfaRecoveryCodesResponse struct {
+	Data authHandlers.MfaRecoveryCodes `json:"data"`
+}
+
+func TestMfaHandlers(t *testing.T) {
+	bed := testPkg.SetupAuthTestBed()
+
+	provider, err := authProviders.NewLocalProvider(bed.Db, authProviders.LocalProviderConfig{Secret: "test-secret", Issuer: "cms"})
+	assert.NoError(t, err)
+
+	email := strings.ToLower(testPkg.RandomEmail())
+	user, err := authUtils.CreateUserWithRole(authTypes.RegisterUserInput{
+		FirstName:        "Jane",
+		Email:            email,
+		Password:         "secret-password",
+		Roles:            []authTypes.Role{authConstants.AdminRole},
+		RegisterFirebase: true,
+	}, provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+	assert.NoError(t, err)
+
+	impersonating := *user
+	impersonating.ImpersonatedBy = bed.AdminUser
+
+	// Enroll
+	req := testPkg.CreateTestRequest(t, http.MethodPost, "/auth/mfa/enroll", "", true, &authModels.User{}, bed.Logger)
+	rr := testPkg.ExecuteHandler(t, authHandlers.MfaEnrollHandler(bed.Db, "cms"), req)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	req = testPkg.CreateTestRequest(t, http.MethodPost, "/auth/mfa/enroll", "", true, &impersonating, bed.Logger)
+	rr = testPkg.ExecuteHandler(t, authHandlers.MfaEnrollHandler(bed.Db, "cms"), req)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	req = testPkg.CreateTestRequest(t, http.MethodPost, "/auth/mfa/enroll", "", true, user, bed.Logger)
+	rr = testPkg.ExecuteHandler(t, authHandlers.MfaEnrollHandler(bed.Db, "cms"), req)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+
+	enrollment := mfaEnrollmentResponse{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &enrollment))
+	assert.Contains(t, enrollment.Data.Uri, "otpauth://totp/cms:")
+
+	// Confirm
+	req = testPkg.CreateTestRequest(t, http.MethodPost, "/auth/mfa/confirm", `{"code": "000000"}`, true, user, bed.Logger)
+	rr = testPkg.ExecuteHandler(t, authHandlers.MfaConfirmHandler(bed.Db), req)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	code, err := authUtils.TotpCode(enrollment.Data.Secret, time.Now())
+	assert.NoError(t, err)
+
+	req = testPkg.CreateTestRequest(t, http.MethodPost, "/auth/mfa/confirm", fmt.Sprintf(`{"code": "%s"}`, code), true, user, bed.Logger)
+	rr = testPkg.ExecuteHandler(t, authHandlers.MfaConfirmHandler(bed.Db), req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	recovery := mfaRecoveryCodesResponse{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &recovery))
+	assert.Len(t, recovery.Data.RecoveryCodes, 10)
+
+	req = testPkg.CreateTestRequest(t, http.MethodPost, "/auth/mfa/enroll", "", true, user, bed.Logger)
+	rr = testPkg.ExecuteHandler(t, authHandlers.MfaEnrollHandler(bed.Db, "cms"), req)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+
+	// Login requires a code
+	body := fmt.Sprintf(`{"email": "%s", "password": "secret-password"}`, email)
+	req = testPkg.CreateTestRequest(t, http.MethodPost, "/auth/login", body, false, nil, bed.Logger)
+	rr = testPkg.ExecuteHandler(t, authHandlers.LoginHandler(provider), req)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Contains(t, rr.Body.String(), authUtils.ErrMfaRequired.Error())
+
+	body = fmt.Sprintf(`{"email": "%s", "password": "secret-password", "code": "%s"}`, email, recovery.Data.RecoveryCodes[0])
+	req = testPkg.CreateTestRequest(t, http.MethodPost, "/auth/login", body, false, nil, bed.Logger)
+	rr = testPkg.ExecuteHandler(t, authHandlers.LoginHandler(provider), req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	// Disable
+	req = testPkg.CreateTestRequest(t, http.MethodPost, "/auth/mfa/disable", `{}`, true, user, bed.Logger)
+	rr = testPkg.ExecuteHandler(t, authHandlers.MfaDisableHandler(bed.Db), req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	body = fmt.Sprintf(`{"code": "%s"}`, recovery.Data.RecoveryCodes[1])
+	req = testPkg.CreateTestRequest(t, http.MethodPost, "/auth/mfa/disable", body, true, user, bed.Logger)
+	rr = testPkg.ExecuteHandler(t, authHandlers.MfaDisableHandler(bed.Db), req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	body = fmt.Sprintf(`{"email": "%s", "password": "secret-password"}`, email)
+	req = testPkg.CreateTestRequest(t, http.MethodPost, "/auth/login", body, false, nil, bed.Logger)
+	rr = testPkg.ExecuteHandler(t, authHandlers.LoginHandler(provider), req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
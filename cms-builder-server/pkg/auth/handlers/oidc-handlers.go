@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	authProviders "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/providers"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
+)
+
+type OIDCCodeInput struct {
+	Code         string `json:"code"`
+	RedirectUri  string `json:"redirectUri"`
+	CodeVerifier string `json:"codeVerifier"` // PKCE verifier, optional
+}
+
+// OIDCTokenHandler exchanges the authorization code of a login for tokens, so clients don't need the client secret.
+// The returned ID token authenticates later requests. Signing in lifts the revocations of tokens
+// without an auth time, see authUtils.CheckRevoked.
+func OIDCTokenHandler(provider *authProviders.OIDCProvider, db *dbTypes.DatabaseConnection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := svrUtils.GetRequestLogger(r)
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodPost)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		// 2. Parse Request Body
+		bodyBytes, err := svrUtils.ReadRequestBody(r)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, "Invalid request body")
+			return
+		}
+
+		input := OIDCCodeInput{}
+		err = json.Unmarshal(bodyBytes, &input)
+		if err != nil || input.Code == "" || input.RedirectUri == "" {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, "Code and redirect uri are required")
+			return
+		}
+
+		// 3. Exchange Code
+		tokens, err := provider.ExchangeCode(r.Context(), input.Code, input.RedirectUri, input.CodeVerifier)
+		if errors.Is(err, authProviders.ErrCodeExchangeFailure) || errors.Is(err, authTypes.ErrProviderInvalidToken) || errors.Is(err, authProviders.ErrEmailNotVerified) {
+			log.Warn().Err(err).Msg("Error exchanging authorization code")
+			svrUtils.SendJsonResponse(w, http.StatusUnauthorized, nil, err.Error())
+			return
+		}
+		if err != nil {
+			log.Error().Err(err).Msg("Error exchanging authorization code")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error exchanging authorization code")
+			return
+		}
+
+		// 4. Lift Session Revocations
+		err = authUtils.LiftSessionRevocations(r.Context(), db, tokens.Subject, log)
+		if err != nil {
+			log.Error().Err(err).Msg("Error lifting session revocations")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error exchanging authorization code")
+			return
+		}
+
+		svrUtils.SendJsonResponse(w, http.StatusOK, tokens, "Logged in successfully")
+	}
+}
@@ -9,12 +9,11 @@ import (
 	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
 	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
 	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
-	cliPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/clients"
 	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
 	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
 )
 
-func RegisterVisitorController(firebase *cliPkg.FirebaseManager, db *dbTypes.DatabaseConnection, getSystemUser func() *authModels.User) http.HandlerFunc {
+func RegisterVisitorController(provider authTypes.AuthProvider, db *dbTypes.DatabaseConnection, getSystemUser func() *authModels.User) http.HandlerFunc {
 
 	return func(w http.ResponseWriter, r *http.Request) {
 
@@ -67,7 +66,7 @@ func RegisterVisitorController(firebase *cliPkg.FirebaseManager, db *dbTypes.Dat
 		}
 
 		// 5. Create User
-		user, err := authUtils.CreateUserWithRole(input, firebase, db, systemUser, requestId, log)
+		user, err := authUtils.CreateUserWithRole(input, provider, db, systemUser, requestId, log)
 		if err != nil {
 			msg := fmt.Sprintf("Error creating user: %s", err.Error())
 			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, msg)
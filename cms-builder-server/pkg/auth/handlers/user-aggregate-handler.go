@@ -0,0 +1,30 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	rmHandlers "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/handlers"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
+	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
+)
+
+// UserAggregateHandler aggregates users like the default handler, but non admins only
+// aggregate their own record, the same way UserListHandler only lists them.
+var UserAggregateHandler rmTypes.ApiFunction = func(a *rmTypes.Resource, db *dbTypes.DatabaseConnection) http.HandlerFunc {
+	aggregate := rmHandlers.DefaultAggregateHandler(a, db)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := svrUtils.GetRequestContext(r).User
+
+		if !user.HasRole(authConstants.AdminRole) {
+			q := r.URL.Query()
+			q.Set("id", fmt.Sprint(user.ID))
+			r.URL.RawQuery = q.Encode()
+		}
+
+		aggregate(w, r)
+	}
+}
@@ -0,0 +1,64 @@
+package auth_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	authHandlers "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/handlers"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	testPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/testing"
+)
+
+func TestUserAggregateHandler(t *testing.T) {
+	bed := testPkg.SetupAuthTestBed()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/users/aggregate", authHandlers.UserAggregateHandler(bed.Src, bed.Db))
+
+	aggregate := func(user *authModels.User, query string) *httptest.ResponseRecorder {
+		req := testPkg.CreateTestRequest(t, http.MethodGet, "/users/aggregate?"+query, "", true, user, bed.Logger)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	count := func(rr *httptest.ResponseRecorder) float64 {
+		var response struct {
+			Data []map[string]interface{} `json:"data"`
+		}
+		err := json.Unmarshal(rr.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Len(t, response.Data, 1)
+
+		return response.Data[0]["count"].(float64)
+	}
+
+	t.Run("Hidden Fields Can't Be Grouped By", func(t *testing.T) {
+		rr := aggregate(bed.VisitorUser, "group_by=email,password_hash")
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Body.String(), "unknown group_by field password_hash")
+	})
+
+	t.Run("Hidden Fields Can't Be Filtered By", func(t *testing.T) {
+		rr := aggregate(bed.VisitorUser, "password_hash=x")
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Unknown filter field password_hash")
+	})
+
+	t.Run("Visitor Only Aggregates Themselves", func(t *testing.T) {
+		rr := aggregate(bed.VisitorUser, "id=1")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, float64(1), count(rr))
+	})
+
+	t.Run("Admin Aggregates Every User", func(t *testing.T) {
+		rr := aggregate(bed.AdminUser, "")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.GreaterOrEqual(t, count(rr), float64(3))
+	})
+}
@@ -13,10 +13,11 @@ import (
 )
 
 var filterKeys = map[string]bool{
-	"ID":        true,
-	"CreatedAt": true,
-	"UpdatedAt": true,
-	"DeletedAt": true,
+	"ID":              true,
+	"CreatedAt":       true,
+	"UpdatedAt":       true,
+	"DeletedAt":       true,
+	"emailVerifiedAt": true, // Only set by the verification flows
 }
 
 // DefaultCreateHandler handles the creation of a new resource.
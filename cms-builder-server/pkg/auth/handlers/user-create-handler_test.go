@@ -117,9 +117,9 @@ func TestUserCannotCreateRestrictedFields(t *testing.T) {
 		{
 			name: "ID",
 			body: map[string]interface{}{
-				"ID":    rand,
-				"name":  testPkg.RandomName(),
-				"email": testPkg.RandomEmail(),
+				"ID":        rand,
+				"firstName": testPkg.RandomName(),
+				"email":     testPkg.RandomEmail(),
 			},
 		},
 	}
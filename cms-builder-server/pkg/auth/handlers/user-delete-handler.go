@@ -1,12 +1,15 @@
 package auth
 
 import (
+	"errors"
 	"net/http"
 
 	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
 	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
 	dbQueries "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/queries"
 	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	rmHandlers "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/handlers"
 	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
 	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
 )
@@ -57,17 +60,44 @@ var UserDeleteHandler rmTypes.ApiFunction = func(a *rmTypes.Resource, db *dbType
 			return
 		}
 
-		// 5. Delete Instance
-		err = dbQueries.Delete(r.Context(), log, db, instance, user, requestId)
+		// Referencing records restrict the delete, or are deleted or unlinked with it
+		restricting, err := rmHandlers.FindRestrictingRecords(r.Context(), db, a, instance)
 		if err != nil {
+			log.Error().Err(err).Msg("Error finding referencing records")
 			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error deleting resource")
 			return
 		}
 
-		// 6. Generate Success Message
+		if len(restricting) > 0 {
+			svrUtils.SendJsonResponse(w, http.StatusConflict, restricting, rmHandlers.ErrDeleteRestricted.Error())
+			return
+		}
+
+		// 5. Revoke Sessions before deleting, so the tokens of the user can't register it again
+		if deletedUser, ok := instance.(*authModels.User); ok {
+			err = authUtils.RevokeUserSessions(r.Context(), db, deletedUser, user, "user deleted", requestId, log)
+			if err != nil {
+				log.Error().Err(err).Uint("user", deletedUser.ID).Msg("Error revoking sessions")
+				svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error deleting resource")
+				return
+			}
+		}
+
+		// 6. Delete Instance
+		err = rmHandlers.DeleteWithReferences(r.Context(), log, db, a, instance, user, requestId)
+		if errors.Is(err, rmHandlers.ErrDeleteRestricted) {
+			svrUtils.SendJsonResponse(w, http.StatusConflict, nil, err.Error())
+			return
+		}
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error deleting resource")
+			return
+		}
+
+		// 7. Generate Success Message
 		msg := a.ResourceNames.Singular + " has been deleted"
 
-		// 7. Send Success Response
+		// 8. Send Success Response
 		svrUtils.SendJsonResponse(w, http.StatusOK, nil, msg)
 	}
 }
@@ -8,8 +8,11 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
 	authHandlers "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/handlers"
 	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
 	testPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/testing"
 )
 
@@ -153,3 +156,54 @@ func TestUserDeleteHandler(t *testing.T) {
 		})
 	}
 }
+
+type UserBadge struct {
+	authModels.SystemData
+	HolderID uint   `json:"holderId"`
+	Name     string `json:"name"`
+}
+
+func TestUserDeleteHandler_RelationPolicies(t *testing.T) {
+	bed := testPkg.SetupAuthTestBed()
+
+	_, err := bed.Mgr.AddResource(&rmTypes.ResourceConfig{
+		Model: UserBadge{},
+		Permissions: authTypes.RolePermissionMap{
+			authConstants.AdminRole: authConstants.AllAllowedAccess,
+		},
+		Relations: []rmTypes.RelationConfig{
+			{Field: "HolderID", Resource: "User"},
+		},
+	})
+	assert.NoError(t, err)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/user/{id}", authHandlers.UserDeleteHandler(bed.Src, bed.Db))
+
+	holder := testPkg.CreateNoRoleUser()
+	assert.NoError(t, bed.Db.DB.Create(holder).Error)
+
+	badge := UserBadge{HolderID: holder.ID, Name: "Early adopter"}
+	badge.CreatedByID = bed.AdminUser.ID
+	assert.NoError(t, bed.Db.DB.Create(&badge).Error)
+
+	send := func() *httptest.ResponseRecorder {
+		req := testPkg.CreateTestRequest(t, http.MethodDelete, "/user/"+holder.StringID(), "", true, bed.AdminUser, bed.Logger)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := send()
+	assert.Equal(t, http.StatusConflict, rr.Code, rr.Body.String())
+	assert.Contains(t, rr.Body.String(), "UserBadge")
+
+	var count int64
+	bed.Db.DB.Model(&authModels.User{}).Where("id = ?", holder.ID).Count(&count)
+	assert.Equal(t, int64(1), count, "A referenced user should not be deleted")
+
+	assert.NoError(t, bed.Db.DB.Delete(&badge).Error)
+
+	rr = send()
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
 	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
 	dbQueries "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/queries"
 	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
@@ -103,7 +104,19 @@ var UserUpdateHandler rmTypes.ApiFunction = func(a *rmTypes.Resource, db *dbType
 			return
 		}
 
-		// 11. Create Instance in Database
+		// 11. Revoke Sessions before saving, the tokens of the user were issued for the previous roles
+		previousUser, _ := previousState.(*authModels.User)
+		updatedUser, ok := instance.(*authModels.User)
+		if ok && previousUser != nil && previousUser.Roles != updatedUser.Roles {
+			err = authUtils.RevokeUserSessions(r.Context(), db, updatedUser, user, "roles changed", requestId, log)
+			if err != nil {
+				log.Error().Err(err).Uint("user", updatedUser.ID).Msg("Error revoking sessions")
+				svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error updating resource")
+				return
+			}
+		}
+
+		// 12. Update Instance in Database
 		err = dbQueries.Update(r.Context(), log, db, instance, user, differences, requestId)
 		if err != nil {
 			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error updating resource")
@@ -112,7 +125,7 @@ var UserUpdateHandler rmTypes.ApiFunction = func(a *rmTypes.Resource, db *dbType
 
 		msg := a.ResourceNames.Singular + " has been updated"
 
-		// 12. Send Success Response
+		// 13. Send Success Response
 		svrUtils.SendJsonResponse(w, http.StatusOK, instance, msg)
 	}
 }
@@ -1,16 +1,20 @@
 package auth_test
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 
 	authHandlers "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/handlers"
 	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
 	testPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/testing"
 )
 
@@ -85,7 +89,7 @@ func TestUserUpdateHandler(t *testing.T) {
 			name:           "Admin can update himself",
 			method:         http.MethodPut,
 			path:           "/user/" + bed.AdminUser.StringID(),
-			requestBody:    `{"name": "` + testPkg.RandomString(10) + `", "email": "` + testPkg.RandomEmail() + `"}`,
+			requestBody:    `{"firstName": "` + testPkg.RandomString(10) + `", "email": "` + testPkg.RandomEmail() + `"}`,
 			user:           bed.AdminUser,
 			expectedStatus: http.StatusOK,
 			expectedBody:   "has been updated",
@@ -94,7 +98,7 @@ func TestUserUpdateHandler(t *testing.T) {
 			name:        "Admin can update others",
 			method:      http.MethodPut,
 			path:        "",
-			requestBody: `{"name": "` + testPkg.RandomString(10) + `", "email": "` + testPkg.RandomEmail() + `"}`,
+			requestBody: `{"firstName": "` + testPkg.RandomString(10) + `", "email": "` + testPkg.RandomEmail() + `"}`,
 			user:        bed.AdminUser,
 			setup: func() *authModels.User {
 				instance := testPkg.CreateNoRoleUser()
@@ -108,7 +112,7 @@ func TestUserUpdateHandler(t *testing.T) {
 			name:           "Visitor can not update himself",
 			method:         http.MethodPut,
 			path:           "/user/" + bed.VisitorUser.StringID(),
-			requestBody:    `{"name": "` + testPkg.RandomString(10) + `", "email": "` + testPkg.RandomEmail() + `"}`,
+			requestBody:    `{"firstName": "` + testPkg.RandomString(10) + `", "email": "` + testPkg.RandomEmail() + `"}`,
 			user:           bed.VisitorUser,
 			expectedStatus: http.StatusForbidden,
 			expectedBody:   "User is not allowed to update this resource",
@@ -117,7 +121,7 @@ func TestUserUpdateHandler(t *testing.T) {
 			name:        "Visitor can not update others",
 			method:      http.MethodPut,
 			path:        "",
-			requestBody: `{"name": "` + testPkg.RandomString(10) + `", "email": "` + testPkg.RandomEmail() + `"}`,
+			requestBody: `{"firstName": "` + testPkg.RandomString(10) + `", "email": "` + testPkg.RandomEmail() + `"}`,
 			user:        bed.VisitorUser,
 			setup: func() *authModels.User {
 				instance := testPkg.CreateNoRoleUser()
@@ -132,7 +136,7 @@ func TestUserUpdateHandler(t *testing.T) {
 			name:           "Invalid Request Body",
 			method:         http.MethodPut,
 			path:           "",
-			requestBody:    `{"name": "Updated Name", "email": "updated@example.com"`, // Malformed JSON
+			requestBody:    `{"firstName": "Updated Name", "email": "updated@example.com"`, // Malformed JSON
 			user:           bed.AdminUser,
 			expectedStatus: http.StatusBadRequest,
 			expectedBody:   "Invalid request body",
@@ -146,7 +150,7 @@ func TestUserUpdateHandler(t *testing.T) {
 			name:           "Validation Errors",
 			method:         http.MethodPut,
 			path:           "",
-			requestBody:    `{"name": ""}`, // Missing required field "name"
+			requestBody:    `{"firstName": ""}`, // Missing required field "firstName"
 			user:           bed.AdminUser,
 			expectedStatus: http.StatusBadRequest,
 			expectedBody:   "Validation failed",
@@ -189,7 +193,7 @@ func TestUserUpdateHandler(t *testing.T) {
 				instance = tt.setup()
 				path = "/user/" + instance.StringID()
 				if tt.overrideBody {
-					body = `{"name": "` + instance.FirstName + `", "email": "` + instance.Email + `"}`
+					body = `{"firstName": "` + instance.FirstName + `", "email": "` + instance.Email + `"}`
 				}
 
 				t.Log("Instance:", instance)
@@ -234,9 +238,9 @@ func TestUserCannotUpdateRestrictedFields(t *testing.T) {
 		{
 			name: "ID",
 			body: map[string]interface{}{
-				"ID":    uint(1),
-				"name":  testPkg.RandomName(),
-				"email": testPkg.RandomEmail(),
+				"ID":        uint(1),
+				"firstName": testPkg.RandomName(),
+				"email":     testPkg.RandomEmail(),
 			},
 		},
 	}
@@ -269,3 +273,43 @@ func TestUserCannotUpdateRestrictedFields(t *testing.T) {
 		})
 	}
 }
+
+func TestUserUpdateHandlerRevokesSessions(t *testing.T) {
+	bed := testPkg.SetupAuthTestBed()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/user/{id}", authHandlers.UserUpdateHandler(bed.Src, bed.Db))
+
+	update := func(user *authModels.User, body string) {
+		req := testPkg.CreateTestRequest(t, http.MethodPut, "/user/"+user.StringID(), body, true, bed.AdminUser, bed.Logger)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	signIn := func(user *authModels.User) *authTypes.ProviderUser {
+		user.FirebaseId = "provider-" + testPkg.RandomString(12)
+		assert.NoError(t, bed.Db.DB.Create(user).Error)
+		return &authTypes.ProviderUser{ID: user.FirebaseId, AuthTime: time.Now().Add(-time.Hour)}
+	}
+
+	t.Run("Changing the roles revokes the sessions", func(t *testing.T) {
+		user := testPkg.CreateVisitorUser()
+		session := signIn(user)
+		assert.NoError(t, authUtils.CheckRevoked(context.Background(), bed.Db, session))
+
+		update(user, `{"roles": "admin,visitor"}`)
+		assert.ErrorIs(t, authUtils.CheckRevoked(context.Background(), bed.Db, session), authUtils.ErrSessionRevoked)
+
+		session.AuthTime = time.Now().Add(time.Second)
+		assert.NoError(t, authUtils.CheckRevoked(context.Background(), bed.Db, session), "Signing in again grants the new roles")
+	})
+
+	t.Run("Other changes keep the sessions", func(t *testing.T) {
+		user := testPkg.CreateVisitorUser()
+		session := signIn(user)
+
+		update(user, `{"firstName": "`+testPkg.RandomName()+`"}`)
+		assert.NoError(t, authUtils.CheckRevoked(context.Background(), bed.Db, session))
+	})
+}
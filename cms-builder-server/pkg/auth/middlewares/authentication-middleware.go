@@ -6,8 +6,8 @@ import (
 
 	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
 	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
 	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
-	cliPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/clients"
 	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
 	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
 )
@@ -15,11 +15,11 @@ import (
 const GodTokenHeader = "X-God-Token"
 
 // AuthMiddleware is a middleware function that verifies the user based on the
-// access token provided in the Authorization header of the request. If the
+// access token, api key or impersonation token provided in the Authorization header of the request. If the
 // verification fails, it will return a 401 error. If the verification is
 // successful, it will continue to the next handler in the chain, setting a
 // "requested_by" header in the request with the ID of the verified user.
-func AuthMiddleware(envGodToken string, godUser *authModels.User, firebase *cliPkg.FirebaseManager, db *dbTypes.DatabaseConnection, systemUser *authModels.User) func(next http.Handler) http.Handler {
+func AuthMiddleware(envGodToken string, godUser *authModels.User, provider authTypes.AuthProvider, db *dbTypes.DatabaseConnection, systemUser *authModels.User) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
@@ -37,12 +37,28 @@ func AuthMiddleware(envGodToken string, godUser *authModels.User, firebase *cliP
 
 			var err error
 			if localUser.ID == 0 && accessToken != "" {
-				localUser, err = authUtils.VerifyUser(accessToken, firebase, db, systemUser, requestId, log)
+				localUser, err = authUtils.VerifyUser(accessToken, provider, db, systemUser, requestId, log)
 				if err != nil {
 					log.Error().Err(err).Msg("Error verifying user. User may not be authenticated")
 				}
 			}
 
+			apiKey := svrUtils.GetRequestApiKey(r)
+			if localUser != nil && localUser.ID == 0 && apiKey != "" {
+				localUser, err = authUtils.VerifyApiKey(apiKey, svrUtils.GetRequestIp(r), db, log)
+				if err != nil {
+					log.Error().Err(err).Msg("Error verifying api key. Request may not be authenticated")
+				}
+			}
+
+			impersonationToken := svrUtils.GetRequestImpersonationToken(r)
+			if localUser != nil && localUser.ID == 0 && impersonationToken != "" {
+				localUser, err = authUtils.VerifyImpersonation(impersonationToken, db, log)
+				if err != nil {
+					log.Error().Err(err).Msg("Error verifying impersonation. Request may not be authenticated")
+				}
+			}
+
 			if localUser != nil {
 
 				// Create a new context with both values
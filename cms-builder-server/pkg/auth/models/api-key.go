@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"net"
+	"strings"
+	"time"
+
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+)
+
+// ApiKey grants machine-to-machine access acting as a service user. Only a hash of the key is stored,
+// the key itself is shown once when created.
+type ApiKey struct {
+	SystemData
+	Name       string     `json:"name"`
+	Prefix     string     `gorm:"index" json:"prefix"` // First characters of the key, to tell keys apart
+	KeyHash    string     `gorm:"uniqueIndex" json:"-"`
+	UserID     uint       `gorm:"not null" json:"userId"` // Service user the key acts as
+	User       *User      `gorm:"foreignKey:UserID" json:"user"`
+	Roles      string     `json:"roles"`      // comma-separated list of roles, the ones of the user when empty
+	Scopes     string     `json:"scopes"`     // comma-separated list of the only resource operations allowed e.g. "Post:read,Post:update"
+	AllowedIps string     `json:"allowedIps"` // comma-separated list of IPs or CIDRs, any IP when empty
+	ExpiresAt  *time.Time `json:"expiresAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	RevokedAt  *time.Time `json:"revokedAt"`
+}
+
+// IsActive returns whether the key is neither revoked nor expired.
+func (k *ApiKey) IsActive(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+// IpAllowed returns whether requests from the given IP may use the key.
+func (k *ApiKey) IpAllowed(ip string) bool {
+	allowed := splitList(k.AllowedIps)
+	if len(allowed) == 0 {
+		return true
+	}
+
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+
+	for _, entry := range allowed {
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if network.Contains(parsed) {
+				return true
+			}
+			continue
+		}
+
+		if allowedIp := net.ParseIP(entry); allowedIp != nil && allowedIp.Equal(parsed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetRoles returns the roles requests made with the key act with, the ones of the user when the key
+// has none. Roles of the key the user doesn't hold are left out, so the key can't do more than its user.
+// Scopes are turned into scoped roles, which restrict the other roles to the listed operations.
+func (k *ApiKey) GetRoles() []authTypes.Role {
+	roles := []authTypes.Role{}
+	for _, role := range splitList(k.Roles) {
+		if k.User != nil && k.User.HasRole(authTypes.Role(role)) {
+			roles = append(roles, authTypes.Role(role))
+		}
+	}
+
+	if k.Roles == "" && k.User != nil {
+		roles = k.User.GetRoles()
+	}
+
+	for _, scope := range splitList(k.Scopes) {
+		resourceName, operation, ok := strings.Cut(scope, ":")
+		if ok {
+			roles = append(roles, authTypes.ScopedRole(resourceName, authTypes.CrudOperation(operation)))
+		}
+	}
+
+	return roles
+}
+
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,19 @@
+package auth
+
+import "time"
+
+// ImpersonationSession lets an admin act as another user until it expires or is deleted. The admin
+// is the creator of the session, only a hash of its token is stored.
+type ImpersonationSession struct {
+	SystemData
+	UserID    uint      `gorm:"not null;index" json:"userId"` // User being impersonated
+	User      *User     `gorm:"foreignKey:UserID" json:"user"`
+	TokenHash string    `gorm:"uniqueIndex" json:"-"`
+	Reason    string    `json:"reason"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// IsActive returns whether the session has not expired yet.
+func (s *ImpersonationSession) IsActive(now time.Time) bool {
+	return now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MfaEnrollment holds the TOTP secret of a user. Codes are only required once the enrollment
+// is confirmed with a first code.
+type MfaEnrollment struct {
+	gorm.Model
+	UserID       uint       `gorm:"uniqueIndex" json:"userId"`
+	Secret       string     `json:"-"`
+	ConfirmedAt  *time.Time `json:"confirmedAt"`
+	LastUsedStep int64      `json:"-"` // Time step of the last accepted code, codes can't be replayed
+
+	FailedAttempts int        `json:"-"` // Invalid codes since the last accepted one
+	LockedUntil    *time.Time `json:"-"` // Codes are rejected until then, after too many invalid ones
+}
+
+// MfaRecoveryCode replaces a TOTP code once, for users who lost their authenticator.
+// Only a hash of the code is stored.
+type MfaRecoveryCode struct {
+	gorm.Model
+	UserID   uint       `gorm:"index" json:"userId"`
+	CodeHash string     `json:"-"`
+	UsedAt   *time.Time `json:"usedAt"`
+}
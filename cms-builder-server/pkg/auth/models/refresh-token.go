@@ -0,0 +1,19 @@
+package auth
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RefreshToken is a refresh token issued by the local auth provider. Only a hash of the token is stored.
+// Tokens are rotated on every refresh, the tokens issued from the same login share a family.
+type RefreshToken struct {
+	gorm.Model
+	UserID    uint       `gorm:"index" json:"userId"`
+	FamilyId  string     `gorm:"index" json:"familyId"`
+	TokenHash string     `gorm:"uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	RevokedAt *time.Time `json:"revokedAt"`
+	Mfa       bool       `json:"mfa"` // Whether the login was confirmed with a second factor
+}
@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"strings"
+
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+)
+
+// Role is a role admins manage at runtime. Predefined roles are seeded, and the permissions
+// compiled into each resource apply to them unless overridden.
+type Role struct {
+	SystemData
+	Name        string `gorm:"uniqueIndex" json:"name"`
+	Description string `json:"description"`
+}
+
+// Permission overrides the operations a role may perform on a resource. The operations
+// compiled into the resource for that role no longer apply, an empty list denies every operation.
+type Permission struct {
+	SystemData
+	RoleID     uint   `gorm:"not null;index" json:"roleId"`
+	Role       *Role  `gorm:"foreignKey:RoleID" json:"role"`
+	Resource   string `gorm:"index" json:"resource"` // Singular name of the resource e.g. "Post"
+	Operations string `json:"operations"`            // comma-separated list of operations e.g. "read,update"
+}
+
+// GetOperations parses the comma-separated list of operations.
+func (p *Permission) GetOperations() []authTypes.CrudOperation {
+	operations := []authTypes.CrudOperation{}
+	for _, operation := range splitList(p.Operations) {
+		operations = append(operations, authTypes.CrudOperation(strings.ToLower(operation)))
+	}
+	return operations
+}
+
+// UserRole assigns a role to a user, on top of the roles stored on the user itself.
+type UserRole struct {
+	SystemData
+	UserID uint  `gorm:"not null;index" json:"userId"`
+	RoleID uint  `gorm:"not null;index" json:"roleId"`
+	Role   *Role `gorm:"foreignKey:RoleID" json:"role"`
+}
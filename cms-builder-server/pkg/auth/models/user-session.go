@@ -0,0 +1,30 @@
+package auth
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserSession tracks a session of a user, as reported by the auth provider with every token.
+// Revoked sessions are rejected even if their tokens haven't expired yet.
+type UserSession struct {
+	gorm.Model
+	UserID     uint       `gorm:"uniqueIndex:idx_user_session" json:"userId"`
+	SessionId  string     `gorm:"uniqueIndex:idx_user_session" json:"-"`
+	AuthTime   time.Time  `json:"authTime"` // When the user signed in
+	LastSeenAt time.Time  `json:"lastSeenAt"`
+	RevokedAt  *time.Time `json:"revokedAt"`
+}
+
+// SessionRevocation rejects the tokens of a provider user signed in before IssuedBefore.
+// Entries are kept by provider id, so they apply even after the local user is deleted.
+// Tokens not telling when the user signed in are rejected until LiftedAt, set on the next interactive sign in.
+type SessionRevocation struct {
+	gorm.Model
+	ProviderId   string     `gorm:"index" json:"providerId"`
+	UserID       uint       `gorm:"index" json:"userId"`
+	IssuedBefore time.Time  `json:"issuedBefore"`
+	Reason       string     `json:"reason"`
+	LiftedAt     *time.Time `json:"liftedAt"`
+}
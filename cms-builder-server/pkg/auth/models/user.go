@@ -2,7 +2,9 @@ package auth
 
 import (
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -15,12 +17,34 @@ var (
 
 type User struct {
 	gorm.Model
-	ID         uint   `gorm:"primaryKey" json:"ID"`
-	FirstName  string `json:"firstName"`
-	LastName   string `json:"lastName"`
-	Email      string `gorm:"unique" json:"email"`
-	FirebaseId string `json:"firebaseId"`
-	Roles      string `json:"roles"` // comma-separated list of roles e.g. "admin,visitor"
+	ID           uint   `gorm:"primaryKey" json:"ID"`
+	FirstName    string `json:"firstName"`
+	LastName     string `json:"lastName"`
+	Email        string `gorm:"unique" json:"email"`
+	FirebaseId   string `json:"firebaseId"` // ID of the user in the auth provider
+	PasswordHash string `json:"-"`          // bcrypt hash, only set for the local auth provider
+	Roles        string `json:"roles"`      // comma-separated list of roles e.g. "admin,visitor"
+
+	// Set once the user proves they own the email, through the provider or an emailed link
+	EmailVerifiedAt *time.Time `json:"emailVerifiedAt"`
+
+	// Roles assigned at runtime, only loaded to authorize requests. Assignments are managed
+	// through their own resource, so they can't be set by updating the user.
+	UserRoles []UserRole `gorm:"foreignKey:UserID" json:"-"`
+
+	// Admin acting as this user, only set on the user of an impersonated request
+	ImpersonatedBy *User `gorm:"-" json:"-"`
+
+	// Api key the request was authenticated with, only set on the service user of the key
+	ApiKeyID uint `gorm:"-" json:"-"`
+
+	// Roles withheld for the request, e.g. when the session lacks a second factor
+	RestrictedRoles []authTypes.Role `gorm:"-" json:"-"`
+}
+
+// IsEmailVerified returns whether the user has verified their email.
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
 }
 
 // ID returns the ID of the SystemData as a string.
@@ -32,26 +56,31 @@ func (u *User) StringID() string {
 }
 
 // GetRoles parses the comma-separated list of roles from the User's Roles field and
-// returns a slice of authPkg.Role objects.
+// returns a slice of authPkg.Role objects, followed by the loaded runtime role assignments.
+// Restricted roles are left out.
 //
 // Returns:
-// - []authPkg.Role: a slice of authPkg.Role objects, or an empty slice if the Roles field is empty.
+// - []authPkg.Role: a slice of authPkg.Role objects, or an empty slice if the user has no roles.
 func (u *User) GetRoles() []authTypes.Role {
 	roles := []authTypes.Role{}
 
-	// Trim leading/trailing spaces and check if the Roles field is empty
-	trimmedRoles := strings.TrimSpace(u.Roles)
-	if trimmedRoles == "" {
-		return roles
+	// Split the roles string and process each role, skipping empty roles
+	for _, role := range splitList(u.Roles) {
+		roles = append(roles, authTypes.Role(role))
 	}
 
-	// Split the roles string and process each role
-	for _, role := range strings.Split(trimmedRoles, ",") {
-		role = strings.TrimSpace(role)
-		if role != "" { // Skip empty roles
-			roles = append(roles, authTypes.Role(role))
+	for _, userRole := range u.UserRoles {
+		if userRole.Role != nil && !slices.Contains(roles, authTypes.Role(userRole.Role.Name)) {
+			roles = append(roles, authTypes.Role(userRole.Role.Name))
 		}
 	}
+
+	if len(u.RestrictedRoles) > 0 {
+		roles = slices.DeleteFunc(roles, func(role authTypes.Role) bool {
+			return slices.Contains(u.RestrictedRoles, role)
+		})
+	}
+
 	return roles
 }
 
@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"firebase.google.com/go/auth"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	cliPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/clients"
+)
+
+// FirebaseProvider authenticates users with Firebase ID tokens.
+type FirebaseProvider struct {
+	Client *cliPkg.FirebaseManager
+}
+
+// NewFirebaseProvider initializes the Firebase client with the given config.
+func NewFirebaseProvider(cfg *cliPkg.FirebaseConfig) (*FirebaseProvider, error) {
+	client, err := cliPkg.NewFirebaseAdmin(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FirebaseProvider{Client: client}, nil
+}
+
+func (p *FirebaseProvider) Type() authTypes.AuthProviderType {
+	return authConstants.AuthProviderFirebase
+}
+
+// VerifyToken verifies a Firebase ID token, name and email are read from its claims.
+func (p *FirebaseProvider) VerifyToken(ctx context.Context, token string) (*authTypes.ProviderUser, error) {
+	accessToken, err := p.Client.VerifyIDToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	name, _ := accessToken.Claims["name"].(string)
+	email, _ := accessToken.Claims["email"].(string)
+	emailVerified, _ := accessToken.Claims["email_verified"].(bool)
+
+	providerUser := &authTypes.ProviderUser{
+		ID:            accessToken.UID,
+		Email:         email,
+		Name:          name,
+		EmailVerified: emailVerified,
+	}
+
+	if info, ok := accessToken.Claims["firebase"].(map[string]interface{}); ok {
+		secondFactor, _ := info["sign_in_second_factor"].(string)
+		providerUser.MfaVerified = secondFactor != ""
+	}
+
+	// ID tokens are refreshed every hour, the time of the sign in identifies the session
+	if authTime, ok := accessToken.Claims["auth_time"].(float64); ok && authTime > 0 {
+		providerUser.SessionId = strconv.FormatInt(int64(authTime), 10)
+		providerUser.AuthTime = time.Unix(int64(authTime), 0)
+	}
+
+	return providerUser, nil
+}
+
+func (p *FirebaseProvider) CreateUser(ctx context.Context, input authTypes.ProviderUserInput) (*authTypes.ProviderUser, error) {
+	userToCreate := &auth.UserToCreate{}
+	userToCreate.DisplayName(input.Name)
+	userToCreate.Email(input.Email)
+	userToCreate.Password(input.Password)
+
+	userRecord, err := p.Client.CreateUser(ctx, userToCreate)
+	if err != nil {
+		if strings.Contains(err.Error(), "EMAIL_EXISTS") {
+			return nil, authTypes.ErrProviderUserExists
+		}
+
+		return nil, fmt.Errorf("failed to create user in Firebase: %s", err.Error())
+	}
+
+	return toProviderUser(userRecord), nil
+}
+
+func (p *FirebaseProvider) DeleteUser(ctx context.Context, id string) error {
+	return p.Client.DeleteUser(ctx, id)
+}
+
+func (p *FirebaseProvider) GetUserByEmail(ctx context.Context, email string) (*authTypes.ProviderUser, error) {
+	userRecord, err := p.Client.GetUserByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	return toProviderUser(userRecord), nil
+}
+
+func toProviderUser(userRecord *auth.UserRecord) *authTypes.ProviderUser {
+	return &authTypes.ProviderUser{
+		ID:            userRecord.UID,
+		Email:         userRecord.Email,
+		Name:          userRecord.DisplayName,
+		EmailVerified: userRecord.EmailVerified,
+	}
+}
@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+)
+
+const (
+	accountTokenPasswordReset     = "password-reset"
+	accountTokenEmailVerification = "email-verification"
+)
+
+var (
+	ErrInvalidAccountToken  = errors.New("invalid or expired token")
+	ErrEmailAlreadyVerified = errors.New("email is already verified")
+)
+
+// AccountToken is a token emailed to a user, for them to reset their password or verify their email.
+type AccountToken struct {
+	User      *authModels.User
+	Token     string
+	ExpiresAt time.Time
+}
+
+// accountTokenClaims are signed with a key derived for the purpose of the token,
+// so they can't be used as access tokens nor for another purpose.
+type accountTokenClaims struct {
+	Email       string `json:"email"`
+	Fingerprint string `json:"fp,omitempty"` // Of the password hash, reset tokens stop working once used
+	jwt.RegisteredClaims
+}
+
+// PasswordResetToken returns a token for the user with the given email to choose a new password.
+// Returns ErrProviderUserNotFound when there is no local account for the email.
+func (p *LocalProvider) PasswordResetToken(ctx context.Context, email string) (*AccountToken, error) {
+	user, err := p.findUser(ctx, "email = ? AND password_hash <> '' AND firebase_id <> ''", strings.ToLower(email))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, authTypes.ErrProviderUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return p.signAccountToken(user, accountTokenPasswordReset, passwordFingerprint(user), p.Config.PasswordResetTTL)
+}
+
+// ResetPassword sets the password of the user the reset token was issued for, and revokes their refresh tokens.
+// The token can't be used again.
+func (p *LocalProvider) ResetPassword(ctx context.Context, token string, password string) (*authModels.User, error) {
+	user, claims, err := p.parseAccountToken(ctx, token, accountTokenPasswordReset)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.PasswordHash == "" || claims.Fingerprint != passwordFingerprint(user) {
+		return nil, ErrInvalidAccountToken
+	}
+
+	hash, err := HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only one reset succeeds when the same token is sent concurrently
+	result := p.DB.DB.WithContext(ctx).Model(user).
+		Where("password_hash = ?", user.PasswordHash).
+		Update("password_hash", hash)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrInvalidAccountToken
+	}
+
+	user.PasswordHash = hash
+	return user, p.revoke(ctx, "user_id = ?", user.ID)
+}
+
+// EmailVerificationToken returns a token for the user with the given email to verify it.
+// Returns ErrProviderUserNotFound when there is no local account for the email.
+func (p *LocalProvider) EmailVerificationToken(ctx context.Context, email string) (*AccountToken, error) {
+	user, err := p.findUser(ctx, "email = ? AND firebase_id <> ''", strings.ToLower(email))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, authTypes.ErrProviderUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if user.IsEmailVerified() {
+		return nil, ErrEmailAlreadyVerified
+	}
+
+	return p.signAccountToken(user, accountTokenEmailVerification, "", p.Config.EmailVerificationTTL)
+}
+
+// VerifyEmailToken returns the user the verification token was issued for.
+// Tokens stop working when the email of the user changes.
+func (p *LocalProvider) VerifyEmailToken(ctx context.Context, token string) (*authModels.User, error) {
+	user, _, err := p.parseAccountToken(ctx, token, accountTokenEmailVerification)
+	return user, err
+}
+
+func (p *LocalProvider) signAccountToken(user *authModels.User, purpose string, fingerprint string, ttl time.Duration) (*AccountToken, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+
+	claims := accountTokenClaims{
+		Email:       user.Email,
+		Fingerprint: fingerprint,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   user.FirebaseId,
+			Issuer:    p.Config.Issuer,
+			Audience:  jwt.ClaimStrings{purpose},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			ID:        uuid.New().String(),
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(p.accountTokenKey(purpose))
+	if err != nil {
+		return nil, err
+	}
+
+	return &AccountToken{
+		User:      user,
+		Token:     token,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func (p *LocalProvider) parseAccountToken(ctx context.Context, token string, purpose string) (*authModels.User, *accountTokenClaims, error) {
+	claims := &accountTokenClaims{}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithAudience(purpose),
+		jwt.WithExpirationRequired(),
+	}
+	if p.Config.Issuer != "" {
+		options = append(options, jwt.WithIssuer(p.Config.Issuer))
+	}
+
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		return p.accountTokenKey(purpose), nil
+	}, options...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrInvalidAccountToken, err.Error())
+	}
+
+	user, err := p.findUser(ctx, "firebase_id = ?", claims.Subject)
+	if errors.Is(err, gorm.ErrRecordNotFound) || claims.Subject == "" {
+		return nil, nil, ErrInvalidAccountToken
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !strings.EqualFold(user.Email, claims.Email) {
+		return nil, nil, ErrInvalidAccountToken
+	}
+
+	return user, claims, nil
+}
+
+// accountTokenKey derives the key the tokens of the purpose are signed with from the secret.
+func (p *LocalProvider) accountTokenKey(purpose string) []byte {
+	mac := hmac.New(sha256.New, []byte(p.Config.Secret))
+	mac.Write([]byte("account-token:" + purpose))
+	return mac.Sum(nil)
+}
+
+func passwordFingerprint(user *authModels.User) string {
+	return hashToken(user.PasswordHash)[:16]
+}
@@ -0,0 +1,377 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+)
+
+const (
+	defaultAccessTokenTTL       = 15 * time.Minute
+	defaultRefreshTokenTTL      = 30 * 24 * time.Hour
+	defaultPasswordResetTTL     = time.Hour
+	defaultEmailVerificationTTL = 48 * time.Hour
+	refreshTokenBytes           = 32
+)
+
+var (
+	ErrInvalidCredentials  = errors.New("invalid email or password")
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrMissingJwtSecret    = errors.New("missing jwt secret")
+)
+
+type LocalProviderConfig struct {
+	Secret               string        // Key the access tokens are signed with
+	Issuer               string        // Issuer claim of the access tokens, optional
+	AccessTokenTTL       time.Duration // Defaults to 15 minutes
+	RefreshTokenTTL      time.Duration // Defaults to 30 days
+	PasswordResetTTL     time.Duration // Defaults to 1 hour
+	EmailVerificationTTL time.Duration // Defaults to 48 hours
+}
+
+// LocalProvider authenticates users with the password hashes stored on User.
+// Logins issue HS256 signed JWT access tokens along with refresh tokens, rotated on every use.
+type LocalProvider struct {
+	DB     *dbTypes.DatabaseConnection
+	Config LocalProviderConfig
+}
+
+// Session holds the tokens issued on login and refresh.
+type Session struct {
+	AccessToken  string    `json:"accessToken"`
+	RefreshToken string    `json:"refreshToken"`
+	ExpiresAt    time.Time `json:"expiresAt"` // Expiration of the access token
+}
+
+type accessTokenClaims struct {
+	Email     string `json:"email"`
+	Name      string `json:"name"`
+	SessionId string `json:"sid"` // Family of the refresh tokens
+	Mfa       bool   `json:"mfa,omitempty"`
+	jwt.RegisteredClaims
+}
+
+func NewLocalProvider(db *dbTypes.DatabaseConnection, cfg LocalProviderConfig) (*LocalProvider, error) {
+	if cfg.Secret == "" {
+		return nil, ErrMissingJwtSecret
+	}
+
+	if cfg.AccessTokenTTL <= 0 {
+		cfg.AccessTokenTTL = defaultAccessTokenTTL
+	}
+
+	if cfg.RefreshTokenTTL <= 0 {
+		cfg.RefreshTokenTTL = defaultRefreshTokenTTL
+	}
+
+	if cfg.PasswordResetTTL <= 0 {
+		cfg.PasswordResetTTL = defaultPasswordResetTTL
+	}
+
+	if cfg.EmailVerificationTTL <= 0 {
+		cfg.EmailVerificationTTL = defaultEmailVerificationTTL
+	}
+
+	err := db.DB.AutoMigrate(&authModels.User{}, &authModels.RefreshToken{}, &authModels.MfaEnrollment{}, &authModels.MfaRecoveryCode{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &LocalProvider{
+		DB:     db,
+		Config: cfg,
+	}, nil
+}
+
+func (p *LocalProvider) Type() authTypes.AuthProviderType {
+	return authConstants.AuthProviderLocal
+}
+
+// VerifyToken verifies an access token issued by Login or Refresh.
+func (p *LocalProvider) VerifyToken(ctx context.Context, token string) (*authTypes.ProviderUser, error) {
+	claims := &accessTokenClaims{}
+
+	options := []jwt.ParserOption{jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()})}
+	if p.Config.Issuer != "" {
+		options = append(options, jwt.WithIssuer(p.Config.Issuer))
+	}
+
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		return []byte(p.Config.Secret), nil
+	}, options...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", authTypes.ErrProviderInvalidToken, err.Error())
+	}
+
+	// Refresh tokens are revoked along with the sessions, so the issue time of the
+	// access token is as good as the time of the login
+	authTime := time.Time{}
+	if claims.IssuedAt != nil {
+		authTime = claims.IssuedAt.Time
+	}
+
+	return &authTypes.ProviderUser{
+		ID:          claims.Subject,
+		Email:       claims.Email,
+		Name:        claims.Name,
+		SessionId:   claims.SessionId,
+		AuthTime:    authTime,
+		MfaVerified: claims.Mfa,
+	}, nil
+}
+
+// CreateUser hashes the password of a new user, the hash is stored on User when the user is created.
+// ErrProviderUserExists is returned for any email of an existing user, with or without a password.
+func (p *LocalProvider) CreateUser(ctx context.Context, input authTypes.ProviderUserInput) (*authTypes.ProviderUser, error) {
+	// Users without credentials can't be claimed by registering their email
+	_, err := p.findUser(ctx, "email = ?", strings.ToLower(input.Email))
+	if err == nil {
+		return nil, authTypes.ErrProviderUserExists
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	hash, err := HashPassword(input.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	return &authTypes.ProviderUser{
+		ID:           uuid.New().String(),
+		Email:        input.Email,
+		Name:         input.Name,
+		PasswordHash: hash,
+	}, nil
+}
+
+// DeleteUser removes the credentials of the user and revokes its refresh tokens.
+func (p *LocalProvider) DeleteUser(ctx context.Context, id string) error {
+	user, err := p.findUser(ctx, "firebase_id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	err = p.DB.DB.WithContext(ctx).Model(user).Update("password_hash", "").Error
+	if err != nil {
+		return err
+	}
+
+	return p.revoke(ctx, "user_id = ?", user.ID)
+}
+
+func (p *LocalProvider) GetUserByEmail(ctx context.Context, email string) (*authTypes.ProviderUser, error) {
+	user, err := p.findUser(ctx, "email = ? AND password_hash <> ''", strings.ToLower(email))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, authTypes.ErrProviderUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return toLocalProviderUser(user), nil
+}
+
+// Login checks the password of the user with the given email and starts a new session.
+// Users enrolled in MFA also need a code of their authenticator, or one of their recovery codes.
+func (p *LocalProvider) Login(ctx context.Context, email string, password string, code string) (*Session, error) {
+	user, err := p.findUser(ctx, "email = ?", strings.ToLower(email))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if user.PasswordHash == "" || user.FirebaseId == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	mfa, err := authUtils.MfaEnabled(ctx, p.DB, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if mfa {
+		if code == "" {
+			return nil, authUtils.ErrMfaRequired
+		}
+
+		err = authUtils.VerifyMfaCode(ctx, p.DB, user.ID, code)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return p.issueSession(ctx, user, uuid.New().String(), mfa)
+}
+
+// Refresh exchanges a refresh token for a new session, the given token can't be used again.
+// Reusing a rotated token revokes every token of its family, as it may have been stolen.
+func (p *LocalProvider) Refresh(ctx context.Context, refreshToken string) (*Session, error) {
+	token := authModels.RefreshToken{}
+	err := p.DB.DB.WithContext(ctx).Where("token_hash = ?", hashToken(refreshToken)).First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if token.RevokedAt != nil {
+		err = p.revoke(ctx, "family_id = ?", token.FamilyId)
+		if err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if time.Now().After(token.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// Only one refresh succeeds when the same token is sent concurrently
+	result := p.DB.DB.WithContext(ctx).Model(&token).Where("revoked_at IS NULL").Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user := authModels.User{}
+	err = p.DB.DB.WithContext(ctx).First(&user, token.UserID).Error
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	return p.issueSession(ctx, &user, token.FamilyId, token.Mfa)
+}
+
+// Logout revokes the session the refresh token belongs to.
+func (p *LocalProvider) Logout(ctx context.Context, refreshToken string) error {
+	token := authModels.RefreshToken{}
+	err := p.DB.DB.WithContext(ctx).Where("token_hash = ?", hashToken(refreshToken)).First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return err
+	}
+
+	return p.revoke(ctx, "family_id = ?", token.FamilyId)
+}
+
+// HashPassword returns the bcrypt hash of the password.
+func HashPassword(password string) (string, error) {
+	if password == "" {
+		return "", fmt.Errorf("password is required")
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hash), nil
+}
+
+func (p *LocalProvider) issueSession(ctx context.Context, user *authModels.User, familyId string, mfa bool) (*Session, error) {
+	now := time.Now()
+	expiresAt := now.Add(p.Config.AccessTokenTTL)
+
+	claims := accessTokenClaims{
+		Email:     user.Email,
+		Name:      strings.TrimSpace(user.FirstName + " " + user.LastName),
+		SessionId: familyId,
+		Mfa:       mfa,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   user.FirebaseId,
+			Issuer:    p.Config.Issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			ID:        uuid.New().String(),
+		},
+	}
+
+	accessToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(p.Config.Secret))
+	if err != nil {
+		return nil, err
+	}
+
+	buffer := make([]byte, refreshTokenBytes)
+	if _, err := rand.Read(buffer); err != nil {
+		return nil, err
+	}
+	refreshToken := base64.RawURLEncoding.EncodeToString(buffer)
+
+	err = p.DB.DB.WithContext(ctx).Create(&authModels.RefreshToken{
+		UserID:    user.ID,
+		FamilyId:  familyId,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: now.Add(p.Config.RefreshTokenTTL),
+		Mfa:       mfa,
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &Session{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresAt:    expiresAt,
+	}, nil
+}
+
+func (p *LocalProvider) findUser(ctx context.Context, query string, args ...interface{}) (*authModels.User, error) {
+	user := authModels.User{}
+	err := p.DB.DB.WithContext(ctx).Where(query, args...).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (p *LocalProvider) revoke(ctx context.Context, query string, args ...interface{}) error {
+	return p.DB.DB.WithContext(ctx).Model(&authModels.RefreshToken{}).
+		Where(query, args...).
+		Where("revoked_at IS NULL").
+		Update("revoked_at", time.Now()).Error
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func toLocalProviderUser(user *authModels.User) *authTypes.ProviderUser {
+	return &authTypes.ProviderUser{
+		ID:           user.FirebaseId,
+		Email:        user.Email,
+		Name:         strings.TrimSpace(user.FirstName + " " + user.LastName),
+		PasswordHash: user.PasswordHash,
+	}
+}
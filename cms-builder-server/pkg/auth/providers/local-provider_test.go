@@ -0,0 +1,328 @@
+package auth_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authProviders "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/providers"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	testPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/testing"
+)
+
+func setupLocalProvider(t *testing.T) (testPkg.TestUtils, *authProviders.LocalProvider, *authModels.User, string) {
+	bed := testPkg.SetupHandlerTestBed()
+
+	provider, err := authProviders.NewLocalProvider(bed.Db, authProviders.LocalProviderConfig{
+		Secret: "test-secret",
+		Issuer: "test",
+	})
+	assert.NoError(t, err)
+
+	input := authTypes.RegisterUserInput{
+		FirstName:        "Jane",
+		LastName:         "Doe",
+		Email:            strings.ToLower(testPkg.RandomEmail()),
+		Password:         testPkg.RandomPassword(),
+		Roles:            []authTypes.Role{authConstants.VisitorRole},
+		RegisterFirebase: true,
+	}
+
+	user, err := authUtils.CreateUserWithRole(input, provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, user.FirebaseId)
+	assert.NotEmpty(t, user.PasswordHash)
+	assert.NotEqual(t, input.Password, user.PasswordHash)
+
+	return bed, provider, user, input.Password
+}
+
+func TestNewLocalProvider_MissingSecret(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+
+	_, err := authProviders.NewLocalProvider(bed.Db, authProviders.LocalProviderConfig{})
+	assert.ErrorIs(t, err, authProviders.ErrMissingJwtSecret)
+}
+
+func TestLocalProvider_Login(t *testing.T) {
+	bed, provider, user, password := setupLocalProvider(t)
+	ctx := context.Background()
+
+	_, err := provider.Login(ctx, user.Email, "wrong", "")
+	assert.ErrorIs(t, err, authProviders.ErrInvalidCredentials)
+
+	_, err = provider.Login(ctx, "missing@example.com", password, "")
+	assert.ErrorIs(t, err, authProviders.ErrInvalidCredentials)
+
+	session, err := provider.Login(ctx, strings.ToUpper(user.Email), password, "")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, session.AccessToken)
+	assert.NotEmpty(t, session.RefreshToken)
+
+	// Access tokens are verified like any provider token
+	verified, err := authUtils.VerifyUser(session.AccessToken, provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, verified.ID)
+
+	// Tokens of the login belong to the same session, refreshes included
+	providerUser, err := provider.VerifyToken(ctx, session.AccessToken)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, providerUser.SessionId)
+
+	refreshed, err := provider.Refresh(ctx, session.RefreshToken)
+	assert.NoError(t, err)
+	refreshedUser, err := provider.VerifyToken(ctx, refreshed.AccessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, providerUser.SessionId, refreshedUser.SessionId)
+
+	_, err = provider.VerifyToken(ctx, session.AccessToken+"x")
+	assert.ErrorIs(t, err, authTypes.ErrProviderInvalidToken)
+}
+
+func TestLocalProvider_ExpiredToken(t *testing.T) {
+	_, provider, user, password := setupLocalProvider(t)
+	provider.Config.AccessTokenTTL = -time.Minute
+
+	session, err := provider.Login(context.Background(), user.Email, password, "")
+	assert.NoError(t, err)
+
+	_, err = provider.VerifyToken(context.Background(), session.AccessToken)
+	assert.ErrorIs(t, err, authTypes.ErrProviderInvalidToken)
+}
+
+func TestLocalProvider_Refresh(t *testing.T) {
+	_, provider, user, password := setupLocalProvider(t)
+	ctx := context.Background()
+
+	session, err := provider.Login(ctx, user.Email, password, "")
+	assert.NoError(t, err)
+
+	refreshed, err := provider.Refresh(ctx, session.RefreshToken)
+	assert.NoError(t, err)
+	assert.NotEqual(t, session.RefreshToken, refreshed.RefreshToken)
+
+	// Reusing a rotated token revokes the whole session
+	_, err = provider.Refresh(ctx, session.RefreshToken)
+	assert.ErrorIs(t, err, authProviders.ErrInvalidRefreshToken)
+
+	_, err = provider.Refresh(ctx, refreshed.RefreshToken)
+	assert.ErrorIs(t, err, authProviders.ErrInvalidRefreshToken)
+}
+
+func TestLocalProvider_Logout(t *testing.T) {
+	_, provider, user, password := setupLocalProvider(t)
+	ctx := context.Background()
+
+	session, err := provider.Login(ctx, user.Email, password, "")
+	assert.NoError(t, err)
+
+	err = provider.Logout(ctx, session.RefreshToken)
+	assert.NoError(t, err)
+
+	_, err = provider.Refresh(ctx, session.RefreshToken)
+	assert.ErrorIs(t, err, authProviders.ErrInvalidRefreshToken)
+
+	err = provider.Logout(ctx, "unknown")
+	assert.ErrorIs(t, err, authProviders.ErrInvalidRefreshToken)
+}
+
+func TestLocalProvider_ExistingUser(t *testing.T) {
+	bed, provider, user, password := setupLocalProvider(t)
+
+	// Registering the same email again keeps the first password
+	input := authTypes.RegisterUserInput{
+		FirstName:        user.FirstName,
+		Email:            user.Email,
+		Password:         "another-password",
+		Roles:            []authTypes.Role{authConstants.VisitorRole},
+		RegisterFirebase: true,
+	}
+
+	again, err := authUtils.CreateUserWithRole(input, provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, again.ID)
+
+	_, err = provider.Login(context.Background(), user.Email, password, "")
+	assert.NoError(t, err)
+}
+
+func TestLocalProvider_SeededUserCannotBeClaimed(t *testing.T) {
+	bed, provider, _, _ := setupLocalProvider(t)
+
+	// Seeded users have no password until they reset it
+	admin := testPkg.CreateAdminUser()
+	admin.Email = strings.ToLower(admin.Email)
+	assert.NoError(t, bed.Db.DB.Create(admin).Error)
+
+	input := authTypes.RegisterUserInput{
+		FirstName:        "Mallory",
+		Email:            admin.Email,
+		Password:         "attacker-password",
+		Roles:            []authTypes.Role{authConstants.VisitorRole},
+		RegisterFirebase: true,
+	}
+
+	_, err := authUtils.CreateUserWithRole(input, provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+	assert.Error(t, err)
+
+	stored := authModels.User{}
+	assert.NoError(t, bed.Db.DB.First(&stored, admin.ID).Error)
+	assert.Empty(t, stored.PasswordHash)
+	assert.Empty(t, stored.FirebaseId)
+	assert.Equal(t, admin.Roles, stored.Roles)
+
+	_, err = provider.Login(context.Background(), admin.Email, input.Password, "")
+	assert.ErrorIs(t, err, authProviders.ErrInvalidCredentials)
+}
+
+func TestLocalProvider_Mfa(t *testing.T) {
+	bed, provider, user, password := setupLocalProvider(t)
+	ctx := context.Background()
+
+	enrollment, err := authUtils.EnrollMfa(ctx, bed.Db, user, "test-request", bed.Logger)
+	assert.NoError(t, err)
+
+	code, err := authUtils.TotpCode(enrollment.Secret, time.Now())
+	assert.NoError(t, err)
+	recoveryCodes, err := authUtils.ConfirmMfa(ctx, bed.Db, user, code, "test-request", bed.Logger)
+	assert.NoError(t, err)
+
+	// The password alone is no longer enough
+	_, err = provider.Login(ctx, user.Email, password, "")
+	assert.ErrorIs(t, err, authUtils.ErrMfaRequired)
+
+	_, err = provider.Login(ctx, user.Email, password, "000000")
+	assert.ErrorIs(t, err, authUtils.ErrInvalidMfaCode)
+
+	_, err = provider.Login(ctx, user.Email, "wrong", recoveryCodes[0])
+	assert.ErrorIs(t, err, authProviders.ErrInvalidCredentials)
+
+	session, err := provider.Login(ctx, user.Email, password, recoveryCodes[0])
+	assert.NoError(t, err)
+
+	// Tokens of the session carry the second factor, refreshes included
+	providerUser, err := provider.VerifyToken(ctx, session.AccessToken)
+	assert.NoError(t, err)
+	assert.True(t, providerUser.MfaVerified)
+
+	refreshed, err := provider.Refresh(ctx, session.RefreshToken)
+	assert.NoError(t, err)
+	providerUser, err = provider.VerifyToken(ctx, refreshed.AccessToken)
+	assert.NoError(t, err)
+	assert.True(t, providerUser.MfaVerified)
+}
+
+func TestLocalProvider_PasswordReset(t *testing.T) {
+	_, provider, user, password := setupLocalProvider(t)
+	ctx := context.Background()
+
+	_, err := provider.PasswordResetToken(ctx, "missing@example.com")
+	assert.ErrorIs(t, err, authTypes.ErrProviderUserNotFound)
+
+	session, err := provider.Login(ctx, user.Email, password, "")
+	assert.NoError(t, err)
+
+	token, err := provider.PasswordResetToken(ctx, strings.ToUpper(user.Email))
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, token.User.ID)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), token.ExpiresAt, time.Minute)
+
+	// Account tokens aren't access tokens, nor tokens of another purpose
+	_, err = provider.VerifyToken(ctx, token.Token)
+	assert.ErrorIs(t, err, authTypes.ErrProviderInvalidToken)
+
+	_, err = provider.VerifyEmailToken(ctx, token.Token)
+	assert.ErrorIs(t, err, authProviders.ErrInvalidAccountToken)
+
+	_, err = provider.ResetPassword(ctx, token.Token, "")
+	assert.Error(t, err)
+
+	reset, err := provider.ResetPassword(ctx, token.Token, "new-password")
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, reset.ID)
+
+	// The token can't be used again, and previous sessions are revoked
+	_, err = provider.ResetPassword(ctx, token.Token, "another-password")
+	assert.ErrorIs(t, err, authProviders.ErrInvalidAccountToken)
+
+	_, err = provider.Refresh(ctx, session.RefreshToken)
+	assert.ErrorIs(t, err, authProviders.ErrInvalidRefreshToken)
+
+	_, err = provider.Login(ctx, user.Email, password, "")
+	assert.ErrorIs(t, err, authProviders.ErrInvalidCredentials)
+
+	_, err = provider.Login(ctx, user.Email, "new-password", "")
+	assert.NoError(t, err)
+}
+
+func TestLocalProvider_PasswordResetExpired(t *testing.T) {
+	bed, provider, user, _ := setupLocalProvider(t)
+	ctx := context.Background()
+
+	expiring, err := authProviders.NewLocalProvider(bed.Db, authProviders.LocalProviderConfig{
+		Secret:           "test-secret",
+		Issuer:           "test",
+		PasswordResetTTL: time.Nanosecond,
+	})
+	assert.NoError(t, err)
+
+	token, err := expiring.PasswordResetToken(ctx, user.Email)
+	assert.NoError(t, err)
+
+	time.Sleep(time.Second)
+	_, err = provider.ResetPassword(ctx, token.Token, "new-password")
+	assert.ErrorIs(t, err, authProviders.ErrInvalidAccountToken)
+
+	// Tokens signed with another secret are rejected
+	other, err := authProviders.NewLocalProvider(bed.Db, authProviders.LocalProviderConfig{Secret: "other-secret", Issuer: "test"})
+	assert.NoError(t, err)
+
+	token, err = other.PasswordResetToken(ctx, user.Email)
+	assert.NoError(t, err)
+
+	_, err = provider.ResetPassword(ctx, token.Token, "new-password")
+	assert.ErrorIs(t, err, authProviders.ErrInvalidAccountToken)
+}
+
+func TestLocalProvider_EmailVerification(t *testing.T) {
+	bed, provider, user, _ := setupLocalProvider(t)
+	ctx := context.Background()
+
+	assert.False(t, user.IsEmailVerified())
+
+	_, err := provider.EmailVerificationToken(ctx, "missing@example.com")
+	assert.ErrorIs(t, err, authTypes.ErrProviderUserNotFound)
+
+	token, err := provider.EmailVerificationToken(ctx, user.Email)
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(48*time.Hour), token.ExpiresAt, time.Minute)
+
+	verified, err := provider.VerifyEmailToken(ctx, token.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, verified.ID)
+
+	_, err = provider.VerifyEmailToken(ctx, "invalid")
+	assert.ErrorIs(t, err, authProviders.ErrInvalidAccountToken)
+
+	// Tokens stop working when the email changes
+	err = bed.Db.DB.Model(&authModels.User{}).Where("id = ?", user.ID).Update("email", "changed"+user.Email).Error
+	assert.NoError(t, err)
+
+	_, err = provider.VerifyEmailToken(ctx, token.Token)
+	assert.ErrorIs(t, err, authProviders.ErrInvalidAccountToken)
+
+	changed := authModels.User{}
+	assert.NoError(t, bed.Db.DB.First(&changed, user.ID).Error)
+
+	err = authUtils.MarkEmailVerified(ctx, bed.Db, &changed, bed.AdminUser, "test-request", bed.Logger)
+	assert.NoError(t, err)
+
+	_, err = provider.EmailVerificationToken(ctx, "changed"+user.Email)
+	assert.ErrorIs(t, err, authProviders.ErrEmailAlreadyVerified)
+}
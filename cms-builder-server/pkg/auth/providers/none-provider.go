@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"context"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+)
+
+// NoneProvider runs the server without an auth provider, useful for offline development.
+// Access tokens are always rejected, so only the god token authenticates requests.
+// Users are still created in the database, without an account in any provider.
+type NoneProvider struct{}
+
+func NewNoneProvider() *NoneProvider {
+	return &NoneProvider{}
+}
+
+func (p *NoneProvider) Type() authTypes.AuthProviderType {
+	return authConstants.AuthProviderNone
+}
+
+func (p *NoneProvider) VerifyToken(ctx context.Context, token string) (*authTypes.ProviderUser, error) {
+	return nil, authTypes.ErrProviderDisabled
+}
+
+// CreateUser returns a user without ID, no account is registered.
+func (p *NoneProvider) CreateUser(ctx context.Context, input authTypes.ProviderUserInput) (*authTypes.ProviderUser, error) {
+	return &authTypes.ProviderUser{
+		Email: input.Email,
+		Name:  input.Name,
+	}, nil
+}
+
+func (p *NoneProvider) DeleteUser(ctx context.Context, id string) error {
+	return nil
+}
+
+func (p *NoneProvider) GetUserByEmail(ctx context.Context, email string) (*authTypes.ProviderUser, error) {
+	return nil, authTypes.ErrProviderDisabled
+}
@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Unknown key ids trigger a refetch, at most once per interval, to pick up rotated keys.
+const jwksMinRefreshInterval = time.Minute
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// jwksCache keeps the signing keys published by the issuer, refetched once the ttl expires.
+type jwksCache struct {
+	mu        sync.Mutex
+	url       string
+	ttl       time.Duration
+	client    *http.Client
+	keys      map[string]interface{}
+	fetchedAt time.Time
+}
+
+func newJwksCache(url string, ttl time.Duration, client *http.Client) *jwksCache {
+	return &jwksCache{
+		url:    url,
+		ttl:    ttl,
+		client: client,
+		keys:   map[string]interface{}{},
+	}
+}
+
+// get returns the public key with the given id.
+func (c *jwksCache) get(ctx context.Context, kid string) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expired := time.Since(c.fetchedAt) > c.ttl
+	key, ok := c.keys[kid]
+	if ok && !expired {
+		return key, nil
+	}
+
+	if expired || time.Since(c.fetchedAt) > jwksMinRefreshInterval {
+		if err := c.fetch(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	key, ok = c.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %s", kid)
+	}
+
+	return key, nil
+}
+
+func (c *jwksCache) fetch(ctx context.Context) error {
+	body := struct {
+		Keys []jsonWebKey `json:"keys"`
+	}{}
+
+	err := getJson(ctx, c.client, c.url, &body)
+	if err != nil {
+		return fmt.Errorf("error fetching jwks: %w", err)
+	}
+
+	keys := map[string]interface{}{}
+	for _, jwk := range body.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		key, err := jwk.publicKey()
+		if err != nil {
+			continue // Keys of unsupported types are skipped
+		}
+		keys[jwk.Kid] = key
+	}
+
+	c.keys = keys
+	c.fetchedAt = time.Now()
+	return nil
+}
+
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		curves := map[string]elliptic.Curve{
+			"P-256": elliptic.P256(),
+			"P-384": elliptic.P384(),
+			"P-521": elliptic.P521(),
+		}
+		curve, ok := curves[k.Crv]
+		if !ok {
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	bytes, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(bytes), nil
+}
+
+func getJson(ctx context.Context, client *http.Client, url string, target interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", res.StatusCode, url)
+	}
+
+	return json.NewDecoder(res.Body).Decode(target)
+}
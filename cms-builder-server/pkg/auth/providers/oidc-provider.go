@@ -0,0 +1,320 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+)
+
+const (
+	defaultJwksTTL        = time.Hour
+	defaultOIDCRoleClaim  = "roles"
+	oidcDiscoveryPath     = "/.well-known/openid-configuration"
+	oidcHttpClientTimeout = 10 * time.Second
+)
+
+var (
+	ErrMissingOIDCConfig   = errors.New("missing oidc issuer or client id")
+	ErrEmailNotVerified    = errors.New("email is not verified by the identity provider")
+	ErrCodeExchangeFailure = errors.New("error exchanging authorization code")
+)
+
+type OIDCProviderConfig struct {
+	Issuer       string                    // Issuer URL, the discovery document is read from it
+	ClientId     string                    // Expected audience of the ID tokens
+	ClientSecret string                    // Used to exchange authorization codes, and to verify HS256 tokens
+	RoleClaim    string                    // Claim holding the groups or roles, dot separated for nested claims, e.g. realm_access.roles
+	RoleMap      map[string]authTypes.Role // Claim values mapped to local roles, unmapped values are ignored
+	JwksTTL      time.Duration             // How long signing keys are cached, one hour by default
+	HttpClient   *http.Client
+}
+
+// OIDCProvider verifies ID tokens issued by an OpenID Connect identity provider, e.g. Keycloak, Azure AD or Okta.
+// Users are managed by the identity provider, they are registered locally on their first login.
+type OIDCProvider struct {
+	Config    OIDCProviderConfig
+	Discovery OIDCDiscovery
+	jwks      *jwksCache
+}
+
+// OIDCDiscovery holds the parts of the discovery document the provider uses.
+type OIDCDiscovery struct {
+	Issuer        string `json:"issuer"`
+	JwksUri       string `json:"jwks_uri"`
+	TokenEndpoint string `json:"token_endpoint"`
+}
+
+// OIDCTokens are the tokens returned by the identity provider for an authorization code.
+type OIDCTokens struct {
+	IdToken      string `json:"idToken"`
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken,omitempty"`
+	ExpiresIn    int    `json:"expiresIn"`
+	Subject      string `json:"-"` // Provider id of the user who signed in
+}
+
+// NewOIDCProvider reads the discovery document of the issuer.
+func NewOIDCProvider(ctx context.Context, cfg OIDCProviderConfig) (*OIDCProvider, error) {
+	if cfg.Issuer == "" || cfg.ClientId == "" {
+		return nil, ErrMissingOIDCConfig
+	}
+
+	cfg.Issuer = strings.TrimSuffix(cfg.Issuer, "/")
+
+	if cfg.RoleClaim == "" {
+		cfg.RoleClaim = defaultOIDCRoleClaim
+	}
+
+	if cfg.JwksTTL <= 0 {
+		cfg.JwksTTL = defaultJwksTTL
+	}
+
+	if cfg.HttpClient == nil {
+		cfg.HttpClient = &http.Client{Timeout: oidcHttpClientTimeout}
+	}
+
+	discovery := OIDCDiscovery{}
+	err := getJson(ctx, cfg.HttpClient, cfg.Issuer+oidcDiscoveryPath, &discovery)
+	if err != nil {
+		return nil, fmt.Errorf("error reading oidc discovery document: %w", err)
+	}
+
+	if strings.TrimSuffix(discovery.Issuer, "/") != cfg.Issuer {
+		return nil, fmt.Errorf("oidc issuer mismatch, expected %s but got %s", cfg.Issuer, discovery.Issuer)
+	}
+
+	if discovery.JwksUri == "" {
+		return nil, fmt.Errorf("oidc discovery document has no jwks_uri")
+	}
+
+	return &OIDCProvider{
+		Config:    cfg,
+		Discovery: discovery,
+		jwks:      newJwksCache(discovery.JwksUri, cfg.JwksTTL, cfg.HttpClient),
+	}, nil
+}
+
+func (p *OIDCProvider) Type() authTypes.AuthProviderType {
+	return authConstants.AuthProviderOIDC
+}
+
+// VerifyToken verifies the signature, issuer, audience and expiration of an ID token,
+// and maps its claims to the user.
+func (p *OIDCProvider) VerifyToken(ctx context.Context, token string) (*authTypes.ProviderUser, error) {
+	methods := []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+	if p.Config.ClientSecret != "" {
+		methods = append(methods, "HS256")
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); ok {
+			return []byte(p.Config.ClientSecret), nil
+		}
+
+		kid, _ := t.Header["kid"].(string)
+		return p.jwks.get(ctx, kid)
+	},
+		jwt.WithValidMethods(methods),
+		jwt.WithIssuer(p.Discovery.Issuer),
+		jwt.WithAudience(p.Config.ClientId),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", authTypes.ErrProviderInvalidToken, err.Error())
+	}
+
+	return p.toProviderUser(claims)
+}
+
+// ManagesRoles tells whether roles come from the role claim, which is the case once a role map is configured.
+func (p *OIDCProvider) ManagesRoles() bool {
+	return len(p.Config.RoleMap) > 0
+}
+
+// CreateUser doesn't register anyone, accounts are managed by the identity provider.
+// Local users are linked by email on their first login.
+func (p *OIDCProvider) CreateUser(ctx context.Context, input authTypes.ProviderUserInput) (*authTypes.ProviderUser, error) {
+	return &authTypes.ProviderUser{
+		Email: input.Email,
+		Name:  input.Name,
+	}, nil
+}
+
+func (p *OIDCProvider) DeleteUser(ctx context.Context, id string) error {
+	return nil
+}
+
+func (p *OIDCProvider) GetUserByEmail(ctx context.Context, email string) (*authTypes.ProviderUser, error) {
+	return nil, authTypes.ErrProviderUserNotFound
+}
+
+// ExchangeCode exchanges an authorization code for tokens at the token endpoint of the issuer.
+// The ID token is verified before being returned.
+func (p *OIDCProvider) ExchangeCode(ctx context.Context, code string, redirectUri string, codeVerifier string) (*OIDCTokens, error) {
+	if p.Discovery.TokenEndpoint == "" {
+		return nil, fmt.Errorf("%w: issuer has no token endpoint", ErrCodeExchangeFailure)
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", redirectUri)
+	form.Set("client_id", p.Config.ClientId)
+	if p.Config.ClientSecret != "" {
+		form.Set("client_secret", p.Config.ClientSecret)
+	}
+	if codeVerifier != "" {
+		form.Set("code_verifier", codeVerifier)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.Discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := p.Config.HttpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCodeExchangeFailure, err.Error())
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: unexpected status %d", ErrCodeExchangeFailure, res.StatusCode)
+	}
+
+	body := struct {
+		IdToken      string `json:"id_token"`
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int    `json:"expires_in"`
+	}{}
+	err = json.NewDecoder(res.Body).Decode(&body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCodeExchangeFailure, err.Error())
+	}
+
+	providerUser, err := p.VerifyToken(ctx, body.IdToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OIDCTokens{
+		IdToken:      body.IdToken,
+		AccessToken:  body.AccessToken,
+		RefreshToken: body.RefreshToken,
+		ExpiresIn:    body.ExpiresIn,
+		Subject:      providerUser.ID,
+	}, nil
+}
+
+func (p *OIDCProvider) toProviderUser(claims jwt.MapClaims) (*authTypes.ProviderUser, error) {
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", authTypes.ErrProviderInvalidToken)
+	}
+
+	// Users are linked by email, so unverified emails are rejected
+	emailVerified, ok := claims["email_verified"].(bool)
+	if ok && !emailVerified {
+		return nil, ErrEmailNotVerified
+	}
+
+	// Azure AD tells no email_verified, its upn is left unverified and isn't linked to existing users
+	email, _ := claims["email"].(string)
+	if email == "" {
+		email, _ = claims["upn"].(string)
+		emailVerified = false
+	}
+
+	name, _ := claims["name"].(string)
+	if name == "" {
+		given, _ := claims["given_name"].(string)
+		family, _ := claims["family_name"].(string)
+		name = strings.TrimSpace(given + " " + family)
+	}
+	if name == "" {
+		name, _ = claims["preferred_username"].(string)
+	}
+
+	// Refreshed tokens get a new jti and iat, so only sid and auth_time identify the session.
+	// Without auth_time, revoked users are rejected until they sign in again through the token route.
+	sessionId, _ := claims["sid"].(string)
+	authTime, _ := claims["auth_time"].(float64)
+	issuedAt, _ := claims["iat"].(float64)
+
+	providerUser := &authTypes.ProviderUser{
+		ID:            subject,
+		Email:         email,
+		Name:          name,
+		Roles:         p.mapRoles(claims),
+		SessionId:     sessionId,
+		EmailVerified: emailVerified,
+	}
+
+	if authTime > 0 {
+		providerUser.AuthTime = time.Unix(int64(authTime), 0)
+	}
+
+	if issuedAt > 0 {
+		providerUser.IssuedAt = time.Unix(int64(issuedAt), 0)
+	}
+
+	// Authentication methods of RFC 8176 involving a second factor
+	if methods, ok := claims["amr"].([]interface{}); ok {
+		for _, method := range methods {
+			switch method {
+			case "mfa", "otp", "hwk", "sms":
+				providerUser.MfaVerified = true
+			}
+		}
+	}
+
+	return providerUser, nil
+}
+
+// mapRoles maps the values of the role claim to local roles.
+func (p *OIDCProvider) mapRoles(claims jwt.MapClaims) []authTypes.Role {
+	var value interface{} = map[string]interface{}(claims)
+	for _, key := range strings.Split(p.Config.RoleClaim, ".") {
+		nested, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = nested[key]
+	}
+
+	values := []string{}
+	switch v := value.(type) {
+	case string:
+		values = strings.FieldsFunc(v, func(r rune) bool { return r == ' ' || r == ',' })
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+
+	roles := []authTypes.Role{}
+	for _, value := range values {
+		role, ok := p.Config.RoleMap[value]
+		if ok && !slices.Contains(roles, role) {
+			roles = append(roles, role)
+		}
+	}
+
+	return roles
+}
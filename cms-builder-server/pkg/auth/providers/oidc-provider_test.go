@@ -0,0 +1,414 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authProviders "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/providers"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	testPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/testing"
+)
+
+const testClientId = "cms-builder"
+
+// testIssuer stands in for an identity provider, serving discovery, keys and the token endpoint.
+type testIssuer struct {
+	*httptest.Server
+	mu        sync.Mutex
+	kid       string
+	key       *rsa.PrivateKey
+	jwksCalls int
+	codes     map[string]jwt.MapClaims
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	issuer := &testIssuer{codes: map[string]jwt.MapClaims{}}
+	issuer.rotateKey(t, "key-1")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":         issuer.URL,
+			"jwks_uri":       issuer.URL + "/keys",
+			"token_endpoint": issuer.URL + "/token",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		issuer.mu.Lock()
+		defer issuer.mu.Unlock()
+		issuer.jwksCalls++
+
+		pub := issuer.key.PublicKey
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": issuer.kid,
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := issuer.codes[r.FormValue("code")]
+		if !ok || r.FormValue("client_id") != testClientId {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id_token":     issuer.sign(t, claims),
+			"access_token": "access",
+			"expires_in":   3600,
+		})
+	})
+
+	issuer.Server = httptest.NewServer(mux)
+	t.Cleanup(issuer.Close)
+	return issuer
+}
+
+func (i *testIssuer) rotateKey(t *testing.T, kid string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.kid = kid
+	i.key = key
+}
+
+func (i *testIssuer) claims(email string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":            i.URL,
+		"aud":            testClientId,
+		"sub":            "subject-" + email,
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"email":          email,
+		"email_verified": true,
+		"given_name":     "Jane",
+		"family_name":    "Doe",
+	}
+}
+
+func (i *testIssuer) sign(t *testing.T, claims jwt.MapClaims) string {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = i.kid
+	signed, err := token.SignedString(i.key)
+	assert.NoError(t, err)
+	return signed
+}
+
+func setupOIDCProvider(t *testing.T, issuer *testIssuer, roleClaim string) *authProviders.OIDCProvider {
+	provider, err := authProviders.NewOIDCProvider(context.Background(), authProviders.OIDCProviderConfig{
+		Issuer:    issuer.URL,
+		ClientId:  testClientId,
+		RoleClaim: roleClaim,
+		RoleMap: map[string]authTypes.Role{
+			"cms-admins": authConstants.AdminRole,
+			"staff":      authConstants.VisitorRole,
+		},
+	})
+	assert.NoError(t, err)
+	return provider
+}
+
+func TestNewOIDCProvider_MissingConfig(t *testing.T) {
+	_, err := authProviders.NewOIDCProvider(context.Background(), authProviders.OIDCProviderConfig{})
+	assert.ErrorIs(t, err, authProviders.ErrMissingOIDCConfig)
+}
+
+func TestOIDCProvider_VerifyToken(t *testing.T) {
+	issuer := newTestIssuer(t)
+	provider := setupOIDCProvider(t, issuer, "")
+	ctx := context.Background()
+
+	claims := issuer.claims("jane@example.com")
+	claims["roles"] = []string{"cms-admins", "unmapped"}
+
+	user, err := provider.VerifyToken(ctx, issuer.sign(t, claims))
+	assert.NoError(t, err)
+	assert.Equal(t, "subject-jane@example.com", user.ID)
+	assert.Equal(t, "jane@example.com", user.Email)
+	assert.Equal(t, "Jane Doe", user.Name)
+	assert.Equal(t, []authTypes.Role{authConstants.AdminRole}, user.Roles)
+
+	// Keys are cached
+	_, err = provider.VerifyToken(ctx, issuer.sign(t, claims))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, issuer.jwksCalls)
+}
+
+func TestOIDCProvider_InvalidTokens(t *testing.T) {
+	issuer := newTestIssuer(t)
+	provider := setupOIDCProvider(t, issuer, "")
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		mutate func(claims jwt.MapClaims)
+	}{
+		{"wrong audience", func(c jwt.MapClaims) { c["aud"] = "another-client" }},
+		{"wrong issuer", func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }},
+		{"expired", func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() }},
+		{"no expiration", func(c jwt.MapClaims) { delete(c, "exp") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := issuer.claims("jane@example.com")
+			tt.mutate(claims)
+
+			_, err := provider.VerifyToken(ctx, issuer.sign(t, claims))
+			assert.ErrorIs(t, err, authTypes.ErrProviderInvalidToken)
+		})
+	}
+
+	// Tokens signed with the client secret are only accepted when one is configured
+	hmacToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, issuer.claims("jane@example.com")).SignedString([]byte(""))
+	assert.NoError(t, err)
+	_, err = provider.VerifyToken(ctx, hmacToken)
+	assert.ErrorIs(t, err, authTypes.ErrProviderInvalidToken)
+
+	claims := issuer.claims("jane@example.com")
+	claims["email_verified"] = false
+	_, err = provider.VerifyToken(ctx, issuer.sign(t, claims))
+	assert.ErrorIs(t, err, authProviders.ErrEmailNotVerified)
+}
+
+func TestOIDCProvider_NestedRoleClaim(t *testing.T) {
+	issuer := newTestIssuer(t)
+	provider := setupOIDCProvider(t, issuer, "realm_access.roles")
+
+	claims := issuer.claims("jane@example.com")
+	claims["realm_access"] = map[string]interface{}{"roles": []string{"staff", "cms-admins", "staff"}}
+
+	user, err := provider.VerifyToken(context.Background(), issuer.sign(t, claims))
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []authTypes.Role{authConstants.VisitorRole, authConstants.AdminRole}, user.Roles)
+}
+
+func TestOIDCProvider_KeyRotation(t *testing.T) {
+	issuer := newTestIssuer(t)
+	provider := setupOIDCProvider(t, issuer, "")
+	ctx := context.Background()
+
+	_, err := provider.VerifyToken(ctx, issuer.sign(t, issuer.claims("jane@example.com")))
+	assert.NoError(t, err)
+
+	// Unknown key ids are refetched at most once a minute
+	issuer.rotateKey(t, "key-2")
+	_, err = provider.VerifyToken(ctx, issuer.sign(t, issuer.claims("jane@example.com")))
+	assert.ErrorIs(t, err, authTypes.ErrProviderInvalidToken)
+	assert.Equal(t, 1, issuer.jwksCalls)
+
+	// Rotated keys are picked up once the cache expires
+	provider, err = authProviders.NewOIDCProvider(ctx, authProviders.OIDCProviderConfig{
+		Issuer:   issuer.URL,
+		ClientId: testClientId,
+		JwksTTL:  time.Millisecond,
+	})
+	assert.NoError(t, err)
+
+	_, err = provider.VerifyToken(ctx, issuer.sign(t, issuer.claims("jane@example.com")))
+	assert.NoError(t, err)
+
+	issuer.rotateKey(t, "key-3")
+	time.Sleep(5 * time.Millisecond)
+	_, err = provider.VerifyToken(ctx, issuer.sign(t, issuer.claims("jane@example.com")))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, issuer.jwksCalls)
+}
+
+func TestOIDCProvider_ProvisionsUser(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+	issuer := newTestIssuer(t)
+	provider := setupOIDCProvider(t, issuer, "groups")
+
+	email := strings.ToLower(testPkg.RandomEmail())
+	claims := issuer.claims(strings.ToUpper(email))
+	claims["groups"] = "staff"
+	token := issuer.sign(t, claims)
+
+	user, err := authUtils.VerifyUser(token, provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+	assert.NoError(t, err)
+	assert.Equal(t, email, user.Email)
+	assert.Equal(t, "subject-"+strings.ToUpper(email), user.FirebaseId)
+	assert.Equal(t, string(authConstants.VisitorRole), user.Roles)
+
+	// Later logins resolve to the same user
+	again, err := authUtils.VerifyUser(token, provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, again.ID)
+}
+
+func TestOIDCProvider_ExchangeCode(t *testing.T) {
+	issuer := newTestIssuer(t)
+	provider := setupOIDCProvider(t, issuer, "")
+	ctx := context.Background()
+
+	issuer.codes["valid-code"] = issuer.claims("jane@example.com")
+
+	tokens, err := provider.ExchangeCode(ctx, "valid-code", "http://localhost/callback", "verifier")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, tokens.IdToken)
+	assert.Equal(t, "access", tokens.AccessToken)
+
+	_, err = provider.ExchangeCode(ctx, "unknown-code", "http://localhost/callback", "")
+	assert.ErrorIs(t, err, authProviders.ErrCodeExchangeFailure)
+}
+
+func TestOIDCProvider_LinksVerifiedEmailsOnly(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+	issuer := newTestIssuer(t)
+	provider := setupOIDCProvider(t, issuer, "groups")
+
+	email := strings.ToLower(testPkg.RandomEmail())
+	existing := &authModels.User{Email: email, FirstName: "Existing", Roles: string(authConstants.AdminRole)}
+	assert.NoError(t, bed.Db.DB.Create(existing).Error)
+
+	// Azure AD style token, the upn is not verified by the provider
+	claims := issuer.claims("")
+	delete(claims, "email")
+	delete(claims, "email_verified")
+	claims["upn"] = email
+	claims["sub"] = "subject-upn-" + email
+
+	_, err := authUtils.VerifyUser(issuer.sign(t, claims), provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+	assert.ErrorIs(t, err, authUtils.ErrUnverifiedEmailLink)
+
+	// Tokens not telling whether the email is verified aren't linked either
+	claims = issuer.claims(email)
+	delete(claims, "email_verified")
+	claims["sub"] = "subject-unverified-" + email
+
+	_, err = authUtils.VerifyUser(issuer.sign(t, claims), provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+	assert.ErrorIs(t, err, authUtils.ErrUnverifiedEmailLink)
+
+	stored := &authModels.User{}
+	assert.NoError(t, bed.Db.DB.First(stored, existing.ID).Error)
+	assert.Empty(t, stored.FirebaseId)
+
+	// Verified emails are linked
+	user, err := authUtils.VerifyUser(issuer.sign(t, issuer.claims(email)), provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+	assert.NoError(t, err)
+	assert.Equal(t, existing.ID, user.ID)
+	assert.Equal(t, "subject-"+email, user.FirebaseId)
+
+	// And never relinked to another account
+	claims = issuer.claims(email)
+	claims["sub"] = "subject-other-" + email
+
+	_, err = authUtils.VerifyUser(issuer.sign(t, claims), provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+	assert.ErrorIs(t, err, authUtils.ErrUnverifiedEmailLink)
+}
+
+func TestOIDCProvider_SyncsRolesOnSignIn(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+	issuer := newTestIssuer(t)
+	provider := setupOIDCProvider(t, issuer, "groups")
+
+	email := strings.ToLower(testPkg.RandomEmail())
+	claims := issuer.claims(email)
+	claims["groups"] = "cms-admins"
+
+	user, err := authUtils.VerifyUser(issuer.sign(t, claims), provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+	assert.NoError(t, err)
+
+	stored := &authModels.User{}
+	assert.NoError(t, bed.Db.DB.First(stored, user.ID).Error)
+	assert.Equal(t, string(authConstants.AdminRole), stored.Roles)
+
+	// Demoted at the provider
+	claims = issuer.claims(email)
+	claims["groups"] = "staff"
+
+	user, err = authUtils.VerifyUser(issuer.sign(t, claims), provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+	assert.NoError(t, err)
+	assert.Equal(t, string(authConstants.VisitorRole), user.Roles)
+
+	stored = &authModels.User{}
+	assert.NoError(t, bed.Db.DB.First(stored, user.ID).Error)
+	assert.Equal(t, string(authConstants.VisitorRole), stored.Roles)
+}
+
+func TestOIDCProvider_RevokedWithoutAuthTime(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+	issuer := newTestIssuer(t)
+	provider := setupOIDCProvider(t, issuer, "groups")
+
+	email := strings.ToLower(testPkg.RandomEmail())
+	signed := func(issuedAt time.Time) string {
+		claims := issuer.claims(email)
+		claims["iat"] = issuedAt.Unix()
+		return issuer.sign(t, claims)
+	}
+
+	stolen := signed(time.Now().Add(-time.Minute))
+	user, err := authUtils.VerifyUser(stolen, provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+	assert.NoError(t, err)
+
+	err = authUtils.RevokeUserSessions(context.Background(), bed.Db, user, user, "test", "test-request", bed.Logger)
+	assert.NoError(t, err)
+
+	// Refreshed tokens are rejected as well, until the user signs in again
+	_, err = authUtils.VerifyUser(stolen, provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+	assert.ErrorIs(t, err, authUtils.ErrSessionRevoked)
+
+	refreshed := signed(time.Now().Add(time.Minute))
+	_, err = authUtils.VerifyUser(refreshed, provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+	assert.ErrorIs(t, err, authUtils.ErrSessionRevoked)
+
+	assert.NoError(t, authUtils.LiftSessionRevocations(context.Background(), bed.Db, user.FirebaseId, bed.Logger))
+
+	_, err = authUtils.VerifyUser(refreshed, provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+	assert.NoError(t, err)
+
+	// Tokens issued before the revocation never work again
+	_, err = authUtils.VerifyUser(stolen, provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+	assert.ErrorIs(t, err, authUtils.ErrSessionRevoked)
+}
+
+func TestOIDCProvider_RevokedWithAuthTime(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+	issuer := newTestIssuer(t)
+	provider := setupOIDCProvider(t, issuer, "groups")
+
+	email := strings.ToLower(testPkg.RandomEmail())
+	signed := func(authTime time.Time, issuedAt time.Time) string {
+		claims := issuer.claims(email)
+		claims["auth_time"] = authTime.Unix()
+		claims["iat"] = issuedAt.Unix()
+		claims["sid"] = "session-" + email
+		return issuer.sign(t, claims)
+	}
+
+	signedIn := time.Now().Add(-time.Minute)
+	user, err := authUtils.VerifyUser(signed(signedIn, signedIn), provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+	assert.NoError(t, err)
+
+	err = authUtils.RevokeUserSessions(context.Background(), bed.Db, user, user, "test", "test-request", bed.Logger)
+	assert.NoError(t, err)
+
+	// Refreshing keeps the auth time of the revoked session
+	_, err = authUtils.VerifyUser(signed(signedIn, time.Now().Add(time.Minute)), provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+	assert.ErrorIs(t, err, authUtils.ErrSessionRevoked)
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"net/http"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authHandlers "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/handlers"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	loggerTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/logger/types"
+	rmPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager"
+	rmHandlers "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/handlers"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
+	rmValidators "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/validators"
+	svrTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/types"
+)
+
+func SetupApiKeyResource(resourceManager *rmPkg.ResourceManager, db *dbTypes.DatabaseConnection, log *loggerTypes.Logger) *rmTypes.ResourceConfig {
+
+	log.Info().Msg("Initializing ApiKey resource")
+
+	// Keys grant access on behalf of any user, only admins manage them
+	permissions := authTypes.RolePermissionMap{
+		authConstants.AdminRole: authConstants.AllAllowedAccess,
+	}
+
+	validators := rmTypes.ValidatorsMap{
+		"Name": rmTypes.ValidatorsList{rmValidators.RequiredValidator},
+	}
+
+	// Keys can't be updated, the checks made on creation would be bypassed and revocation undone
+	handlers := &rmTypes.ApiHandlers{
+		Create: authHandlers.ApiKeyCreateHandler(resourceManager),
+		Update: rmHandlers.DisabledHandler,
+	}
+
+	routes := []svrTypes.Route{
+		{
+			Path:         "/api/api-keys/{id}/revoke",
+			Handler:      authHandlers.ApiKeyRevokeHandler(resourceManager, db),
+			Name:         "api-keys-revoke",
+			RequiresAuth: true,
+			Methods:      []string{http.MethodPut},
+		},
+	}
+
+	config := &rmTypes.ResourceConfig{
+		Model:       authModels.ApiKey{},
+		Validators:  validators,
+		Permissions: permissions,
+		Handlers:    handlers,
+		Routes:      routes,
+		Search: &rmTypes.SearchConfig{
+			Fields:     []string{"Name", "Prefix"},
+			LabelField: "Name",
+		},
+	}
+
+	return config
+}
@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"net/http"
+
+	authHandlers "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/handlers"
+	authProviders "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/providers"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	svrTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/types"
+)
+
+// SetupLocalAuthRoutes returns the login, refresh and logout routes of the local auth provider,
+// along with the routes managing the MFA enrollment of the current user.
+func SetupLocalAuthRoutes(provider *authProviders.LocalProvider) []svrTypes.Route {
+	return []svrTypes.Route{
+		{
+			Path:         "/auth/login",
+			Handler:      authHandlers.LoginHandler(provider),
+			Name:         "login",
+			RequiresAuth: false,
+			Methods:      []string{http.MethodPost},
+		},
+		{
+			Path:         "/auth/refresh",
+			Handler:      authHandlers.RefreshHandler(provider),
+			Name:         "refresh",
+			RequiresAuth: false,
+			Methods:      []string{http.MethodPost},
+		},
+		{
+			Path:         "/auth/logout",
+			Handler:      authHandlers.LogoutHandler(provider),
+			Name:         "logout",
+			RequiresAuth: false,
+			Methods:      []string{http.MethodPost},
+		},
+		{
+			Path:         "/auth/mfa/enroll",
+			Handler:      authHandlers.MfaEnrollHandler(provider.DB, provider.Config.Issuer),
+			Name:         "mfa-enroll",
+			RequiresAuth: true,
+			Methods:      []string{http.MethodPost},
+		},
+		{
+			Path:         "/auth/mfa/confirm",
+			Handler:      authHandlers.MfaConfirmHandler(provider.DB),
+			Name:         "mfa-confirm",
+			RequiresAuth: true,
+			Methods:      []string{http.MethodPost},
+		},
+		{
+			Path:         "/auth/mfa/disable",
+			Handler:      authHandlers.MfaDisableHandler(provider.DB),
+			Name:         "mfa-disable",
+			RequiresAuth: true,
+			Methods:      []string{http.MethodPost},
+		},
+	}
+}
+
+// SetupAccountRoutes returns the password reset and email verification routes of the local auth provider.
+// Links are emailed by the mailer, clients send the tokens back from the pages they open.
+func SetupAccountRoutes(provider *authProviders.LocalProvider, mailer *authUtils.AccountMailer) []svrTypes.Route {
+	return []svrTypes.Route{
+		{
+			Path:         "/auth/forgot-password",
+			Handler:      authHandlers.ForgotPasswordHandler(provider, mailer),
+			Name:         "forgot-password",
+			RequiresAuth: false,
+			Methods:      []string{http.MethodPost},
+		},
+		{
+			Path:         "/auth/reset-password",
+			Handler:      authHandlers.ResetPasswordHandler(provider, provider.DB),
+			Name:         "reset-password",
+			RequiresAuth: false,
+			Methods:      []string{http.MethodPost},
+		},
+		{
+			Path:         "/auth/verify-email/send",
+			Handler:      authHandlers.SendVerificationHandler(provider, mailer),
+			Name:         "verify-email-send",
+			RequiresAuth: false,
+			Methods:      []string{http.MethodPost},
+		},
+		{
+			Path:         "/auth/verify-email",
+			Handler:      authHandlers.VerifyEmailHandler(provider, provider.DB),
+			Name:         "verify-email",
+			RequiresAuth: false,
+			Methods:      []string{http.MethodPost},
+		},
+	}
+}
+
+// SetupOIDCRoutes returns the route exchanging authorization codes of the OIDC auth provider.
+func SetupOIDCRoutes(provider *authProviders.OIDCProvider, db *dbTypes.DatabaseConnection) []svrTypes.Route {
+	return []svrTypes.Route{
+		{
+			Path:         "/auth/oidc/token",
+			Handler:      authHandlers.OIDCTokenHandler(provider, db),
+			Name:         "oidc-token",
+			RequiresAuth: false,
+			Methods:      []string{http.MethodPost},
+		},
+	}
+}
+
+// SetupSessionRoutes returns the routes managing the sessions of the current user, for every provider.
+func SetupSessionRoutes(db *dbTypes.DatabaseConnection) []svrTypes.Route {
+	return []svrTypes.Route{
+		{
+			Path:         "/auth/logout-all",
+			Handler:      authHandlers.LogoutAllHandler(db),
+			Name:         "logout-all",
+			RequiresAuth: true,
+			Methods:      []string{http.MethodPost},
+		},
+	}
+}
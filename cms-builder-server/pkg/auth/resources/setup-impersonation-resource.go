@@ -0,0 +1,31 @@
+package auth
+
+import (
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	loggerTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/logger/types"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
+)
+
+func SetupImpersonationSessionResource(log *loggerTypes.Logger) *rmTypes.ResourceConfig {
+
+	log.Info().Msg("Initializing ImpersonationSession resource")
+
+	// Sessions are started through the users impersonate route, admins may review them
+	// or delete them to end the impersonation early
+	permissions := authTypes.RolePermissionMap{
+		authConstants.AdminRole: []authTypes.CrudOperation{authConstants.OperationRead, authConstants.OperationDelete},
+	}
+
+	config := &rmTypes.ResourceConfig{
+		Model:       authModels.ImpersonationSession{},
+		Permissions: permissions,
+		Search: &rmTypes.SearchConfig{
+			Fields:     []string{"Reason"},
+			LabelField: "Reason",
+		},
+	}
+
+	return config
+}
@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	loggerTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/logger/types"
+	rmPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
+	rmValidators "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/validators"
+)
+
+// Roles and permissions decide what everyone else may do, only admins manage them.
+// Changes go through the default handlers, so they are recorded in the database log.
+var rolePermissions = authTypes.RolePermissionMap{
+	authConstants.AdminRole: authConstants.AllAllowedAccess,
+}
+
+func SetupRoleResource(log *loggerTypes.Logger) *rmTypes.ResourceConfig {
+
+	log.Info().Msg("Initializing Role resource")
+
+	validators := rmTypes.ValidatorsMap{
+		"Name": rmTypes.ValidatorsList{rmValidators.RequiredValidator},
+	}
+
+	config := &rmTypes.ResourceConfig{
+		Model:       authModels.Role{},
+		Validators:  validators,
+		Permissions: rolePermissions,
+		Search: &rmTypes.SearchConfig{
+			Fields:     []string{"Name", "Description"},
+			LabelField: "Name",
+		},
+	}
+
+	return config
+}
+
+func SetupPermissionResource(resourceManager *rmPkg.ResourceManager, log *loggerTypes.Logger) *rmTypes.ResourceConfig {
+
+	log.Info().Msg("Initializing Permission resource")
+
+	validators := rmTypes.ValidatorsMap{
+		"Resource":   rmTypes.ValidatorsList{rmValidators.RequiredValidator, resourceNameValidator(resourceManager)},
+		"Operations": rmTypes.ValidatorsList{operationsValidator},
+	}
+
+	config := &rmTypes.ResourceConfig{
+		Model:       authModels.Permission{},
+		Validators:  validators,
+		Permissions: rolePermissions,
+	}
+
+	return config
+}
+
+func SetupUserRoleResource(log *loggerTypes.Logger) *rmTypes.ResourceConfig {
+
+	log.Info().Msg("Initializing UserRole resource")
+
+	config := &rmTypes.ResourceConfig{
+		Model:       authModels.UserRole{},
+		Permissions: rolePermissions,
+	}
+
+	return config
+}
+
+// resourceNameValidator checks the field names a registered resource, permissions of unknown resources never apply.
+func resourceNameValidator(resourceManager *rmPkg.ResourceManager) rmTypes.Validator {
+	return func(fieldName string, instance rmTypes.EntityData, output *rmTypes.ValidationError) *rmTypes.ValidationError {
+		value, _ := instance[fieldName].(string)
+		if value == "" {
+			return output
+		}
+
+		_, err := resourceManager.GetResourceByName(value)
+		if err != nil {
+			output.Error = fmt.Sprintf("%s %s is not a known resource", fieldName, value)
+		}
+
+		return output
+	}
+}
+
+// operationsValidator checks every item of a comma-separated list of operations is a known operation.
+func operationsValidator(fieldName string, instance rmTypes.EntityData, output *rmTypes.ValidationError) *rmTypes.ValidationError {
+	value, _ := instance[fieldName].(string)
+
+	for _, operation := range strings.Split(value, ",") {
+		operation = strings.ToLower(strings.TrimSpace(operation))
+		if operation != "" && !slices.Contains(authConstants.AllAllowedAccess, authTypes.CrudOperation(operation)) {
+			output.Error = fmt.Sprintf("%s has an unknown operation %s", fieldName, operation)
+			return output
+		}
+	}
+
+	return output
+}
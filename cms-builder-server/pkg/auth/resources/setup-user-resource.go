@@ -7,7 +7,6 @@ import (
 	authHandlers "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/handlers"
 	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
 	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
-	cliPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/clients"
 	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
 	loggerTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/logger/types"
 	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
@@ -15,7 +14,7 @@ import (
 	svrTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/types"
 )
 
-func SetupUserResource(firebase *cliPkg.FirebaseManager, db *dbTypes.DatabaseConnection, log *loggerTypes.Logger, getSystemUser func() *authModels.User) *rmTypes.ResourceConfig {
+func SetupUserResource(provider authTypes.AuthProvider, db *dbTypes.DatabaseConnection, log *loggerTypes.Logger, getSystemUser func() *authModels.User) *rmTypes.ResourceConfig {
 
 	log.Info().Msg("Initializing User resource")
 
@@ -32,21 +31,29 @@ func SetupUserResource(firebase *cliPkg.FirebaseManager, db *dbTypes.DatabaseCon
 	}
 
 	handlers := &rmTypes.ApiHandlers{
-		List:   authHandlers.UserListHandler,
-		Detail: authHandlers.UserDetailHandler,
-		Create: authHandlers.UserCreateHandler,
-		Update: authHandlers.UserUpdateHandler,
-		Delete: authHandlers.UserDeleteHandler,
+		List:      authHandlers.UserListHandler,
+		Detail:    authHandlers.UserDetailHandler,
+		Create:    authHandlers.UserCreateHandler,
+		Update:    authHandlers.UserUpdateHandler,
+		Delete:    authHandlers.UserDeleteHandler,
+		Aggregate: authHandlers.UserAggregateHandler,
 	}
 
 	routes := []svrTypes.Route{
 		{
 			Path:         "/auth/register",
-			Handler:      authHandlers.RegisterVisitorController(firebase, db, getSystemUser),
+			Handler:      authHandlers.RegisterVisitorController(provider, db, getSystemUser),
 			Name:         "register",
 			RequiresAuth: false,
 			Methods:      []string{http.MethodPost},
 		},
+		{
+			Path:         "/api/users/{id}/impersonate",
+			Handler:      authHandlers.ImpersonateHandler(db),
+			Name:         "users-impersonate",
+			RequiresAuth: true,
+			Methods:      []string{http.MethodPost},
+		},
 	}
 
 	config := &rmTypes.ResourceConfig{
@@ -56,6 +63,8 @@ func SetupUserResource(firebase *cliPkg.FirebaseManager, db *dbTypes.DatabaseCon
 		Permissions:     permissions,
 		Handlers:        handlers,
 		Routes:          routes,
+		// Not searchable, users are readable by visitors but the search can't limit
+		// them to their own record like UserListHandler does
 	}
 
 	return config
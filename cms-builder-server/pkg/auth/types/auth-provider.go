@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrProviderUserExists   = errors.New("user already exists in the auth provider")
+	ErrProviderUserNotFound = errors.New("user not found in the auth provider")
+	ErrProviderInvalidToken = errors.New("invalid access token")
+	ErrProviderDisabled     = errors.New("auth provider is disabled")
+)
+
+type AuthProviderType string
+
+// ProviderUser is a user as known by the auth provider.
+type ProviderUser struct {
+	ID           string // ID of the user in the provider, stored as the user FirebaseId
+	Email        string
+	Name         string
+	PasswordHash string `json:"-"` // Kept on the user by providers that own credentials, empty otherwise
+	Roles        []Role // Roles granted by the provider, users are registered as visitors when empty
+
+	// Session the token belongs to, empty when the provider doesn't tell.
+	// Tokens refreshed without signing in again keep the same session and auth time.
+	SessionId string
+	AuthTime  time.Time
+	IssuedAt  time.Time // When the token was issued, checked against revocations when the auth time is unknown
+
+	MfaVerified   bool // Whether the user signed in with a second factor
+	EmailVerified bool // Whether the provider has verified the email of the user
+}
+
+// ProviderUserInput holds the data needed to register a user in the auth provider.
+type ProviderUserInput struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// AuthProvider verifies access tokens and manages the accounts users sign in with.
+type AuthProvider interface {
+	Type() AuthProviderType
+	VerifyToken(ctx context.Context, token string) (*ProviderUser, error)
+	CreateUser(ctx context.Context, input ProviderUserInput) (*ProviderUser, error) // Returns ErrProviderUserExists when the email is taken
+	DeleteUser(ctx context.Context, id string) error
+	GetUserByEmail(ctx context.Context, email string) (*ProviderUser, error)
+}
+
+// RoleManagingProvider is implemented by providers granting the roles of their users, e.g. from token claims.
+// The roles of the provider user then replace the local roles of the user on every sign in.
+type RoleManagingProvider interface {
+	ManagesRoles() bool
+}
@@ -16,5 +16,6 @@ type RegisterUserInput struct {
 	Email            string `json:"email"`
 	Password         string `json:"password"`
 	Roles            []Role `json:"roles"`
-	RegisterFirebase bool
+	RegisterFirebase bool   // Registers the user in the configured auth provider
+	EmailVerified    bool   `json:"-"` // Marks the email as verified, e.g. for users seeded from the config
 }
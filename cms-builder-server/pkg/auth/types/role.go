@@ -1,9 +1,24 @@
 package auth
 
+import "strings"
+
 // Role represents a user role.
 type Role string
 
+// Scoped roles restrict the other roles to a single operation on a single resource, e.g. for API keys.
+const scopedRolePrefix = "scope:"
+
 // S converts a Role to its string representation.
 func (r Role) S() string {
 	return string(r)
 }
+
+// ScopedRole returns the role allowing the given operation on the given resource only.
+func ScopedRole(resourceName string, action CrudOperation) Role {
+	return Role(scopedRolePrefix + resourceName + ":" + string(action))
+}
+
+// IsScoped returns whether the role is a scoped role.
+func (r Role) IsScoped() bool {
+	return strings.HasPrefix(string(r), scopedRolePrefix)
+}
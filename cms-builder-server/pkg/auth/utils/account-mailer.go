@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	emailTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/email/types"
+)
+
+const (
+	PasswordResetPath     = "/auth/reset-password"
+	EmailVerificationPath = "/auth/verify-email"
+)
+
+// AccountMailer emails the links of the password reset and email verification flows.
+// Links point to the pages of the admin app, which send the token back to the server.
+type AccountMailer struct {
+	Sender  emailTypes.Sender
+	AppName string
+	LinkUrl string // URL of the admin app, e.g. https://admin.example.com
+	pending sync.WaitGroup
+}
+
+// Go runs fn in the background, so responses don't wait for the mail server.
+func (m *AccountMailer) Go(fn func()) {
+	m.pending.Add(1)
+	go func() {
+		defer m.pending.Done()
+		fn()
+	}()
+}
+
+// Wait blocks until the emails started with Go are sent.
+func (m *AccountMailer) Wait() {
+	m.pending.Wait()
+}
+
+// SendPasswordReset emails the user a link to choose a new password.
+func (m *AccountMailer) SendPasswordReset(user *authModels.User, token string, expiresAt time.Time) error {
+	subject := fmt.Sprintf("Reset your %s password", m.AppName)
+	body := m.body(user,
+		"We received a request to reset your password. Follow the link to choose a new one.",
+		"Reset password",
+		m.link(PasswordResetPath, token),
+		expiresAt,
+		"If you didn't ask for it, you can ignore this email.",
+	)
+
+	return m.Sender.SendEmail([]string{user.Email}, subject, body)
+}
+
+// SendEmailVerification emails the user a link confirming they own their email.
+func (m *AccountMailer) SendEmailVerification(user *authModels.User, token string, expiresAt time.Time) error {
+	subject := fmt.Sprintf("Verify your %s email", m.AppName)
+	body := m.body(user,
+		"Follow the link to verify your email.",
+		"Verify email",
+		m.link(EmailVerificationPath, token),
+		expiresAt,
+		"If you didn't create an account, you can ignore this email.",
+	)
+
+	return m.Sender.SendEmail([]string{user.Email}, subject, body)
+}
+
+func (m *AccountMailer) link(path string, token string) string {
+	return strings.TrimRight(m.LinkUrl, "/") + path + "?token=" + url.QueryEscape(token)
+}
+
+func (m *AccountMailer) body(user *authModels.User, intro string, action string, link string, expiresAt time.Time, outro string) string {
+	name := strings.TrimSpace(user.FirstName)
+	if name == "" {
+		name = user.Email
+	}
+
+	return fmt.Sprintf(
+		"<p>Hi %s,</p><p>%s</p><p><a href=\"%s\">%s</a></p><p>The link expires on %s.</p><p>%s</p>",
+		html.EscapeString(name),
+		html.EscapeString(intro),
+		html.EscapeString(link),
+		html.EscapeString(action),
+		expiresAt.UTC().Format("January 2, 2006 15:04 MST"),
+		html.EscapeString(outro),
+	)
+}
@@ -5,21 +5,23 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
 
 	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
 	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
-	cliPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/clients"
 	dbQueries "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/queries"
 	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
 	loggerTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/logger/types"
 	utilsPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/utils"
 )
 
-func CreateUserWithRole(input authTypes.RegisterUserInput, firebase *cliPkg.FirebaseManager, db *dbTypes.DatabaseConnection, systemUser *authModels.User, requestId string, log *loggerTypes.Logger) (*authModels.User, error) {
+var ErrUserExists = errors.New("a user with this email already exists")
 
-	log.Debug().Interface("input", input).Msg("Creating user with role")
+func CreateUserWithRole(input authTypes.RegisterUserInput, provider authTypes.AuthProvider, db *dbTypes.DatabaseConnection, systemUser *authModels.User, requestId string, log *loggerTypes.Logger) (*authModels.User, error) {
+
+	log.Debug().Str("email", input.Email).Interface("roles", input.Roles).Msg("Creating user with role")
 
 	ctx := context.Background()
 
@@ -29,10 +31,10 @@ func CreateUserWithRole(input authTypes.RegisterUserInput, firebase *cliPkg.Fire
 	// Convert roles slice to a comma-separated string
 	roles := FormatRoles(input.Roles)
 
-	// Register user in Firebase if required
-	fbUserId, err := registerOrGetFirebaseUser(ctx, firebase, input, log)
+	// Register user in the auth provider if required
+	providerUser, err := registerOrGetProviderUser(ctx, provider, input, log)
 	if err != nil {
-		return nil, fmt.Errorf("failed to register or get Firebase user: %w", err)
+		return nil, fmt.Errorf("failed to register or get provider user: %w", err)
 	}
 
 	// Check if a user with the same email already exists in the database
@@ -51,17 +53,23 @@ func CreateUserWithRole(input authTypes.RegisterUserInput, firebase *cliPkg.Fire
 			// Then proceed to create new user
 		} else {
 			// Active user exists - handle normally
-			return handleExistingUser(existingUser, fbUserId, db, systemUser, requestId, log)
+			return handleExistingUser(existingUser, providerUser, input.EmailVerified, db, systemUser, requestId, log)
 		}
 	}
 
 	// Create the user in the database
 	newUser := authModels.User{
-		FirstName:  input.FirstName,
-		LastName:   input.LastName,
-		Email:      input.Email,
-		FirebaseId: fbUserId,
-		Roles:      roles,
+		FirstName:    input.FirstName,
+		LastName:     input.LastName,
+		Email:        input.Email,
+		FirebaseId:   providerUser.ID,
+		PasswordHash: providerUser.PasswordHash,
+		Roles:        roles,
+	}
+
+	if input.EmailVerified {
+		now := time.Now()
+		newUser.EmailVerifiedAt = &now
 	}
 
 	if err := dbQueries.Create(context.Background(), log, db, &newUser, systemUser, requestId); err != nil {
@@ -73,28 +81,37 @@ func CreateUserWithRole(input authTypes.RegisterUserInput, firebase *cliPkg.Fire
 	return &newUser, nil
 }
 
-// Helper function to register or get an existing Firebase user
-func registerOrGetFirebaseUser(ctx context.Context, firebase *cliPkg.FirebaseManager, input authTypes.RegisterUserInput, log *loggerTypes.Logger) (string, error) {
+// Helper function to register or get an existing auth provider user
+func registerOrGetProviderUser(ctx context.Context, provider authTypes.AuthProvider, input authTypes.RegisterUserInput, log *loggerTypes.Logger) (*authTypes.ProviderUser, error) {
 	if !input.RegisterFirebase {
-		return "", nil
+		return &authTypes.ProviderUser{}, nil
 	}
 
-	log.Info().Str("email", input.Email).Msg("Registering user in Firebase")
+	log.Info().Str("email", input.Email).Str("provider", string(provider.Type())).Msg("Registering user in auth provider")
 
-	firebaseInput := cliPkg.FirebaseUserInput{
+	providerInput := authTypes.ProviderUserInput{
 		Email:    input.Email,
 		Name:     input.FirstName + " " + input.LastName,
 		Password: input.Password,
 	}
 
-	fbUser, err := firebase.GetOrCreateUser(ctx, firebaseInput)
+	providerUser, err := provider.CreateUser(ctx, providerInput)
+	if errors.Is(err, authTypes.ErrProviderUserExists) {
+		providerUser, err = provider.GetUserByEmail(ctx, input.Email)
+
+		// Users seeded from the config may exist without credentials, e.g. after switching providers
+		if errors.Is(err, authTypes.ErrProviderUserNotFound) && input.EmailVerified {
+			log.Warn().Str("email", input.Email).Msg("User exists without credentials in the auth provider")
+			return &authTypes.ProviderUser{}, nil
+		}
+	}
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to register or get Firebase user")
-		return "", fmt.Errorf("failed to register or get Firebase user: %w", err)
+		log.Error().Err(err).Msg("Failed to register or get provider user")
+		return nil, fmt.Errorf("failed to register or get provider user: %w", err)
 	}
 
-	log.Info().Interface("user", fbUser).Msg("User registered in Firebase")
-	return fbUser.UID, nil
+	log.Info().Str("id", providerUser.ID).Str("email", providerUser.Email).Msg("User registered in auth provider")
+	return providerUser, nil
 }
 
 // Helper function to find a user by email
@@ -123,26 +140,38 @@ func findUserByEmail(db *dbTypes.DatabaseConnection, email string, log *loggerTy
 	return &user, nil
 }
 
-// Helper function to handle existing user (update Firebase ID if necessary)
-func handleExistingUser(existingUser *authModels.User, fbUserId string, db *dbTypes.DatabaseConnection, systemUser *authModels.User, requestId string, log *loggerTypes.Logger) (*authModels.User, error) {
-	if existingUser.FirebaseId == fbUserId {
-		if fbUserId != "" {
-			log.Info().Msg("User already exists in database with matching Firebase ID")
+// Helper function to handle existing user (link the provider ID if the email is known to be theirs).
+// Linking lets the owner of the provider account sign in as the user, so a user already linked to
+// another account is never relinked, and credentials are never attached to an existing user.
+func handleExistingUser(existingUser *authModels.User, providerUser *authTypes.ProviderUser, emailVerified bool, db *dbTypes.DatabaseConnection, systemUser *authModels.User, requestId string, log *loggerTypes.Logger) (*authModels.User, error) {
+	if emailVerified {
+		if err := MarkEmailVerified(context.Background(), db, existingUser, systemUser, requestId, log); err != nil {
+			return nil, fmt.Errorf("failed to mark email as verified: %w", err)
+		}
+	}
+
+	if existingUser.FirebaseId == providerUser.ID || providerUser.ID == "" {
+		if providerUser.ID != "" {
+			log.Info().Msg("User already exists in database with matching provider ID")
 		}
 		return existingUser, nil
 	}
 
-	previousState := *existingUser
-	differences := utilsPkg.CompareInterfaces(previousState, existingUser)
+	if existingUser.FirebaseId != "" || !(emailVerified || providerUser.EmailVerified) {
+		log.Warn().Uint("user", existingUser.ID).Msg("Provider account not linked to existing user")
+		return nil, ErrUserExists
+	}
 
-	existingUser.FirebaseId = fbUserId
+	previousState := *existingUser
+	existingUser.FirebaseId = providerUser.ID
+	differences := utilsPkg.CompareInterfaces(previousState, *existingUser)
 
 	if err := dbQueries.Update(context.Background(), log, db, existingUser, systemUser, differences, requestId); err != nil {
 		log.Error().Err(err).Msg("Failed to update user in database")
 		return nil, fmt.Errorf("failed to update user in database: %w", err)
 	}
 
-	log.Info().Interface("user", existingUser).Msg("User updated successfully")
+	log.Info().Uint("user", existingUser.ID).Msg("User linked to provider account")
 	return existingUser, nil
 }
 
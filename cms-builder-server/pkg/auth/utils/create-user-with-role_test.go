@@ -1,13 +1,19 @@
 package auth_test
 
 import (
+	"context"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
 	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
 	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
 	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	dbModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/models"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	testPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/testing"
 )
 
 func TestFormatRoles(t *testing.T) {
@@ -40,3 +46,51 @@ func TestFormatRoles(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateUserWithRole_ExistingUser(t *testing.T) {
+	bed := testPkg.SetupAuthTestBed()
+	provider := testPkg.NewMockAuthProvider()
+
+	admin := testPkg.CreateAdminUser()
+	admin.Email = strings.ToLower(admin.Email)
+	assert.NoError(t, bed.Db.DB.Create(admin).Error)
+
+	input := authTypes.RegisterUserInput{
+		Email:            admin.Email,
+		Password:         "attacker-password",
+		Roles:            []authTypes.Role{authConstants.VisitorRole},
+		RegisterFirebase: true,
+	}
+
+	t.Run("Registering does not link new provider accounts", func(t *testing.T) {
+		_, err := authUtils.CreateUserWithRole(input, provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+		assert.ErrorIs(t, err, authUtils.ErrUserExists)
+
+		stored := authModels.User{}
+		assert.NoError(t, bed.Db.DB.First(&stored, admin.ID).Error)
+		assert.Empty(t, stored.FirebaseId)
+	})
+
+	t.Run("Seeded users are linked to their provider account", func(t *testing.T) {
+		seed := input
+		seed.EmailVerified = true
+
+		user, err := authUtils.CreateUserWithRole(seed, provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+		assert.NoError(t, err)
+		assert.Equal(t, admin.ID, user.ID)
+		assert.Equal(t, provider.Users[admin.Email].ID, user.FirebaseId)
+		assert.Equal(t, admin.Roles, user.Roles)
+
+		logs := []dbModels.DatabaseLog{}
+		bed.Db.DB.Where("resource_name = ? AND resource_id = ? AND action = ?", "User", admin.StringID(), dbTypes.UpdateCRUDAction).Find(&logs)
+		assert.NotEmpty(t, logs)
+		assert.Contains(t, logs[len(logs)-1].Detail, "firebaseId")
+	})
+
+	t.Run("Linked users are not relinked", func(t *testing.T) {
+		provider.DeleteUser(context.Background(), provider.Users[admin.Email].ID)
+
+		_, err := authUtils.CreateUserWithRole(input, provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+		assert.ErrorIs(t, err, authUtils.ErrUserExists)
+	})
+}
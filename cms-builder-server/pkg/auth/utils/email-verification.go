@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	dbQueries "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/queries"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	loggerTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/logger/types"
+	utilsPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/utils"
+)
+
+// MarkEmailVerified records that the user owns their email, it does nothing for verified users.
+// The change is recorded in the database log.
+func MarkEmailVerified(ctx context.Context, db *dbTypes.DatabaseConnection, user *authModels.User, verifiedBy *authModels.User, requestId string, log *loggerTypes.Logger) error {
+	if user.IsEmailVerified() {
+		return nil
+	}
+
+	previousState := *user
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+
+	differences := utilsPkg.CompareInterfaces(previousState, *user)
+	err := dbQueries.Update(ctx, log, db, user, verifiedBy, differences, requestId)
+	if err != nil {
+		user.EmailVerifiedAt = nil
+		return err
+	}
+
+	log.Info().Uint("user", user.ID).Msg("Email verified")
+	return nil
+}
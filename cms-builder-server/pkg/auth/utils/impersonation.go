@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	dbQueries "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/queries"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	loggerTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/logger/types"
+)
+
+const (
+	impersonationPrefix = "imp_"
+	ImpersonationTTL    = 30 * time.Minute
+)
+
+var (
+	ErrInvalidImpersonation = errors.New("invalid impersonation token")
+	ErrImpersonationExpired = errors.New("impersonation session has expired")
+)
+
+// StartImpersonation opens a session for the admin to act as the user, and returns its token.
+// The session is recorded in the database log.
+func StartImpersonation(ctx context.Context, admin *authModels.User, user *authModels.User, reason string, db *dbTypes.DatabaseConnection, requestId string, log *loggerTypes.Logger) (*authModels.ImpersonationSession, string, error) {
+	token, hash, err := generateToken(impersonationPrefix)
+	if err != nil {
+		return nil, "", err
+	}
+
+	session := &authModels.ImpersonationSession{
+		UserID:    user.ID,
+		TokenHash: hash,
+		Reason:    reason,
+		ExpiresAt: time.Now().Add(ImpersonationTTL),
+	}
+	session.CreatedByID = admin.ID
+	session.UpdatedByID = admin.ID
+
+	err = dbQueries.Create(ctx, log, db, session, admin, requestId)
+	if err != nil {
+		return nil, "", err
+	}
+
+	session.User = user
+	return session, token, nil
+}
+
+// VerifyImpersonation returns the impersonated user of the session, with the admin as ImpersonatedBy.
+// Sessions stop working as soon as the admin loses the admin role.
+func VerifyImpersonation(token string, db *dbTypes.DatabaseConnection, log *loggerTypes.Logger) (*authModels.User, error) {
+	session := authModels.ImpersonationSession{}
+	filters := map[string]interface{}{
+		"token_hash": hashToken(token),
+	}
+
+	err := dbQueries.FindOne(context.Background(), log, db, &session, filters, []string{"User", "CreatedBy"})
+	if err != nil || session.User == nil || session.CreatedBy == nil {
+		return nil, ErrInvalidImpersonation
+	}
+
+	if !session.IsActive(time.Now()) {
+		return nil, ErrImpersonationExpired
+	}
+
+	admin := session.CreatedBy
+	LoadUserRoles(context.Background(), db, admin, log)
+	if !admin.HasRole(authConstants.AdminRole) {
+		return nil, ErrInvalidImpersonation
+	}
+
+	user := session.User
+	LoadUserRoles(context.Background(), db, user, log)
+	user.ImpersonatedBy = admin
+
+	return user, nil
+}
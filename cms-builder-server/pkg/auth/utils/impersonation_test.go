@@ -0,0 +1,84 @@
+package auth_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	dbModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/models"
+	testPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/testing"
+)
+
+func TestImpersonation(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+	assert.NoError(t, bed.Db.DB.AutoMigrate(&authModels.ImpersonationSession{}, &authModels.UserRole{}))
+
+	session, token, err := authUtils.StartImpersonation(context.Background(), bed.AdminUser, bed.VisitorUser, "support ticket", bed.Db, "test-request", bed.Logger)
+	assert.NoError(t, err)
+	assert.NotContains(t, token, session.TokenHash)
+
+	// The session is audited as created by the admin
+	entry := dbModels.DatabaseLog{}
+	assert.NoError(t, bed.Db.DB.Where("resource_name = ? AND resource_id = ?", "ImpersonationSession", session.StringID()).First(&entry).Error)
+	assert.Equal(t, bed.AdminUser.ID, entry.UserId)
+
+	user, err := authUtils.VerifyImpersonation(token, bed.Db, bed.Logger)
+	assert.NoError(t, err)
+	assert.Equal(t, bed.VisitorUser.ID, user.ID)
+	assert.Equal(t, bed.VisitorUser.GetRoles(), user.GetRoles())
+	assert.Equal(t, bed.AdminUser.ID, user.ImpersonatedBy.ID)
+
+	_, err = authUtils.VerifyImpersonation(token+"x", bed.Db, bed.Logger)
+	assert.ErrorIs(t, err, authUtils.ErrInvalidImpersonation)
+
+	// Expired sessions are rejected
+	assert.NoError(t, bed.Db.DB.Model(session).UpdateColumn("expires_at", time.Now().Add(-time.Minute)).Error)
+	_, err = authUtils.VerifyImpersonation(token, bed.Db, bed.Logger)
+	assert.ErrorIs(t, err, authUtils.ErrImpersonationExpired)
+}
+
+func TestImpersonation_AdminLosesRole(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+	assert.NoError(t, bed.Db.DB.AutoMigrate(&authModels.ImpersonationSession{}, &authModels.UserRole{}))
+
+	admin := testPkg.CreateAdminUser()
+	assert.NoError(t, bed.Db.DB.Create(admin).Error)
+
+	_, token, err := authUtils.StartImpersonation(context.Background(), admin, bed.VisitorUser, "", bed.Db, "test-request", bed.Logger)
+	assert.NoError(t, err)
+
+	admin.RemoveRole(authConstants.AdminRole)
+	assert.NoError(t, bed.Db.DB.Save(admin).Error)
+
+	_, err = authUtils.VerifyImpersonation(token, bed.Db, bed.Logger)
+	assert.ErrorIs(t, err, authUtils.ErrInvalidImpersonation)
+}
+
+func TestImpersonation_AdminSignsOutEverywhere(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+	assert.NoError(t, bed.Db.DB.AutoMigrate(&authModels.ImpersonationSession{}, &authModels.UserRole{}, &authModels.UserSession{}, &authModels.SessionRevocation{}))
+
+	admin := testPkg.CreateAdminUser()
+	assert.NoError(t, bed.Db.DB.Create(admin).Error)
+
+	_, token, err := authUtils.StartImpersonation(context.Background(), admin, bed.VisitorUser, "", bed.Db, "test-request", bed.Logger)
+	assert.NoError(t, err)
+
+	// Sessions started by other admins are kept
+	_, otherToken, err := authUtils.StartImpersonation(context.Background(), bed.AdminUser, bed.VisitorUser, "", bed.Db, "test-request", bed.Logger)
+	assert.NoError(t, err)
+
+	err = authUtils.RevokeUserSessions(context.Background(), bed.Db, admin, admin, "logout", "test-request", bed.Logger)
+	assert.NoError(t, err)
+
+	_, err = authUtils.VerifyImpersonation(token, bed.Db, bed.Logger)
+	assert.ErrorIs(t, err, authUtils.ErrInvalidImpersonation)
+
+	_, err = authUtils.VerifyImpersonation(otherToken, bed.Db, bed.Logger)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,311 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	dbQueries "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/queries"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	loggerTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/logger/types"
+	utilsPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/utils"
+)
+
+const (
+	recoveryCodeCount    = 10
+	recoveryCodeLength   = 10
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789" // No lookalike characters
+
+	// Codes are six digits, attempts are limited so they can't be guessed
+	mfaMaxAttempts = 5
+	mfaLockout     = 15 * time.Minute
+)
+
+var (
+	ErrMfaRequired        = errors.New("mfa code is required")
+	ErrInvalidMfaCode     = errors.New("invalid mfa code")
+	ErrMfaAlreadyEnrolled = errors.New("mfa is already enrolled")
+	ErrMfaNotEnrolled     = errors.New("mfa is not enrolled")
+	ErrMfaLocked          = errors.New("too many invalid mfa codes, try again later")
+)
+
+// mfaRequiredRoles are withheld from users whose session lacks a second factor.
+var (
+	mfaPolicyMu      sync.RWMutex
+	mfaRequiredRoles []authTypes.Role
+)
+
+// SetMfaRequiredRoles makes the given roles require a second factor, users signed in without one
+// are treated as if they didn't hold them. No roles disables the policy.
+func SetMfaRequiredRoles(roles ...authTypes.Role) {
+	mfaPolicyMu.Lock()
+	defer mfaPolicyMu.Unlock()
+	mfaRequiredRoles = roles
+}
+
+func getMfaRequiredRoles() []authTypes.Role {
+	mfaPolicyMu.RLock()
+	defer mfaPolicyMu.RUnlock()
+	return mfaRequiredRoles
+}
+
+// RoleRequiresMfa returns whether the role is withheld from sessions without a second factor.
+func RoleRequiresMfa(role authTypes.Role) bool {
+	return slices.Contains(getMfaRequiredRoles(), role)
+}
+
+// ApplyMfaPolicy restricts the roles requiring a second factor when the user signed in without one.
+func ApplyMfaPolicy(user *authModels.User, providerUser *authTypes.ProviderUser, log *loggerTypes.Logger) {
+	if providerUser.MfaVerified {
+		return
+	}
+
+	for _, role := range getMfaRequiredRoles() {
+		if user.HasRole(role) {
+			user.RestrictedRoles = append(user.RestrictedRoles, role)
+		}
+	}
+
+	if len(user.RestrictedRoles) > 0 {
+		log.Warn().Uint("user", user.ID).Interface("roles", user.RestrictedRoles).Msg("Roles withheld, session has no second factor")
+	}
+}
+
+// MfaEnabled returns whether the user has a confirmed enrollment, so codes are required to sign in.
+func MfaEnabled(ctx context.Context, db *dbTypes.DatabaseConnection, userId uint) (bool, error) {
+	var count int64
+	err := db.DB.WithContext(ctx).Model(&authModels.MfaEnrollment{}).
+		Where("user_id = ? AND confirmed_at IS NOT NULL", userId).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// EnrollMfa starts the enrollment of the user, replacing any pending one.
+// The enrollment has to be confirmed with a code of the returned secret.
+func EnrollMfa(ctx context.Context, db *dbTypes.DatabaseConnection, user *authModels.User, requestId string, log *loggerTypes.Logger) (*authModels.MfaEnrollment, error) {
+	enrollment := authModels.MfaEnrollment{}
+	err := dbQueries.FindOne(ctx, log, db, &enrollment, map[string]interface{}{"user_id": user.ID}, []string{})
+	if err == nil {
+		if enrollment.ConfirmedAt != nil {
+			return nil, ErrMfaAlreadyEnrolled
+		}
+
+		err = dbQueries.HardDelete(ctx, log, db, &enrollment, user, requestId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	secret, err := GenerateTotpSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	enrollment = authModels.MfaEnrollment{
+		UserID: user.ID,
+		Secret: secret,
+	}
+
+	err = dbQueries.Create(ctx, log, db, &enrollment, user, requestId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &enrollment, nil
+}
+
+// ConfirmMfa confirms the pending enrollment of the user with a first code, and returns the
+// recovery codes. It is the only time they are shown.
+func ConfirmMfa(ctx context.Context, db *dbTypes.DatabaseConnection, user *authModels.User, code string, requestId string, log *loggerTypes.Logger) ([]string, error) {
+	enrollment := authModels.MfaEnrollment{}
+	err := dbQueries.FindOne(ctx, log, db, &enrollment, map[string]interface{}{"user_id": user.ID}, []string{})
+	if err != nil {
+		return nil, ErrMfaNotEnrolled
+	}
+
+	if enrollment.ConfirmedAt != nil {
+		return nil, ErrMfaAlreadyEnrolled
+	}
+
+	step, ok := validateTotp(enrollment.Secret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidMfaCode
+	}
+
+	previousState := enrollment
+	now := time.Now()
+	enrollment.ConfirmedAt = &now
+	enrollment.LastUsedStep = step
+
+	differences := utilsPkg.CompareInterfaces(previousState, enrollment)
+	err = dbQueries.Update(ctx, log, db, &enrollment, user, differences, requestId)
+	if err != nil {
+		return nil, err
+	}
+
+	return generateRecoveryCodes(ctx, db, user)
+}
+
+// VerifyMfaCode accepts a code of the authenticator of the user, or one of the unused recovery codes.
+// Accepted codes can't be used again.
+func VerifyMfaCode(ctx context.Context, db *dbTypes.DatabaseConnection, userId uint, code string) error {
+	enrollment := authModels.MfaEnrollment{}
+	err := db.DB.WithContext(ctx).Where("user_id = ? AND confirmed_at IS NOT NULL", userId).First(&enrollment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrMfaNotEnrolled
+	}
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if enrollment.LockedUntil != nil && now.Before(*enrollment.LockedUntil) {
+		return ErrMfaLocked
+	}
+
+	err = checkMfaCode(ctx, db, &enrollment, code, now)
+	if errors.Is(err, ErrInvalidMfaCode) {
+		return recordFailedMfaAttempt(ctx, db, &enrollment, now)
+	}
+	if err != nil {
+		return err
+	}
+
+	if enrollment.FailedAttempts > 0 {
+		return db.DB.WithContext(ctx).Model(&enrollment).UpdateColumn("failed_attempts", 0).Error
+	}
+
+	return nil
+}
+
+// checkMfaCode accepts a TOTP code not used before, or an unused recovery code.
+func checkMfaCode(ctx context.Context, db *dbTypes.DatabaseConnection, enrollment *authModels.MfaEnrollment, code string, now time.Time) error {
+	if step, ok := validateTotp(enrollment.Secret, code, now); ok {
+		// Only one request succeeds when the same code is sent concurrently
+		result := db.DB.WithContext(ctx).Model(enrollment).
+			Where("last_used_step < ?", step).
+			UpdateColumn("last_used_step", step)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidMfaCode
+		}
+
+		return nil
+	}
+
+	result := db.DB.WithContext(ctx).Model(&authModels.MfaRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", enrollment.UserID, hashToken(normalizeRecoveryCode(code))).
+		UpdateColumn("used_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidMfaCode
+	}
+
+	return nil
+}
+
+// recordFailedMfaAttempt counts the invalid code, locking the enrollment once there are too many.
+// Counting happens in the database, so concurrent attempts are all counted.
+func recordFailedMfaAttempt(ctx context.Context, db *dbTypes.DatabaseConnection, enrollment *authModels.MfaEnrollment, now time.Time) error {
+	err := db.DB.WithContext(ctx).Model(enrollment).
+		UpdateColumn("failed_attempts", gorm.Expr("failed_attempts + 1")).Error
+	if err != nil {
+		return err
+	}
+
+	result := db.DB.WithContext(ctx).Model(enrollment).
+		Where("failed_attempts >= ?", mfaMaxAttempts).
+		UpdateColumns(map[string]interface{}{"failed_attempts": 0, "locked_until": now.Add(mfaLockout)})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		return ErrMfaLocked
+	}
+
+	return ErrInvalidMfaCode
+}
+
+// DisableMfa removes the enrollment and recovery codes of the user, after checking a code.
+func DisableMfa(ctx context.Context, db *dbTypes.DatabaseConnection, user *authModels.User, code string, requestId string, log *loggerTypes.Logger) error {
+	err := VerifyMfaCode(ctx, db, user.ID, code)
+	if err != nil {
+		return err
+	}
+
+	enrollment := authModels.MfaEnrollment{}
+	err = dbQueries.FindOne(ctx, log, db, &enrollment, map[string]interface{}{"user_id": user.ID}, []string{})
+	if err != nil {
+		return ErrMfaNotEnrolled
+	}
+
+	err = dbQueries.HardDelete(ctx, log, db, &enrollment, user, requestId)
+	if err != nil {
+		return err
+	}
+
+	return db.DB.WithContext(ctx).Unscoped().Where("user_id = ?", user.ID).Delete(&authModels.MfaRecoveryCode{}).Error
+}
+
+// generateRecoveryCodes replaces the recovery codes of the user.
+func generateRecoveryCodes(ctx context.Context, db *dbTypes.DatabaseConnection, user *authModels.User) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	stored := make([]authModels.MfaRecoveryCode, 0, recoveryCodeCount)
+
+	for range recoveryCodeCount {
+		bytes := make([]byte, recoveryCodeLength)
+		if _, err := rand.Read(bytes); err != nil {
+			return nil, err
+		}
+
+		code := make([]byte, recoveryCodeLength)
+		for i, b := range bytes {
+			code[i] = recoveryCodeAlphabet[int(b)%len(recoveryCodeAlphabet)]
+		}
+
+		// Shown in two halves, e.g. abcde-fghjk
+		formatted := string(code[:recoveryCodeLength/2]) + "-" + string(code[recoveryCodeLength/2:])
+		codes = append(codes, formatted)
+		stored = append(stored, authModels.MfaRecoveryCode{
+			UserID:   user.ID,
+			CodeHash: hashToken(normalizeRecoveryCode(formatted)),
+		})
+	}
+
+	err := db.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(&authModels.MfaRecoveryCode{}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(&stored).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.Join(strings.FieldsFunc(code, func(r rune) bool {
+		return !slices.Contains([]rune(recoveryCodeAlphabet), r)
+	}), "")
+}
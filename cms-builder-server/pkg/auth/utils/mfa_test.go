@@ -0,0 +1,138 @@
+package auth_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	testPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/testing"
+)
+
+func TestMfa(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+	assert.NoError(t, bed.Db.DB.AutoMigrate(&authModels.MfaEnrollment{}, &authModels.MfaRecoveryCode{}))
+	ctx := context.Background()
+	user := bed.VisitorUser
+
+	// Enrollments are only enforced once confirmed
+	enrollment, err := authUtils.EnrollMfa(ctx, bed.Db, user, "test-request", bed.Logger)
+	assert.NoError(t, err)
+
+	enabled, err := authUtils.MfaEnabled(ctx, bed.Db, user.ID)
+	assert.NoError(t, err)
+	assert.False(t, enabled)
+
+	_, err = authUtils.ConfirmMfa(ctx, bed.Db, user, "000000", "test-request", bed.Logger)
+	assert.ErrorIs(t, err, authUtils.ErrInvalidMfaCode)
+
+	code, err := authUtils.TotpCode(enrollment.Secret, time.Now())
+	assert.NoError(t, err)
+
+	recoveryCodes, err := authUtils.ConfirmMfa(ctx, bed.Db, user, code, "test-request", bed.Logger)
+	assert.NoError(t, err)
+	assert.Len(t, recoveryCodes, 10)
+
+	enabled, err = authUtils.MfaEnabled(ctx, bed.Db, user.ID)
+	assert.NoError(t, err)
+	assert.True(t, enabled)
+
+	_, err = authUtils.EnrollMfa(ctx, bed.Db, user, "test-request", bed.Logger)
+	assert.ErrorIs(t, err, authUtils.ErrMfaAlreadyEnrolled)
+
+	// Codes can't be replayed
+	err = authUtils.VerifyMfaCode(ctx, bed.Db, user.ID, code)
+	assert.ErrorIs(t, err, authUtils.ErrInvalidMfaCode)
+
+	next, err := authUtils.TotpCode(enrollment.Secret, time.Now().Add(30*time.Second))
+	assert.NoError(t, err)
+	assert.NoError(t, authUtils.VerifyMfaCode(ctx, bed.Db, user.ID, next))
+
+	// Recovery codes work once, however they are typed
+	typed := " " + strings.ToUpper(recoveryCodes[0]) + " "
+	assert.NoError(t, authUtils.VerifyMfaCode(ctx, bed.Db, user.ID, typed))
+	assert.ErrorIs(t, authUtils.VerifyMfaCode(ctx, bed.Db, user.ID, recoveryCodes[0]), authUtils.ErrInvalidMfaCode)
+
+	// Disabling requires a code
+	err = authUtils.DisableMfa(ctx, bed.Db, user, "wrong", "test-request", bed.Logger)
+	assert.ErrorIs(t, err, authUtils.ErrInvalidMfaCode)
+
+	err = authUtils.DisableMfa(ctx, bed.Db, user, recoveryCodes[1], "test-request", bed.Logger)
+	assert.NoError(t, err)
+
+	enabled, err = authUtils.MfaEnabled(ctx, bed.Db, user.ID)
+	assert.NoError(t, err)
+	assert.False(t, enabled)
+
+	var count int64
+	bed.Db.DB.Unscoped().Model(&authModels.MfaRecoveryCode{}).Where("user_id = ?", user.ID).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestMfa_Throttling(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+	assert.NoError(t, bed.Db.DB.AutoMigrate(&authModels.MfaEnrollment{}, &authModels.MfaRecoveryCode{}))
+	ctx := context.Background()
+
+	user := testPkg.CreateVisitorUser()
+	assert.NoError(t, bed.Db.DB.Create(user).Error)
+
+	enrollment, err := authUtils.EnrollMfa(ctx, bed.Db, user, "test-request", bed.Logger)
+	assert.NoError(t, err)
+
+	code, err := authUtils.TotpCode(enrollment.Secret, time.Now())
+	assert.NoError(t, err)
+	recoveryCodes, err := authUtils.ConfirmMfa(ctx, bed.Db, user, code, "test-request", bed.Logger)
+	assert.NoError(t, err)
+
+	// Accepted codes reset the count of invalid ones
+	for i := 0; i < 4; i++ {
+		assert.ErrorIs(t, authUtils.VerifyMfaCode(ctx, bed.Db, user.ID, "000000"), authUtils.ErrInvalidMfaCode)
+	}
+	next, err := authUtils.TotpCode(enrollment.Secret, time.Now().Add(30*time.Second))
+	assert.NoError(t, err)
+	assert.NoError(t, authUtils.VerifyMfaCode(ctx, bed.Db, user.ID, next))
+
+	for i := 0; i < 4; i++ {
+		assert.ErrorIs(t, authUtils.VerifyMfaCode(ctx, bed.Db, user.ID, "000000"), authUtils.ErrInvalidMfaCode)
+	}
+	assert.ErrorIs(t, authUtils.VerifyMfaCode(ctx, bed.Db, user.ID, "000000"), authUtils.ErrMfaLocked)
+
+	// Valid codes are rejected while locked
+	assert.ErrorIs(t, authUtils.VerifyMfaCode(ctx, bed.Db, user.ID, recoveryCodes[0]), authUtils.ErrMfaLocked)
+
+	// And accepted again once the lockout is over
+	err = bed.Db.DB.Model(&authModels.MfaEnrollment{}).Where("user_id = ?", user.ID).
+		UpdateColumn("locked_until", time.Now().Add(-time.Minute)).Error
+	assert.NoError(t, err)
+	assert.NoError(t, authUtils.VerifyMfaCode(ctx, bed.Db, user.ID, recoveryCodes[0]))
+}
+
+func TestApplyMfaPolicy(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+
+	authUtils.SetMfaRequiredRoles(authConstants.AdminRole)
+	t.Cleanup(func() { authUtils.SetMfaRequiredRoles() })
+
+	admin := *bed.AdminUser
+	admin.Roles = authUtils.FormatRoles([]authTypes.Role{authConstants.AdminRole, authConstants.VisitorRole})
+
+	withoutMfa := admin
+	authUtils.ApplyMfaPolicy(&withoutMfa, &authTypes.ProviderUser{}, bed.Logger)
+	assert.Equal(t, []authTypes.Role{authConstants.VisitorRole}, withoutMfa.GetRoles())
+	assert.Equal(t, admin.Roles, withoutMfa.Roles)
+
+	withMfa := admin
+	authUtils.ApplyMfaPolicy(&withMfa, &authTypes.ProviderUser{MfaVerified: true}, bed.Logger)
+	assert.True(t, withMfa.HasRole(authConstants.AdminRole))
+
+	visitor := *bed.VisitorUser
+	authUtils.ApplyMfaPolicy(&visitor, &authTypes.ProviderUser{}, bed.Logger)
+	assert.Empty(t, visitor.RestrictedRoles)
+}
@@ -0,0 +1,211 @@
+package auth
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	loggerTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/logger/types"
+)
+
+// permissionStore is consulted by UserIsAllowed, permissions compiled into the resources apply when unset.
+var (
+	permissionStoreMu sync.RWMutex
+	permissionStore   *PermissionStore
+)
+
+// SetPermissionStore makes UserIsAllowed merge the permissions stored in the database with the
+// ones compiled into the resources. A nil store restores the compiled permissions only.
+func SetPermissionStore(store *PermissionStore) {
+	permissionStoreMu.Lock()
+	defer permissionStoreMu.Unlock()
+	permissionStore = store
+}
+
+func getPermissionStore() *PermissionStore {
+	permissionStoreMu.RLock()
+	defer permissionStoreMu.RUnlock()
+	return permissionStore
+}
+
+// adminPinnedResources keep the compiled admin permissions whatever is stored, so no stored
+// permission locks admins out of managing roles and permissions.
+var adminPinnedResources = []string{"Role", "Permission", "UserRole"}
+
+// PermissionStore caches the permissions stored in the database, grouped by resource.
+// Changes made through this connection invalidate the cache, changes made by other
+// instances are picked up once the ttl expires.
+type PermissionStore struct {
+	db          *dbTypes.DatabaseConnection
+	log         *loggerTypes.Logger
+	ttl         time.Duration
+	mu          sync.Mutex
+	permissions map[string]authTypes.RolePermissionMap
+	loadedAt    time.Time
+}
+
+// invalidatedStores holds the stores of each connection, keyed by its config as sessions share it.
+// The invalidation callbacks are registered once per connection and invalidate all of them.
+var (
+	invalidatedStoresMu sync.Mutex
+	invalidatedStores   = map[*gorm.Config][]*PermissionStore{}
+)
+
+func NewPermissionStore(db *dbTypes.DatabaseConnection, ttl time.Duration, log *loggerTypes.Logger) (*PermissionStore, error) {
+	store := &PermissionStore{
+		db:  db,
+		log: log,
+		ttl: ttl,
+	}
+
+	err := registerPermissionStore(db, store)
+	if err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// registerPermissionStore adds the store to the ones invalidated by changes made through the connection,
+// registering the callbacks the first time a store is created for it.
+func registerPermissionStore(db *dbTypes.DatabaseConnection, store *PermissionStore) error {
+	invalidatedStoresMu.Lock()
+	defer invalidatedStoresMu.Unlock()
+
+	config := db.DB.Config
+	if stores, ok := invalidatedStores[config]; ok {
+		invalidatedStores[config] = append(stores, store)
+		return nil
+	}
+
+	invalidate := func(tx *gorm.DB) {
+		if tx.Statement.Schema == nil {
+			return
+		}
+
+		switch tx.Statement.Schema.Table {
+		case "permissions", "roles":
+			invalidatedStoresMu.Lock()
+			stores := invalidatedStores[config]
+			invalidatedStoresMu.Unlock()
+
+			for _, store := range stores {
+				store.Invalidate()
+			}
+		}
+	}
+
+	callbacks := db.DB.Callback()
+	err := callbacks.Create().After("gorm:create").Register("auth:invalidate_permissions_create", invalidate)
+	if err != nil {
+		return err
+	}
+
+	err = callbacks.Update().After("gorm:update").Register("auth:invalidate_permissions_update", invalidate)
+	if err != nil {
+		return err
+	}
+
+	err = callbacks.Delete().After("gorm:delete").Register("auth:invalidate_permissions_delete", invalidate)
+	if err != nil {
+		return err
+	}
+
+	invalidatedStores[config] = []*PermissionStore{store}
+	return nil
+}
+
+// Invalidate drops the cached permissions, they are reloaded on the next check.
+func (s *PermissionStore) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.permissions = nil
+}
+
+// Effective returns the permissions of the resource, the stored permissions of a role replacing
+// the compiled ones for that role. Admins keep their compiled permissions on the role resources.
+func (s *PermissionStore) Effective(defaults authTypes.RolePermissionMap, resourceName string) authTypes.RolePermissionMap {
+	overrides := s.get(resourceName)
+	if len(overrides) == 0 {
+		return defaults
+	}
+
+	effective := authTypes.RolePermissionMap{}
+	for role, operations := range defaults {
+		effective[role] = operations
+	}
+
+	for role, operations := range overrides {
+		if role == authConstants.AdminRole && slices.Contains(adminPinnedResources, resourceName) {
+			continue
+		}
+		effective[role] = operations
+	}
+
+	return effective
+}
+
+func (s *PermissionStore) get(resourceName string) authTypes.RolePermissionMap {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.permissions == nil || time.Since(s.loadedAt) > s.ttl {
+		err := s.load()
+		if err != nil {
+			s.log.Error().Err(err).Msg("Error loading permissions, using the resource defaults")
+			return nil
+		}
+	}
+
+	return s.permissions[resourceName]
+}
+
+func (s *PermissionStore) load() error {
+	stored := []authModels.Permission{}
+	err := s.db.DB.WithContext(context.Background()).Preload("Role").Find(&stored).Error
+	if err != nil {
+		return err
+	}
+
+	permissions := map[string]authTypes.RolePermissionMap{}
+	for _, permission := range stored {
+		if permission.Role == nil {
+			continue
+		}
+
+		if _, ok := permissions[permission.Resource]; !ok {
+			permissions[permission.Resource] = authTypes.RolePermissionMap{}
+		}
+
+		role := authTypes.Role(permission.Role.Name)
+		permissions[permission.Resource][role] = append(permissions[permission.Resource][role], permission.GetOperations()...)
+	}
+
+	s.permissions = permissions
+	s.loadedAt = time.Now()
+	s.log.Debug().Int("permissions", len(stored)).Msg("Permissions loaded")
+	return nil
+}
+
+// LoadUserRoles loads the roles assigned to the user at runtime, so GetRoles includes them.
+func LoadUserRoles(ctx context.Context, db *dbTypes.DatabaseConnection, user *authModels.User, log *loggerTypes.Logger) {
+	if user == nil || user.ID == 0 {
+		return
+	}
+
+	userRoles := []authModels.UserRole{}
+	err := db.DB.WithContext(ctx).Preload("Role").Where("user_id = ?", user.ID).Find(&userRoles).Error
+	if err != nil {
+		log.Debug().Err(err).Msg("Error loading user roles")
+		return
+	}
+
+	user.UserRoles = userRoles
+}
@@ -0,0 +1,175 @@
+package auth_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authResources "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/resources"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	testPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/testing"
+)
+
+const editorRole authTypes.Role = "editor"
+
+func setupPermissionStore(t *testing.T) testPkg.TestUtils {
+	bed := testPkg.SetupHandlerTestBed()
+	assert.NoError(t, bed.Db.DB.AutoMigrate(&authModels.Role{}, &authModels.Permission{}, &authModels.UserRole{}))
+
+	err := authUtils.SeedRoles(context.Background(), bed.Db, bed.AdminUser, "test-request", bed.Logger,
+		authConstants.AdminRole, authConstants.VisitorRole, editorRole)
+	assert.NoError(t, err)
+
+	store, err := authUtils.NewPermissionStore(bed.Db, time.Hour, bed.Logger)
+	assert.NoError(t, err)
+
+	authUtils.SetPermissionStore(store)
+	t.Cleanup(func() { authUtils.SetPermissionStore(nil) })
+
+	return bed
+}
+
+func findRole(t *testing.T, bed testPkg.TestUtils, name authTypes.Role) authModels.Role {
+	role := authModels.Role{}
+	assert.NoError(t, bed.Db.DB.Where("name = ?", string(name)).First(&role).Error)
+	return role
+}
+
+func createPermission(t *testing.T, bed testPkg.TestUtils, role authTypes.Role, resource string, operations string) {
+	permission := &authModels.Permission{
+		RoleID:     findRole(t, bed, role).ID,
+		Resource:   resource,
+		Operations: operations,
+	}
+	permission.CreatedByID = bed.AdminUser.ID
+	permission.UpdatedByID = bed.AdminUser.ID
+	assert.NoError(t, bed.Db.DB.Create(permission).Error)
+}
+
+func TestSeedRoles(t *testing.T) {
+	bed := setupPermissionStore(t)
+
+	// Seeding again doesn't duplicate roles
+	err := authUtils.SeedRoles(context.Background(), bed.Db, bed.AdminUser, "test-request", bed.Logger, authConstants.AdminRole)
+	assert.NoError(t, err)
+
+	var count int64
+	bed.Db.DB.Model(&authModels.Role{}).Where("name = ?", string(authConstants.AdminRole)).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestPermissionStore_Overrides(t *testing.T) {
+	bed := setupPermissionStore(t)
+
+	defaults := authTypes.RolePermissionMap{
+		authConstants.AdminRole:   authConstants.AllAllowedAccess,
+		authConstants.VisitorRole: []authTypes.CrudOperation{authConstants.OperationRead},
+	}
+
+	visitor := []authTypes.Role{authConstants.VisitorRole}
+	editor := []authTypes.Role{editorRole}
+
+	// Compiled permissions apply until overridden
+	assert.True(t, authUtils.UserIsAllowed(defaults, visitor, authConstants.OperationRead, "Post", bed.Logger))
+	assert.False(t, authUtils.UserIsAllowed(defaults, editor, authConstants.OperationUpdate, "Post", bed.Logger))
+
+	// Stored permissions are picked up as soon as they change
+	createPermission(t, bed, editorRole, "Post", "read,update")
+	createPermission(t, bed, authConstants.VisitorRole, "Post", "")
+
+	assert.True(t, authUtils.UserIsAllowed(defaults, editor, authConstants.OperationUpdate, "Post", bed.Logger))
+	assert.False(t, authUtils.UserIsAllowed(defaults, editor, authConstants.OperationDelete, "Post", bed.Logger))
+	assert.False(t, authUtils.UserIsAllowed(defaults, visitor, authConstants.OperationRead, "Post", bed.Logger))
+
+	// Other resources and roles keep their compiled permissions
+	assert.True(t, authUtils.UserIsAllowed(defaults, visitor, authConstants.OperationRead, "Comment", bed.Logger))
+	assert.True(t, authUtils.UserIsAllowed(defaults, []authTypes.Role{authConstants.AdminRole}, authConstants.OperationDelete, "Post", bed.Logger))
+
+	// Deleted permissions no longer apply
+	assert.NoError(t, bed.Db.DB.Where("resource = ?", "Post").Delete(&authModels.Permission{}).Error)
+	assert.True(t, authUtils.UserIsAllowed(defaults, visitor, authConstants.OperationRead, "Post", bed.Logger))
+	assert.False(t, authUtils.UserIsAllowed(defaults, editor, authConstants.OperationUpdate, "Post", bed.Logger))
+}
+
+func TestPermissionStore_SharedConnection(t *testing.T) {
+	bed := setupPermissionStore(t)
+	t.Cleanup(func() {
+		bed.Db.DB.Where("resource = ?", "Article").Delete(&authModels.Permission{})
+	})
+
+	// A second store on the same connection doesn't register the callbacks again,
+	// and changes invalidate both stores
+	other, err := authUtils.NewPermissionStore(bed.Db, time.Hour, bed.Logger)
+	assert.NoError(t, err)
+
+	defaults := authTypes.RolePermissionMap{}
+	editor := []authTypes.Role{editorRole}
+
+	assert.Empty(t, other.Effective(defaults, "Article"))
+	assert.False(t, authUtils.UserIsAllowed(defaults, editor, authConstants.OperationRead, "Article", bed.Logger))
+
+	createPermission(t, bed, editorRole, "Article", "read")
+
+	assert.Contains(t, other.Effective(defaults, "Article"), editorRole)
+	assert.True(t, authUtils.UserIsAllowed(defaults, editor, authConstants.OperationRead, "Article", bed.Logger))
+}
+
+func TestPermissionStore_AdminsKeepRoleResources(t *testing.T) {
+	bed := setupPermissionStore(t)
+
+	adminRole := findRole(t, bed, authConstants.AdminRole)
+	t.Cleanup(func() {
+		bed.Db.DB.Where("role_id = ?", adminRole.ID).Delete(&authModels.Permission{})
+	})
+
+	admin := []authTypes.Role{authConstants.AdminRole}
+	defaults := authTypes.RolePermissionMap{
+		authConstants.AdminRole: authConstants.AllAllowedAccess,
+	}
+
+	for _, resource := range []string{"Role", "Permission", "UserRole"} {
+		createPermission(t, bed, authConstants.AdminRole, resource, "")
+		assert.True(t, authUtils.UserIsAllowed(defaults, admin, authConstants.OperationUpdate, resource, bed.Logger), resource)
+	}
+
+	// Other resources may still be restricted for admins
+	createPermission(t, bed, authConstants.AdminRole, "Post", "read")
+	assert.False(t, authUtils.UserIsAllowed(defaults, admin, authConstants.OperationUpdate, "Post", bed.Logger))
+}
+
+func TestPermissionResource_ValidatesResource(t *testing.T) {
+	bed := setupPermissionStore(t)
+
+	resource, err := bed.Mgr.AddResource(authResources.SetupPermissionResource(bed.Mgr, bed.Logger))
+	assert.NoError(t, err)
+
+	valid := resource.Validate(authModels.Permission{Resource: "Permission", Operations: "read"}, bed.Logger)
+	assert.Empty(t, valid.Errors)
+
+	invalid := resource.Validate(authModels.Permission{Resource: "Unknown", Operations: "read"}, bed.Logger)
+	assert.Len(t, invalid.Errors, 1)
+}
+
+func TestLoadUserRoles(t *testing.T) {
+	bed := setupPermissionStore(t)
+
+	userRole := &authModels.UserRole{
+		UserID: bed.VisitorUser.ID,
+		RoleID: findRole(t, bed, editorRole).ID,
+	}
+	userRole.CreatedByID = bed.AdminUser.ID
+	userRole.UpdatedByID = bed.AdminUser.ID
+	assert.NoError(t, bed.Db.DB.Create(userRole).Error)
+
+	user := authModels.User{}
+	assert.NoError(t, bed.Db.DB.First(&user, bed.VisitorUser.ID).Error)
+	assert.NotContains(t, user.GetRoles(), editorRole)
+
+	authUtils.LoadUserRoles(context.Background(), bed.Db, &user, bed.Logger)
+	assert.Equal(t, []authTypes.Role{authConstants.VisitorRole, editorRole}, user.GetRoles())
+}
@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"context"
+
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	dbQueries "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/queries"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	loggerTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/logger/types"
+)
+
+// SeedRoles creates the records of the given roles, unless they exist already.
+func SeedRoles(ctx context.Context, db *dbTypes.DatabaseConnection, systemUser *authModels.User, requestId string, log *loggerTypes.Logger, roles ...authTypes.Role) error {
+	for _, role := range roles {
+		existing := authModels.Role{}
+		err := dbQueries.FindOne(ctx, log, db, &existing, map[string]interface{}{"name": string(role)}, []string{})
+		if err == nil {
+			continue
+		}
+
+		record := &authModels.Role{Name: string(role)}
+		record.CreatedByID = systemUser.ID
+		record.UpdatedByID = systemUser.ID
+
+		err = dbQueries.Create(ctx, log, db, record, systemUser, requestId)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm/clause"
+
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	dbQueries "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/queries"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	loggerTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/logger/types"
+)
+
+// sessionSeenInterval limits how often the last activity of a session is written.
+const sessionSeenInterval = time.Minute
+
+var ErrSessionRevoked = errors.New("session has been revoked")
+
+// CheckRevoked returns ErrSessionRevoked when the sessions of the provider user were revoked after
+// the user signed in. When the provider doesn't report the auth time, tokens issued before a revocation
+// are rejected, and so is any token until the revocation is lifted by an interactive sign in.
+func CheckRevoked(ctx context.Context, db *dbTypes.DatabaseConnection, providerUser *authTypes.ProviderUser) error {
+	if providerUser.ID == "" {
+		return nil
+	}
+
+	query := db.DB.WithContext(ctx).Model(&authModels.SessionRevocation{})
+	if providerUser.AuthTime.IsZero() {
+		query = query.Where("provider_id = ? AND (lifted_at IS NULL OR issued_before > ?)", providerUser.ID, providerUser.IssuedAt.UTC())
+	} else {
+		query = query.Where("provider_id = ? AND issued_before > ?", providerUser.ID, providerUser.AuthTime.UTC())
+	}
+
+	var count int64
+	err := query.Count(&count).Error
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return ErrSessionRevoked
+	}
+
+	return nil
+}
+
+// LiftSessionRevocations lets tokens without an auth time through again, once the provider user
+// signed in interactively. Tokens issued before the revocations are still rejected.
+func LiftSessionRevocations(ctx context.Context, db *dbTypes.DatabaseConnection, providerId string, log *loggerTypes.Logger) error {
+	result := db.DB.WithContext(ctx).Model(&authModels.SessionRevocation{}).
+		Where("provider_id = ? AND lifted_at IS NULL", providerId).
+		Update("lifted_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		log.Info().Str("provider", providerId).Msg("Session revocations lifted")
+	}
+
+	return nil
+}
+
+// TrackSession records the session of the token on its first use, returning ErrSessionRevoked
+// when the session was revoked since.
+func TrackSession(ctx context.Context, db *dbTypes.DatabaseConnection, user *authModels.User, providerUser *authTypes.ProviderUser, log *loggerTypes.Logger) error {
+	if user.ID == 0 || providerUser.SessionId == "" {
+		return nil
+	}
+
+	now := time.Now()
+	session := authModels.UserSession{
+		UserID:     user.ID,
+		SessionId:  providerUser.SessionId,
+		AuthTime:   providerUser.AuthTime,
+		LastSeenAt: now,
+	}
+
+	// Concurrent requests of a new session insert it at once, the row is read back in any case
+	err := db.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&session).Error
+	if err != nil {
+		return err
+	}
+
+	session = authModels.UserSession{}
+	err = db.DB.WithContext(ctx).
+		Where("user_id = ? AND session_id = ?", user.ID, providerUser.SessionId).
+		First(&session).Error
+	if err != nil {
+		return err
+	}
+
+	if session.RevokedAt != nil {
+		return ErrSessionRevoked
+	}
+
+	if now.Sub(session.LastSeenAt) > sessionSeenInterval {
+		err = db.DB.WithContext(ctx).Model(&session).UpdateColumn("last_seen_at", now).Error
+		if err != nil {
+			log.Debug().Err(err).Msg("Error updating session activity")
+		}
+	}
+
+	return nil
+}
+
+// RevokeUserSessions signs the user out of every device. Tracked sessions, refresh tokens and the
+// impersonations the user started are revoked, and tokens of sessions started until now are rejected from then on.
+// The revocation is recorded in the database log.
+func RevokeUserSessions(ctx context.Context, db *dbTypes.DatabaseConnection, user *authModels.User, revokedBy *authModels.User, reason string, requestId string, log *loggerTypes.Logger) error {
+	now := time.Now()
+
+	// Auth times are truncated to seconds by most providers, the sessions started within the
+	// same second are only rejected if they were tracked
+	revocation := &authModels.SessionRevocation{
+		ProviderId:   user.FirebaseId,
+		UserID:       user.ID,
+		IssuedBefore: now.UTC().Truncate(time.Second),
+		Reason:       reason,
+	}
+
+	err := dbQueries.Create(ctx, log, db, revocation, revokedBy, requestId)
+	if err != nil {
+		return err
+	}
+
+	err = db.DB.WithContext(ctx).Model(&authModels.UserSession{}).
+		Where("user_id = ? AND revoked_at IS NULL", user.ID).
+		Update("revoked_at", now).Error
+	if err != nil {
+		return err
+	}
+
+	// Refresh tokens are only issued by the local provider
+	if db.DB.Migrator().HasTable(&authModels.RefreshToken{}) {
+		err = db.DB.WithContext(ctx).Model(&authModels.RefreshToken{}).
+			Where("user_id = ? AND revoked_at IS NULL", user.ID).
+			Update("revoked_at", now).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	// Impersonation tokens outlive the session of the admin who started them otherwise
+	if db.DB.Migrator().HasTable(&authModels.ImpersonationSession{}) {
+		err = db.DB.WithContext(ctx).
+			Where("created_by_id = ? AND expires_at > ?", user.ID, now).
+			Delete(&authModels.ImpersonationSession{}).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Info().Uint("user", user.ID).Str("reason", reason).Msg("User sessions revoked")
+	return nil
+}
@@ -0,0 +1,77 @@
+package auth_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	testPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/testing"
+)
+
+func TestVerifyUser_RevokedSessions(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+	provider := testPkg.NewMockAuthProvider()
+
+	email := testPkg.RandomEmail()
+	_, err := provider.CreateUser(context.Background(), authTypes.ProviderUserInput{Name: "Jane", Email: email})
+	assert.NoError(t, err)
+
+	phone := provider.IssueToken(email)
+	laptop := provider.IssueToken(email)
+
+	user, err := authUtils.VerifyUser(phone, provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+	assert.NoError(t, err)
+	_, err = authUtils.VerifyUser(laptop, provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+	assert.NoError(t, err)
+
+	var count int64
+	bed.Db.DB.Model(&authModels.UserSession{}).Where("user_id = ?", user.ID).Count(&count)
+	assert.Equal(t, int64(2), count)
+
+	// Every session is rejected once revoked
+	err = authUtils.RevokeUserSessions(context.Background(), bed.Db, user, user, "test", "test-request", bed.Logger)
+	assert.NoError(t, err)
+
+	_, err = authUtils.VerifyUser(phone, provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+	assert.ErrorIs(t, err, authUtils.ErrSessionRevoked)
+	_, err = authUtils.VerifyUser(laptop, provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+	assert.ErrorIs(t, err, authUtils.ErrSessionRevoked)
+
+	// Signing in again starts a new session
+	again, err := authUtils.VerifyUser(provider.IssueToken(email), provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, again.ID)
+}
+
+func TestVerifyUser_RevokedUntrackedSessions(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+	provider := testPkg.NewMockAuthProvider()
+
+	email := testPkg.RandomEmail()
+	_, err := provider.CreateUser(context.Background(), authTypes.ProviderUserInput{Name: "Jane", Email: email})
+	assert.NoError(t, err)
+
+	user, err := authUtils.VerifyUser(provider.IssueToken(email), provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+	assert.NoError(t, err)
+
+	// A token of a session started earlier, never seen by the server
+	token := provider.IssueToken(email)
+	provider.AuthTimes[token] = time.Now().Add(-time.Hour)
+
+	assert.NoError(t, bed.Db.DB.Delete(user).Error)
+	err = authUtils.RevokeUserSessions(context.Background(), bed.Db, user, bed.AdminUser, "user deleted", "test-request", bed.Logger)
+	assert.NoError(t, err)
+
+	// Deleted users aren't registered again by their tokens
+	_, err = authUtils.VerifyUser(token, provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+	assert.ErrorIs(t, err, authUtils.ErrSessionRevoked)
+
+	var count int64
+	bed.Db.DB.Model(&authModels.User{}).Where("email = ?", user.Email).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
@@ -0,0 +1,46 @@
+package auth_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+)
+
+func TestTotpCode(t *testing.T) {
+	// Test vectors of RFC 6238, truncated to 6 digits
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ" // "12345678901234567890"
+
+	tests := []struct {
+		at       int64
+		expected string
+	}{
+		{at: 59, expected: "287082"},
+		{at: 1111111109, expected: "081804"},
+		{at: 1234567890, expected: "005924"},
+		{at: 2000000000, expected: "279037"},
+	}
+
+	for _, tt := range tests {
+		code, err := authUtils.TotpCode(secret, time.Unix(tt.at, 0))
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expected, code)
+	}
+
+	_, err := authUtils.TotpCode("not base32!", time.Now())
+	assert.Error(t, err)
+}
+
+func TestTotpUri(t *testing.T) {
+	secret, err := authUtils.GenerateTotpSecret()
+	assert.NoError(t, err)
+	assert.Len(t, secret, 32)
+
+	uri := authUtils.TotpUri("My CMS", "jane@example.com", secret)
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/My%20CMS:jane@example.com?"))
+	assert.Contains(t, uri, "secret="+secret)
+	assert.Contains(t, uri, "issuer=My+CMS")
+}
@@ -5,8 +5,29 @@ import (
 	loggerTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/logger/types"
 )
 
+// UserIsAllowed checks whether any of the roles may perform the action on the resource. The permissions
+// compiled into the resource are merged with the stored ones, when a permission store is set.
+// Scoped roles don't grant access, when present the action must also be one of them.
 func UserIsAllowed(appPermissions authTypes.RolePermissionMap, userRoles []authTypes.Role, action authTypes.CrudOperation, resourceName string, log *loggerTypes.Logger) bool {
 
+	if store := getPermissionStore(); store != nil {
+		appPermissions = store.Effective(appPermissions, resourceName)
+	}
+
+	scoped := false
+	inScope := false
+	for _, role := range userRoles {
+		if role.IsScoped() {
+			scoped = true
+			inScope = inScope || role == authTypes.ScopedRole(resourceName, action)
+		}
+	}
+
+	if scoped && !inScope {
+		log.Debug().Msgf("Denied access: No scope allows to %s resource %s", action, resourceName)
+		return false
+	}
+
 	// Loop over the user's roles and their associated permissions
 	for _, role := range userRoles {
 		if _, ok := appPermissions[role]; ok {
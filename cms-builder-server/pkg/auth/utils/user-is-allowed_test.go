@@ -72,6 +72,40 @@ func TestUserIsAllowed(t *testing.T) {
 			action:         authConstants.OperationRead,
 			expectedResult: false,
 		},
+		{
+			name:           "scoped role alone grants nothing",
+			appPermissions: authTypes.RolePermissionMap{},
+			userRoles:      []authTypes.Role{authTypes.ScopedRole("test-app-name", authConstants.OperationRead)},
+			action:         authConstants.OperationRead,
+			expectedResult: false,
+		},
+		{
+			name: "scoped role allows its operation when the other roles do",
+			appPermissions: authTypes.RolePermissionMap{
+				EditorRole: authConstants.AllAllowedAccess,
+			},
+			userRoles:      []authTypes.Role{EditorRole, authTypes.ScopedRole("test-app-name", authConstants.OperationRead)},
+			action:         authConstants.OperationRead,
+			expectedResult: true,
+		},
+		{
+			name: "scoped role restricts other operations",
+			appPermissions: authTypes.RolePermissionMap{
+				EditorRole: authConstants.AllAllowedAccess,
+			},
+			userRoles:      []authTypes.Role{EditorRole, authTypes.ScopedRole("test-app-name", authConstants.OperationRead)},
+			action:         authConstants.OperationDelete,
+			expectedResult: false,
+		},
+		{
+			name: "scoped role restricts other resources",
+			appPermissions: authTypes.RolePermissionMap{
+				EditorRole: authConstants.AllAllowedAccess,
+			},
+			userRoles:      []authTypes.Role{EditorRole, authTypes.ScopedRole("another-app", authConstants.OperationRead)},
+			action:         authConstants.OperationRead,
+			expectedResult: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	dbQueries "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/queries"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	loggerTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/logger/types"
+)
+
+const (
+	tokenBytes         = 32
+	apiKeyPrefix       = "cms_"
+	apiKeyPrefixLength = len(apiKeyPrefix) + 8
+)
+
+var (
+	ErrInvalidApiKey      = errors.New("invalid api key")
+	ErrApiKeyInactive     = errors.New("api key is revoked or expired")
+	ErrApiKeyIpNotAllowed = errors.New("api key is not allowed from this ip")
+)
+
+// GenerateApiKey returns a new random key, along with the prefix and hash to be stored.
+func GenerateApiKey() (key string, prefix string, hash string, err error) {
+	key, hash, err = generateToken(apiKeyPrefix)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	return key, key[:apiKeyPrefixLength], hash, nil
+}
+
+func HashApiKey(key string) string {
+	return hashToken(key)
+}
+
+// generateToken returns a random token with the given prefix, and the hash to be stored.
+func generateToken(prefix string) (token string, hash string, err error) {
+	bytes := make([]byte, tokenBytes)
+	if _, err = rand.Read(bytes); err != nil {
+		return "", "", err
+	}
+
+	token = prefix + base64.RawURLEncoding.EncodeToString(bytes)
+	return token, hashToken(token), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyApiKey returns the service user of the key, acting with the roles and scopes of the key.
+// Roles the service user no longer holds are dropped. The last used timestamp of the key is recorded.
+//
+// Keys are not subject to the MFA policy, they can't answer a code. Keys carrying roles that
+// require a second factor can only be created by users whose session passed one instead.
+func VerifyApiKey(key string, ip string, db *dbTypes.DatabaseConnection, log *loggerTypes.Logger) (*authModels.User, error) {
+	apiKey := authModels.ApiKey{}
+	filters := map[string]interface{}{
+		"key_hash": HashApiKey(key),
+	}
+
+	err := dbQueries.FindOne(context.Background(), log, db, &apiKey, filters, []string{"User"})
+	if err != nil || apiKey.User == nil {
+		return nil, ErrInvalidApiKey
+	}
+
+	now := time.Now()
+	if !apiKey.IsActive(now) {
+		return nil, ErrApiKeyInactive
+	}
+
+	if !apiKey.IpAllowed(ip) {
+		log.Warn().Str("ip", ip).Uint("apiKey", apiKey.ID).Msg("Api key used from a not allowed ip")
+		return nil, ErrApiKeyIpNotAllowed
+	}
+
+	// Usage isn't a change worth a database log entry
+	err = db.DB.Model(&apiKey).UpdateColumn("last_used_at", now).Error
+	if err != nil {
+		log.Error().Err(err).Msg("Error recording api key usage")
+	}
+
+	// Keys without roles act with every role of the user, the others with the ones it holds
+	LoadUserRoles(context.Background(), db, apiKey.User, log)
+
+	user := *apiKey.User
+	user.Roles = FormatRoles(apiKey.GetRoles())
+	user.UserRoles = nil
+	user.ApiKeyID = apiKey.ID
+	return &user, nil
+}
+
+// ValidScope returns whether the scope has the resource:operation format, with a known operation.
+func ValidScope(scope string, operations []authTypes.CrudOperation) bool {
+	resourceName, operation, ok := strings.Cut(scope, ":")
+	return ok && resourceName != "" && slices.Contains(operations, authTypes.CrudOperation(operation))
+}
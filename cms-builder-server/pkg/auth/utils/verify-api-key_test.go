@@ -0,0 +1,149 @@
+package auth_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	testPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/testing"
+)
+
+func createApiKey(t *testing.T, bed testPkg.TestUtils, apiKey *authModels.ApiKey) string {
+	key, prefix, hash, err := authUtils.GenerateApiKey()
+	assert.NoError(t, err)
+	assert.Equal(t, prefix, key[:len(prefix)])
+	assert.NotEqual(t, key, hash)
+
+	apiKey.Name = "integration"
+	apiKey.Prefix = prefix
+	apiKey.KeyHash = hash
+	apiKey.UserID = bed.VisitorUser.ID
+	apiKey.CreatedByID = bed.AdminUser.ID
+	apiKey.UpdatedByID = bed.AdminUser.ID
+	assert.NoError(t, bed.Db.DB.Create(apiKey).Error)
+
+	return key
+}
+
+func TestVerifyApiKey(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+	assert.NoError(t, bed.Db.DB.AutoMigrate(&authModels.ApiKey{}))
+
+	apiKey := &authModels.ApiKey{}
+	key := createApiKey(t, bed, apiKey)
+
+	user, err := authUtils.VerifyApiKey(key, "10.0.0.1", bed.Db, bed.Logger)
+	assert.NoError(t, err)
+	assert.Equal(t, bed.VisitorUser.ID, user.ID)
+	assert.Equal(t, bed.VisitorUser.GetRoles(), user.GetRoles())
+	assert.Equal(t, apiKey.ID, user.ApiKeyID)
+
+	stored := authModels.ApiKey{}
+	assert.NoError(t, bed.Db.DB.First(&stored, apiKey.ID).Error)
+	assert.NotNil(t, stored.LastUsedAt)
+
+	_, err = authUtils.VerifyApiKey(key+"x", "10.0.0.1", bed.Db, bed.Logger)
+	assert.ErrorIs(t, err, authUtils.ErrInvalidApiKey)
+}
+
+func TestVerifyApiKey_RolesAndScopes(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+	assert.NoError(t, bed.Db.DB.AutoMigrate(&authModels.ApiKey{}))
+
+	// Roles the service user doesn't hold are dropped
+	key := createApiKey(t, bed, &authModels.ApiKey{
+		Roles:  authUtils.FormatRoles([]authTypes.Role{authConstants.VisitorRole, authConstants.AdminRole}),
+		Scopes: "Post:read,Post:update",
+	})
+
+	user, err := authUtils.VerifyApiKey(key, "10.0.0.1", bed.Db, bed.Logger)
+	assert.NoError(t, err)
+	assert.Equal(t, []authTypes.Role{
+		authConstants.VisitorRole,
+		authTypes.ScopedRole("Post", authConstants.OperationRead),
+		authTypes.ScopedRole("Post", authConstants.OperationUpdate),
+	}, user.GetRoles())
+
+	// The stored user keeps its own roles
+	stored := authModels.User{}
+	assert.NoError(t, bed.Db.DB.First(&stored, bed.VisitorUser.ID).Error)
+	assert.Equal(t, bed.VisitorUser.Roles, stored.Roles)
+
+	// Scopes restrict the roles, they don't grant what the roles don't allow
+	permissions := authTypes.RolePermissionMap{
+		authConstants.VisitorRole: authConstants.AllAllowedAccess,
+	}
+	assert.True(t, authUtils.UserIsAllowed(permissions, user.GetRoles(), authConstants.OperationUpdate, "Post", bed.Logger))
+	assert.False(t, authUtils.UserIsAllowed(permissions, user.GetRoles(), authConstants.OperationDelete, "Post", bed.Logger))
+	assert.False(t, authUtils.UserIsAllowed(permissions, user.GetRoles(), authConstants.OperationRead, "Comment", bed.Logger))
+
+	readOnly := authTypes.RolePermissionMap{
+		authConstants.VisitorRole: []authTypes.CrudOperation{authConstants.OperationRead},
+	}
+	assert.False(t, authUtils.UserIsAllowed(readOnly, user.GetRoles(), authConstants.OperationUpdate, "Post", bed.Logger))
+	assert.False(t, authUtils.UserIsAllowed(authTypes.RolePermissionMap{}, user.GetRoles(), authConstants.OperationRead, "Post", bed.Logger))
+}
+
+func TestVerifyApiKey_ScopesOnly(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+	assert.NoError(t, bed.Db.DB.AutoMigrate(&authModels.ApiKey{}))
+
+	// Keys with scopes only act with the roles of the user, restricted to the scopes
+	key := createApiKey(t, bed, &authModels.ApiKey{Scopes: "Post:read"})
+
+	user, err := authUtils.VerifyApiKey(key, "10.0.0.1", bed.Db, bed.Logger)
+	assert.NoError(t, err)
+	assert.Equal(t, []authTypes.Role{
+		authConstants.VisitorRole,
+		authTypes.ScopedRole("Post", authConstants.OperationRead),
+	}, user.GetRoles())
+}
+
+func TestVerifyApiKey_RolesNotHeld(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+	assert.NoError(t, bed.Db.DB.AutoMigrate(&authModels.ApiKey{}))
+
+	// A key whose roles are all dropped doesn't fall back to the roles of the user
+	key := createApiKey(t, bed, &authModels.ApiKey{Roles: string(authConstants.AdminRole)})
+
+	user, err := authUtils.VerifyApiKey(key, "10.0.0.1", bed.Db, bed.Logger)
+	assert.NoError(t, err)
+	assert.Empty(t, user.GetRoles())
+}
+
+func TestVerifyApiKey_Rejected(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+	assert.NoError(t, bed.Db.DB.AutoMigrate(&authModels.ApiKey{}))
+
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name     string
+		apiKey   *authModels.ApiKey
+		ip       string
+		expected error
+	}{
+		{"revoked", &authModels.ApiKey{RevokedAt: &past}, "10.0.0.1", authUtils.ErrApiKeyInactive},
+		{"expired", &authModels.ApiKey{ExpiresAt: &past}, "10.0.0.1", authUtils.ErrApiKeyInactive},
+		{"ip not allowed", &authModels.ApiKey{AllowedIps: "10.0.0.2,192.168.0.0/16"}, "10.0.0.1", authUtils.ErrApiKeyIpNotAllowed},
+		{"ip allowed", &authModels.ApiKey{AllowedIps: "10.0.0.2,192.168.0.0/16"}, "192.168.4.20", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := createApiKey(t, bed, tt.apiKey)
+
+			_, err := authUtils.VerifyApiKey(key, tt.ip, bed.Db, bed.Logger)
+			if tt.expected == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.expected)
+		})
+	}
+}
@@ -2,47 +2,88 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"fmt"
-
-	firebaseAuth "firebase.google.com/go/auth"
+	"strings"
+	"time"
 
 	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
 	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
-	cliPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/clients"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
 	dbQueries "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/queries"
 	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
 	loggerTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/logger/types"
+	utilsPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/utils"
 )
 
-func VerifyUser(userIdToken string, firebase *cliPkg.FirebaseManager, db *dbTypes.DatabaseConnection, systemUser *authModels.User, requestId string, log *loggerTypes.Logger) (*authModels.User, error) {
+var ErrUnverifiedEmailLink = errors.New("a user with this email exists, the auth provider must verify the email to sign in as them")
+
+// VerifyUser verifies the access token with the auth provider and returns the matching local user.
+// Users unknown to the database are registered as visitors. Tokens of revoked sessions are rejected,
+// and roles requiring MFA are withheld from sessions without a second factor.
+// Emails verified by the provider are marked as verified, and providers managing roles set them on every sign in.
+func VerifyUser(userIdToken string, provider authTypes.AuthProvider, db *dbTypes.DatabaseConnection, systemUser *authModels.User, requestId string, log *loggerTypes.Logger) (*authModels.User, error) {
 
-	accessToken, err := firebase.VerifyIDToken(context.Background(), userIdToken)
+	providerUser, err := provider.VerifyToken(context.Background(), userIdToken)
 	if err != nil {
 		log.Error().Err(err).Msg("Error verifying token")
 		return nil, err
 	}
 
-	localUser := authModels.User{}
+	// Checked before looking up the user, so deleted users aren't registered again
+	err = CheckRevoked(context.Background(), db, providerUser)
+	if err != nil {
+		log.Warn().Err(err).Str("provider", string(provider.Type())).Msg("Token of a revoked session")
+		return nil, err
+	}
+
+	localUser := &authModels.User{}
 	filters := map[string]interface{}{
-		"firebase_id": accessToken.UID,
+		"firebase_id": providerUser.ID,
+	}
+
+	err = dbQueries.FindOne(context.Background(), log, db, localUser, filters, []string{})
+	if err != nil {
+		log.Warn().Str("provider", string(provider.Type())).Msg("User is provider user but not in database")
+		localUser, err = RegisterProviderUserInDatabase(providerUser, db, systemUser, requestId, log)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		LoadUserRoles(context.Background(), db, localUser, log)
+	}
+
+	if rolesProvider, ok := provider.(authTypes.RoleManagingProvider); ok && rolesProvider.ManagesRoles() {
+		err = syncProviderRoles(context.Background(), db, localUser, providerUser, systemUser, requestId, log)
+		if err != nil {
+			log.Error().Err(err).Uint("user", localUser.ID).Msg("Error updating roles granted by the provider")
+			return nil, err
+		}
+	}
+
+	if providerUser.EmailVerified {
+		err = MarkEmailVerified(context.Background(), db, localUser, systemUser, requestId, log)
+		if err != nil {
+			log.Error().Err(err).Uint("user", localUser.ID).Msg("Error marking email as verified")
+		}
 	}
 
-	err = dbQueries.FindOne(context.Background(), log, db, &localUser, filters, []string{})
+	err = TrackSession(context.Background(), db, localUser, providerUser, log)
 	if err != nil {
-		log.Warn().Msg("User is firebase user but not in database")
-		return RegisterFirebaseUserInDatabase(accessToken, firebase, db, systemUser, requestId, log)
+		log.Warn().Err(err).Uint("user", localUser.ID).Msg("Token of a revoked session")
+		return nil, err
 	}
 
-	return &localUser, nil
+	ApplyMfaPolicy(localUser, providerUser, log)
+	return localUser, nil
 }
 
-func RegisterFirebaseUserInDatabase(accessToken *firebaseAuth.Token, firebase *cliPkg.FirebaseManager, db *dbTypes.DatabaseConnection, systemUser *authModels.User, requestId string, log *loggerTypes.Logger) (*authModels.User, error) {
-	// Extract name and email from the access token's claims
-	name, _ := accessToken.Claims["name"].(string)    // Name might not always be present
-	email, ok := accessToken.Claims["email"].(string) // Email is usually required
+func RegisterProviderUserInDatabase(providerUser *authTypes.ProviderUser, db *dbTypes.DatabaseConnection, systemUser *authModels.User, requestId string, log *loggerTypes.Logger) (*authModels.User, error) {
+	name := providerUser.Name                    // Name might not always be present
+	email := strings.ToLower(providerUser.Email) // Email is usually required
 
 	// Check if email is present and valid
-	if !ok || email == "" {
+	if email == "" {
 		return nil, fmt.Errorf("email claim is missing or invalid in the access token")
 	}
 
@@ -51,16 +92,22 @@ func RegisterFirebaseUserInDatabase(accessToken *firebaseAuth.Token, firebase *c
 		name = "No Name" // Or any other default value
 	}
 
-	roles := string(authConstants.VisitorRole)
+	roles := providerRoles(providerUser)
 
 	// Create a local user object
 	localUser := &authModels.User{
-		FirstName: name,
-		Email:     email,
-		Roles:     roles, // Assign roles if applicable
+		FirstName:  name,
+		Email:      email,
+		FirebaseId: providerUser.ID,
+		Roles:      roles, // Assign roles if applicable
 	}
 
-	err := getOrCreateLocalUser(context.Background(), localUser, log, db, systemUser, requestId)
+	if providerUser.EmailVerified {
+		now := time.Now()
+		localUser.EmailVerifiedAt = &now
+	}
+
+	err := getOrCreateLocalUser(context.Background(), localUser, providerUser.EmailVerified, log, db, systemUser, requestId)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to create user")
 		return nil, err
@@ -69,15 +116,55 @@ func RegisterFirebaseUserInDatabase(accessToken *firebaseAuth.Token, firebase *c
 	return localUser, nil
 }
 
-func getOrCreateLocalUser(ctx context.Context, localUser *authModels.User, log *loggerTypes.Logger, db *dbTypes.DatabaseConnection, systemUser *authModels.User, requestId string) error {
+func getOrCreateLocalUser(ctx context.Context, localUser *authModels.User, emailVerified bool, log *loggerTypes.Logger, db *dbTypes.DatabaseConnection, systemUser *authModels.User, requestId string) error {
 	filters := map[string]interface{}{
 		"email": localUser.Email,
 	}
 
+	providerId := localUser.FirebaseId
+
 	err := dbQueries.FindOne(ctx, log, db, localUser, filters, []string{})
 	if err != nil {
 		return dbQueries.Create(ctx, log, db, localUser, systemUser, requestId)
 	}
 
-	return nil
+	if localUser.FirebaseId == providerId {
+		return nil
+	}
+
+	// Link users created before their first login, e.g. the admin, to the provider.
+	// Whoever holds the account signs in as the user, so the provider must vouch for the email.
+	if localUser.FirebaseId != "" || !emailVerified {
+		log.Warn().Uint("user", localUser.ID).Msg("Provider account not linked to existing user")
+		return ErrUnverifiedEmailLink
+	}
+
+	previousState := *localUser
+	localUser.FirebaseId = providerId
+	differences := utilsPkg.CompareInterfaces(previousState, *localUser)
+
+	return dbQueries.Update(ctx, log, db, localUser, systemUser, differences, requestId)
+}
+
+// providerRoles returns the roles the provider grants to the user, visitor when it grants none.
+func providerRoles(providerUser *authTypes.ProviderUser) string {
+	if len(providerUser.Roles) == 0 {
+		return string(authConstants.VisitorRole)
+	}
+	return FormatRoles(providerUser.Roles)
+}
+
+// syncProviderRoles replaces the roles of the user with the ones granted by the provider, so roles
+// removed at the provider are removed here too. Tokens issued before keep their roles until they expire.
+func syncProviderRoles(ctx context.Context, db *dbTypes.DatabaseConnection, localUser *authModels.User, providerUser *authTypes.ProviderUser, systemUser *authModels.User, requestId string, log *loggerTypes.Logger) error {
+	roles := providerRoles(providerUser)
+	if localUser.Roles == roles {
+		return nil
+	}
+
+	previousState := *localUser
+	localUser.Roles = roles
+	differences := utilsPkg.CompareInterfaces(previousState, *localUser)
+
+	return dbQueries.Update(ctx, log, db, localUser, systemUser, differences, requestId)
 }
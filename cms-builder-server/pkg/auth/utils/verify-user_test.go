@@ -0,0 +1,101 @@
+package auth_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	testPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/testing"
+)
+
+func TestVerifyUser_RegistersProviderUser(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+	provider := testPkg.NewMockAuthProvider()
+
+	email := testPkg.RandomEmail()
+	providerUser, err := provider.CreateUser(context.Background(), authTypes.ProviderUserInput{Name: "Jane", Email: email})
+	assert.NoError(t, err)
+
+	token := provider.IssueToken(email)
+
+	user, err := authUtils.VerifyUser(token, provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+	assert.NoError(t, err)
+	assert.Equal(t, strings.ToLower(email), user.Email)
+	assert.Equal(t, providerUser.ID, user.FirebaseId)
+	assert.True(t, user.HasRole(authConstants.VisitorRole))
+
+	// The second time the user is found by provider ID
+	again, err := authUtils.VerifyUser(token, provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, again.ID)
+}
+
+func TestVerifyUser_InvalidToken(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+	provider := testPkg.NewMockAuthProvider()
+
+	user, err := authUtils.VerifyUser("invalid", provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+	assert.ErrorIs(t, err, authTypes.ErrProviderInvalidToken)
+	assert.Nil(t, user)
+}
+
+func TestCreateUserWithRole_RegistersInProvider(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+	provider := testPkg.NewMockAuthProvider()
+
+	input := authTypes.RegisterUserInput{
+		FirstName:        "John",
+		LastName:         "Doe",
+		Email:            strings.ToLower(testPkg.RandomEmail()),
+		Password:         "secret",
+		Roles:            []authTypes.Role{authConstants.VisitorRole},
+		RegisterFirebase: true,
+	}
+
+	user, err := authUtils.CreateUserWithRole(input, provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+	assert.NoError(t, err)
+
+	providerUser, err := provider.GetUserByEmail(context.Background(), input.Email)
+	assert.NoError(t, err)
+	assert.Equal(t, providerUser.ID, user.FirebaseId)
+
+	// Users already in the provider are linked instead of failing
+	_, err = provider.CreateUser(context.Background(), authTypes.ProviderUserInput{Email: "existing" + input.Email})
+	assert.NoError(t, err)
+
+	input.Email = "existing" + input.Email
+	user, err = authUtils.CreateUserWithRole(input, provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, user.FirebaseId)
+}
+
+func TestVerifyUser_ProviderVerifiedEmail(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+	provider := testPkg.NewMockAuthProvider()
+
+	email := testPkg.RandomEmail()
+	_, err := provider.CreateUser(context.Background(), authTypes.ProviderUserInput{Name: "Jane", Email: email})
+	assert.NoError(t, err)
+
+	user, err := authUtils.VerifyUser(provider.IssueToken(email), provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+	assert.NoError(t, err)
+	assert.False(t, user.IsEmailVerified())
+
+	providerUser := provider.Users[email]
+	providerUser.EmailVerified = true
+	provider.Users[email] = providerUser
+
+	user, err = authUtils.VerifyUser(provider.IssueToken(email), provider, bed.Db, bed.AdminUser, "test-request", bed.Logger)
+	assert.NoError(t, err)
+	assert.True(t, user.IsEmailVerified())
+
+	stored := authModels.User{}
+	assert.NoError(t, bed.Db.DB.First(&stored, user.ID).Error)
+	assert.True(t, stored.IsEmailVerified())
+}
@@ -0,0 +1,120 @@
+package comment
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	commentModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/comment/models"
+	dbQueries "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/queries"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	notificationTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/notification/types"
+	rmPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
+	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
+)
+
+// CommentInput is the request body of a new comment.
+type CommentInput struct {
+	ResourceName string `json:"resourceName"`
+	ResourceId   string `json:"resourceId"`
+	ParentID     *uint  `json:"parentId"`
+	Body         string `json:"body"`
+}
+
+// CreateCommentHandler attaches a comment to a record the user can read, and
+// notifies the users mentioned in it who can read the record too.
+func CreateCommentHandler(mgr *rmPkg.ResourceManager, notifier notificationTypes.Notifier) rmTypes.ApiFunction {
+	return func(a *rmTypes.Resource, db *dbTypes.DatabaseConnection) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			requestCtx := svrUtils.GetRequestContext(r)
+			log := requestCtx.Logger
+			user := requestCtx.User
+			requestId := requestCtx.RequestId
+
+			// 1. Validate Request Method
+			err := svrUtils.ValidateRequestMethod(r, http.MethodPost)
+			if err != nil {
+				svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+				return
+			}
+
+			// 2. Check Permissions
+			if !authUtils.UserIsAllowed(a.Permissions, user.GetRoles(), authConstants.OperationCreate, a.ResourceNames.Singular, log) {
+				svrUtils.SendJsonResponse(w, http.StatusForbidden, nil, "User is not allowed to create this resource")
+				return
+			}
+
+			// 3. Parse Request Body
+			bodyBytes, err := svrUtils.ReadRequestBody(r)
+			if err != nil {
+				svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, "Invalid request body")
+				return
+			}
+
+			input := CommentInput{}
+			err = json.Unmarshal(bodyBytes, &input)
+			if err != nil {
+				svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, "Invalid request body")
+				return
+			}
+
+			if strings.TrimSpace(input.Body) == "" {
+				svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, "Comment body is required")
+				return
+			}
+
+			// 4. Verify Commented Record and Parent
+			err = findCommentedRecord(r.Context(), log, mgr, db, user, input.ResourceName, input.ResourceId)
+			if err != nil {
+				sendRecordError(w, err)
+				return
+			}
+
+			if input.ParentID != nil {
+				parent := commentModels.Comment{}
+				err = db.DB.WithContext(r.Context()).
+					Where("id = ? AND resource_name = ? AND resource_id = ?", *input.ParentID, input.ResourceName, input.ResourceId).
+					First(&parent).Error
+				if err != nil {
+					svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, ErrInvalidParent.Error())
+					return
+				}
+			}
+
+			// 5. Find Mentions
+			mentions := parseMentions(input.Body)
+			mentioned, err := findMentionedUsers(r.Context(), db, mentions)
+			if err != nil {
+				log.Error().Err(err).Msg("Error finding mentioned users")
+				svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error creating comment")
+				return
+			}
+			mentioned = filterRecordReaders(r.Context(), log, mgr, db, mentioned, input.ResourceName, input.ResourceId)
+
+			comment := commentModels.Comment{
+				ResourceName: input.ResourceName,
+				ResourceId:   input.ResourceId,
+				ParentID:     input.ParentID,
+				Body:         input.Body,
+				Mentions:     strings.Join(mentions, ","),
+			}
+			comment.CreatedByID = user.ID
+			comment.UpdatedByID = user.ID
+
+			// 6. Create Comment
+			err = dbQueries.Create(r.Context(), log, db, &comment, user, requestId)
+			if err != nil {
+				svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error creating comment")
+				return
+			}
+
+			// 7. Notify Mentioned Users
+			notifyMentions(r.Context(), log, notifier, user, &comment, mentioned, "")
+
+			svrUtils.SendJsonResponse(w, http.StatusCreated, &comment, a.ResourceNames.Singular+" has been created")
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package comment_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	commentModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/comment/models"
+	commentResources "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/comment/resources"
+	notificationConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/notification/constants"
+	notificationTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/notification/types"
+	testPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/testing"
+)
+
+type mockNotifier struct {
+	Notified []notificationTypes.NotificationInput
+}
+
+func (n *mockNotifier) Notify(ctx context.Context, input notificationTypes.NotificationInput) error {
+	n.Notified = append(n.Notified, input)
+	return nil
+}
+
+type commentTestBed struct {
+	testPkg.TestUtils
+	Router   *mux.Router
+	Notifier *mockNotifier
+}
+
+func setupCommentTestBed(t *testing.T) commentTestBed {
+	bed := testPkg.SetupHandlerTestBed()
+	notifier := &mockNotifier{}
+
+	config := commentResources.SetupCommentResource(bed.Mgr, bed.Db, notifier, bed.Logger)
+	_, err := bed.Mgr.AddResource(config)
+	assert.NoError(t, err)
+
+	router := mux.NewRouter()
+	for _, route := range bed.Mgr.GetRoutes("") {
+		router.HandleFunc(route.Path, route.Handler).Methods(route.Methods...)
+	}
+
+	return commentTestBed{TestUtils: bed, Router: router, Notifier: notifier}
+}
+
+func (bed commentTestBed) send(t *testing.T, method string, path string, body string, user *authModels.User) (*httptest.ResponseRecorder, commentModels.Comment) {
+	req := testPkg.CreateTestRequest(t, method, path, body, true, user, bed.Logger)
+	rr := httptest.NewRecorder()
+	bed.Router.ServeHTTP(rr, req)
+
+	response := struct {
+		Data commentModels.Comment `json:"data"`
+	}{}
+	if rr.Code < 300 {
+		json.Unmarshal(rr.Body.Bytes(), &response)
+	}
+	return rr, response.Data
+}
+
+func TestCreateCommentHandler(t *testing.T) {
+	bed := setupCommentTestBed(t)
+
+	record := testPkg.CreateMockResourceInstance(bed.VisitorUser.ID)
+	assert.NoError(t, bed.Db.DB.Create(record).Error)
+	target := fmt.Sprintf(`"resourceName": "MockStruct", "resourceId": "%d"`, record.ID)
+
+	t.Run("Mentions are stored as written and notified", func(t *testing.T) {
+		body := fmt.Sprintf(`{%s, "body": "Can you check this @%s? cc @%s and @nobody@example.com"}`, target, bed.AdminUser.Email, bed.VisitorUser.Email)
+		rr, comment := bed.send(t, http.MethodPost, "/api/comments/new", body, bed.VisitorUser)
+		assert.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+		mentions := []string{strings.ToLower(bed.AdminUser.Email), strings.ToLower(bed.VisitorUser.Email), "nobody@example.com"}
+		assert.Equal(t, mentions, strings.Split(comment.Mentions, ","), "Unknown emails can't be told apart from users")
+		assert.Len(t, bed.Notifier.Notified, 1)
+		assert.Equal(t, []uint{bed.AdminUser.ID}, bed.Notifier.Notified[0].UserIDs, "The author is not notified")
+		assert.Equal(t, notificationConstants.KindMentioned, bed.Notifier.Notified[0].Kind)
+		assert.Contains(t, bed.Notifier.Notified[0].Title, "mentioned you on MockStruct")
+		assert.Equal(t, fmt.Sprint(record.ID), bed.Notifier.Notified[0].ResourceId)
+	})
+
+	t.Run("Users who can't read the record aren't notified", func(t *testing.T) {
+		outsider := testPkg.CreateVisitorUser()
+		assert.NoError(t, bed.Db.DB.Create(outsider).Error)
+		notified := len(bed.Notifier.Notified)
+
+		body := fmt.Sprintf(`{%s, "body": "Have a look @%s"}`, target, outsider.Email)
+		rr, _ := bed.send(t, http.MethodPost, "/api/comments/new", body, bed.VisitorUser)
+		assert.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+		assert.Len(t, bed.Notifier.Notified, notified, "User binding of the commented resource applies")
+	})
+
+	t.Run("Replies belong to the same record", func(t *testing.T) {
+		_, parent := bed.send(t, http.MethodPost, "/api/comments/new", fmt.Sprintf(`{%s, "body": "First"}`, target), bed.AdminUser)
+
+		rr, reply := bed.send(t, http.MethodPost, "/api/comments/new", fmt.Sprintf(`{%s, "parentId": %d, "body": "Reply"}`, target, parent.ID), bed.VisitorUser)
+		assert.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+		assert.Equal(t, parent.ID, *reply.ParentID)
+
+		other := testPkg.CreateMockResourceInstance(bed.AdminUser.ID)
+		assert.NoError(t, bed.Db.DB.Create(other).Error)
+
+		body := fmt.Sprintf(`{"resourceName": "MockStruct", "resourceId": "%d", "parentId": %d, "body": "Misplaced"}`, other.ID, parent.ID)
+		rr, _ = bed.send(t, http.MethodPost, "/api/comments/new", body, bed.AdminUser)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Commented records must be readable", func(t *testing.T) {
+		other := testPkg.CreateMockResourceInstance(bed.AdminUser.ID)
+		assert.NoError(t, bed.Db.DB.Create(other).Error)
+
+		body := fmt.Sprintf(`{"resourceName": "MockStruct", "resourceId": "%d", "body": "Hello"}`, other.ID)
+		rr, _ := bed.send(t, http.MethodPost, "/api/comments/new", body, bed.VisitorUser)
+		assert.Equal(t, http.StatusNotFound, rr.Code, "User binding of the commented resource applies")
+
+		rr, _ = bed.send(t, http.MethodPost, "/api/comments/new", `{"resourceName": "Unknown", "resourceId": "1", "body": "Hello"}`, bed.AdminUser)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+		rr, _ = bed.send(t, http.MethodPost, "/api/comments/new", fmt.Sprintf(`{%s, "body": " "}`, target), bed.AdminUser)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
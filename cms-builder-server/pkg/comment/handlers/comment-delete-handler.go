@@ -0,0 +1,31 @@
+package comment
+
+import (
+	"net/http"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	commentModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/comment/models"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	rmHandlers "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/handlers"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
+	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
+)
+
+// DeleteCommentHandler lets authors and admins delete a comment.
+// The default delete handler does the rest, replies are deleted with their comment.
+var DeleteCommentHandler rmTypes.ApiFunction = func(a *rmTypes.Resource, db *dbTypes.DatabaseConnection) http.HandlerFunc {
+	deleteHandler := rmHandlers.DefaultDeleteHandler(a, db)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := svrUtils.GetRequestContext(r).User
+
+		comment := commentModels.Comment{}
+		err := db.DB.WithContext(r.Context()).Where("id = ?", svrUtils.GetUrlParam("id", r)).First(&comment).Error
+		if err == nil && comment.CreatedByID != user.ID && !user.HasRole(authConstants.AdminRole) {
+			svrUtils.SendJsonResponse(w, http.StatusForbidden, nil, "Only the author can delete a comment")
+			return
+		}
+
+		deleteHandler(w, r)
+	}
+}
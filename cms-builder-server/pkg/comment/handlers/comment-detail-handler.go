@@ -0,0 +1,65 @@
+package comment
+
+import (
+	"net/http"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	commentModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/comment/models"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	rmPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
+	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
+)
+
+// CommentDetailHandler returns a comment with its replies, if the user can read the commented record.
+func CommentDetailHandler(mgr *rmPkg.ResourceManager) rmTypes.ApiFunction {
+	return func(a *rmTypes.Resource, db *dbTypes.DatabaseConnection) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			requestCtx := svrUtils.GetRequestContext(r)
+			log := requestCtx.Logger
+			user := requestCtx.User
+
+			// 1. Validate Request Method
+			err := svrUtils.ValidateRequestMethod(r, http.MethodGet)
+			if err != nil {
+				svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+				return
+			}
+
+			// 2. Check Permissions
+			if !authUtils.UserIsAllowed(a.Permissions, user.GetRoles(), authConstants.OperationRead, a.ResourceNames.Singular, log) {
+				svrUtils.SendJsonResponse(w, http.StatusForbidden, nil, "User is not allowed to access this resource")
+				return
+			}
+
+			// 3. Find Comment
+			comment := commentModels.Comment{}
+			err = db.DB.WithContext(r.Context()).Where("id = ?", svrUtils.GetUrlParam("id", r)).First(&comment).Error
+			if err != nil {
+				svrUtils.SendJsonResponse(w, http.StatusNotFound, nil, "Instance not found")
+				return
+			}
+
+			err = findCommentedRecord(r.Context(), log, mgr, db, user, comment.ResourceName, comment.ResourceId)
+			if err != nil {
+				sendRecordError(w, err)
+				return
+			}
+
+			// 4. Nest Replies
+			replies := []commentModels.Comment{}
+			err = db.DB.WithContext(r.Context()).
+				Where("resource_name = ? AND resource_id = ? AND parent_id IS NOT NULL", comment.ResourceName, comment.ResourceId).
+				Order("id asc").
+				Find(&replies).Error
+			if err != nil {
+				svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error finding comments")
+				return
+			}
+
+			thread := buildThreads([]commentModels.Comment{comment}, replies)
+			svrUtils.SendJsonResponse(w, http.StatusOK, thread[0], a.ResourceNames.Singular+" Detail")
+		}
+	}
+}
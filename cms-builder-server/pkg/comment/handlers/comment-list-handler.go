@@ -0,0 +1,93 @@
+package comment
+
+import (
+	"net/http"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	commentModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/comment/models"
+	dbQueries "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/queries"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	rmPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
+	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
+)
+
+// ListCommentsHandler lists the threads of a record, taking the same resource_name and
+// resource_id parameters as the database timeline. Threads are paginated, replies are nested.
+func ListCommentsHandler(mgr *rmPkg.ResourceManager) rmTypes.ApiFunction {
+	return func(a *rmTypes.Resource, db *dbTypes.DatabaseConnection) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			requestCtx := svrUtils.GetRequestContext(r)
+			log := requestCtx.Logger
+			user := requestCtx.User
+
+			// 1. Validate Request Method
+			err := svrUtils.ValidateRequestMethod(r, http.MethodGet)
+			if err != nil {
+				svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+				return
+			}
+
+			// 2. Check Permissions
+			if !authUtils.UserIsAllowed(a.Permissions, user.GetRoles(), authConstants.OperationRead, a.ResourceNames.Singular, log) {
+				svrUtils.SendJsonResponse(w, http.StatusForbidden, nil, "User is not allowed to access this resource")
+				return
+			}
+
+			// 3. Parse Query Parameters
+			queryParams, err := svrUtils.GetRequestQueryParams(r)
+			if err != nil {
+				svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, err.Error())
+				return
+			}
+
+			resourceName := queryParams.Query["resource_name"]
+			resourceId := queryParams.Query["resource_id"]
+
+			// 4. Verify Commented Record
+			err = findCommentedRecord(r.Context(), log, mgr, db, user, resourceName, resourceId)
+			if err != nil {
+				sendRecordError(w, err)
+				return
+			}
+
+			// 5. Find Threads
+			filters := map[string]interface{}{
+				"resource_name": resourceName,
+				"resource_id":   resourceId,
+				"parent_id":     nil,
+			}
+
+			if resolved := queryParams.Query["resolved"]; resolved != "" {
+				filters["resolved"] = resolved == "true"
+			}
+
+			threads := []commentModels.Comment{}
+			pagination := &dbTypes.Pagination{
+				Total: 0,
+				Page:  queryParams.Page,
+				Limit: queryParams.Limit,
+			}
+
+			err = dbQueries.FindMany(r.Context(), log, db, &threads, pagination, "id asc", filters, []string{})
+			if err != nil {
+				svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error finding comments")
+				return
+			}
+
+			// 6. Nest Replies
+			replies := []commentModels.Comment{}
+			err = db.DB.WithContext(r.Context()).
+				Where("resource_name = ? AND resource_id = ? AND parent_id IS NOT NULL", resourceName, resourceId).
+				Order("id asc").
+				Find(&replies).Error
+			if err != nil {
+				svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error finding comments")
+				return
+			}
+
+			svrUtils.SendJsonResponseWithPagination(w, http.StatusOK, buildThreads(threads, replies), a.ResourceNames.Plural+" List", pagination)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package comment_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	commentModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/comment/models"
+	dbHandlers "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/handlers"
+	dbModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/models"
+	dbResources "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/resources"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	testPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/testing"
+)
+
+func TestListCommentsHandler(t *testing.T) {
+	bed := setupCommentTestBed(t)
+
+	record := testPkg.CreateMockResourceInstance(bed.VisitorUser.ID)
+	assert.NoError(t, bed.Db.DB.Create(record).Error)
+	target := fmt.Sprintf(`"resourceName": "MockStruct", "resourceId": "%d"`, record.ID)
+
+	_, first := bed.send(t, http.MethodPost, "/api/comments/new", fmt.Sprintf(`{%s, "body": "First"}`, target), bed.AdminUser)
+	_, reply := bed.send(t, http.MethodPost, "/api/comments/new", fmt.Sprintf(`{%s, "parentId": %d, "body": "Reply"}`, target, first.ID), bed.VisitorUser)
+	bed.send(t, http.MethodPost, "/api/comments/new", fmt.Sprintf(`{%s, "parentId": %d, "body": "Nested"}`, target, reply.ID), bed.AdminUser)
+	_, second := bed.send(t, http.MethodPost, "/api/comments/new", fmt.Sprintf(`{%s, "body": "Second"}`, target), bed.VisitorUser)
+
+	list := func(query string) (*httptest.ResponseRecorder, []commentModels.Comment) {
+		req := testPkg.CreateTestRequest(t, http.MethodGet, "/api/comments?"+query, "", true, bed.VisitorUser, bed.Logger)
+		rr := httptest.NewRecorder()
+		bed.Router.ServeHTTP(rr, req)
+
+		response := struct {
+			Data []commentModels.Comment `json:"data"`
+		}{}
+		if rr.Code == http.StatusOK {
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		}
+		return rr, response.Data
+	}
+
+	t.Run("Threads nest their replies", func(t *testing.T) {
+		rr, threads := list(fmt.Sprintf("resource_name=MockStruct&resource_id=%d", record.ID))
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.Len(t, threads, 2)
+		assert.Equal(t, first.ID, threads[0].ID)
+		assert.Equal(t, second.ID, threads[1].ID)
+		assert.Len(t, threads[0].Replies, 1)
+		assert.Equal(t, "Nested", threads[0].Replies[0].Replies[0].Body)
+	})
+
+	t.Run("Threads can be filtered by state", func(t *testing.T) {
+		rr, _ := bed.send(t, http.MethodPut, fmt.Sprintf("/api/comments/%d/resolve", second.ID), "", bed.VisitorUser)
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		_, threads := list(fmt.Sprintf("resource_name=MockStruct&resource_id=%d&resolved=false", record.ID))
+		assert.Len(t, threads, 1)
+		assert.Equal(t, first.ID, threads[0].ID)
+	})
+
+	t.Run("A record is required", func(t *testing.T) {
+		rr, _ := list("")
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Comments are only reachable through their record", func(t *testing.T) {
+		for _, path := range []string{"/api/comments/aggregate?group_by=body", fmt.Sprintf("/api/comments/%d/references", first.ID)} {
+			req := testPkg.CreateTestRequest(t, http.MethodGet, path, "", true, bed.VisitorUser, bed.Logger)
+			rr := httptest.NewRecorder()
+			bed.Router.ServeHTTP(rr, req)
+			assert.Equal(t, http.StatusNotFound, rr.Code, path)
+		}
+
+		activity := func(query string) (*httptest.ResponseRecorder, []dbModels.DatabaseLog) {
+			req := testPkg.CreateTestRequest(t, http.MethodGet, "/api/activity"+query, "", true, bed.VisitorUser, bed.Logger)
+			rr := testPkg.ExecuteHandler(t, dbHandlers.ActivityHandler(bed.Mgr, bed.Db), req)
+
+			response := struct {
+				Data []dbModels.DatabaseLog `json:"data"`
+			}{}
+			if rr.Code == http.StatusOK {
+				assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+			}
+			return rr, response.Data
+		}
+
+		rr, _ := activity("?resource_name=Comment")
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+
+		rr, entries := activity("?limit=100")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		for _, entry := range entries {
+			assert.NotEqual(t, "Comment", entry.ResourceName, "Log entries hold the comment bodies")
+		}
+	})
+
+	t.Run("Comments are listed in the record timeline", func(t *testing.T) {
+		_, err := bed.Mgr.AddResource(dbResources.SetupDBLoggerResource(bed.Mgr, bed.Db, bed.Logger))
+		assert.NoError(t, err)
+
+		bed.Db.DB.Create(&dbModels.DatabaseLog{
+			Action:       dbTypes.UpdateCRUDAction,
+			ResourceName: "MockStruct",
+			ResourceId:   fmt.Sprint(record.ID),
+		})
+
+		path := fmt.Sprintf("/api/database-timeline?resource_name=MockStruct&resource_id=%d&limit=100", record.ID)
+		req := testPkg.CreateTestRequest(t, http.MethodGet, path, "", true, bed.AdminUser, bed.Logger)
+		rr := testPkg.ExecuteHandler(t, dbHandlers.TimelineHandler(bed.Mgr, bed.Db), req)
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		response := struct {
+			Data []dbModels.DatabaseLog `json:"data"`
+		}{}
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+
+		commented := map[string]bool{}
+		for _, entry := range response.Data {
+			commented[entry.ResourceName+":"+entry.ResourceId] = true
+		}
+		assert.True(t, commented[fmt.Sprintf("MockStruct:%d", record.ID)])
+		for _, comment := range []commentModels.Comment{first, reply, second} {
+			assert.True(t, commented[fmt.Sprintf("Comment:%d", comment.ID)], comment.Body)
+		}
+	})
+}
@@ -0,0 +1,95 @@
+package comment
+
+import (
+	"net/http"
+	"time"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	commentModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/comment/models"
+	dbQueries "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/queries"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	rmPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager"
+	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
+	"github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/utils"
+)
+
+// ResolveCommentHandler resolves or reopens the thread started by a comment.
+// Anyone allowed to comment on the record can change the state of its threads.
+func ResolveCommentHandler(mgr *rmPkg.ResourceManager, db *dbTypes.DatabaseConnection, resolved bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestCtx := svrUtils.GetRequestContext(r)
+		log := requestCtx.Logger
+		user := requestCtx.User
+		requestId := requestCtx.RequestId
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodPut)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		// 2. Check Permissions
+		a, err := mgr.GetResource(commentModels.Comment{})
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, err.Error())
+			return
+		}
+
+		if !authUtils.UserIsAllowed(a.Permissions, user.GetRoles(), authConstants.OperationUpdate, a.ResourceNames.Singular, log) {
+			svrUtils.SendJsonResponse(w, http.StatusForbidden, nil, "User is not allowed to update this resource")
+			return
+		}
+
+		// 3. Find Thread
+		comment := commentModels.Comment{}
+		err = db.DB.WithContext(r.Context()).Where("id = ?", svrUtils.GetUrlParam("id", r)).First(&comment).Error
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusNotFound, nil, "Instance not found")
+			return
+		}
+
+		err = findCommentedRecord(r.Context(), log, mgr, db, user, comment.ResourceName, comment.ResourceId)
+		if err != nil {
+			sendRecordError(w, err)
+			return
+		}
+
+		if comment.ParentID != nil {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, "Only the first comment of a thread can be resolved")
+			return
+		}
+
+		if comment.Resolved == resolved {
+			svrUtils.SendJsonResponse(w, http.StatusOK, &comment, a.ResourceNames.Singular+" is up to date")
+			return
+		}
+
+		// 4. Update State
+		previousState := comment
+		comment.Resolved = resolved
+		comment.ResolvedAt = nil
+		comment.ResolvedByID = nil
+		comment.UpdatedByID = user.ID
+		if resolved {
+			now := time.Now()
+			comment.ResolvedAt = &now
+			comment.ResolvedByID = &user.ID
+		}
+
+		differences := utils.CompareInterfaces(previousState, comment)
+		err = dbQueries.Update(r.Context(), log, db, &comment, user, differences, requestId)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error updating comment")
+			return
+		}
+
+		msg := "Thread reopened"
+		if resolved {
+			msg = "Thread resolved"
+		}
+
+		svrUtils.SendJsonResponse(w, http.StatusOK, &comment, msg)
+	}
+}
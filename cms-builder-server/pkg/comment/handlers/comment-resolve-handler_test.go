@@ -0,0 +1,67 @@
+package comment_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	dbModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/models"
+	testPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/testing"
+)
+
+func TestResolveCommentHandler(t *testing.T) {
+	bed := setupCommentTestBed(t)
+
+	record := testPkg.CreateMockResourceInstance(bed.VisitorUser.ID)
+	assert.NoError(t, bed.Db.DB.Create(record).Error)
+	target := fmt.Sprintf(`"resourceName": "MockStruct", "resourceId": "%d"`, record.ID)
+
+	_, thread := bed.send(t, http.MethodPost, "/api/comments/new", fmt.Sprintf(`{%s, "body": "Typo in the title"}`, target), bed.VisitorUser)
+	_, reply := bed.send(t, http.MethodPost, "/api/comments/new", fmt.Sprintf(`{%s, "parentId": %d, "body": "Fixed"}`, target, thread.ID), bed.AdminUser)
+
+	t.Run("Resolve and reopen a thread", func(t *testing.T) {
+		rr, comment := bed.send(t, http.MethodPut, fmt.Sprintf("/api/comments/%d/resolve", thread.ID), "", bed.AdminUser)
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.True(t, comment.Resolved)
+		assert.Equal(t, bed.AdminUser.ID, *comment.ResolvedByID)
+		assert.NotNil(t, comment.ResolvedAt)
+
+		logs := []dbModels.DatabaseLog{}
+		bed.Db.DB.Where("resource_name = ? AND resource_id = ? AND action = ?", "Comment", fmt.Sprint(thread.ID), "updated").Find(&logs)
+		assert.Len(t, logs, 1, "State changes are audited")
+
+		rr, comment = bed.send(t, http.MethodPut, fmt.Sprintf("/api/comments/%d/unresolve", thread.ID), "", bed.VisitorUser)
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.False(t, comment.Resolved)
+		assert.Nil(t, comment.ResolvedByID)
+	})
+
+	t.Run("Replies cannot be resolved", func(t *testing.T) {
+		rr, _ := bed.send(t, http.MethodPut, fmt.Sprintf("/api/comments/%d/resolve", reply.ID), "", bed.AdminUser)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Only the author edits, authors and admins delete", func(t *testing.T) {
+		rr, _ := bed.send(t, http.MethodPut, fmt.Sprintf("/api/comments/%d/update", reply.ID), `{"body": "Changed"}`, bed.VisitorUser)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+
+		rr, comment := bed.send(t, http.MethodPut, fmt.Sprintf("/api/comments/%d/update", thread.ID), fmt.Sprintf(`{"body": "Typo, right @%s?"}`, bed.AdminUser.Email), bed.VisitorUser)
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.Equal(t, strings.ToLower(bed.AdminUser.Email), comment.Mentions)
+		assert.Len(t, bed.Notifier.Notified, 1, "Newly mentioned users are notified")
+		assert.Equal(t, []uint{bed.AdminUser.ID}, bed.Notifier.Notified[0].UserIDs)
+
+		rr, _ = bed.send(t, http.MethodDelete, fmt.Sprintf("/api/comments/%d/delete", reply.ID), "", bed.VisitorUser)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+
+		rr, _ = bed.send(t, http.MethodDelete, fmt.Sprintf("/api/comments/%d/delete", thread.ID), "", bed.VisitorUser)
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		var count int64
+		bed.Db.DB.Table("comments").Where("id = ? AND deleted_at IS NULL", reply.ID).Count(&count)
+		assert.Equal(t, int64(0), count, "Replies are deleted with their comment")
+	})
+}
@@ -0,0 +1,104 @@
+package comment
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	commentModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/comment/models"
+	dbQueries "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/queries"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	notificationTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/notification/types"
+	rmPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
+	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
+	"github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/utils"
+)
+
+// UpdateCommentHandler lets authors edit the body of their comments.
+// Users mentioned for the first time are notified, if they can read the commented record.
+func UpdateCommentHandler(mgr *rmPkg.ResourceManager, notifier notificationTypes.Notifier) rmTypes.ApiFunction {
+	return func(a *rmTypes.Resource, db *dbTypes.DatabaseConnection) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			requestCtx := svrUtils.GetRequestContext(r)
+			log := requestCtx.Logger
+			user := requestCtx.User
+			requestId := requestCtx.RequestId
+
+			// 1. Validate Request Method
+			err := svrUtils.ValidateRequestMethod(r, http.MethodPut)
+			if err != nil {
+				svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+				return
+			}
+
+			// 2. Check Permissions
+			if !authUtils.UserIsAllowed(a.Permissions, user.GetRoles(), authConstants.OperationUpdate, a.ResourceNames.Singular, log) {
+				svrUtils.SendJsonResponse(w, http.StatusForbidden, nil, "User is not allowed to update this resource")
+				return
+			}
+
+			// 3. Find Comment (Authors only)
+			comment := commentModels.Comment{}
+			err = db.DB.WithContext(r.Context()).Where("id = ?", svrUtils.GetUrlParam("id", r)).First(&comment).Error
+			if err != nil {
+				svrUtils.SendJsonResponse(w, http.StatusNotFound, nil, "Instance not found")
+				return
+			}
+
+			if comment.CreatedByID != user.ID {
+				svrUtils.SendJsonResponse(w, http.StatusForbidden, nil, "Only the author can edit a comment")
+				return
+			}
+
+			// 4. Parse Request Body
+			bodyBytes, err := svrUtils.ReadRequestBody(r)
+			if err != nil {
+				svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, "Invalid request body")
+				return
+			}
+
+			input := CommentInput{}
+			err = json.Unmarshal(bodyBytes, &input)
+			if err != nil || strings.TrimSpace(input.Body) == "" {
+				svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, "Comment body is required")
+				return
+			}
+
+			// 5. Find Mentions
+			mentions := parseMentions(input.Body)
+			mentioned, err := findMentionedUsers(r.Context(), db, mentions)
+			if err != nil {
+				log.Error().Err(err).Msg("Error finding mentioned users")
+				svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error updating comment")
+				return
+			}
+			mentioned = filterRecordReaders(r.Context(), log, mgr, db, mentioned, comment.ResourceName, comment.ResourceId)
+
+			previousState := comment
+			comment.Body = input.Body
+			comment.Mentions = strings.Join(mentions, ",")
+			comment.UpdatedByID = user.ID
+
+			differences := utils.CompareInterfaces(previousState, comment)
+			if diffMap, ok := differences.(map[string]interface{}); ok && len(diffMap) == 0 {
+				svrUtils.SendJsonResponse(w, http.StatusOK, &comment, a.ResourceNames.Singular+" is up to date")
+				return
+			}
+
+			// 6. Update Comment
+			err = dbQueries.Update(r.Context(), log, db, &comment, user, differences, requestId)
+			if err != nil {
+				svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error updating comment")
+				return
+			}
+
+			// 7. Notify Newly Mentioned Users
+			notifyMentions(r.Context(), log, notifier, user, &comment, mentioned, previousState.Mentions)
+
+			svrUtils.SendJsonResponse(w, http.StatusOK, &comment, a.ResourceNames.Singular+" updated")
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package comment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	commentModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/comment/models"
+	dbQueries "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/queries"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	loggerTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/logger/types"
+	notificationConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/notification/constants"
+	notificationTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/notification/types"
+	rmPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager"
+	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
+)
+
+var (
+	ErrUnknownResource = errors.New("comments are not available for this resource")
+	ErrRecordNotFound  = errors.New("commented record not found")
+	ErrRecordForbidden = errors.New("user is not allowed to read the commented record")
+	ErrInvalidParent   = errors.New("replies must belong to a comment of the same record")
+)
+
+// mentionPattern matches mentions written as @ followed by the email of a user.
+var mentionPattern = regexp.MustCompile(`@([A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,})`)
+
+// findCommentedRecord ensures the given record exists and the user can read it.
+// User binding of the commented resource applies, so users only comment on records they can see.
+func findCommentedRecord(ctx context.Context, log *loggerTypes.Logger, mgr *rmPkg.ResourceManager, db *dbTypes.DatabaseConnection, user *authModels.User, resourceName string, resourceId string) error {
+	if resourceName == "" || resourceId == "" || resourceName == "Comment" {
+		return ErrUnknownResource
+	}
+
+	a, err := mgr.GetResourceByName(resourceName)
+	if err != nil {
+		return ErrUnknownResource
+	}
+
+	if !authUtils.UserIsAllowed(a.Permissions, user.GetRoles(), authConstants.OperationRead, a.ResourceNames.Singular, log) {
+		return ErrRecordForbidden
+	}
+
+	filters := map[string]interface{}{
+		"id": resourceId,
+	}
+
+	if !(a.SkipUserBinding || user.HasRole(authConstants.AdminRole)) {
+		filters["created_by_id"] = user.ID
+	}
+
+	instance := a.GetOne()
+	err = dbQueries.FindOne(ctx, log, db, instance, filters, []string{})
+	if err != nil {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// sendRecordError answers with the status matching an error of findCommentedRecord.
+func sendRecordError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrRecordForbidden):
+		svrUtils.SendJsonResponse(w, http.StatusForbidden, nil, err.Error())
+	case errors.Is(err, ErrRecordNotFound):
+		svrUtils.SendJsonResponse(w, http.StatusNotFound, nil, err.Error())
+	default:
+		svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, err.Error())
+	}
+}
+
+// parseMentions returns the emails mentioned in the given comment body, lower cased and without duplicates.
+// They are stored as written in Comment.Mentions, whether a user has them or not, so comments
+// don't tell which emails belong to users.
+func parseMentions(body string) []string {
+	emails := []string{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		email := strings.ToLower(match[1])
+		if !slices.Contains(emails, email) {
+			emails = append(emails, email)
+		}
+	}
+	return emails
+}
+
+// findMentionedUsers returns the users having one of the given emails.
+func findMentionedUsers(ctx context.Context, db *dbTypes.DatabaseConnection, emails []string) ([]authModels.User, error) {
+	users := []authModels.User{}
+	if len(emails) == 0 {
+		return users, nil
+	}
+
+	err := db.DB.WithContext(ctx).Where("LOWER(email) IN ?", emails).Order("id").Find(&users).Error
+	return users, err
+}
+
+// filterRecordReaders leaves out the users who can't read the commented record, so mentions
+// don't disclose it to anyone else.
+func filterRecordReaders(ctx context.Context, log *loggerTypes.Logger, mgr *rmPkg.ResourceManager, db *dbTypes.DatabaseConnection, users []authModels.User, resourceName string, resourceId string) []authModels.User {
+	readers := []authModels.User{}
+	for i := range users {
+		user := &users[i]
+		authUtils.LoadUserRoles(ctx, db, user, log)
+
+		if findCommentedRecord(ctx, log, mgr, db, user, resourceName, resourceId) == nil {
+			readers = append(readers, *user)
+		}
+	}
+	return readers
+}
+
+// notifyMentions adds a notification to the inbox of the mentioned users, leaving out the author
+// and the emails in skip, as stored in Comment.Mentions. The inbox emails the users who opted in.
+// Failures are logged, a comment is never lost because a notification could not be delivered.
+func notifyMentions(ctx context.Context, log *loggerTypes.Logger, notifier notificationTypes.Notifier, author *authModels.User, comment *commentModels.Comment, users []authModels.User, skip string) {
+	if notifier == nil {
+		return
+	}
+
+	skipped := strings.Split(skip, ",")
+	ids := []uint{}
+	for _, user := range users {
+		if user.ID == author.ID || slices.Contains(skipped, strings.ToLower(user.Email)) {
+			continue
+		}
+		ids = append(ids, user.ID)
+	}
+
+	if len(ids) == 0 {
+		return
+	}
+
+	err := notifier.Notify(ctx, notificationTypes.NotificationInput{
+		UserIDs:      ids,
+		Kind:         notificationConstants.KindMentioned,
+		Title:        fmt.Sprintf("%s mentioned you on %s %s", displayName(author), comment.ResourceName, comment.ResourceId),
+		Body:         comment.Body,
+		ResourceName: comment.ResourceName,
+		ResourceId:   comment.ResourceId,
+	})
+	if err != nil {
+		log.Error().Err(err).Uint("commentId", comment.ID).Msg("Error notifying mentioned users")
+	}
+}
+
+func displayName(user *authModels.User) string {
+	name := strings.TrimSpace(user.FirstName + " " + user.LastName)
+	if name == "" {
+		return user.Email
+	}
+	return name
+}
+
+// buildThreads nests the given replies under the given comments, at any depth.
+func buildThreads(comments []commentModels.Comment, replies []commentModels.Comment) []commentModels.Comment {
+	children := map[uint][]commentModels.Comment{}
+	for _, reply := range replies {
+		if reply.ParentID != nil {
+			children[*reply.ParentID] = append(children[*reply.ParentID], reply)
+		}
+	}
+
+	var nest func(items []commentModels.Comment, depth int) []commentModels.Comment
+	nest = func(items []commentModels.Comment, depth int) []commentModels.Comment {
+		for i := range items {
+			if depth < len(replies) {
+				items[i].Replies = nest(children[items[i].ID], depth+1)
+			}
+		}
+		return items
+	}
+
+	return nest(comments, 0)
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+)
+
+// Comment is a message attached to a record of any resource.
+// Replies point to the comment they answer, and threads are resolved on their first comment.
+type Comment struct {
+	authModels.SystemData
+	ResourceName string     `gorm:"index:idx_comment_record" json:"resourceName"`
+	ResourceId   string     `gorm:"index:idx_comment_record" json:"resourceId"`
+	ParentID     *uint      `json:"parentId"`
+	Body         string     `json:"body"`
+	Mentions     string     `json:"mentions"` // comma-separated emails mentioned in the body
+	Resolved     bool       `json:"resolved"`
+	ResolvedAt   *time.Time `json:"resolvedAt"`
+	ResolvedByID *uint      `json:"resolvedById"`
+	Replies      []Comment  `gorm:"-" json:"replies,omitempty"`
+}
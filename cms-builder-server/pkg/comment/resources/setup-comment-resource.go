@@ -0,0 +1,74 @@
+package comment
+
+import (
+	"net/http"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	commentHandlers "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/comment/handlers"
+	commentModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/comment/models"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	loggerTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/logger/types"
+	notificationTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/notification/types"
+	rmPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager"
+	rmHandlers "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/handlers"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
+	svrTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/types"
+)
+
+func SetupCommentResource(resourceManager *rmPkg.ResourceManager, db *dbTypes.DatabaseConnection, notifier notificationTypes.Notifier, log *loggerTypes.Logger) *rmTypes.ResourceConfig {
+
+	log.Info().Msg("Initializing Comment resource")
+
+	skipUserBinding := true // Comments are shared by everyone who can read the commented record
+
+	permissions := authTypes.RolePermissionMap{
+		authConstants.AdminRole:   authConstants.AllAllowedAccess,
+		authConstants.VisitorRole: authConstants.AllAllowedAccess,
+	}
+
+	handlers := &rmTypes.ApiHandlers{
+		List:   commentHandlers.ListCommentsHandler(resourceManager),
+		Detail: commentHandlers.CommentDetailHandler(resourceManager),
+		Create: commentHandlers.CreateCommentHandler(resourceManager, notifier),
+		Update: commentHandlers.UpdateCommentHandler(resourceManager, notifier),
+		Delete: commentHandlers.DeleteCommentHandler,
+
+		// Readers are decided per commented record, the default handlers would list every comment
+		Aggregate:  rmHandlers.DisabledHandler,
+		References: rmHandlers.DisabledHandler,
+		Lock:       rmHandlers.DisabledHandler,
+		Unlock:     rmHandlers.DisabledHandler,
+	}
+
+	routes := []svrTypes.Route{
+		{
+			Path:         "/api/comments/{id}/resolve",
+			Handler:      commentHandlers.ResolveCommentHandler(resourceManager, db, true),
+			Name:         "comments-resolve",
+			RequiresAuth: true,
+			Methods:      []string{http.MethodPut},
+		},
+		{
+			Path:         "/api/comments/{id}/unresolve",
+			Handler:      commentHandlers.ResolveCommentHandler(resourceManager, db, false),
+			Name:         "comments-unresolve",
+			RequiresAuth: true,
+			Methods:      []string{http.MethodPut},
+		},
+	}
+
+	config := &rmTypes.ResourceConfig{
+		Model:           commentModels.Comment{},
+		SkipUserBinding: skipUserBinding,
+		PrivateActivity: true, // Log entries hold the comment bodies
+		Permissions:     permissions,
+		Handlers:        handlers,
+		Routes:          routes,
+		Relations: []rmTypes.RelationConfig{
+			{Field: "ParentID", Resource: "Comment", OnDelete: rmTypes.CascadeOnDelete},
+		},
+	}
+
+	return config
+}
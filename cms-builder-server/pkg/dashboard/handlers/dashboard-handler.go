@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	dbModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/models"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	loggerTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/logger/types"
+	rlModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/request-logger/models"
+	rmPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
+	schConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/scheduler/constants"
+	schModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/scheduler/models"
+	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
+)
+
+const (
+	defaultDashboardDays = 7
+	topEditorsLimit      = 5
+	recentFailuresLimit  = 5
+)
+
+type EditorSummary struct {
+	UserId   uint   `json:"userId"`
+	Username string `json:"username"`
+	Changes  int64  `json:"changes"`
+}
+
+type ResourceSummary struct {
+	Name         string          `json:"name"`
+	Plural       string          `json:"pluralName"`
+	KebabPlural  string          `json:"kebabPluralName"`
+	Total        int64           `json:"total"`
+	Recent       int64           `json:"recent"`
+	LastModified *time.Time      `json:"lastModified"`
+	TopEditors   []EditorSummary `json:"topEditors,omitempty"`
+}
+
+type SchedulerHealth struct {
+	Failures       int64                     `json:"failures"`
+	RecentFailures []schModels.SchedulerTask `json:"recentFailures"`
+}
+
+type RequestHealth struct {
+	Total        int64   `json:"total"`
+	ClientErrors int64   `json:"clientErrors"`
+	ServerErrors int64   `json:"serverErrors"`
+	ErrorRate    float64 `json:"errorRate"`
+}
+
+type Dashboard struct {
+	Since     time.Time         `json:"since"`
+	Resources []ResourceSummary `json:"resources"`
+	Scheduler *SchedulerHealth  `json:"scheduler,omitempty"`
+	Requests  *RequestHealth    `json:"requests,omitempty"`
+}
+
+// DashboardHandler summarizes every resource the user can read.
+// The optional days query parameter sets the window used for recent counts, failures and error rates.
+func DashboardHandler(mgr *rmPkg.ResourceManager, db *dbTypes.DatabaseConnection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		requestCtx := svrUtils.GetRequestContext(r)
+		log := requestCtx.Logger
+		user := requestCtx.User
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodGet)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		// 2. Parse window
+		days := defaultDashboardDays
+		if value := r.URL.Query().Get("days"); value != "" {
+			days, err = strconv.Atoi(value)
+			if err != nil || days < 1 {
+				svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, "Invalid days parameter")
+				return
+			}
+		}
+		since := time.Now().AddDate(0, 0, -days)
+
+		// 3. Check whether the user can see the audit log
+		canReadLogs := false
+		if logResource, err := mgr.GetResource(&dbModels.DatabaseLog{}); err == nil {
+			canReadLogs = authUtils.UserIsAllowed(logResource.Permissions, user.GetRoles(), authConstants.OperationRead, logResource.ResourceNames.Singular, log)
+		}
+
+		dashboard := Dashboard{
+			Since:     since,
+			Resources: []ResourceSummary{},
+		}
+
+		// 4. Summarize readable resources
+		// Resources readable per record, such as notifications, would count the records of everyone
+		isAdmin := user.HasRole(authConstants.AdminRole)
+		for _, a := range mgr.Resources {
+			if a.PrivateActivity && !isAdmin {
+				continue
+			}
+
+			if !authUtils.UserIsAllowed(a.Permissions, user.GetRoles(), authConstants.OperationRead, a.ResourceNames.Singular, log) {
+				continue
+			}
+
+			summary, err := summarizeResource(r, a, db, user, since, canReadLogs)
+			if err != nil {
+				log.Error().Err(err).Str("resource", a.ResourceNames.Singular).Msg("Error summarizing resource")
+				svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error building dashboard")
+				return
+			}
+
+			dashboard.Resources = append(dashboard.Resources, *summary)
+		}
+
+		sort.Slice(dashboard.Resources, func(i, j int) bool {
+			return dashboard.Resources[i].Name < dashboard.Resources[j].Name
+		})
+
+		// 5. Scheduler health
+		if a, err := mgr.GetResource(&schModels.SchedulerTask{}); err == nil && isAllowedToRead(a, user, log) {
+			health, err := getSchedulerHealth(r, a, db, since)
+			if err != nil {
+				log.Error().Err(err).Msg("Error getting scheduler health")
+				svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error building dashboard")
+				return
+			}
+			dashboard.Scheduler = health
+		}
+
+		// 6. Request error rates
+		if a, err := mgr.GetResource(&rlModels.RequestLog{}); err == nil && isAllowedToRead(a, user, log) {
+			health, err := getRequestHealth(r, a, db, since)
+			if err != nil {
+				log.Error().Err(err).Msg("Error getting request health")
+				svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error building dashboard")
+				return
+			}
+			dashboard.Requests = health
+		}
+
+		svrUtils.SendJsonResponse(w, http.StatusOK, dashboard, "dashboard")
+	}
+}
+
+func isAllowedToRead(a *rmTypes.Resource, user *authModels.User, log *loggerTypes.Logger) bool {
+	return authUtils.UserIsAllowed(a.Permissions, user.GetRoles(), authConstants.OperationRead, a.ResourceNames.Singular, log)
+}
+
+func summarizeResource(r *http.Request, a *rmTypes.Resource, db *dbTypes.DatabaseConnection, user *authModels.User, since time.Time, canReadLogs bool) (*ResourceSummary, error) {
+	summary := &ResourceSummary{
+		Name:        a.ResourceNames.Singular,
+		Plural:      a.ResourceNames.Plural,
+		KebabPlural: a.ResourceNames.KebabPlural,
+	}
+
+	query := db.DB.WithContext(r.Context()).Model(a.Model)
+
+	// Apply default user binding filter (unless skipped or user is Admin)
+	if !(a.SkipUserBinding || user.HasRole(authConstants.AdminRole)) {
+		query = query.Where("created_by_id = ?", user.ID)
+	}
+
+	if err := query.Session(&gorm.Session{}).Count(&summary.Total).Error; err != nil {
+		return nil, err
+	}
+
+	if a.HasField("CreatedAt") {
+		err := query.Session(&gorm.Session{}).Where("created_at > ?", since).Count(&summary.Recent).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if a.HasField("UpdatedAt") {
+		var updates []time.Time
+		err := query.Session(&gorm.Session{}).Order("updated_at desc").Limit(1).Pluck("updated_at", &updates).Error
+		if err != nil {
+			return nil, err
+		}
+		if len(updates) > 0 {
+			summary.LastModified = &updates[0]
+		}
+	}
+
+	if canReadLogs {
+		summary.TopEditors = []EditorSummary{}
+		err := db.DB.WithContext(r.Context()).Model(&dbModels.DatabaseLog{}).
+			Select("user_id, username, COUNT(*) as changes").
+			Where("resource_name = ? AND created_at > ?", a.ResourceNames.Singular, since).
+			Group("user_id, username").
+			Order("changes desc").
+			Limit(topEditorsLimit).
+			Scan(&summary.TopEditors).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return summary, nil
+}
+
+func getSchedulerHealth(r *http.Request, a *rmTypes.Resource, db *dbTypes.DatabaseConnection, since time.Time) (*SchedulerHealth, error) {
+	health := &SchedulerHealth{
+		RecentFailures: []schModels.SchedulerTask{},
+	}
+
+	query := db.DB.WithContext(r.Context()).Model(a.Model).
+		Where("status = ? AND created_at > ?", schConstants.TaskStatusFailed, since)
+
+	if err := query.Session(&gorm.Session{}).Count(&health.Failures).Error; err != nil {
+		return nil, err
+	}
+
+	err := query.Session(&gorm.Session{}).Order("created_at desc").Limit(recentFailuresLimit).Find(&health.RecentFailures).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return health, nil
+}
+
+func getRequestHealth(r *http.Request, a *rmTypes.Resource, db *dbTypes.DatabaseConnection, since time.Time) (*RequestHealth, error) {
+	health := &RequestHealth{}
+
+	query := db.DB.WithContext(r.Context()).Model(a.Model).Where("timestamp > ?", since)
+
+	if err := query.Session(&gorm.Session{}).Count(&health.Total).Error; err != nil {
+		return nil, err
+	}
+
+	// Status codes are stored as three digit strings, so they compare lexicographically
+	err := query.Session(&gorm.Session{}).Where("status_code >= ? AND status_code < ?", "400", "500").Count(&health.ClientErrors).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = query.Session(&gorm.Session{}).Where("status_code >= ?", "500").Count(&health.ServerErrors).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if health.Total > 0 {
+		health.ErrorRate = float64(health.ClientErrors+health.ServerErrors) / float64(health.Total)
+	}
+
+	return health, nil
+}
@@ -0,0 +1,170 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	dashboardHandlers "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/dashboard/handlers"
+	dbModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/models"
+	dbResources "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/resources"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	notificationModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/notification/models"
+	notificationResources "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/notification/resources"
+	rlModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/request-logger/models"
+	rlResources "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/request-logger/resources"
+	schConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/scheduler/constants"
+	schModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/scheduler/models"
+	schResources "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/scheduler/resources"
+	testPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/testing"
+)
+
+type dashboardResponse struct {
+	Data dashboardHandlers.Dashboard `json:"data"`
+}
+
+func setupDashboardTestBed(t *testing.T) testPkg.TestUtils {
+	bed := testPkg.SetupHandlerTestBed()
+
+	_, err := bed.Mgr.AddResource(dbResources.SetupDBLoggerResource(bed.Mgr, bed.Db, bed.Logger))
+	assert.NoError(t, err)
+
+	_, err = bed.Mgr.AddResource(rlResources.SetupRequestLoggerResource(bed.Mgr, bed.Db, bed.Logger))
+	assert.NoError(t, err)
+
+	_, err = bed.Mgr.AddResource(schResources.SetupSchedulerTaskResource())
+	assert.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		instance := testPkg.CreateMockResourceInstance(bed.VisitorUser.ID)
+		bed.Db.DB.Create(&instance)
+	}
+
+	for i := 0; i < 2; i++ {
+		instance := testPkg.CreateMockResourceInstance(bed.AdminUser.ID)
+		bed.Db.DB.Create(&instance)
+
+		bed.Db.DB.Create(&dbModels.DatabaseLog{
+			UserId:       bed.AdminUser.ID,
+			Username:     bed.AdminUser.Email,
+			Action:       dbTypes.CreateCRUDAction,
+			ResourceName: bed.Src.ResourceNames.Singular,
+			Timestamp:    time.Now().Format(time.RFC3339Nano),
+		})
+	}
+
+	bed.Db.DB.Create(&rlModels.RequestLog{Timestamp: time.Now(), StatusCode: "200"})
+	bed.Db.DB.Create(&rlModels.RequestLog{Timestamp: time.Now(), StatusCode: "404"})
+	bed.Db.DB.Create(&rlModels.RequestLog{Timestamp: time.Now(), StatusCode: "500"})
+
+	bed.Db.DB.Create(&schModels.SchedulerTask{
+		SystemData:        &authModels.SystemData{CreatedByID: bed.AdminUser.ID, UpdatedByID: bed.AdminUser.ID},
+		JobDefinitionName: "failing-job",
+		Status:            schConstants.TaskStatusFailed,
+		Error:             "boom",
+	})
+
+	return bed
+}
+
+func getDashboard(t *testing.T, bed testPkg.TestUtils, method string, user *authModels.User, query string) (*httptest.ResponseRecorder, *dashboardHandlers.Dashboard) {
+	req := testPkg.CreateTestRequest(t, method, "/api/dashboard"+query, "", true, user, bed.Logger)
+	rr := testPkg.ExecuteHandler(t, dashboardHandlers.DashboardHandler(bed.Mgr, bed.Db), req)
+
+	response := dashboardResponse{}
+	if rr.Code == http.StatusOK {
+		err := json.Unmarshal(rr.Body.Bytes(), &response)
+		assert.NoError(t, err)
+	}
+
+	return rr, &response.Data
+}
+
+func findSummary(dashboard *dashboardHandlers.Dashboard, name string) *dashboardHandlers.ResourceSummary {
+	for _, summary := range dashboard.Resources {
+		if summary.Name == name {
+			return &summary
+		}
+	}
+	return nil
+}
+
+func TestDashboardHandler_Admin(t *testing.T) {
+	bed := setupDashboardTestBed(t)
+
+	rr, dashboard := getDashboard(t, bed, http.MethodGet, bed.AdminUser, "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	summary := findSummary(dashboard, bed.Src.ResourceNames.Singular)
+	assert.NotNil(t, summary)
+	assert.GreaterOrEqual(t, summary.Total, int64(5))
+	assert.GreaterOrEqual(t, summary.Recent, int64(5))
+	assert.NotNil(t, summary.LastModified)
+	assert.NotEmpty(t, summary.TopEditors)
+
+	assert.NotNil(t, findSummary(dashboard, "DatabaseLog"))
+
+	assert.NotNil(t, dashboard.Scheduler)
+	assert.GreaterOrEqual(t, dashboard.Scheduler.Failures, int64(1))
+	assert.NotEmpty(t, dashboard.Scheduler.RecentFailures)
+
+	assert.NotNil(t, dashboard.Requests)
+	assert.GreaterOrEqual(t, dashboard.Requests.ClientErrors, int64(1))
+	assert.GreaterOrEqual(t, dashboard.Requests.ServerErrors, int64(1))
+	assert.Greater(t, dashboard.Requests.ErrorRate, float64(0))
+}
+
+func TestDashboardHandler_Visitor(t *testing.T) {
+	bed := setupDashboardTestBed(t)
+
+	rr, dashboard := getDashboard(t, bed, http.MethodGet, bed.VisitorUser, "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	// Visitors only see the resources they can read, bound to their own records
+	assert.Len(t, dashboard.Resources, 1)
+	summary := findSummary(dashboard, bed.Src.ResourceNames.Singular)
+	assert.NotNil(t, summary)
+	assert.Equal(t, int64(3), summary.Total)
+	assert.Nil(t, summary.TopEditors)
+
+	assert.Nil(t, dashboard.Scheduler)
+	assert.Nil(t, dashboard.Requests)
+}
+
+func TestDashboardHandler_PrivateResources(t *testing.T) {
+	bed := setupDashboardTestBed(t)
+
+	_, err := bed.Mgr.AddResource(notificationResources.SetupNotificationResource(bed.Mgr, bed.Db, bed.Logger))
+	assert.NoError(t, err)
+
+	bed.Db.DB.Create(&notificationModels.Notification{UserID: bed.AdminUser.ID, Title: "For the admin"})
+
+	// Notifications are bound to their recipient, visitors would count everyone's
+	rr, dashboard := getDashboard(t, bed, http.MethodGet, bed.VisitorUser, "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Nil(t, findSummary(dashboard, "Notification"))
+
+	rr, dashboard = getDashboard(t, bed, http.MethodGet, bed.AdminUser, "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotNil(t, findSummary(dashboard, "Notification"))
+}
+
+func TestDashboardHandler_Errors(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+
+	rr, _ := getDashboard(t, bed, http.MethodPost, bed.AdminUser, "")
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+
+	rr, _ = getDashboard(t, bed, http.MethodGet, bed.AdminUser, "?days=zero")
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "Invalid days parameter")
+
+	rr, dashboard := getDashboard(t, bed, http.MethodGet, bed.NoRoleUser, "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, dashboard.Resources)
+}
@@ -0,0 +1,20 @@
+package dashboard
+
+import (
+	"net/http"
+
+	dashboardHandlers "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/dashboard/handlers"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	rmPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager"
+	svrTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/types"
+)
+
+func SetupDashboardRoute(mgr *rmPkg.ResourceManager, db *dbTypes.DatabaseConnection) svrTypes.Route {
+	return svrTypes.Route{
+		Path:         "/api/dashboard",
+		Handler:      dashboardHandlers.DashboardHandler(mgr, db),
+		Name:         "dashboard",
+		RequiresAuth: true,
+		Methods:      []string{http.MethodGet},
+	}
+}
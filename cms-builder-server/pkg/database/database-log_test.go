@@ -93,3 +93,20 @@ func TestNewDatabaseLogEntry(t *testing.T) {
 		})
 	}
 }
+
+func TestNewDatabaseLogEntry_Impersonation(t *testing.T) {
+	admin := &authModels.User{ID: uint(1), Email: "admin@example.com"}
+	user := &authModels.User{ID: uint(2), Email: "user@example.com", ImpersonatedBy: admin}
+
+	got, err := dbPkg.NewDatabaseLogEntry(dbTypes.CreateCRUDAction, user, &TestStruct{ID: "123"}, nil, "23")
+	if err != nil {
+		t.Fatalf("NewLogHistoryEntry() error = %v", err)
+	}
+
+	if got.UserId != user.ID || got.Username != user.Email {
+		t.Errorf("NewLogHistoryEntry() user = %d %q, want the impersonated user", got.UserId, got.Username)
+	}
+	if got.ImpersonatorId != admin.ID || got.ImpersonatorLabel != admin.Email {
+		t.Errorf("NewLogHistoryEntry() impersonator = %d %q, want the admin", got.ImpersonatorId, got.ImpersonatorLabel)
+	}
+}
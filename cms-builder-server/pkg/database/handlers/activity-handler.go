@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	dbModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/models"
+	dbQueries "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/queries"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	rmPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager"
+	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
+)
+
+// ActivityHandler lists database log entries across resources, newest first.
+// The feed is narrowed with the optional user_id, trace_id, resource_name and action query parameters.
+// Only entries of resources the user can read are listed. Unless the user is an admin, entries of
+// resources bound to their creator are limited to the changes the user made, and entries of resources
+// with private activity are left out.
+func ActivityHandler(m *rmPkg.ResourceManager, db *dbTypes.DatabaseConnection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestCtx := svrUtils.GetRequestContext(r)
+		log := requestCtx.Logger
+		user := requestCtx.User
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodGet)
+		if err != nil {
+			log.Error().Err(err).Msgf("Error validating request method")
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		// 2. Parse Query Parameters
+		queryParams, err := svrUtils.GetRequestQueryParams(r)
+		if err != nil {
+			log.Error().Err(err).Msgf("Error validating query parameters")
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, err.Error())
+			return
+		}
+
+		filters := map[string]interface{}{}
+
+		if value := queryParams.Query["user_id"]; value != "" {
+			userId, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, "Invalid user_id parameter")
+				return
+			}
+			filters["user_id = ?"] = uint(userId)
+		}
+
+		if value := queryParams.Query["trace_id"]; value != "" {
+			filters["trace_id = ?"] = value
+		}
+
+		if value := queryParams.Query["action"]; value != "" {
+			filters["action = ?"] = dbTypes.CRUDAction(value)
+		}
+
+		// 3. Collect Readable Resources
+		resourceName := queryParams.Query["resource_name"]
+		if resourceName != "" {
+			if _, err := m.GetResourceByName(resourceName); err != nil {
+				svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, "Unknown resource name")
+				return
+			}
+		}
+
+		unbound := []string{}
+		bound := []string{}
+		isAdmin := user.HasRole(authConstants.AdminRole)
+		for _, a := range m.Resources {
+			name := a.ResourceNames.Singular
+			if resourceName != "" && name != resourceName {
+				continue
+			}
+
+			if a.PrivateActivity && !isAdmin {
+				continue
+			}
+
+			if !authUtils.UserIsAllowed(a.Permissions, user.GetRoles(), authConstants.OperationRead, name, log) {
+				continue
+			}
+
+			if a.SkipUserBinding || isAdmin {
+				unbound = append(unbound, name)
+			} else {
+				bound = append(bound, name)
+			}
+		}
+
+		if resourceName != "" && len(unbound) == 0 && len(bound) == 0 {
+			svrUtils.SendJsonResponse(w, http.StatusForbidden, nil, "User is not allowed to read this resource")
+			return
+		}
+
+		filters["resource_name IN ? OR (resource_name IN ? AND user_id = ?)"] = []interface{}{unbound, bound, user.ID}
+
+		// 4. Find Query
+		instances := []dbModels.DatabaseLog{}
+		pagination := &dbTypes.Pagination{
+			Total: 0,
+			Page:  queryParams.Page,
+			Limit: queryParams.Limit,
+		}
+
+		err = dbQueries.FindMany(r.Context(), log, db, &instances, pagination, queryParams.Order, filters, []string{})
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error finding activity")
+			return
+		}
+
+		svrUtils.SendJsonResponseWithPagination(w, http.StatusOK, instances, "activity", pagination)
+	}
+}
@@ -0,0 +1,130 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	dbHandlers "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/handlers"
+	dbModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/models"
+	dbResources "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/resources"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	testPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/testing"
+)
+
+type activityResponse struct {
+	Data       []dbModels.DatabaseLog `json:"data"`
+	Pagination dbTypes.Pagination     `json:"pagination"`
+}
+
+func setupActivityTestBed(t *testing.T) (testPkg.TestUtils, string) {
+	bed := testPkg.SetupHandlerTestBed()
+
+	_, err := bed.Mgr.AddResource(dbResources.SetupDBLoggerResource(bed.Mgr, bed.Db, bed.Logger))
+	assert.NoError(t, err)
+
+	traceId := uuid.New().String()
+	entries := []struct {
+		user         *authModels.User
+		action       dbTypes.CRUDAction
+		resourceName string
+	}{
+		{bed.AdminUser, dbTypes.CreateCRUDAction, bed.Src.ResourceNames.Singular},
+		{bed.AdminUser, dbTypes.UpdateCRUDAction, bed.Src.ResourceNames.Singular},
+		{bed.VisitorUser, dbTypes.CreateCRUDAction, bed.Src.ResourceNames.Singular},
+		{bed.AdminUser, dbTypes.CreateCRUDAction, "DatabaseLog"},
+	}
+
+	for _, entry := range entries {
+		bed.Db.DB.Create(&dbModels.DatabaseLog{
+			UserId:       entry.user.ID,
+			Username:     entry.user.Email,
+			Action:       entry.action,
+			ResourceName: entry.resourceName,
+			Timestamp:    time.Now().Format(time.RFC3339Nano),
+			TraceId:      traceId,
+		})
+	}
+
+	return bed, traceId
+}
+
+func getActivity(t *testing.T, bed testPkg.TestUtils, method string, user *authModels.User, query string) (*httptest.ResponseRecorder, *activityResponse) {
+	req := testPkg.CreateTestRequest(t, method, "/api/activity"+query, "", true, user, bed.Logger)
+	rr := testPkg.ExecuteHandler(t, dbHandlers.ActivityHandler(bed.Mgr, bed.Db), req)
+
+	response := activityResponse{}
+	if rr.Code == http.StatusOK {
+		err := json.Unmarshal(rr.Body.Bytes(), &response)
+		assert.NoError(t, err)
+	}
+
+	return rr, &response
+}
+
+func TestActivityHandler_Admin(t *testing.T) {
+	bed, traceId := setupActivityTestBed(t)
+
+	rr, response := getActivity(t, bed, http.MethodGet, bed.AdminUser, "?trace_id="+traceId)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, int64(4), response.Pagination.Total)
+
+	rr, response = getActivity(t, bed, http.MethodGet, bed.AdminUser, "?trace_id="+traceId+"&action=updated")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Len(t, response.Data, 1)
+	assert.Equal(t, dbTypes.UpdateCRUDAction, response.Data[0].Action)
+
+	rr, response = getActivity(t, bed, http.MethodGet, bed.AdminUser, "?trace_id="+traceId+"&resource_name=DatabaseLog")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Len(t, response.Data, 1)
+
+	rr, response = getActivity(t, bed, http.MethodGet, bed.AdminUser, "?limit=1&page=2&trace_id="+traceId)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Len(t, response.Data, 1)
+	assert.Equal(t, int64(4), response.Pagination.Total)
+}
+
+func TestActivityHandler_ByUser(t *testing.T) {
+	bed, traceId := setupActivityTestBed(t)
+
+	rr, response := getActivity(t, bed, http.MethodGet, bed.AdminUser, "?trace_id="+traceId+"&user_id="+bed.VisitorUser.StringID())
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Len(t, response.Data, 1)
+	assert.Equal(t, bed.VisitorUser.ID, response.Data[0].UserId)
+}
+
+func TestActivityHandler_Visitor(t *testing.T) {
+	bed, traceId := setupActivityTestBed(t)
+
+	// Visitors cannot read the database log and only see their own changes of bound resources
+	rr, response := getActivity(t, bed, http.MethodGet, bed.VisitorUser, "?trace_id="+traceId)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Len(t, response.Data, 1)
+	assert.Equal(t, bed.VisitorUser.ID, response.Data[0].UserId)
+
+	rr, _ = getActivity(t, bed, http.MethodGet, bed.VisitorUser, "?resource_name=DatabaseLog")
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	rr, response = getActivity(t, bed, http.MethodGet, bed.NoRoleUser, "?trace_id="+traceId)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, response.Data)
+}
+
+func TestActivityHandler_Errors(t *testing.T) {
+	bed, _ := setupActivityTestBed(t)
+
+	rr, _ := getActivity(t, bed, http.MethodPost, bed.AdminUser, "")
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+
+	rr, _ = getActivity(t, bed, http.MethodGet, bed.AdminUser, "?user_id=abc")
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr, _ = getActivity(t, bed, http.MethodGet, bed.AdminUser, "?resource_name=Unknown")
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
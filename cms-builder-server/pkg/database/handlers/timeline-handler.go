@@ -5,6 +5,7 @@ import (
 
 	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
 	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	commentModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/comment/models"
 	dbModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/models"
 	dbQueries "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/queries"
 	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
@@ -81,6 +82,17 @@ func TimelineHandler(m *rmPkg.ResourceManager, db *dbTypes.DatabaseConnection) h
 			"resource_name": resourceName,
 		}
 
+		// Comments on the record are listed with its changes
+		if _, err := m.GetResourceByName("Comment"); err == nil && resourceId != "" && resourceName != "Comment" {
+			comments := db.DB.Unscoped().Model(&commentModels.Comment{}).
+				Select("CAST(id AS TEXT)").
+				Where("resource_name = ? AND resource_id = ?", resourceName, resourceId)
+
+			filters = map[string]interface{}{
+				"(resource_name = ? AND resource_id = ?) OR (resource_name = ? AND resource_id IN (?))": []interface{}{resourceName, resourceId, "Comment", comments},
+			}
+		}
+
 		err = dbQueries.FindMany(r.Context(), log, db, instances, pagination, queryParams.Order, filters, []string{})
 		if err != nil {
 			svrUtils.SendJsonResponse(w, http.StatusNotFound, nil, "Instance not found")
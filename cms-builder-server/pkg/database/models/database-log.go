@@ -15,4 +15,8 @@ type DatabaseLog struct {
 	Timestamp    string             `gorm:"type:timestamp" json:"timestamp"`
 	Detail       string             `json:"detail"`
 	TraceId      string             `json:"traceId"`
+
+	// Admin acting as the user, when the change was made while impersonating
+	ImpersonatorId    uint   `json:"impersonatorId"`
+	ImpersonatorLabel string `json:"impersonatorLabel"`
 }
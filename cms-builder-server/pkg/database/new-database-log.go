@@ -68,5 +68,10 @@ func NewDatabaseLogEntry(action dbTypes.CRUDAction, user *authModels.User, objec
 		TraceId:      traceId,
 	}
 
+	if user.ImpersonatedBy != nil {
+		historyEntry.ImpersonatorId = user.ImpersonatedBy.ID
+		historyEntry.ImpersonatorLabel = user.ImpersonatedBy.Email
+	}
+
 	return historyEntry, nil
 }
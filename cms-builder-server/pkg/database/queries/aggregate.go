@@ -0,0 +1,116 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	loggerTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/logger/types"
+)
+
+// Aggregate runs a GROUP BY query over the given model and returns one row per group.
+// Columns must be validated by the caller, they are quoted but otherwise used as is.
+func Aggregate(ctx context.Context, log *loggerTypes.Logger, db *dbTypes.DatabaseConnection, model interface{}, groups []dbTypes.AggregateGroup, metrics []dbTypes.AggregateMetric, filters map[string]interface{}) ([]map[string]interface{}, error) {
+	if len(metrics) == 0 {
+		return nil, fmt.Errorf("at least one metric is required")
+	}
+
+	selects := []string{}
+	groupBy := []string{}
+
+	for _, group := range groups {
+		expression, err := groupExpression(db, group)
+		if err != nil {
+			return nil, err
+		}
+		selects = append(selects, fmt.Sprintf("%s AS %s", expression, quote(db, group.Alias)))
+		groupBy = append(groupBy, expression)
+	}
+
+	for _, metric := range metrics {
+		expression, err := metricExpression(db, metric)
+		if err != nil {
+			return nil, err
+		}
+		selects = append(selects, fmt.Sprintf("%s AS %s", expression, quote(db, metric.Alias)))
+	}
+
+	query := db.DB.Model(model).WithContext(ctx).Select(strings.Join(selects, ", "))
+
+	// Apply filters
+	for key, value := range filters {
+		query = query.Where(key, value)
+	}
+
+	if len(groupBy) > 0 {
+		query = query.Group(strings.Join(groupBy, ", ")).Order(strings.Join(groupBy, ", "))
+	}
+
+	results := []map[string]interface{}{}
+	if err := query.Find(&results).Error; err != nil {
+		log.Error().
+			Err(err).
+			Interface("filters", filters).
+			Msg("Failed to aggregate records")
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func quote(db *dbTypes.DatabaseConnection, name string) string {
+	builder := strings.Builder{}
+	db.DB.Dialector.QuoteTo(&builder, name)
+	return builder.String()
+}
+
+func metricExpression(db *dbTypes.DatabaseConnection, metric dbTypes.AggregateMetric) (string, error) {
+	switch metric.Function {
+	case dbTypes.CountAggregateFunction:
+		if metric.Column == "" {
+			return "COUNT(*)", nil
+		}
+		return fmt.Sprintf("COUNT(%s)", quote(db, metric.Column)), nil
+	case dbTypes.SumAggregateFunction, dbTypes.AvgAggregateFunction, dbTypes.MinAggregateFunction, dbTypes.MaxAggregateFunction:
+		if metric.Column == "" {
+			return "", fmt.Errorf("metric %s requires a field", metric.Function)
+		}
+		return fmt.Sprintf("%s(%s)", strings.ToUpper(string(metric.Function)), quote(db, metric.Column)), nil
+	}
+
+	return "", fmt.Errorf("unsupported metric %s", metric.Function)
+}
+
+// groupExpression returns the expression used to group by the given column.
+// Date buckets are rendered as strings (2006-01-02 for day and week, 2006-01 for month),
+// weeks start on Monday.
+func groupExpression(db *dbTypes.DatabaseConnection, group dbTypes.AggregateGroup) (string, error) {
+	column := quote(db, group.Column)
+
+	if group.Bucket == "" {
+		return column, nil
+	}
+
+	if db.Config != nil && db.Config.Driver == "postgres" {
+		switch group.Bucket {
+		case dbTypes.DayDateBucket:
+			return fmt.Sprintf("to_char(date_trunc('day', %s), 'YYYY-MM-DD')", column), nil
+		case dbTypes.WeekDateBucket:
+			return fmt.Sprintf("to_char(date_trunc('week', %s), 'YYYY-MM-DD')", column), nil
+		case dbTypes.MonthDateBucket:
+			return fmt.Sprintf("to_char(date_trunc('month', %s), 'YYYY-MM')", column), nil
+		}
+	} else {
+		switch group.Bucket {
+		case dbTypes.DayDateBucket:
+			return fmt.Sprintf("date(%s)", column), nil
+		case dbTypes.WeekDateBucket:
+			return fmt.Sprintf("date(%s, '-6 days', 'weekday 1')", column), nil
+		case dbTypes.MonthDateBucket:
+			return fmt.Sprintf("strftime('%%Y-%%m', %s)", column), nil
+		}
+	}
+
+	return "", fmt.Errorf("unsupported date bucket %s", group.Bucket)
+}
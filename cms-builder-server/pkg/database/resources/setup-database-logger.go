@@ -34,6 +34,13 @@ func SetupDBLoggerResource(resourceManager *rmPkg.ResourceManager, db *dbTypes.D
 			RequiresAuth: true,
 			Methods:      []string{http.MethodGet},
 		},
+		{
+			Path:         "/api/activity",
+			Handler:      dbHandlers.ActivityHandler(resourceManager, db),
+			Name:         "activity",
+			RequiresAuth: true,
+			Methods:      []string{http.MethodGet},
+		},
 	}
 
 	config := &rmTypes.ResourceConfig{
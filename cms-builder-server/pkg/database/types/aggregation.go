@@ -0,0 +1,35 @@
+package types
+
+type AggregateFunction string
+
+const (
+	CountAggregateFunction AggregateFunction = "count"
+	SumAggregateFunction   AggregateFunction = "sum"
+	AvgAggregateFunction   AggregateFunction = "avg"
+	MinAggregateFunction   AggregateFunction = "min"
+	MaxAggregateFunction   AggregateFunction = "max"
+)
+
+type DateBucket string
+
+const (
+	DayDateBucket   DateBucket = "day"
+	WeekDateBucket  DateBucket = "week"
+	MonthDateBucket DateBucket = "month"
+)
+
+// AggregateMetric describes a single aggregated value, such as count or sum:price.
+// Column is empty for count(*).
+type AggregateMetric struct {
+	Function AggregateFunction
+	Column   string
+	Alias    string
+}
+
+// AggregateGroup describes a GROUP BY column. When Bucket is set the column
+// is expected to hold a date and values are truncated to the given bucket.
+type AggregateGroup struct {
+	Column string
+	Bucket DateBucket
+	Alias  string
+}
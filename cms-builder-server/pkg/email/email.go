@@ -38,6 +38,9 @@ func (s *EmailSender) SendEmail(to []string, subject, body string) error {
 
 	toHeader := strings.Join(to, ",")
 
+	// Subjects may hold user input, line breaks would start new headers
+	subject = strings.NewReplacer("\r", " ", "\n", " ").Replace(subject)
+
 	msg := []byte(fmt.Sprintf(
 		"From: %s\r\n"+
 			"To: %s\r\n"+
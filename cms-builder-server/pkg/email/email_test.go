@@ -33,3 +33,18 @@ func TestEmailSenderSendEmail(t *testing.T) {
 		assert.Error(t, err, "Expected error when attempting to send without recipients")
 	})
 }
+
+func TestEmailSenderSendEmail_SubjectLineBreaks(t *testing.T) {
+	server, err := testPkg.StartSmtpServer()
+	assert.NoError(t, err)
+	defer server.Close()
+
+	err = server.Sender().SendEmail([]string{"jane@test.com"}, "Hello\r\nBcc: attacker@test.com", "<p>Hi</p>")
+	assert.NoError(t, err)
+
+	messages := server.Messages()
+	assert.Len(t, messages, 1)
+	assert.Equal(t, []string{"jane@test.com"}, messages[0].To)
+	assert.Contains(t, messages[0].Data, "Subject: Hello  Bcc: attacker@test.com\r\n")
+	assert.NotContains(t, messages[0].Data, "\r\nBcc:")
+}
@@ -0,0 +1,6 @@
+package email
+
+// Sender sends HTML emails. EmailSender is the SMTP implementation.
+type Sender interface {
+	SendEmail(to []string, subject, body string) error
+}
@@ -1,11 +1,14 @@
 package file
 
 import (
+	"fmt"
 	"net/http"
 
 	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
 	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
 	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	notificationConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/notification/constants"
+	notificationTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/notification/types"
 	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
 	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
 	storeTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/store/types"
@@ -13,7 +16,9 @@ import (
 	fileUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/file/utils"
 )
 
-func CreateStoredFilesHandler(db *dbTypes.DatabaseConnection, st storeTypes.Store, apiBaseUrl string) rmTypes.ApiFunction {
+// CreateStoredFilesHandler stores an uploaded file. The uploader is notified once the file is processed,
+// when a notifier is given.
+func CreateStoredFilesHandler(db *dbTypes.DatabaseConnection, st storeTypes.Store, apiBaseUrl string, notifier notificationTypes.Notifier) rmTypes.ApiFunction {
 
 	return func(a *rmTypes.Resource, db *dbTypes.DatabaseConnection) http.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
@@ -71,6 +76,21 @@ func CreateStoredFilesHandler(db *dbTypes.DatabaseConnection, st storeTypes.Stor
 				return
 			}
 
+			if notifier != nil {
+				err = notifier.Notify(r.Context(), notificationTypes.NotificationInput{
+					UserIDs:      []uint{user.ID},
+					Kind:         notificationConstants.KindFileUploaded,
+					Title:        fmt.Sprintf("File %s uploaded", storedFile.Name),
+					Body:         fmt.Sprintf("%s is ready to use.", storedFile.Name),
+					Link:         storedFile.Url,
+					ResourceName: a.ResourceNames.Singular,
+					ResourceId:   storedFile.StringID(),
+				})
+				if err != nil {
+					log.Error().Err(err).Msg("Error notifying uploaded file")
+				}
+			}
+
 			svrUtils.SendJsonResponse(w, http.StatusCreated, storedFile, a.ResourceNames.Singular+" created")
 		}
 	}
@@ -32,7 +32,7 @@ func TestCreateStoredFilesHandler_ValidMimeType(t *testing.T) {
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 
 	// Execute the handler
-	rr := testPkg.ExecuteHandler(t, fileHandlers.CreateStoredFilesHandler(testBed.Db, testBed.Store, "http://localhost:8080")(testBed.Src, testBed.Db), req)
+	rr := testPkg.ExecuteHandler(t, fileHandlers.CreateStoredFilesHandler(testBed.Db, testBed.Store, "http://localhost:8080", nil)(testBed.Src, testBed.Db), req)
 
 	// Assertions
 	assert.Equal(t, http.StatusCreated, rr.Code) // Expect 201 Created
@@ -59,7 +59,7 @@ func TestCreateStoredFilesHandler_InvalidMimeType(t *testing.T) {
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 
 	// Execute the handler
-	rr := testPkg.ExecuteHandler(t, fileHandlers.CreateStoredFilesHandler(testBed.Db, testBed.Store, "http://localhost:8080")(testBed.Src, testBed.Db), req)
+	rr := testPkg.ExecuteHandler(t, fileHandlers.CreateStoredFilesHandler(testBed.Db, testBed.Store, "http://localhost:8080", nil)(testBed.Src, testBed.Db), req)
 
 	// Assertions
 	assert.Equal(t, http.StatusBadRequest, rr.Code) // Expect 400 Bad Request
@@ -84,7 +84,7 @@ func TestCreateStoredFilesHandler_EmptyFile(t *testing.T) {
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 
 	// Execute the handler
-	rr := testPkg.ExecuteHandler(t, fileHandlers.CreateStoredFilesHandler(testBed.Db, testBed.Store, "http://localhost:8080")(testBed.Src, testBed.Db), req)
+	rr := testPkg.ExecuteHandler(t, fileHandlers.CreateStoredFilesHandler(testBed.Db, testBed.Store, "http://localhost:8080", nil)(testBed.Src, testBed.Db), req)
 
 	// Assertions
 	assert.Equal(t, http.StatusBadRequest, rr.Code) // Expect 400 Bad Request
@@ -112,7 +112,7 @@ func TestCreateStoredFilesHandler_LargeFile(t *testing.T) {
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 
 	// Execute the handler
-	rr := testPkg.ExecuteHandler(t, fileHandlers.CreateStoredFilesHandler(testBed.Db, testBed.Store, "http://localhost:8080")(testBed.Src, testBed.Db), req)
+	rr := testPkg.ExecuteHandler(t, fileHandlers.CreateStoredFilesHandler(testBed.Db, testBed.Store, "http://localhost:8080", nil)(testBed.Src, testBed.Db), req)
 
 	// Assertions
 	assert.Equal(t, http.StatusBadRequest, rr.Code) // Expect 400 Bad Request
@@ -127,7 +127,7 @@ func TestCreateStoredFilesHandler_InvalidMethod(t *testing.T) {
 	req := testPkg.CreateTestRequest(t, http.MethodGet, "/files", "", true, testBed.AdminUser, testBed.Logger)
 
 	// Execute the handler
-	rr := testPkg.ExecuteHandler(t, fileHandlers.CreateStoredFilesHandler(testBed.Db, testBed.Store, "http://localhost:8080")(testBed.Src, testBed.Db), req)
+	rr := testPkg.ExecuteHandler(t, fileHandlers.CreateStoredFilesHandler(testBed.Db, testBed.Store, "http://localhost:8080", nil)(testBed.Src, testBed.Db), req)
 
 	// Assertions
 	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
@@ -153,7 +153,7 @@ func TestCreateStoredFilesHandler_PermissionDenied(t *testing.T) {
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 
 	// Execute the handler
-	rr := testPkg.ExecuteHandler(t, fileHandlers.CreateStoredFilesHandler(testBed.Db, testBed.Store, "http://localhost:8080")(testBed.Src, testBed.Db), req)
+	rr := testPkg.ExecuteHandler(t, fileHandlers.CreateStoredFilesHandler(testBed.Db, testBed.Store, "http://localhost:8080", nil)(testBed.Src, testBed.Db), req)
 
 	// Assertions
 	assert.Equal(t, http.StatusForbidden, rr.Code)
@@ -1,6 +1,7 @@
 package file
 
 import (
+	"errors"
 	"net/http"
 
 	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
@@ -8,6 +9,7 @@ import (
 	dbQueries "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/queries"
 	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
 	fileModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/file/models"
+	rmHandlers "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/handlers"
 	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
 	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
 	storeTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/store/types"
@@ -56,17 +58,35 @@ func DeleteStoredFilesHandler(db *dbTypes.DatabaseConnection, st storeTypes.Stor
 				return
 			}
 
-			err = st.DeleteFile(&instance, log)
+			// Referencing records restrict the delete, or are deleted or unlinked with it
+			restricting, err := rmHandlers.FindRestrictingRecords(r.Context(), db, a, &instance)
 			if err != nil {
-				log.Warn().Err(err).Msg("Error deleting file. Path may not exist")
+				log.Error().Err(err).Msg("Error finding referencing records")
+				svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error deleting file")
+				return
+			}
+
+			if len(restricting) > 0 {
+				svrUtils.SendJsonResponse(w, http.StatusConflict, restricting, rmHandlers.ErrDeleteRestricted.Error())
+				return
 			}
 
-			err = dbQueries.Delete(r.Context(), log, db, &instance, user, requestId)
+			err = rmHandlers.DeleteWithReferences(r.Context(), log, db, a, &instance, user, requestId)
+			if errors.Is(err, rmHandlers.ErrDeleteRestricted) {
+				svrUtils.SendJsonResponse(w, http.StatusConflict, nil, err.Error())
+				return
+			}
 			if err != nil {
 				svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error deleting file")
 				return
 			}
 
+			// The stored file goes once the record is gone, a refused delete keeps both
+			err = st.DeleteFile(&instance, log)
+			if err != nil {
+				log.Warn().Err(err).Msg("Error deleting file. Path may not exist")
+			}
+
 			svrUtils.SendJsonResponse(w, http.StatusOK, nil, a.ResourceNames.Singular+" deleted")
 		}
 	}
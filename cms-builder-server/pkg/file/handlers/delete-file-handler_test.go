@@ -7,8 +7,11 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
 	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
 	fileHandlers "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/file/handlers"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
 	testPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/testing"
 
 	fileModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/file/models"
@@ -133,3 +136,58 @@ func TestDeleteStoredFilesHandler(t *testing.T) {
 		})
 	}
 }
+
+type FileAttachment struct {
+	authModels.SystemData
+	FileID uint   `json:"fileId"`
+	Label  string `json:"label"`
+}
+
+func TestDeleteStoredFilesHandler_RelationPolicies(t *testing.T) {
+	bed := testPkg.SetupFileTestBed()
+
+	_, err := bed.Mgr.AddResource(&rmTypes.ResourceConfig{
+		Model: FileAttachment{},
+		Permissions: authTypes.RolePermissionMap{
+			authConstants.AdminRole: authConstants.AllAllowedAccess,
+		},
+		Relations: []rmTypes.RelationConfig{
+			{Field: "FileID", Resource: "File"},
+		},
+	})
+	assert.NoError(t, err)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/files/{id}", fileHandlers.DeleteStoredFilesHandler(bed.Db, bed.Store)(bed.Src, bed.Db))
+
+	file := &fileModels.File{
+		SystemData: &authModels.SystemData{
+			CreatedByID: bed.AdminUser.ID,
+		},
+	}
+	assert.NoError(t, bed.Db.DB.Create(file).Error)
+
+	attachment := FileAttachment{FileID: file.ID, Label: "Cover"}
+	attachment.CreatedByID = bed.AdminUser.ID
+	assert.NoError(t, bed.Db.DB.Create(&attachment).Error)
+
+	send := func() *httptest.ResponseRecorder {
+		req := testPkg.CreateTestRequest(t, http.MethodDelete, "/files/"+file.StringID(), "", true, bed.AdminUser, bed.Logger)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := send()
+	assert.Equal(t, http.StatusConflict, rr.Code, rr.Body.String())
+	assert.Contains(t, rr.Body.String(), "FileAttachment")
+
+	var count int64
+	bed.Db.DB.Model(&fileModels.File{}).Where("id = ?", file.ID).Count(&count)
+	assert.Equal(t, int64(1), count, "A referenced file should not be deleted")
+
+	assert.NoError(t, bed.Db.DB.Delete(&attachment).Error)
+
+	rr = send()
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+}
@@ -36,7 +36,7 @@ func UploadMockFile(t *testing.T, testBed *testPkg.TestUtils, content string, fi
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 
 	// Execute the handler
-	rr := testPkg.ExecuteHandler(t, fileHandlers.CreateStoredFilesHandler(testBed.Db, testBed.Store, "http://localhost:8080")(testBed.Src, testBed.Db), req)
+	rr := testPkg.ExecuteHandler(t, fileHandlers.CreateStoredFilesHandler(testBed.Db, testBed.Store, "http://localhost:8080", nil)(testBed.Src, testBed.Db), req)
 
 	// Assertions
 	assert.Equal(t, http.StatusCreated, rr.Code) // Expect 201 Created
@@ -9,6 +9,7 @@ import (
 	fileHandlers "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/file/handlers"
 	fileModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/file/models"
 	loggerTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/logger/types"
+	notificationTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/notification/types"
 	rmPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager"
 	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
 	rmValidators "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/validators"
@@ -16,7 +17,7 @@ import (
 	storeTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/store/types"
 )
 
-func SetupFileResource(resourceManager *rmPkg.ResourceManager, db *dbTypes.DatabaseConnection, st storeTypes.Store, log *loggerTypes.Logger, apiBaseUrl string) *rmTypes.ResourceConfig {
+func SetupFileResource(resourceManager *rmPkg.ResourceManager, db *dbTypes.DatabaseConnection, st storeTypes.Store, log *loggerTypes.Logger, apiBaseUrl string, notifier notificationTypes.Notifier) *rmTypes.ResourceConfig {
 
 	log.Info().Msg("Initializing File resource")
 
@@ -32,7 +33,7 @@ func SetupFileResource(resourceManager *rmPkg.ResourceManager, db *dbTypes.Datab
 	}
 
 	handlers := &rmTypes.ApiHandlers{
-		Create: fileHandlers.CreateStoredFilesHandler(db, st, apiBaseUrl),
+		Create: fileHandlers.CreateStoredFilesHandler(db, st, apiBaseUrl, notifier),
 		Delete: fileHandlers.DeleteStoredFilesHandler(db, st),
 		Update: fileHandlers.UpdateStoredFilesHandler,
 	}
@@ -54,6 +55,9 @@ func SetupFileResource(resourceManager *rmPkg.ResourceManager, db *dbTypes.Datab
 		Permissions:     permissions,
 		Handlers:        handlers,
 		Routes:          routes,
+		Search: &rmTypes.SearchConfig{
+			Fields: []string{"Name"},
+		},
 	}
 
 	return config
@@ -0,0 +1,12 @@
+package constants
+
+import (
+	notificationTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/notification/types"
+)
+
+const (
+	KindJobFailed       notificationTypes.NotificationKind = "job-failed"       // A scheduler job failed.
+	KindFileUploaded    notificationTypes.NotificationKind = "file-uploaded"    // An uploaded file was processed.
+	KindReviewRequested notificationTypes.NotificationKind = "review-requested" // A user was asked to review a record.
+	KindMentioned       notificationTypes.NotificationKind = "mentioned"        // A user was mentioned in a comment.
+)
@@ -0,0 +1,46 @@
+package notification
+
+import (
+	"net/http"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	notificationModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/notification/models"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
+	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
+)
+
+// NotificationDetailHandler returns a notification from the inbox of the user.
+var NotificationDetailHandler rmTypes.ApiFunction = func(a *rmTypes.Resource, db *dbTypes.DatabaseConnection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestCtx := svrUtils.GetRequestContext(r)
+		log := requestCtx.Logger
+		user := requestCtx.User
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodGet)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		// 2. Check Permissions
+		if !authUtils.UserIsAllowed(a.Permissions, user.GetRoles(), authConstants.OperationRead, a.ResourceNames.Singular, log) {
+			svrUtils.SendJsonResponse(w, http.StatusForbidden, nil, "User is not allowed to access this resource")
+			return
+		}
+
+		// 3. Find Notification (Inbox of the user)
+		notification := notificationModels.Notification{}
+		err = db.DB.WithContext(r.Context()).
+			Where("id = ? AND user_id = ?", svrUtils.GetUrlParam("id", r), user.ID).
+			First(&notification).Error
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusNotFound, nil, "Instance not found")
+			return
+		}
+
+		svrUtils.SendJsonResponse(w, http.StatusOK, &notification, a.ResourceNames.Singular+" Detail")
+	}
+}
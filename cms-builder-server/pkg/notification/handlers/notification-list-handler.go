@@ -0,0 +1,71 @@
+package notification
+
+import (
+	"net/http"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	dbQueries "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/queries"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	notificationModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/notification/models"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
+	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
+)
+
+// ListNotificationsHandler lists the inbox of the user, newest first.
+// The unread parameter leaves out the notifications already read, kind filters them by kind.
+var ListNotificationsHandler rmTypes.ApiFunction = func(a *rmTypes.Resource, db *dbTypes.DatabaseConnection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestCtx := svrUtils.GetRequestContext(r)
+		log := requestCtx.Logger
+		user := requestCtx.User
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodGet)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		// 2. Check Permissions
+		if !authUtils.UserIsAllowed(a.Permissions, user.GetRoles(), authConstants.OperationRead, a.ResourceNames.Singular, log) {
+			svrUtils.SendJsonResponse(w, http.StatusForbidden, nil, "User is not allowed to access this resource")
+			return
+		}
+
+		// 3. Parse Query Parameters
+		queryParams, err := svrUtils.GetRequestQueryParams(r)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, err.Error())
+			return
+		}
+
+		// 4. Find Notifications (Inbox of the user)
+		filters := map[string]interface{}{
+			"user_id": user.ID,
+		}
+
+		if queryParams.Query["unread"] == "true" {
+			filters["is_read"] = false
+		}
+
+		if kind := queryParams.Query["kind"]; kind != "" {
+			filters["kind"] = kind
+		}
+
+		notifications := []notificationModels.Notification{}
+		pagination := &dbTypes.Pagination{
+			Total: 0,
+			Page:  queryParams.Page,
+			Limit: queryParams.Limit,
+		}
+
+		err = dbQueries.FindMany(r.Context(), log, db, &notifications, pagination, "id desc", filters, []string{})
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error finding notifications")
+			return
+		}
+
+		svrUtils.SendJsonResponseWithPagination(w, http.StatusOK, notifications, a.ResourceNames.Plural+" List", pagination)
+	}
+}
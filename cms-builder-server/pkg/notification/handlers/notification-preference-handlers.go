@@ -0,0 +1,131 @@
+package notification
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"gorm.io/gorm"
+
+	dbQueries "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/queries"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	notificationModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/notification/models"
+	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
+	"github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/utils"
+)
+
+// PreferenceInput is the request body of a preference update.
+type PreferenceInput struct {
+	Email *bool `json:"email"`
+}
+
+// PreferenceDetailHandler returns the notification preferences of the user.
+// Users who never saved them get the defaults, which do not send emails.
+func PreferenceDetailHandler(db *dbTypes.DatabaseConnection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestCtx := svrUtils.GetRequestContext(r)
+		user := requestCtx.User
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodGet)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		// 2. Find Preferences
+		preference, err := findPreference(r, db)
+		if err != nil {
+			requestCtx.Logger.Error().Err(err).Msg("Error finding notification preferences")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error finding notification preferences")
+			return
+		}
+
+		if preference == nil {
+			preference = &notificationModels.NotificationPreference{UserID: user.ID}
+		}
+
+		svrUtils.SendJsonResponse(w, http.StatusOK, preference, "Notification Preferences")
+	}
+}
+
+// PreferenceUpdateHandler saves the notification preferences of the user.
+func PreferenceUpdateHandler(db *dbTypes.DatabaseConnection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestCtx := svrUtils.GetRequestContext(r)
+		log := requestCtx.Logger
+		user := requestCtx.User
+		requestId := requestCtx.RequestId
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodPut)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		// 2. Parse Request Body
+		bodyBytes, err := svrUtils.ReadRequestBody(r)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, "Invalid request body")
+			return
+		}
+
+		input := PreferenceInput{}
+		err = json.Unmarshal(bodyBytes, &input)
+		if err != nil || input.Email == nil {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, "Invalid request body")
+			return
+		}
+
+		// 3. Find Preferences
+		preference, err := findPreference(r, db)
+		if err != nil {
+			log.Error().Err(err).Msg("Error finding notification preferences")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error updating notification preferences")
+			return
+		}
+
+		// 4. Save Preferences
+		if preference == nil {
+			preference = &notificationModels.NotificationPreference{
+				UserID: user.ID,
+				Email:  *input.Email,
+			}
+			preference.CreatedByID = user.ID
+			preference.UpdatedByID = user.ID
+
+			err = dbQueries.Create(r.Context(), log, db, preference, user, requestId)
+		} else {
+			previousState := *preference
+			preference.Email = *input.Email
+			preference.UpdatedByID = user.ID
+
+			differences := utils.CompareInterfaces(previousState, *preference)
+			err = dbQueries.Update(r.Context(), log, db, preference, user, differences, requestId)
+		}
+
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error updating notification preferences")
+			return
+		}
+
+		svrUtils.SendJsonResponse(w, http.StatusOK, preference, "Notification Preferences updated")
+	}
+}
+
+// findPreference returns the saved preferences of the request user, or nil when there are none.
+func findPreference(r *http.Request, db *dbTypes.DatabaseConnection) (*notificationModels.NotificationPreference, error) {
+	user := svrUtils.GetRequestContext(r).User
+
+	preference := notificationModels.NotificationPreference{}
+	err := db.DB.WithContext(r.Context()).Where("user_id = ?", user.ID).First(&preference).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &preference, nil
+}
@@ -0,0 +1,120 @@
+package notification
+
+import (
+	"net/http"
+	"time"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	dbQueries "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/queries"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	notificationModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/notification/models"
+	rmPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager"
+	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
+	"github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/utils"
+)
+
+// MarkReadHandler marks a notification of the user as read.
+func MarkReadHandler(mgr *rmPkg.ResourceManager, db *dbTypes.DatabaseConnection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestCtx := svrUtils.GetRequestContext(r)
+		log := requestCtx.Logger
+		user := requestCtx.User
+		requestId := requestCtx.RequestId
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodPut)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		// 2. Check Permissions
+		a, err := mgr.GetResource(notificationModels.Notification{})
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, err.Error())
+			return
+		}
+
+		if !authUtils.UserIsAllowed(a.Permissions, user.GetRoles(), authConstants.OperationRead, a.ResourceNames.Singular, log) {
+			svrUtils.SendJsonResponse(w, http.StatusForbidden, nil, "User is not allowed to access this resource")
+			return
+		}
+
+		// 3. Find Notification (Inbox of the user)
+		notification := notificationModels.Notification{}
+		err = db.DB.WithContext(r.Context()).
+			Where("id = ? AND user_id = ?", svrUtils.GetUrlParam("id", r), user.ID).
+			First(&notification).Error
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusNotFound, nil, "Instance not found")
+			return
+		}
+
+		if notification.Read {
+			svrUtils.SendJsonResponse(w, http.StatusOK, &notification, a.ResourceNames.Singular+" is up to date")
+			return
+		}
+
+		// 4. Update State
+		previousState := notification
+		now := time.Now()
+		notification.Read = true
+		notification.ReadAt = &now
+		notification.UpdatedByID = user.ID
+
+		differences := utils.CompareInterfaces(previousState, notification)
+		err = dbQueries.Update(r.Context(), log, db, &notification, user, differences, requestId)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error updating notification")
+			return
+		}
+
+		svrUtils.SendJsonResponse(w, http.StatusOK, &notification, a.ResourceNames.Singular+" marked as read")
+	}
+}
+
+// MarkAllReadHandler marks every unread notification of the user as read, and returns how many changed.
+// It runs a single update, so no database log entry is made per notification.
+func MarkAllReadHandler(mgr *rmPkg.ResourceManager, db *dbTypes.DatabaseConnection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestCtx := svrUtils.GetRequestContext(r)
+		log := requestCtx.Logger
+		user := requestCtx.User
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodPut)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		// 2. Check Permissions
+		a, err := mgr.GetResource(notificationModels.Notification{})
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, err.Error())
+			return
+		}
+
+		if !authUtils.UserIsAllowed(a.Permissions, user.GetRoles(), authConstants.OperationRead, a.ResourceNames.Singular, log) {
+			svrUtils.SendJsonResponse(w, http.StatusForbidden, nil, "User is not allowed to access this resource")
+			return
+		}
+
+		// 3. Update State
+		result := db.DB.WithContext(r.Context()).Model(&notificationModels.Notification{}).
+			Where("user_id = ? AND is_read = ?", user.ID, false).
+			Updates(map[string]interface{}{
+				"is_read":       true,
+				"read_at":       time.Now(),
+				"updated_by_id": user.ID,
+			})
+		if result.Error != nil {
+			log.Error().Err(result.Error).Msg("Error marking notifications as read")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error updating notifications")
+			return
+		}
+
+		svrUtils.SendJsonResponse(w, http.StatusOK, map[string]int64{"updated": result.RowsAffected}, a.ResourceNames.Plural+" marked as read")
+	}
+}
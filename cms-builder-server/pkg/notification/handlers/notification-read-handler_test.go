@@ -0,0 +1,149 @@
+package notification_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	dbHandlers "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/handlers"
+	dbModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/models"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	notificationModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/notification/models"
+	notificationResources "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/notification/resources"
+	testPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/testing"
+)
+
+func TestNotificationInbox(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+
+	config := notificationResources.SetupNotificationResource(bed.Mgr, bed.Db, bed.Logger)
+	_, err := bed.Mgr.AddResource(config)
+	assert.NoError(t, err)
+	assert.NoError(t, bed.Db.DB.AutoMigrate(&notificationModels.NotificationPreference{}))
+
+	bed.Db.DB.Unscoped().Where("1 = 1").Delete(&notificationModels.Notification{})
+	bed.Db.DB.Unscoped().Where("1 = 1").Delete(&notificationModels.NotificationPreference{})
+
+	router := mux.NewRouter()
+	for _, route := range bed.Mgr.GetRoutes("") {
+		router.HandleFunc(route.Path, route.Handler).Methods(route.Methods...)
+	}
+
+	send := func(method string, path string, body string, user *authModels.User, data interface{}) *httptest.ResponseRecorder {
+		req := testPkg.CreateTestRequest(t, method, path, body, true, user, bed.Logger)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		response := struct {
+			Data interface{} `json:"data"`
+		}{Data: data}
+		if rr.Code < 300 && data != nil {
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		}
+		return rr
+	}
+
+	notify := func(user *authModels.User, title string) notificationModels.Notification {
+		notification := notificationModels.Notification{UserID: user.ID, Title: title}
+		notification.CreatedByID = bed.AdminUser.ID
+		notification.UpdatedByID = bed.AdminUser.ID
+		assert.NoError(t, bed.Db.DB.Create(&notification).Error)
+		return notification
+	}
+
+	first := notify(bed.VisitorUser, "First")
+	notify(bed.VisitorUser, "Second")
+	notify(bed.VisitorUser, "Third")
+	other := notify(bed.AdminUser, "Admin")
+
+	t.Run("Users list their own unread notifications", func(t *testing.T) {
+		unread := []notificationModels.Notification{}
+		rr := send(http.MethodGet, "/api/notifications?unread=true", "", bed.VisitorUser, &unread)
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.Len(t, unread, 3)
+		assert.Equal(t, "Third", unread[0].Title, "Newest first")
+
+		rr = send(http.MethodGet, fmt.Sprintf("/api/notifications/%d", other.ID), "", bed.VisitorUser, nil)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Users can't reach the notifications of others", func(t *testing.T) {
+		for _, path := range []string{"/api/notifications/aggregate?group_by=title", fmt.Sprintf("/api/notifications/%d/references", other.ID)} {
+			rr := send(http.MethodGet, path, "", bed.VisitorUser, nil)
+			assert.Equal(t, http.StatusNotFound, rr.Code, path)
+		}
+
+		rr := send(http.MethodPost, fmt.Sprintf("/api/notifications/%d/lock", other.ID), "", bed.VisitorUser, nil)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+
+		bed.Db.DB.Create(&dbModels.DatabaseLog{
+			Action:       dbTypes.CreateCRUDAction,
+			ResourceName: "Notification",
+			ResourceId:   other.StringID(),
+			Detail:       other.Title,
+		})
+
+		req := testPkg.CreateTestRequest(t, http.MethodGet, "/api/activity?resource_name=Notification", "", true, bed.VisitorUser, bed.Logger)
+		rr = testPkg.ExecuteHandler(t, dbHandlers.ActivityHandler(bed.Mgr, bed.Db), req)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+
+		req = testPkg.CreateTestRequest(t, http.MethodGet, "/api/activity?limit=100", "", true, bed.VisitorUser, bed.Logger)
+		rr = testPkg.ExecuteHandler(t, dbHandlers.ActivityHandler(bed.Mgr, bed.Db), req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.NotContains(t, rr.Body.String(), `"resourceName":"Notification"`)
+	})
+
+	t.Run("Mark read", func(t *testing.T) {
+		notification := notificationModels.Notification{}
+		rr := send(http.MethodPut, fmt.Sprintf("/api/notifications/%d/read", first.ID), "", bed.VisitorUser, &notification)
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.True(t, notification.Read)
+		assert.NotNil(t, notification.ReadAt)
+
+		unread := []notificationModels.Notification{}
+		send(http.MethodGet, "/api/notifications?unread=true", "", bed.VisitorUser, &unread)
+		assert.Len(t, unread, 2)
+
+		rr = send(http.MethodPut, fmt.Sprintf("/api/notifications/%d/read", other.ID), "", bed.VisitorUser, nil)
+		assert.Equal(t, http.StatusNotFound, rr.Code, "Notifications of other users are not found")
+	})
+
+	t.Run("Mark all read", func(t *testing.T) {
+		result := map[string]int64{}
+		rr := send(http.MethodPut, "/api/notifications/read-all", "", bed.VisitorUser, &result)
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.Equal(t, int64(2), result["updated"])
+
+		unread := []notificationModels.Notification{}
+		send(http.MethodGet, "/api/notifications?unread=true", "", bed.VisitorUser, &unread)
+		assert.Empty(t, unread)
+
+		adminUnread := []notificationModels.Notification{}
+		send(http.MethodGet, "/api/notifications?unread=true", "", bed.AdminUser, &adminUnread)
+		assert.Len(t, adminUnread, 1)
+	})
+
+	t.Run("Email preference is opt-in", func(t *testing.T) {
+		preference := notificationModels.NotificationPreference{}
+		rr := send(http.MethodGet, "/api/notifications/preferences", "", bed.VisitorUser, &preference)
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.False(t, preference.Email)
+
+		rr = send(http.MethodPut, "/api/notifications/preferences", `{"email": true}`, bed.VisitorUser, &preference)
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.True(t, preference.Email)
+
+		preference = notificationModels.NotificationPreference{}
+		send(http.MethodGet, "/api/notifications/preferences", "", bed.VisitorUser, &preference)
+		assert.True(t, preference.Email)
+
+		rr = send(http.MethodPut, "/api/notifications/preferences", `{}`, bed.VisitorUser, nil)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
@@ -0,0 +1,151 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"slices"
+
+	"github.com/google/uuid"
+
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	dbQueries "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/queries"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	emailTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/email/types"
+	loggerTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/logger/types"
+	notificationModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/notification/models"
+	notificationTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/notification/types"
+)
+
+// Inbox stores notifications in the database and emails the recipients who opted in.
+type Inbox struct {
+	DB     *dbTypes.DatabaseConnection // Database connection for persisting notifications.
+	Sender emailTypes.Sender           // Sender of the opt-in emails, none are sent when nil.
+	User   *authModels.User            // User recorded as the author of the notifications.
+	Logger *loggerTypes.Logger
+}
+
+// NewInbox initializes the notification inbox, notifications are created on behalf of the given user.
+func NewInbox(db *dbTypes.DatabaseConnection, sender emailTypes.Sender, user *authModels.User, log *loggerTypes.Logger) (*Inbox, error) {
+	err := db.DB.AutoMigrate(&notificationModels.Notification{}, &notificationModels.NotificationPreference{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Inbox{
+		DB:     db,
+		Sender: sender,
+		User:   user,
+		Logger: log,
+	}, nil
+}
+
+// Notify adds a notification to the inbox of every recipient of the given input.
+// Emails are sent once the notifications are stored, failures to send them are only logged.
+func (i *Inbox) Notify(ctx context.Context, input notificationTypes.NotificationInput) error {
+	recipients, err := i.findRecipients(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	requestId := "automated::" + uuid.New().String()
+	for _, recipient := range recipients {
+		notification := notificationModels.Notification{
+			UserID:       recipient.ID,
+			Kind:         input.Kind,
+			Title:        input.Title,
+			Body:         input.Body,
+			Link:         input.Link,
+			ResourceName: input.ResourceName,
+			ResourceId:   input.ResourceId,
+		}
+		notification.CreatedByID = i.User.ID
+		notification.UpdatedByID = i.User.ID
+
+		err = dbQueries.Create(ctx, i.Logger, i.DB, &notification, i.User, requestId)
+		if err != nil {
+			return err
+		}
+	}
+
+	i.sendEmails(ctx, input, recipients)
+	return nil
+}
+
+// findRecipients returns the users listed in the input along with the users holding its role.
+func (i *Inbox) findRecipients(ctx context.Context, input notificationTypes.NotificationInput) ([]authModels.User, error) {
+	recipients := []authModels.User{}
+
+	if len(input.UserIDs) > 0 {
+		err := i.DB.DB.WithContext(ctx).Where("id IN ?", input.UserIDs).Order("id").Find(&recipients).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if input.Role == "" {
+		return recipients, nil
+	}
+
+	// Roles are stored as a comma-separated list or assigned with UserRole, matches are confirmed with HasRole
+	assigned := i.DB.DB.Model(&authModels.UserRole{}).
+		Select("user_roles.user_id").
+		Joins("JOIN roles ON roles.id = user_roles.role_id AND roles.deleted_at IS NULL").
+		Where("roles.name = ?", string(input.Role))
+
+	candidates := []authModels.User{}
+	err := i.DB.DB.WithContext(ctx).
+		Where("roles LIKE ?", "%"+string(input.Role)+"%").
+		Or("id IN (?)", assigned).
+		Order("id").Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, candidate := range candidates {
+		authUtils.LoadUserRoles(ctx, i.DB, &candidate, i.Logger)
+
+		listed := slices.ContainsFunc(recipients, func(user authModels.User) bool {
+			return user.ID == candidate.ID
+		})
+		if candidate.HasRole(input.Role) && !listed {
+			recipients = append(recipients, candidate)
+		}
+	}
+
+	return recipients, nil
+}
+
+// sendEmails emails the notification to the recipients who opted in.
+func (i *Inbox) sendEmails(ctx context.Context, input notificationTypes.NotificationInput, recipients []authModels.User) {
+	if i.Sender == nil || len(recipients) == 0 {
+		return
+	}
+
+	ids := []uint{}
+	for _, recipient := range recipients {
+		ids = append(ids, recipient.ID)
+	}
+
+	optedIn := []uint{}
+	err := i.DB.DB.WithContext(ctx).Model(&notificationModels.NotificationPreference{}).
+		Where("user_id IN ? AND email = ?", ids, true).
+		Pluck("user_id", &optedIn).Error
+	if err != nil {
+		i.Logger.Error().Err(err).Msg("Error finding notification preferences")
+		return
+	}
+
+	body := fmt.Sprintf("<p>%s</p>", html.EscapeString(input.Body))
+	for _, recipient := range recipients {
+		if !slices.Contains(optedIn, recipient.ID) {
+			continue
+		}
+
+		err = i.Sender.SendEmail([]string{recipient.Email}, input.Title, body)
+		if err != nil {
+			i.Logger.Error().Err(err).Uint("userId", recipient.ID).Msg("Error sending notification email")
+		}
+	}
+}
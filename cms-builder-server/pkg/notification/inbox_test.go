@@ -0,0 +1,94 @@
+package notification_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	notificationPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/notification"
+	notificationConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/notification/constants"
+	notificationModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/notification/models"
+	notificationTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/notification/types"
+	testPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/testing"
+)
+
+type mockSender struct {
+	To [][]string
+}
+
+func (s *mockSender) SendEmail(to []string, subject, body string) error {
+	s.To = append(s.To, to)
+	return nil
+}
+
+func TestInbox_Notify(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+	sender := &mockSender{}
+
+	assert.NoError(t, bed.Db.DB.AutoMigrate(&authModels.Role{}, &authModels.UserRole{}))
+
+	inbox, err := notificationPkg.NewInbox(bed.Db, sender, bed.AdminUser, bed.Logger)
+	assert.NoError(t, err)
+
+	bed.Db.DB.Unscoped().Where("1 = 1").Delete(&notificationModels.Notification{})
+	bed.Db.DB.Unscoped().Where("1 = 1").Delete(&notificationModels.NotificationPreference{})
+
+	preference := notificationModels.NotificationPreference{UserID: bed.VisitorUser.ID, Email: true}
+	preference.CreatedByID = bed.VisitorUser.ID
+	preference.UpdatedByID = bed.VisitorUser.ID
+	assert.NoError(t, bed.Db.DB.Create(&preference).Error)
+
+	err = inbox.Notify(context.Background(), notificationTypes.NotificationInput{
+		UserIDs: []uint{bed.VisitorUser.ID, bed.AdminUser.ID},
+		Role:    authConstants.AdminRole,
+		Kind:    notificationConstants.KindReviewRequested,
+		Title:   "Review requested",
+		Body:    "Please review the draft",
+	})
+	assert.NoError(t, err)
+
+	t.Run("Listed users and role holders are notified once", func(t *testing.T) {
+		visitorCount := int64(0)
+		bed.Db.DB.Model(&notificationModels.Notification{}).Where("user_id = ?", bed.VisitorUser.ID).Count(&visitorCount)
+		assert.Equal(t, int64(1), visitorCount)
+
+		adminCount := int64(0)
+		bed.Db.DB.Model(&notificationModels.Notification{}).Where("user_id = ?", bed.AdminUser.ID).Count(&adminCount)
+		assert.Equal(t, int64(1), adminCount)
+	})
+
+	t.Run("Only users who opted in are emailed", func(t *testing.T) {
+		assert.Equal(t, [][]string{{bed.VisitorUser.Email}}, sender.To)
+	})
+}
+
+func TestInbox_NotifyAssignedRoles(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+	assert.NoError(t, bed.Db.DB.AutoMigrate(&authModels.Role{}, &authModels.UserRole{}))
+
+	inbox, err := notificationPkg.NewInbox(bed.Db, nil, bed.AdminUser, bed.Logger)
+	assert.NoError(t, err)
+
+	role := authModels.Role{Name: "reviewer-" + testPkg.RandomString(8)}
+	assert.NoError(t, bed.Db.DB.Create(&role).Error)
+
+	reviewer := testPkg.CreateNoRoleUser()
+	assert.NoError(t, bed.Db.DB.Create(reviewer).Error)
+	assert.NoError(t, bed.Db.DB.Create(&authModels.UserRole{UserID: reviewer.ID, RoleID: role.ID}).Error)
+
+	err = inbox.Notify(context.Background(), notificationTypes.NotificationInput{
+		Role:  authTypes.Role(role.Name),
+		Kind:  notificationConstants.KindReviewRequested,
+		Title: "Review requested",
+	})
+	assert.NoError(t, err)
+
+	notifications := []notificationModels.Notification{}
+	bed.Db.DB.Where("title = ? AND user_id IN ?", "Review requested", []uint{reviewer.ID, bed.VisitorUser.ID}).Find(&notifications)
+	assert.Len(t, notifications, 1, "Holders of a role assigned with UserRole are notified")
+	assert.Equal(t, reviewer.ID, notifications[0].UserID)
+}
@@ -0,0 +1,13 @@
+package models
+
+import (
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+)
+
+// NotificationPreference holds how a user wants to be notified.
+// Notifications always reach the inbox, emails are sent only to users who opted in.
+type NotificationPreference struct {
+	authModels.SystemData
+	UserID uint `gorm:"uniqueIndex" json:"userId"`
+	Email  bool `json:"email"`
+}
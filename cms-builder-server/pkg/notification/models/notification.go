@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	notificationTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/notification/types"
+)
+
+// Notification is a message in the inbox of a user.
+type Notification struct {
+	authModels.SystemData
+	UserID       uint                               `gorm:"index:idx_notification_inbox" json:"userId"`
+	Read         bool                               `gorm:"column:is_read;index:idx_notification_inbox" json:"read"`
+	ReadAt       *time.Time                         `json:"readAt"`
+	Kind         notificationTypes.NotificationKind `json:"kind"`
+	Title        string                             `json:"title"`
+	Body         string                             `json:"body"`
+	Link         string                             `json:"link"`
+	ResourceName string                             `json:"resourceName"`
+	ResourceId   string                             `json:"resourceId"`
+}
@@ -0,0 +1,82 @@
+package notification
+
+import (
+	"net/http"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	loggerTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/logger/types"
+	notificationHandlers "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/notification/handlers"
+	notificationModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/notification/models"
+	rmPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager"
+	rmHandlers "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/handlers"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
+	svrTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/types"
+)
+
+func SetupNotificationResource(resourceManager *rmPkg.ResourceManager, db *dbTypes.DatabaseConnection, log *loggerTypes.Logger) *rmTypes.ResourceConfig {
+
+	log.Info().Msg("Initializing Notification resource")
+
+	skipUserBinding := true // Notifications are bound to their recipient, handlers filter by user_id
+
+	// Notifications are created through the Inbox, users only read their own
+	permissions := authTypes.RolePermissionMap{
+		authConstants.AdminRole:   authConstants.AllAllowedAccess,
+		authConstants.VisitorRole: []authTypes.CrudOperation{authConstants.OperationRead},
+	}
+
+	handlers := &rmTypes.ApiHandlers{
+		List:   notificationHandlers.ListNotificationsHandler,
+		Detail: notificationHandlers.NotificationDetailHandler,
+
+		// The default handlers don't filter by recipient
+		Aggregate:  rmHandlers.DisabledHandler,
+		References: rmHandlers.DisabledHandler,
+		Lock:       rmHandlers.DisabledHandler,
+		Unlock:     rmHandlers.DisabledHandler,
+	}
+
+	routes := []svrTypes.Route{
+		{
+			Path:         "/api/notifications/{id}/read",
+			Handler:      notificationHandlers.MarkReadHandler(resourceManager, db),
+			Name:         "notifications-read",
+			RequiresAuth: true,
+			Methods:      []string{http.MethodPut},
+		},
+		{
+			Path:         "/api/notifications/read-all",
+			Handler:      notificationHandlers.MarkAllReadHandler(resourceManager, db),
+			Name:         "notifications-read-all",
+			RequiresAuth: true,
+			Methods:      []string{http.MethodPut},
+		},
+		{
+			Path:         "/api/notifications/preferences",
+			Handler:      notificationHandlers.PreferenceDetailHandler(db),
+			Name:         "notifications-preferences",
+			RequiresAuth: true,
+			Methods:      []string{http.MethodGet},
+		},
+		{
+			Path:         "/api/notifications/preferences",
+			Handler:      notificationHandlers.PreferenceUpdateHandler(db),
+			Name:         "notifications-preferences-update",
+			RequiresAuth: true,
+			Methods:      []string{http.MethodPut},
+		},
+	}
+
+	config := &rmTypes.ResourceConfig{
+		Model:           notificationModels.Notification{},
+		SkipUserBinding: skipUserBinding,
+		PrivateActivity: true, // Log entries hold the notifications of every recipient
+		Permissions:     permissions,
+		Handlers:        handlers,
+		Routes:          routes,
+	}
+
+	return config
+}
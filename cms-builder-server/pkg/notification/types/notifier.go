@@ -0,0 +1,27 @@
+package notification
+
+import (
+	"context"
+
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+)
+
+// NotificationKind tells what a notification is about, so clients can group or filter them.
+type NotificationKind string
+
+// NotificationInput describes a notification delivered to the inbox of its recipients.
+type NotificationInput struct {
+	UserIDs      []uint           // Users notified
+	Role         authTypes.Role   // Every user holding this role is notified as well
+	Kind         NotificationKind // What the notification is about
+	Title        string
+	Body         string
+	Link         string // Where the client takes the user, optional
+	ResourceName string // Record the notification refers to, optional
+	ResourceId   string
+}
+
+// Notifier delivers notifications to the inbox of users. Inbox is the database implementation.
+type Notifier interface {
+	Notify(ctx context.Context, input NotificationInput) error
+}
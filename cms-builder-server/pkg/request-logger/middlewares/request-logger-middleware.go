@@ -101,6 +101,11 @@ func RequestLoggerMiddleware(db *dbTypes.DatabaseConnection) func(next http.Hand
 					TraceId:    traceId,
 				}
 
+				if user.ImpersonatedBy != nil {
+					logEntry.ImpersonatorId = user.ImpersonatedBy.ID
+					logEntry.ImpersonatorLabel = user.ImpersonatedBy.Email
+				}
+
 				if db != nil && db.DB != nil {
 					if createErr := db.DB.Create(&logEntry).Error; createErr != nil {
 						log.Error().Err(createErr).Msg("Error creating request log")
@@ -26,4 +26,8 @@ type RequestLog struct {
 	Body       string    `json:"body"`
 	Response   string    `json:"response"`
 	TraceId    string    `json:"traceId"`
+
+	// Admin acting as the user, when the request was made while impersonating
+	ImpersonatorId    uint   `json:"impersonatorId"`
+	ImpersonatorLabel string `json:"impersonatorLabel"`
 }
@@ -12,13 +12,16 @@ type Endpoint struct {
 	Path   string `json:"path"`
 }
 type appInfo struct {
-	Name        string              `json:"name"`
-	Plural      string              `json:"pluralName"`
-	Snake       string              `json:"snakeName"`
-	Kebab       string              `json:"kebabName"`
-	SnakePlural string              `json:"snakePluralName"`
-	KebabPlural string              `json:"kebabPluralName"`
-	Endpoints   map[string]Endpoint `json:"endpoints"`
+	Name        string                 `json:"name"`
+	Plural      string                 `json:"pluralName"`
+	Snake       string                 `json:"snakeName"`
+	Kebab       string                 `json:"kebabName"`
+	SnakePlural string                 `json:"snakePluralName"`
+	KebabPlural string                 `json:"kebabPluralName"`
+	Endpoints   map[string]Endpoint    `json:"endpoints"`
+	Singleton   bool                   `json:"singleton"`
+	Display     *rmTypes.DisplayConfig `json:"display"`
+	Locales     *rmTypes.LocaleConfig  `json:"locales,omitempty"`
 }
 
 func ApiHandler(resources map[string]*rmTypes.Resource, apiBaseUrl string) http.HandlerFunc {
@@ -35,6 +38,9 @@ func ApiHandler(resources map[string]*rmTypes.Resource, apiBaseUrl string) http.
 		for _, rsc := range resources {
 
 			url := apiBaseUrl + "/api/" + rsc.ResourceNames.KebabPlural + "/schema"
+			if rsc.Singleton {
+				url = apiBaseUrl + "/api/" + rsc.ResourceNames.KebabSingular + "/schema"
+			}
 
 			data := appInfo{
 				Name:        rsc.ResourceNames.Singular,
@@ -49,6 +55,12 @@ func ApiHandler(resources map[string]*rmTypes.Resource, apiBaseUrl string) http.
 						Path:   url,
 					},
 				},
+				Singleton: rsc.Singleton,
+				Display:   rsc.Display,
+			}
+
+			if len(rsc.Translatable) > 0 {
+				data.Locales = rsc.Locales
 			}
 
 			output = append(output, data)
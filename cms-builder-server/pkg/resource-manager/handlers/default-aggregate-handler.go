@@ -0,0 +1,247 @@
+package resourcemanager
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm/schema"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	dbQueries "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/queries"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
+	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
+)
+
+// aggregateQueryKeys defines URL query parameters that configure the aggregation
+// and should not be applied as database filters.
+var aggregateQueryKeys = map[string]bool{
+	"group_by": true,
+	"metrics":  true,
+	"page":     true,
+	"limit":    true,
+	"order":    true,
+}
+
+var schemaCache = &sync.Map{}
+
+// DefaultAggregateHandler groups the instances of a resource and computes metrics over them.
+//
+// Query parameters:
+//   - group_by: comma separated fields. Date fields accept a bucket: created_at:month (day, week or month).
+//   - metrics: comma separated metrics, defaults to count. Supported: count, count:field, sum:field, avg:field, min:field, max:field.
+//   - any other parameter is applied as an equality filter.
+var DefaultAggregateHandler rmTypes.ApiFunction = func(a *rmTypes.Resource, db *dbTypes.DatabaseConnection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestCtx := svrUtils.GetRequestContext(r)
+		log := requestCtx.Logger
+		user := requestCtx.User
+		isAdmin := user.HasRole(authConstants.AdminRole)
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodGet)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		// 2. Check Permissions
+		if !authUtils.UserIsAllowed(a.Permissions, user.GetRoles(), authConstants.OperationRead, a.ResourceNames.Singular, log) {
+			svrUtils.SendJsonResponse(w, http.StatusForbidden, nil, "User is not allowed to read this resource")
+			return
+		}
+
+		// 3. Resolve model fields
+		fields, err := getModelFields(a, db)
+		if err != nil {
+			log.Error().Err(err).Msg("Error parsing model schema")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, err.Error())
+			return
+		}
+
+		q := r.URL.Query()
+
+		// 4. Parse groups and metrics
+		groups, err := parseAggregateGroups(q.Get("group_by"), fields)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, err.Error())
+			return
+		}
+
+		metrics, err := parseAggregateMetrics(q.Get("metrics"), fields)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, err.Error())
+			return
+		}
+
+		filters := map[string]interface{}{}
+
+		// 5. Apply default user binding filter (unless skipped or user is Admin)
+		if !(a.SkipUserBinding || isAdmin) {
+			filters["created_by_id"] = user.ID
+		}
+
+		// 6. Apply query filters, only known fields are accepted
+		for key := range q {
+			if _, exists := aggregateQueryKeys[key]; exists {
+				continue
+			}
+
+			value := q.Get(key)
+			if value == "" {
+				continue
+			}
+
+			field, ok := fields[key]
+			if !ok {
+				svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, fmt.Sprintf("Unknown filter field %s", key))
+				return
+			}
+
+			if field.DBName == "created_by_id" && !(a.SkipUserBinding || isAdmin) {
+				continue
+			}
+
+			filters[field.DBName] = value
+		}
+
+		// 7. Execute query
+		results, err := dbQueries.Aggregate(r.Context(), log, db, a.Model, groups, metrics, filters)
+		if err != nil {
+			log.Error().Err(err).Msg("Error aggregating instances")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error aggregating instances")
+			return
+		}
+
+		svrUtils.SendJsonResponse(w, http.StatusOK, results, a.ResourceNames.Plural+" Aggregate")
+	}
+}
+
+// getModelFields returns the database fields of the resource model indexed by
+// both their column name and their json name. Fields hidden from json, such as
+// password hashes, are left out so they can't be grouped or filtered by.
+func getModelFields(a *rmTypes.Resource, db *dbTypes.DatabaseConnection) (map[string]*schema.Field, error) {
+	s, err := getModelSchema(a, db)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]*schema.Field{}
+	for _, field := range s.Fields {
+		if field.DBName == "" {
+			continue
+		}
+
+		jsonName := strings.Split(field.Tag.Get("json"), ",")[0]
+		if jsonName == "-" {
+			continue
+		}
+
+		fields[field.DBName] = field
+
+		if jsonName != "" {
+			if _, exists := fields[jsonName]; !exists {
+				fields[jsonName] = field
+			}
+		}
+	}
+
+	return fields, nil
+}
+
+// getModelSchema parses the resource model with the naming strategy of the connection.
+func getModelSchema(a *rmTypes.Resource, db *dbTypes.DatabaseConnection) (*schema.Schema, error) {
+	return schema.Parse(a.Model, schemaCache, db.DB.NamingStrategy)
+}
+
+func parseAggregateGroups(input string, fields map[string]*schema.Field) ([]dbTypes.AggregateGroup, error) {
+	groups := []dbTypes.AggregateGroup{}
+	if input == "" {
+		return groups, nil
+	}
+
+	for _, item := range strings.Split(input, ",") {
+		parts := strings.SplitN(strings.TrimSpace(item), ":", 2)
+		name := parts[0]
+
+		field, ok := fields[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown group_by field %s", name)
+		}
+
+		group := dbTypes.AggregateGroup{
+			Column: field.DBName,
+			Alias:  name,
+		}
+
+		if len(parts) == 2 {
+			bucket := dbTypes.DateBucket(parts[1])
+			switch bucket {
+			case dbTypes.DayDateBucket, dbTypes.WeekDateBucket, dbTypes.MonthDateBucket:
+			default:
+				return nil, fmt.Errorf("invalid date bucket %s, expected day, week or month", parts[1])
+			}
+
+			if field.DataType != schema.Time {
+				return nil, fmt.Errorf("field %s is not a date", name)
+			}
+
+			group.Bucket = bucket
+			group.Alias = name + "_" + string(bucket)
+		}
+
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
+
+func parseAggregateMetrics(input string, fields map[string]*schema.Field) ([]dbTypes.AggregateMetric, error) {
+	if input == "" {
+		input = string(dbTypes.CountAggregateFunction)
+	}
+
+	metrics := []dbTypes.AggregateMetric{}
+	for _, item := range strings.Split(input, ",") {
+		parts := strings.SplitN(strings.TrimSpace(item), ":", 2)
+
+		metric := dbTypes.AggregateMetric{
+			Function: dbTypes.AggregateFunction(parts[0]),
+			Alias:    parts[0],
+		}
+
+		switch metric.Function {
+		case dbTypes.CountAggregateFunction, dbTypes.SumAggregateFunction, dbTypes.AvgAggregateFunction, dbTypes.MinAggregateFunction, dbTypes.MaxAggregateFunction:
+		default:
+			return nil, fmt.Errorf("unsupported metric %s", parts[0])
+		}
+
+		if len(parts) == 2 {
+			field, ok := fields[parts[1]]
+			if !ok {
+				return nil, fmt.Errorf("unknown metric field %s", parts[1])
+			}
+
+			isNumeric := field.DataType == schema.Int || field.DataType == schema.Uint || field.DataType == schema.Float
+			if metric.Function != dbTypes.CountAggregateFunction && !isNumeric && field.DataType != schema.Time {
+				return nil, fmt.Errorf("field %s is not numeric", parts[1])
+			}
+
+			if (metric.Function == dbTypes.SumAggregateFunction || metric.Function == dbTypes.AvgAggregateFunction) && !isNumeric {
+				return nil, fmt.Errorf("field %s is not numeric", parts[1])
+			}
+
+			metric.Column = field.DBName
+			metric.Alias = parts[0] + "_" + parts[1]
+		} else if metric.Function != dbTypes.CountAggregateFunction {
+			return nil, fmt.Errorf("metric %s requires a field, e.g. %s:price", parts[0], parts[0])
+		}
+
+		metrics = append(metrics, metric)
+	}
+
+	return metrics, nil
+}
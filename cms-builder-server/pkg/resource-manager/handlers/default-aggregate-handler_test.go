@@ -0,0 +1,169 @@
+package resourcemanager_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	rmHandlers "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/handlers"
+	testPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/testing"
+)
+
+func TestDefaultAggregateHandler(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+
+	tests := []struct {
+		name           string
+		method         string
+		queryParams    map[string]string
+		user           *authModels.User
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Count Without Groups",
+			method:         http.MethodGet,
+			queryParams:    map[string]string{},
+			user:           bed.AdminUser,
+			expectedStatus: http.StatusOK,
+			expectedBody:   "\"count\"",
+		},
+		{
+			name:           "Group By Field With Metrics",
+			method:         http.MethodGet,
+			queryParams:    map[string]string{"group_by": "field1", "metrics": "count,max:id,sum:id"},
+			user:           bed.AdminUser,
+			expectedStatus: http.StatusOK,
+			expectedBody:   "\"sum_id\"",
+		},
+		{
+			name:           "Group By Date Bucket",
+			method:         http.MethodGet,
+			queryParams:    map[string]string{"group_by": "created_at:month"},
+			user:           bed.AdminUser,
+			expectedStatus: http.StatusOK,
+			expectedBody:   time.Now().Format("2006-01"),
+		},
+		{
+			name:           "Unknown Group Field",
+			method:         http.MethodGet,
+			queryParams:    map[string]string{"group_by": "unknown"},
+			user:           bed.AdminUser,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "unknown group_by field unknown",
+		},
+		{
+			name:           "Bucket On Non Date Field",
+			method:         http.MethodGet,
+			queryParams:    map[string]string{"group_by": "field1:day"},
+			user:           bed.AdminUser,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "field field1 is not a date",
+		},
+		{
+			name:           "Sum On Non Numeric Field",
+			method:         http.MethodGet,
+			queryParams:    map[string]string{"metrics": "sum:field1"},
+			user:           bed.AdminUser,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "field field1 is not numeric",
+		},
+		{
+			name:           "Unknown Filter Field",
+			method:         http.MethodGet,
+			queryParams:    map[string]string{"nope": "1"},
+			user:           bed.AdminUser,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Unknown filter field nope",
+		},
+		{
+			name:           "Invalid Method",
+			method:         http.MethodPost,
+			queryParams:    map[string]string{},
+			user:           bed.AdminUser,
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedBody:   "Method not allowed",
+		},
+		{
+			name:           "Unauthorized User",
+			method:         http.MethodGet,
+			queryParams:    map[string]string{},
+			user:           bed.NoRoleUser,
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "User is not allowed to read this resource",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bed := testPkg.SetupHandlerTestBed()
+
+			for i := 0; i < 3; i++ {
+				instance := testPkg.CreateMockResourceInstance(bed.AdminUser.ID)
+				bed.Db.DB.Create(&instance)
+			}
+
+			router := mux.NewRouter()
+			router.HandleFunc("/mock-struct/aggregate", rmHandlers.DefaultAggregateHandler(bed.Src, bed.Db))
+
+			req := testPkg.CreateTestRequest(t, tt.method, "/mock-struct/aggregate", "", true, tt.user, bed.Logger)
+			q := req.URL.Query()
+			for key, value := range tt.queryParams {
+				q.Add(key, value)
+			}
+			req.URL.RawQuery = q.Encode()
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestDefaultAggregateHandler_UserBinding(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+
+	for i := 0; i < 2; i++ {
+		instance := testPkg.CreateMockResourceInstance(bed.VisitorUser.ID)
+		instance.Field2 = "bound"
+		bed.Db.DB.Create(&instance)
+	}
+
+	for i := 0; i < 4; i++ {
+		instance := testPkg.CreateMockResourceInstance(bed.AdminUser.ID)
+		instance.Field2 = "bound"
+		bed.Db.DB.Create(&instance)
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/mock-struct/aggregate", rmHandlers.DefaultAggregateHandler(bed.Src, bed.Db))
+
+	count := func(user *authModels.User, query string) float64 {
+		req := testPkg.CreateTestRequest(t, http.MethodGet, "/mock-struct/aggregate?"+query, "", true, user, bed.Logger)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var response struct {
+			Data []map[string]interface{} `json:"data"`
+		}
+		err := json.Unmarshal(rr.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Len(t, response.Data, 1)
+
+		return response.Data[0]["count"].(float64)
+	}
+
+	// Visitor only aggregates their own instances, even when filtering by another owner
+	assert.Equal(t, float64(2), count(bed.VisitorUser, "field2=bound"))
+	assert.Equal(t, float64(2), count(bed.VisitorUser, "field2=bound&created_by_id=1"))
+	assert.GreaterOrEqual(t, count(bed.AdminUser, "field2=bound"), float64(6))
+}
@@ -2,6 +2,7 @@ package resourcemanager
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
@@ -78,7 +79,22 @@ var DefaultCreateHandler rmTypes.ApiFunction = func(a *rmTypes.Resource, db *dbT
 		}
 
 		// 8. Create Instance in Database
-		err = dbQueries.Create(r.Context(), log, db, instance, user, requestId)
+		create := func() error {
+			if a.Tree {
+				return createTreeNode(r.Context(), log, db, a, instance, user, user.HasRole(authConstants.AdminRole), requestId)
+			}
+			return dbQueries.Create(r.Context(), log, db, instance, user, requestId)
+		}
+
+		if a.Slug != nil {
+			err = createWithSlug(r.Context(), db, a, instance, create)
+		} else {
+			err = create()
+		}
+		if errors.Is(err, ErrTreeParentNotFound) {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, err.Error())
+			return
+		}
 		if err != nil {
 			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error creating resource")
 			return
@@ -1,6 +1,7 @@
 package resourcemanager
 
 import (
+	"errors"
 	"net/http"
 
 	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
@@ -54,8 +55,44 @@ var DefaultDeleteHandler rmTypes.ApiFunction = func(a *rmTypes.Resource, db *dbT
 			return
 		}
 
+		// 4. Reject Deletes While Another User Holds The Lock
+		if !checkLock(w, r, a, db, instance) {
+			return
+		}
+
+		// Children would be left without a parent
+		if a.Tree {
+			err = ensureTreeLeaf(r.Context(), db, a, svrUtils.GetUrlParam("id", r))
+			if errors.Is(err, ErrTreeHasChildren) {
+				svrUtils.SendJsonResponse(w, http.StatusConflict, nil, err.Error())
+				return
+			}
+			if err != nil {
+				log.Error().Err(err).Msg("Error finding children")
+				svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error deleting resource")
+				return
+			}
+		}
+
+		// Referencing records restrict the delete, or are deleted or unlinked with it
+		restricting, err := FindRestrictingRecords(r.Context(), db, a, instance)
+		if err != nil {
+			log.Error().Err(err).Msg("Error finding referencing records")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error deleting resource")
+			return
+		}
+
+		if len(restricting) > 0 {
+			svrUtils.SendJsonResponse(w, http.StatusConflict, restricting, ErrDeleteRestricted.Error())
+			return
+		}
+
 		// 5. Delete Instance
-		err = dbQueries.Delete(r.Context(), log, db, instance, user, requestId)
+		err = DeleteWithReferences(r.Context(), log, db, a, instance, user, requestId)
+		if errors.Is(err, ErrDeleteRestricted) {
+			svrUtils.SendJsonResponse(w, http.StatusConflict, nil, err.Error())
+			return
+		}
 		if err != nil {
 			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error deleting resource")
 			return
@@ -48,9 +48,43 @@ var DefaultDetailHandler rmTypes.ApiFunction = func(a *rmTypes.Resource, db *dbT
 			return
 		}
 
+		// 4. Localize Translatable Fields
+		locale, err := ResolveLocale(r, a)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, err.Error())
+			return
+		}
+
+		data, err := LocalizeInstances(r.Context(), db, a, instance, locale)
+		if err != nil {
+			log.Error().Err(err).Msgf("Error localizing instance")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error localizing instance")
+			return
+		}
+		setContentLanguage(w, locale)
+
+		// 5. Add Lock Information
+		lock, err := FindActiveLock(r.Context(), db, a, instanceId(instance))
+		if err != nil {
+			log.Error().Err(err).Msgf("Error finding lock")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error finding lock")
+			return
+		}
+
+		if lock != nil {
+			output, err := rmTypes.InterfaceToMap(data)
+			if err != nil {
+				log.Error().Err(err).Msgf("Error adding lock")
+				svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error adding lock")
+				return
+			}
+			output["lock"] = lock
+			data = output
+		}
+
 		msg := a.ResourceNames.Singular + " Detail"
 
-		// 5. Send Success Response
-		svrUtils.SendJsonResponse(w, http.StatusOK, instance, msg)
+		// 6. Send Success Response
+		svrUtils.SendJsonResponse(w, http.StatusOK, data, msg)
 	}
 }
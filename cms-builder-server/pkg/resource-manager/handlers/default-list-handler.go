@@ -14,9 +14,10 @@ import (
 // excludedQueryKeys defines URL query parameters that should be ignored
 // and not applied as simple database equality filters.
 var excludedQueryKeys = map[string]bool{
-	"page":  true,
-	"limit": true,
-	"order": true,
+	"page":   true,
+	"limit":  true,
+	"order":  true,
+	"locale": true,
 }
 
 // DefaultListHandler handles the retrieval of a list of resources.
@@ -47,6 +48,12 @@ var DefaultListHandler rmTypes.ApiFunction = func(a *rmTypes.Resource, db *dbTyp
 			return
 		}
 
+		locale, err := ResolveLocale(r, a)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, err.Error())
+			return
+		}
+
 		// 4. Create Slice for Model Instances
 		instances, err := a.GetSlice()
 		if err != nil {
@@ -62,6 +69,13 @@ var DefaultListHandler rmTypes.ApiFunction = func(a *rmTypes.Resource, db *dbTyp
 			Limit: queryParams.Limit,
 		}
 		order := queryParams.Order
+		if svrUtils.GetQueryParam(r, "order") == "" && a.Display != nil && a.Display.DefaultSort != "" {
+			order, err = svrUtils.ValidateOrderParam(a.Display.DefaultSort)
+			if err != nil {
+				svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, err.Error())
+				return
+			}
+		}
 
 		filters := map[string]interface{}{}
 
@@ -91,10 +105,19 @@ var DefaultListHandler rmTypes.ApiFunction = func(a *rmTypes.Resource, db *dbTyp
 			return
 		}
 
-		// 9. Generate Success Message
+		// 9. Localize Translatable Fields
+		data, err := LocalizeInstances(r.Context(), db, a, instances, locale)
+		if err != nil {
+			log.Error().Err(err).Msgf("Error localizing instances")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error localizing instances")
+			return
+		}
+		setContentLanguage(w, locale)
+
+		// 10. Generate Success Message
 		msg := a.ResourceNames.Plural + " List"
 
-		// 10. Send Paginated Response
-		svrUtils.SendJsonResponseWithPagination(w, http.StatusOK, instances, msg, pagination)
+		// 11. Send Paginated Response
+		svrUtils.SendJsonResponseWithPagination(w, http.StatusOK, data, msg, pagination)
 	}
 }
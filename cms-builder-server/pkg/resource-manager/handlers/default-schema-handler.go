@@ -9,6 +9,8 @@ import (
 	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
 )
 
+// DefaultSchemaHandler returns the JSON schema of the resource model, including
+// the admin display metadata under the x-display key.
 func DefaultSchemaHandler(app *rmTypes.Resource) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 
@@ -20,6 +22,11 @@ func DefaultSchemaHandler(app *rmTypes.Resource) http.HandlerFunc {
 
 		msg := "Schema for " + app.ResourceNames.Singular
 
+		if app.JsonSchema != nil {
+			svrUtils.SendJsonResponse(w, http.StatusOK, app.JsonSchema, msg)
+			return
+		}
+
 		schema := jsonschema.Reflect(app.Model)
 		svrUtils.SendJsonResponse(w, http.StatusOK, schema, msg)
 	}
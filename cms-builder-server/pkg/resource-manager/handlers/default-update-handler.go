@@ -19,7 +19,6 @@ var DefaultUpdateHandler rmTypes.ApiFunction = func(a *rmTypes.Resource, db *dbT
 		requestCtx := svrUtils.GetRequestContext(r)
 		log := requestCtx.Logger
 		user := requestCtx.User
-		requestId := requestCtx.RequestId
 		isAdmin := user.HasRole(authConstants.AdminRole)
 
 		// 1. Validate Request Method
@@ -57,61 +56,106 @@ var DefaultUpdateHandler rmTypes.ApiFunction = func(a *rmTypes.Resource, db *dbT
 			return
 		}
 
+		// 4. Reject Writes While Another User Holds The Lock
+		if !checkLock(w, r, a, db, instance) {
+			return
+		}
+
 		previousState := a.GetOne()
 		_ = dbQueries.FindOne(r.Context(), log, db, &previousState, filters, []string{})
 
-		// 5. Format Request Body and Filter Keys
-		body, err := svrUtils.FormatRequestBody(r, filterKeys)
-		if err != nil {
-			log.Error().Err(err).Msg("Error formatting request body")
-			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, "Invalid request body")
-			return
-		}
+		updateInstance(w, r, a, db, instance, previousState)
+	}
+}
 
-		// 6. Add User Information
-		body["UpdatedByID"] = user.ID
+// updateInstance applies the request body to the given instance, validates it and
+// saves it with an update entry in the database log.
+func updateInstance(w http.ResponseWriter, r *http.Request, a *rmTypes.Resource, db *dbTypes.DatabaseConnection, instance interface{}, previousState interface{}) {
+	requestCtx := svrUtils.GetRequestContext(r)
+	log := requestCtx.Logger
+	user := requestCtx.User
+	requestId := requestCtx.RequestId
+
+	// 5. Format Request Body and Filter Keys
+	body, err := svrUtils.FormatRequestBody(r, filterKeys)
+	if err != nil {
+		log.Error().Err(err).Msg("Error formatting request body")
+		svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, "Invalid request body")
+		return
+	}
 
-		// 7. Marshal Body to JSON
-		bodyBytes, err := json.Marshal(body)
-		if err != nil {
-			fmt.Printf("Error marshalling request body: %v\n", err)
-			log.Error().Err(err).Msg("Error marshalling request body")
-			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Invalid request body")
-			return
+	// Tree nodes are moved through the move and reorder endpoints
+	if a.Tree {
+		for _, key := range treeKeys {
+			delete(body, key)
 		}
+	}
+
+	// 6. Add User Information
+	body["UpdatedByID"] = user.ID
+
+	// 7. Marshal Body to JSON
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		fmt.Printf("Error marshalling request body: %v\n", err)
+		log.Error().Err(err).Msg("Error marshalling request body")
+		svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Invalid request body")
+		return
+	}
 
-		// 8. Unmarshal Body into Instance
-		err = json.Unmarshal(bodyBytes, &instance)
+	// 8. Unmarshal Body into Instance
+	err = json.Unmarshal(bodyBytes, &instance)
+	if err != nil {
+		fmt.Printf("Error unmarshalling request body: %v\n", err)
+		svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Invalid request body")
+		return
+	}
+
+	// 9. Run Validations
+	validationErrors := a.Validate(instance, log)
+	if len(validationErrors.Errors) > 0 {
+		svrUtils.SendJsonResponse(w, http.StatusBadRequest, validationErrors, "Validation failed")
+		return
+	}
+
+	// Slugs follow the source field or an explicit change, the old slug redirects to the new one
+	previousSlug := ""
+	if a.Slug != nil {
+		previousSlug, err = updateSlug(r.Context(), db, a, instance, previousState)
 		if err != nil {
-			fmt.Printf("Error unmarshalling request body: %v\n", err)
-			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Invalid request body")
+			log.Error().Err(err).Msg("Error generating slug")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error generating slug")
 			return
 		}
+	}
 
-		// 9. Run Validations
-		validationErrors := a.Validate(instance, log)
-		if len(validationErrors.Errors) > 0 {
-			svrUtils.SendJsonResponse(w, http.StatusBadRequest, validationErrors, "Validation failed")
-			return
-		}
+	// 10. Find differences with existing instance
+	differences := utils.CompareInterfaces(previousState, instance)
+	if diffMap, ok := differences.(map[string]interface{}); ok && len(diffMap) == 0 {
+		svrUtils.SendJsonResponse(w, http.StatusOK, instance, a.ResourceNames.Singular+" is up to date")
+		return
+	}
 
-		// 10. Find differences with existing instance
-		differences := utils.CompareInterfaces(previousState, instance)
-		if diffMap, ok := differences.(map[string]interface{}); ok && len(diffMap) == 0 {
-			svrUtils.SendJsonResponse(w, http.StatusOK, instance, a.ResourceNames.Singular+" is up to date")
-			return
-		}
+	// 11. Create Instance in Database
+	err = dbQueries.Update(r.Context(), log, db, instance, user, differences, requestId)
+	if a.Slug != nil && isUniqueViolation(err) {
+		svrUtils.SendJsonResponse(w, http.StatusConflict, nil, ErrSlugTaken.Error())
+		return
+	}
+	if err != nil {
+		svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error updating resource")
+		return
+	}
 
-		// 11. Create Instance in Database
-		err = dbQueries.Update(r.Context(), log, db, instance, user, differences, requestId)
+	if a.Slug != nil {
+		err = saveSlugRedirect(r.Context(), log, db, a, instance, previousSlug, user, requestId)
 		if err != nil {
-			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error updating resource")
-			return
+			log.Error().Err(err).Msg("Error saving slug redirect")
 		}
+	}
 
-		msg := a.ResourceNames.Singular + " has been updated"
+	msg := a.ResourceNames.Singular + " has been updated"
 
-		// 12. Send Success Response
-		svrUtils.SendJsonResponse(w, http.StatusOK, instance, msg)
-	}
+	// 12. Send Success Response
+	svrUtils.SendJsonResponse(w, http.StatusOK, instance, msg)
 }
@@ -0,0 +1,334 @@
+package resourcemanager
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm/schema"
+
+	dbQueries "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/queries"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
+	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
+)
+
+// deliveryQueryKeys are the query parameters of the delivery list that are not filters.
+var deliveryQueryKeys = map[string]bool{
+	"page":   true,
+	"limit":  true,
+	"order":  true,
+	"locale": true,
+}
+
+// DefaultDeliveryListHandler sends the published records of a resource to the public.
+// It never applies user binding, so only whitelisted fields can be filtered, sorted and sent.
+var DefaultDeliveryListHandler rmTypes.ApiFunction = func(a *rmTypes.Resource, db *dbTypes.DatabaseConnection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestCtx := svrUtils.GetRequestContext(r)
+		log := requestCtx.Logger
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodGet)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		// 2. Serve Cached Response
+		locale, err := ResolveLocale(r, a)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, err.Error())
+			return
+		}
+
+		key := deliveryCacheKey(r, locale)
+		if entry, ok := a.DeliveryCache.Get(key); ok {
+			sendDeliveryEntry(w, r, a, entry, "HIT")
+			return
+		}
+
+		// 3. Parse Query Parameters
+		queryParams, err := svrUtils.GetRequestQueryParams(r)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, err.Error())
+			return
+		}
+
+		fields, err := getModelFields(a, db)
+		if err != nil {
+			log.Error().Err(err).Msg("Error parsing model fields")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, err.Error())
+			return
+		}
+
+		order, err := deliveryOrder(a, fields, svrUtils.GetQueryParam(r, "order"))
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, err.Error())
+			return
+		}
+
+		// 4. Construct Filters (Published records only)
+		filters, err := deliveryFilters(a, fields)
+		if err != nil {
+			log.Error().Err(err).Msg("Error building delivery filters")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, err.Error())
+			return
+		}
+
+		for key, values := range r.URL.Query() {
+			if deliveryQueryKeys[key] {
+				continue
+			}
+
+			// The published field is never filtered by the client, drafts would be sent otherwise
+			field, ok := fields[key]
+			if !ok || !isDelivered(a, field) || isPublishedField(a, fields, field) {
+				svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, fmt.Sprintf("Cannot filter by %s", key))
+				return
+			}
+
+			filters[field.DBName] = values[0]
+		}
+
+		// 5. Find Instances
+		instances, err := a.GetSlice()
+		if err != nil {
+			log.Error().Err(err).Msgf("Error creating slice for model")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, err.Error())
+			return
+		}
+
+		pagination := &dbTypes.Pagination{
+			Total: 0,
+			Page:  queryParams.Page,
+			Limit: queryParams.Limit,
+		}
+
+		err = dbQueries.FindMany(r.Context(), log, db, instances, pagination, order, filters, []string{})
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error finding instances")
+			return
+		}
+
+		// 6. Localize and Keep Whitelisted Fields
+		localized, err := LocalizeInstances(r.Context(), db, a, instances, locale)
+		if err != nil {
+			log.Error().Err(err).Msgf("Error localizing instances")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error localizing instances")
+			return
+		}
+
+		items, err := toItems(localized)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, err.Error())
+			return
+		}
+
+		for i, item := range items {
+			items[i] = deliveredItem(a, item)
+		}
+
+		// 7. Cache and Send Response
+		body := svrUtils.MarshalJsonResponse(http.StatusOK, items, a.ResourceNames.Plural+" List", pagination)
+		sendDeliveryEntry(w, r, a, cacheDeliveryResponse(a, key, locale, body), "MISS")
+	}
+}
+
+// DefaultDeliveryDetailHandler sends a published record of a resource to the public,
+// found by id or, on the by-slug route, by slug. Singletons send their only record.
+var DefaultDeliveryDetailHandler rmTypes.ApiFunction = func(a *rmTypes.Resource, db *dbTypes.DatabaseConnection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestCtx := svrUtils.GetRequestContext(r)
+		log := requestCtx.Logger
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodGet)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		// 2. Serve Cached Response
+		locale, err := ResolveLocale(r, a)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, err.Error())
+			return
+		}
+
+		key := deliveryCacheKey(r, locale)
+		if entry, ok := a.DeliveryCache.Get(key); ok {
+			sendDeliveryEntry(w, r, a, entry, "HIT")
+			return
+		}
+
+		// 3. Construct Filters (Published records only)
+		fields, err := getModelFields(a, db)
+		if err != nil {
+			log.Error().Err(err).Msg("Error parsing model fields")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, err.Error())
+			return
+		}
+
+		filters, err := deliveryFilters(a, fields)
+		if err != nil {
+			log.Error().Err(err).Msg("Error building delivery filters")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, err.Error())
+			return
+		}
+
+		if slug := svrUtils.GetUrlParam("slug", r); slug != "" && a.Slug != nil {
+			column, err := slugColumn(a, db)
+			if err != nil {
+				svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, err.Error())
+				return
+			}
+			filters[column] = slug
+		} else if !a.Singleton {
+			filters["id"] = svrUtils.GetUrlParam("id", r)
+		}
+
+		// 4. Find Instance
+		instance := a.GetOne()
+		err = dbQueries.FindOne(r.Context(), log, db, &instance, filters, []string{})
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusNotFound, nil, "Instance not found")
+			return
+		}
+
+		// 5. Localize and Keep Whitelisted Fields
+		localized, err := LocalizeInstances(r.Context(), db, a, instance, locale)
+		if err != nil {
+			log.Error().Err(err).Msgf("Error localizing instance")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error localizing instance")
+			return
+		}
+
+		item, err := rmTypes.InterfaceToMap(localized)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, err.Error())
+			return
+		}
+
+		// 6. Cache and Send Response
+		body := svrUtils.MarshalJsonResponse(http.StatusOK, deliveredItem(a, item), a.ResourceNames.Singular+" Detail", nil)
+		sendDeliveryEntry(w, r, a, cacheDeliveryResponse(a, key, locale, body), "MISS")
+	}
+}
+
+// deliveryFilters returns the filters that keep unpublished records out of the delivery API.
+func deliveryFilters(a *rmTypes.Resource, fields map[string]*schema.Field) (map[string]interface{}, error) {
+	filters := map[string]interface{}{}
+	if a.Delivery.Published == "" {
+		return filters, nil
+	}
+
+	field, ok := fields[a.FieldNames[a.Delivery.Published]]
+	if !ok {
+		return nil, fmt.Errorf("published field %s not found in model", a.Delivery.Published)
+	}
+
+	if field.DataType == schema.Bool {
+		filters[field.DBName] = true
+	} else {
+		filters[field.DBName+" <= ?"] = time.Now()
+	}
+
+	return filters, nil
+}
+
+// isPublishedField returns whether the field is the one deciding which records are published.
+func isPublishedField(a *rmTypes.Resource, fields map[string]*schema.Field, field *schema.Field) bool {
+	if a.Delivery.Published == "" {
+		return false
+	}
+
+	published, ok := fields[a.FieldNames[a.Delivery.Published]]
+	return ok && published.DBName == field.DBName
+}
+
+// deliveryOrder validates the order parameter against the delivered fields and returns the order clause.
+func deliveryOrder(a *rmTypes.Resource, fields map[string]*schema.Field, input string) (string, error) {
+	if input == "" {
+		return "id desc", nil
+	}
+
+	order := []string{}
+	for _, name := range strings.Split(input, ",") {
+		direction := ""
+		if strings.HasPrefix(name, "-") {
+			direction = " desc"
+			name = strings.TrimPrefix(name, "-")
+		}
+
+		field, ok := fields[name]
+		if !ok || !isDelivered(a, field) {
+			return "", fmt.Errorf("cannot order by %s", name)
+		}
+
+		order = append(order, field.DBName+direction)
+	}
+
+	return strings.Join(order, ","), nil
+}
+
+func isDelivered(a *rmTypes.Resource, field *schema.Field) bool {
+	return slices.Contains(a.Delivery.Fields, a.FieldNames[field.Name])
+}
+
+// deliveredItem keeps the whitelisted fields of the given item.
+func deliveredItem(a *rmTypes.Resource, item map[string]interface{}) map[string]interface{} {
+	output := map[string]interface{}{}
+	for _, key := range a.Delivery.Fields {
+		if value, ok := item[key]; ok {
+			output[key] = value
+		}
+	}
+	return output
+}
+
+func deliveryCacheKey(r *http.Request, locale string) string {
+	return r.URL.Path + "?" + r.URL.Query().Encode() + "#" + locale
+}
+
+func cacheDeliveryResponse(a *rmTypes.Resource, key string, locale string, body []byte) *rmTypes.DeliveryCacheEntry {
+	sum := sha256.Sum256(body)
+
+	entry := &rmTypes.DeliveryCacheEntry{
+		Key:       key,
+		Status:    http.StatusOK,
+		Body:      body,
+		ETag:      `"` + hex.EncodeToString(sum[:16]) + `"`,
+		Locale:    locale,
+		ExpiresAt: time.Now().Add(a.Delivery.MaxAge),
+	}
+
+	a.DeliveryCache.Set(entry)
+	return entry
+}
+
+// sendDeliveryEntry writes the given response with cache headers, or a bare
+// not modified status when the client already holds it.
+func sendDeliveryEntry(w http.ResponseWriter, r *http.Request, a *rmTypes.Resource, entry *rmTypes.DeliveryCacheEntry, cacheStatus string) {
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(a.Delivery.MaxAge.Seconds())))
+	w.Header().Set("ETag", entry.ETag)
+	w.Header().Set("Vary", "Accept-Language")
+	w.Header().Set("X-Cache", cacheStatus)
+	setContentLanguage(w, entry.Locale)
+
+	for _, tag := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == entry.ETag || tag == "*" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(entry.Status)
+	w.Write(entry.Body)
+}
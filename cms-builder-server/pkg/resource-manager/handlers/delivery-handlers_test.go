@@ -0,0 +1,153 @@
+package resourcemanager_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
+	testPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/testing"
+)
+
+type NewsStory struct {
+	authModels.SystemData
+	Headline  string `json:"headline"`
+	Body      string `json:"body"`
+	Notes     string `json:"notes"`
+	Published bool   `json:"published"`
+}
+
+func TestDeliveryHandlers(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+	bed.Db.DB.Unscoped().Where("1 = 1").Delete(&NewsStory{})
+
+	resource, err := bed.Mgr.AddResource(&rmTypes.ResourceConfig{
+		Model: NewsStory{},
+		Delivery: &rmTypes.DeliveryConfig{
+			Fields:    []string{"Headline", "Body"},
+			Published: "Published",
+		},
+	})
+	assert.NoError(t, err)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/content/news-stories", resource.Api.DeliveryList(resource, bed.Db))
+	router.HandleFunc("/content/news-stories/{id}", resource.Api.DeliveryDetail(resource, bed.Db))
+
+	send := func(path string, headers map[string]string) *httptest.ResponseRecorder {
+		req := testPkg.CreateTestRequest(t, http.MethodGet, path, "", false, nil, bed.Logger)
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	list := func(rr *httptest.ResponseRecorder) []map[string]interface{} {
+		response := struct {
+			Data []map[string]interface{} `json:"data"`
+		}{}
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		return response.Data
+	}
+
+	published := NewsStory{Headline: "Launch", Body: "We are live", Notes: "Internal", Published: true}
+	draft := NewsStory{Headline: "Draft", Body: "Not yet", Published: false}
+	assert.NoError(t, bed.Db.DB.Create(&published).Error)
+	assert.NoError(t, bed.Db.DB.Create(&draft).Error)
+
+	t.Run("List sends published records and whitelisted fields", func(t *testing.T) {
+		rr := send("/content/news-stories", nil)
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.Equal(t, "MISS", rr.Header().Get("X-Cache"))
+		assert.Equal(t, "public, max-age=300", rr.Header().Get("Cache-Control"))
+		assert.NotEmpty(t, rr.Header().Get("ETag"))
+
+		data := list(rr)
+		assert.Len(t, data, 1)
+		assert.Equal(t, "Launch", data[0]["headline"])
+		assert.Contains(t, data[0], "ID")
+		assert.NotContains(t, data[0], "notes")
+		assert.NotContains(t, data[0], "createdById")
+	})
+
+	t.Run("Responses are cached and revalidated", func(t *testing.T) {
+		first := send("/content/news-stories", nil)
+		assert.Equal(t, "HIT", first.Header().Get("X-Cache"))
+
+		rr := send("/content/news-stories", map[string]string{"If-None-Match": first.Header().Get("ETag")})
+		assert.Equal(t, http.StatusNotModified, rr.Code)
+		assert.Empty(t, rr.Body.String())
+	})
+
+	t.Run("Writes purge the cache", func(t *testing.T) {
+		before := send("/content/news-stories", nil)
+
+		assert.NoError(t, bed.Db.DB.Model(&draft).Update("published", true).Error)
+
+		rr := send("/content/news-stories", nil)
+		assert.Equal(t, "MISS", rr.Header().Get("X-Cache"))
+		assert.NotEqual(t, before.Header().Get("ETag"), rr.Header().Get("ETag"))
+		assert.Len(t, list(rr), 2)
+
+		assert.NoError(t, bed.Db.DB.Model(&draft).Update("published", false).Error)
+	})
+
+	t.Run("Rolled back writes keep the cache", func(t *testing.T) {
+		send("/content/news-stories", nil)
+
+		failing := "test:fail_news_stories"
+		err := bed.Db.DB.Callback().Update().After("gorm:after_update").Before("gorm:commit_or_rollback_transaction").Register(failing, func(tx *gorm.DB) {
+			tx.AddError(errors.New("update failed"))
+		})
+		assert.NoError(t, err)
+		defer bed.Db.DB.Callback().Update().Remove(failing)
+
+		assert.Error(t, bed.Db.DB.Model(&draft).Update("published", true).Error)
+
+		rr := send("/content/news-stories", nil)
+		assert.Equal(t, "HIT", rr.Header().Get("X-Cache"))
+		assert.Len(t, list(rr), 1)
+	})
+
+	t.Run("Filters and order are limited to delivered fields", func(t *testing.T) {
+		rr := send("/content/news-stories?headline=Launch&order=-headline", nil)
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.Len(t, list(rr), 1)
+
+		rr = send("/content/news-stories?notes=Internal", nil)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+		rr = send("/content/news-stories?order=notes", nil)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Published field can't be filtered even when delivered", func(t *testing.T) {
+		fields := resource.Delivery.Fields
+		resource.Delivery.Fields = append(slices.Clone(fields), "Published")
+		defer func() { resource.Delivery.Fields = fields }()
+
+		rr := send("/content/news-stories?published=false", nil)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.NotContains(t, rr.Body.String(), "Not yet")
+	})
+
+	t.Run("Detail hides unpublished records", func(t *testing.T) {
+		rr := send(fmt.Sprintf("/content/news-stories/%d", published.ID), nil)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.NotContains(t, rr.Body.String(), "Internal")
+
+		rr = send(fmt.Sprintf("/content/news-stories/%d", draft.ID), nil)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
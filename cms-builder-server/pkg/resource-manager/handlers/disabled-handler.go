@@ -0,0 +1,17 @@
+package resourcemanager
+
+import (
+	"net/http"
+
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
+	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
+)
+
+// DisabledHandler answers as if the route did not exist. Resources checking access per record in
+// their own handlers use it for the default routes that would bypass the check.
+var DisabledHandler rmTypes.ApiFunction = func(a *rmTypes.Resource, db *dbTypes.DatabaseConnection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		svrUtils.SendJsonResponse(w, http.StatusNotFound, nil, "Not found")
+	}
+}
@@ -0,0 +1,296 @@
+package resourcemanager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	dbQueries "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/queries"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	rmModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/models"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
+	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
+)
+
+var (
+	ErrRecordLocked = errors.New("record is locked by another user")
+	ErrLockNotHeld  = errors.New("record is not locked by this user")
+)
+
+var (
+	DefaultLockTTL = 2 * time.Minute
+	MaxLockTTL     = 15 * time.Minute
+)
+
+// LockInput is the optional request body of a lock request, the TTL is given in seconds.
+type LockInput struct {
+	TTL int `json:"ttl"`
+}
+
+// DefaultLockHandler acquires the advisory lock of a record, or extends it when the user already holds it.
+// Calling it periodically works as a heartbeat, expired locks are taken over by the next caller.
+var DefaultLockHandler rmTypes.ApiFunction = func(a *rmTypes.Resource, db *dbTypes.DatabaseConnection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestCtx := svrUtils.GetRequestContext(r)
+		log := requestCtx.Logger
+		user := requestCtx.User
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodPost)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		// 2. Check Permissions
+		if !authUtils.UserIsAllowed(a.Permissions, user.GetRoles(), authConstants.OperationUpdate, a.ResourceNames.Singular, log) {
+			svrUtils.SendJsonResponse(w, http.StatusForbidden, nil, "User is not allowed to update this resource")
+			return
+		}
+
+		// 3. Parse Request Body
+		bodyBytes, err := svrUtils.ReadRequestBody(r)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, "Invalid request body")
+			return
+		}
+
+		input := LockInput{}
+		if len(bodyBytes) > 0 {
+			err = json.Unmarshal(bodyBytes, &input)
+			if err != nil || input.TTL < 0 {
+				svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, "Invalid lock ttl")
+				return
+			}
+		}
+
+		// 4. Find Instance (User Binding)
+		instance, ok := findLockedInstance(w, r, a, db)
+		if !ok {
+			return
+		}
+
+		// 5. Acquire Lock
+		lock, err := acquireLock(r.Context(), db, a, instance, user, lockTTL(input.TTL))
+		if errors.Is(err, ErrRecordLocked) {
+			svrUtils.SendJsonResponse(w, http.StatusConflict, lock, err.Error())
+			return
+		}
+		if err != nil {
+			log.Error().Err(err).Msg("Error acquiring lock")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error acquiring lock")
+			return
+		}
+
+		svrUtils.SendJsonResponse(w, http.StatusOK, lock, a.ResourceNames.Singular+" is locked")
+	}
+}
+
+// DefaultUnlockHandler releases the lock of a record. Admins can release locks held by other users.
+var DefaultUnlockHandler rmTypes.ApiFunction = func(a *rmTypes.Resource, db *dbTypes.DatabaseConnection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestCtx := svrUtils.GetRequestContext(r)
+		log := requestCtx.Logger
+		user := requestCtx.User
+		isAdmin := user.HasRole(authConstants.AdminRole)
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodDelete)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		// 2. Check Permissions
+		if !authUtils.UserIsAllowed(a.Permissions, user.GetRoles(), authConstants.OperationUpdate, a.ResourceNames.Singular, log) {
+			svrUtils.SendJsonResponse(w, http.StatusForbidden, nil, "User is not allowed to update this resource")
+			return
+		}
+
+		// 3. Find Instance (User Binding)
+		instance, ok := findLockedInstance(w, r, a, db)
+		if !ok {
+			return
+		}
+
+		// 4. Release Lock
+		lock, err := FindActiveLock(r.Context(), db, a, instanceId(instance))
+		if err != nil {
+			log.Error().Err(err).Msg("Error finding lock")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error releasing lock")
+			return
+		}
+
+		if lock == nil {
+			svrUtils.SendJsonResponse(w, http.StatusOK, nil, a.ResourceNames.Singular+" is not locked")
+			return
+		}
+
+		if lock.UserID != user.ID && !isAdmin {
+			svrUtils.SendJsonResponse(w, http.StatusForbidden, lock, ErrLockNotHeld.Error())
+			return
+		}
+
+		err = db.DB.WithContext(r.Context()).Unscoped().Delete(lock).Error
+		if err != nil {
+			log.Error().Err(err).Msg("Error releasing lock")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error releasing lock")
+			return
+		}
+
+		svrUtils.SendJsonResponse(w, http.StatusOK, nil, a.ResourceNames.Singular+" has been unlocked")
+	}
+}
+
+// FindActiveLock returns the unexpired lock of the given record, or nil when it is not locked.
+func FindActiveLock(ctx context.Context, db *dbTypes.DatabaseConnection, a *rmTypes.Resource, id uint) (*rmModels.RecordLock, error) {
+	locks := []rmModels.RecordLock{}
+	err := db.DB.WithContext(ctx).
+		Where("resource_name = ? AND resource_id = ? AND expires_at > ?", a.ResourceNames.Singular, fmt.Sprint(id), time.Now()).
+		Limit(1).Find(&locks).Error
+	if err != nil || len(locks) == 0 {
+		return nil, err
+	}
+	return &locks[0], nil
+}
+
+// findLockedInstance finds the record a lock request refers to, answering with not found otherwise.
+func findLockedInstance(w http.ResponseWriter, r *http.Request, a *rmTypes.Resource, db *dbTypes.DatabaseConnection) (interface{}, bool) {
+	requestCtx := svrUtils.GetRequestContext(r)
+	user := requestCtx.User
+
+	filters := map[string]interface{}{
+		"id": svrUtils.GetUrlParam("id", r),
+	}
+
+	if !(a.SkipUserBinding || user.HasRole(authConstants.AdminRole)) {
+		filters["created_by_id"] = user.ID
+	}
+
+	instance := a.GetOne()
+	err := dbQueries.FindOne(r.Context(), requestCtx.Logger, db, instance, filters, []string{})
+	if err != nil {
+		svrUtils.SendJsonResponse(w, http.StatusNotFound, nil, "Instance not found")
+		return nil, false
+	}
+
+	return instance, true
+}
+
+// acquireLock locks the given record for the user. The lock held by another user is returned
+// along with ErrRecordLocked while it has not expired.
+// Locks are not written to the database log, they change with every heartbeat and hold no content.
+func acquireLock(ctx context.Context, db *dbTypes.DatabaseConnection, a *rmTypes.Resource, instance interface{}, user *authModels.User, ttl time.Duration) (*rmModels.RecordLock, error) {
+	id := instanceId(instance)
+	lock := rmModels.RecordLock{}
+
+	err := db.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		locks := []rmModels.RecordLock{}
+		err := tx.Where("resource_name = ? AND resource_id = ?", a.ResourceNames.Singular, fmt.Sprint(id)).
+			Limit(1).Find(&locks).Error
+		if err != nil {
+			return err
+		}
+
+		if len(locks) == 0 {
+			lock = rmModels.RecordLock{
+				ResourceName: a.ResourceNames.Singular,
+				ResourceId:   fmt.Sprint(id),
+				UserID:       user.ID,
+				UserEmail:    user.Email,
+				ExpiresAt:    now.Add(ttl),
+			}
+			lock.CreatedByID = user.ID
+			lock.UpdatedByID = user.ID
+			return tx.Create(&lock).Error
+		}
+
+		lock = locks[0]
+		if lock.UserID != user.ID && lock.ExpiresAt.After(now) {
+			return ErrRecordLocked
+		}
+
+		// Expired locks are taken over
+		lock.UserID = user.ID
+		lock.UserEmail = user.Email
+		lock.ExpiresAt = now.Add(ttl)
+		lock.UpdatedByID = user.ID
+		return tx.Save(&lock).Error
+	})
+
+	// A concurrent request created the lock first
+	if isUniqueViolation(err) {
+		active, findErr := FindActiveLock(ctx, db, a, id)
+		if findErr != nil || active == nil {
+			return nil, err
+		}
+		if active.UserID != user.ID {
+			return active, ErrRecordLocked
+		}
+		return active, nil
+	}
+
+	if errors.Is(err, ErrRecordLocked) {
+		return &lock, err
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &lock, nil
+}
+
+// lockTTL returns the duration of a lock from the requested seconds, within the allowed maximum.
+func lockTTL(seconds int) time.Duration {
+	if seconds == 0 {
+		return DefaultLockTTL
+	}
+
+	ttl := time.Duration(seconds) * time.Second
+	if ttl > MaxLockTTL {
+		return MaxLockTTL
+	}
+	return ttl
+}
+
+// checkLock answers with conflict when another user holds the lock of the given record.
+// Admins force the write with the force query parameter.
+func checkLock(w http.ResponseWriter, r *http.Request, a *rmTypes.Resource, db *dbTypes.DatabaseConnection, instance interface{}) bool {
+	return checkLocks(w, r, a, db, instanceId(instance))
+}
+
+// checkLocks is checkLock for writes touching several records, any of them being locked rejects the write.
+func checkLocks(w http.ResponseWriter, r *http.Request, a *rmTypes.Resource, db *dbTypes.DatabaseConnection, ids ...uint) bool {
+	requestCtx := svrUtils.GetRequestContext(r)
+	user := requestCtx.User
+
+	if user.HasRole(authConstants.AdminRole) && r.URL.Query().Get("force") == "true" {
+		return true
+	}
+
+	for _, id := range ids {
+		lock, err := FindActiveLock(r.Context(), db, a, id)
+		if err != nil {
+			requestCtx.Logger.Error().Err(err).Msg("Error finding lock")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error finding lock")
+			return false
+		}
+
+		if lock != nil && lock.UserID != user.ID {
+			svrUtils.SendJsonResponse(w, http.StatusConflict, lock, ErrRecordLocked.Error())
+			return false
+		}
+	}
+
+	return true
+}
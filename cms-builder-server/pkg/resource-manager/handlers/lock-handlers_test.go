@@ -0,0 +1,142 @@
+package resourcemanager_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	rmModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/models"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
+	testPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/testing"
+)
+
+type DraftNote struct {
+	authModels.SystemData
+	Text string `json:"text"`
+}
+
+func TestLockHandlers(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+	bed.Db.DB.Unscoped().Where("1 = 1").Delete(&DraftNote{})
+	bed.Db.DB.Unscoped().Where("1 = 1").Delete(&rmModels.RecordLock{})
+
+	_, err := bed.Mgr.AddResource(&rmTypes.ResourceConfig{
+		Model:           DraftNote{},
+		SkipUserBinding: true,
+		Permissions: authTypes.RolePermissionMap{
+			authConstants.AdminRole:   authConstants.AllAllowedAccess,
+			authConstants.VisitorRole: authConstants.AllAllowedAccess,
+		},
+	})
+	assert.NoError(t, err)
+
+	router := mux.NewRouter()
+	for _, route := range bed.Mgr.GetRoutes("") {
+		router.HandleFunc(route.Path, route.Handler).Methods(route.Methods...)
+	}
+
+	send := func(method string, path string, body string, user *authModels.User) (*httptest.ResponseRecorder, map[string]interface{}) {
+		req := testPkg.CreateTestRequest(t, method, path, body, true, user, bed.Logger)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		response := struct {
+			Data map[string]interface{} `json:"data"`
+		}{}
+		json.Unmarshal(rr.Body.Bytes(), &response)
+		return rr, response.Data
+	}
+
+	note := DraftNote{Text: "Draft"}
+	note.CreatedByID = bed.AdminUser.ID
+	assert.NoError(t, bed.Db.DB.Create(&note).Error)
+
+	base := fmt.Sprintf("/api/draft-notes/%d", note.ID)
+
+	t.Run("Holders lock and extend, others are rejected", func(t *testing.T) {
+		rr, lock := send(http.MethodPost, base+"/lock", `{"ttl": 60}`, bed.VisitorUser)
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.Equal(t, float64(bed.VisitorUser.ID), lock["userId"])
+		firstExpiry := lock["expiresAt"]
+
+		rr, lock = send(http.MethodPost, base+"/lock", `{"ttl": 600}`, bed.VisitorUser)
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.NotEqual(t, firstExpiry, lock["expiresAt"], "Heartbeats extend the lock")
+
+		rr, lock = send(http.MethodPost, base+"/lock", "", bed.AdminUser)
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		assert.Equal(t, bed.VisitorUser.Email, lock["userEmail"])
+	})
+
+	t.Run("Detail includes the lock", func(t *testing.T) {
+		rr, data := send(http.MethodGet, base, "", bed.AdminUser)
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.Equal(t, "Draft", data["text"])
+
+		lock, ok := data["lock"].(map[string]interface{})
+		assert.True(t, ok)
+		assert.Equal(t, float64(bed.VisitorUser.ID), lock["userId"])
+	})
+
+	t.Run("Updates from non holders need an admin to force them", func(t *testing.T) {
+		rr, _ := send(http.MethodPut, base+"/update", `{"text": "Admin"}`, bed.AdminUser)
+		assert.Equal(t, http.StatusConflict, rr.Code)
+
+		rr, _ = send(http.MethodPut, base+"/update?force=true", `{"text": "Admin"}`, bed.AdminUser)
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		rr, _ = send(http.MethodPut, base+"/update", `{"text": "Visitor"}`, bed.VisitorUser)
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	})
+
+	t.Run("Admins release locks of other users", func(t *testing.T) {
+		rr, _ := send(http.MethodDelete, base+"/lock", "", bed.AdminUser)
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		rr, _ = send(http.MethodPost, base+"/lock", "", bed.AdminUser)
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		rr, _ = send(http.MethodDelete, base+"/lock", "", bed.VisitorUser)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+
+		rr, _ = send(http.MethodPut, base+"/update?force=true", `{"text": "Forced"}`, bed.VisitorUser)
+		assert.Equal(t, http.StatusConflict, rr.Code, "Only admins force updates")
+	})
+
+	t.Run("Expired locks are taken over", func(t *testing.T) {
+		err := bed.Db.DB.Model(&rmModels.RecordLock{}).
+			Where("resource_name = ? AND resource_id = ?", "DraftNote", fmt.Sprint(note.ID)).
+			Update("expires_at", time.Now().Add(-time.Minute)).Error
+		assert.NoError(t, err)
+
+		rr, data := send(http.MethodGet, base, "", bed.AdminUser)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.NotContains(t, data, "lock")
+
+		rr, lock := send(http.MethodPost, base+"/lock", "", bed.VisitorUser)
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.Equal(t, float64(bed.VisitorUser.ID), lock["userId"])
+	})
+
+	t.Run("Deletes from non holders need an admin to force them", func(t *testing.T) {
+		rr, _ := send(http.MethodDelete, base+"/delete", "", bed.AdminUser)
+		assert.Equal(t, http.StatusConflict, rr.Code)
+
+		rr, _ = send(http.MethodDelete, base+"/delete?force=true", "", bed.AdminUser)
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	})
+
+	t.Run("Unknown record", func(t *testing.T) {
+		rr, _ := send(http.MethodPost, "/api/draft-notes/999999/lock", "", bed.AdminUser)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
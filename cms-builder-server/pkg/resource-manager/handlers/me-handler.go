@@ -0,0 +1,132 @@
+package resourcemanager
+
+import (
+	"net/http"
+	"sort"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	loggerTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/logger/types"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
+	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
+)
+
+type MeRoute struct {
+	Name    string   `json:"name"`
+	Path    string   `json:"path"`
+	Methods []string `json:"methods"`
+}
+
+type MeResource struct {
+	Name        string                    `json:"name"`
+	KebabPlural string                    `json:"kebabPluralName"`
+	Operations  []authTypes.CrudOperation `json:"operations"` // CRUD operations the user may perform
+	Routes      []MeRoute                 `json:"routes"`     // Custom routes the user may call
+}
+
+type MeResponse struct {
+	User      *authModels.User `json:"user"`
+	Roles     []authTypes.Role `json:"roles"`
+	Resources []MeResource     `json:"resources"`
+}
+
+// routeOperations maps the methods of custom routes to the operation they are checked against.
+var routeOperations = map[string]authTypes.CrudOperation{
+	http.MethodGet:    authConstants.OperationRead,
+	http.MethodHead:   authConstants.OperationRead,
+	http.MethodPost:   authConstants.OperationCreate,
+	http.MethodPut:    authConstants.OperationUpdate,
+	http.MethodPatch:  authConstants.OperationUpdate,
+	http.MethodDelete: authConstants.OperationDelete,
+}
+
+// MeHandler returns the current user, their roles, and what they may do on every resource,
+// so clients can hide what would be forbidden.
+func MeHandler(resources map[string]*rmTypes.Resource) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestCtx := svrUtils.GetRequestContext(r)
+		log := requestCtx.Logger
+		user := requestCtx.User
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodGet)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		if user == nil || user.ID == 0 {
+			svrUtils.SendJsonResponse(w, http.StatusUnauthorized, nil, "User is not authenticated")
+			return
+		}
+
+		roles := user.GetRoles()
+
+		// 2. Check Permissions of every resource
+		output := MeResponse{
+			User:      user,
+			Roles:     roles,
+			Resources: []MeResource{},
+		}
+
+		for _, a := range resources {
+			name := a.ResourceNames.Singular
+
+			resource := MeResource{
+				Name:        name,
+				KebabPlural: a.ResourceNames.KebabPlural,
+				Operations:  []authTypes.CrudOperation{},
+				Routes:      []MeRoute{},
+			}
+
+			for _, operation := range authConstants.AllAllowedAccess {
+				if authUtils.UserIsAllowed(a.Permissions, roles, operation, name, log) {
+					resource.Operations = append(resource.Operations, operation)
+				}
+			}
+
+			for _, route := range a.CustomRoutes {
+				if route.RequiresAuth && !routeIsAllowed(a, route.Methods, roles, log) {
+					continue
+				}
+
+				path := route.Path
+				if route.RequiresAuth {
+					path = "/private" + path
+				}
+
+				resource.Routes = append(resource.Routes, MeRoute{
+					Name:    route.Name,
+					Path:    path,
+					Methods: route.Methods,
+				})
+			}
+
+			output.Resources = append(output.Resources, resource)
+		}
+
+		sort.Slice(output.Resources, func(i, j int) bool {
+			return output.Resources[i].Name < output.Resources[j].Name
+		})
+
+		svrUtils.SendJsonResponse(w, http.StatusOK, output, "me")
+	}
+}
+
+// routeIsAllowed checks the operation of every method of the route, unknown methods are checked as updates.
+func routeIsAllowed(a *rmTypes.Resource, methods []string, roles []authTypes.Role, log *loggerTypes.Logger) bool {
+	for _, method := range methods {
+		operation, ok := routeOperations[method]
+		if !ok {
+			operation = authConstants.OperationUpdate
+		}
+
+		if !authUtils.UserIsAllowed(a.Permissions, roles, operation, a.ResourceNames.Singular, log) {
+			return false
+		}
+	}
+
+	return true
+}
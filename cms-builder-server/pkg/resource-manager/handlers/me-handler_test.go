@@ -0,0 +1,120 @@
+package resourcemanager_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	rmHandlers "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/handlers"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
+	svrTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/types"
+	testPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/testing"
+)
+
+type PublishableArticle struct {
+	authModels.SystemData
+	Title string `json:"title"`
+}
+
+type meResponse struct {
+	Data rmHandlers.MeResponse `json:"data"`
+}
+
+func findMeResource(t *testing.T, response meResponse, name string) rmHandlers.MeResource {
+	for _, resource := range response.Data.Resources {
+		if resource.Name == name {
+			return resource
+		}
+	}
+
+	t.Fatalf("resource %s not found", name)
+	return rmHandlers.MeResource{}
+}
+
+func TestMeHandler(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+
+	noop := func(w http.ResponseWriter, r *http.Request) {}
+	_, err := bed.Mgr.AddResource(&rmTypes.ResourceConfig{
+		Model: PublishableArticle{},
+		Permissions: authTypes.RolePermissionMap{
+			authConstants.AdminRole:   authConstants.AllAllowedAccess,
+			authConstants.VisitorRole: []authTypes.CrudOperation{authConstants.OperationRead},
+		},
+		Routes: []svrTypes.Route{
+			{Path: "/api/publishable-articles/{id}/publish", Handler: noop, Name: "publish", RequiresAuth: true, Methods: []string{http.MethodPut}},
+			{Path: "/api/publishable-articles/stats", Handler: noop, Name: "stats", RequiresAuth: true, Methods: []string{http.MethodGet}},
+			{Path: "/feeds/articles", Handler: noop, Name: "feed", RequiresAuth: false, Methods: []string{http.MethodGet}},
+		},
+	})
+	assert.NoError(t, err)
+
+	handler := rmHandlers.MeHandler(bed.Mgr.Resources)
+
+	tests := []struct {
+		name               string
+		user               *authModels.User
+		expectedOperations []authTypes.CrudOperation
+		expectedRoutes     []string
+	}{
+		{
+			name:               "Admin",
+			user:               bed.AdminUser,
+			expectedOperations: authConstants.AllAllowedAccess,
+			expectedRoutes:     []string{"publish", "stats", "feed"},
+		},
+		{
+			name:               "Visitor",
+			user:               bed.VisitorUser,
+			expectedOperations: []authTypes.CrudOperation{authConstants.OperationRead},
+			expectedRoutes:     []string{"stats", "feed"},
+		},
+		{
+			name:               "No role",
+			user:               bed.NoRoleUser,
+			expectedOperations: []authTypes.CrudOperation{},
+			expectedRoutes:     []string{"feed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := testPkg.CreateTestRequest(t, http.MethodGet, "/api/me", "", true, tt.user, bed.Logger)
+			rr := testPkg.ExecuteHandler(t, handler, req)
+			assert.Equal(t, http.StatusOK, rr.Code)
+
+			response := meResponse{}
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+			assert.Equal(t, tt.user.ID, response.Data.User.ID)
+			assert.Equal(t, tt.user.GetRoles(), response.Data.Roles)
+			assert.Len(t, response.Data.Resources, len(bed.Mgr.Resources))
+
+			resource := findMeResource(t, response, "PublishableArticle")
+			assert.ElementsMatch(t, tt.expectedOperations, resource.Operations)
+
+			routes := []string{}
+			for _, route := range resource.Routes {
+				routes = append(routes, route.Name)
+			}
+			assert.ElementsMatch(t, tt.expectedRoutes, routes)
+		})
+	}
+}
+
+func TestMeHandler_Errors(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+	handler := rmHandlers.MeHandler(bed.Mgr.Resources)
+
+	req := testPkg.CreateTestRequest(t, http.MethodPost, "/api/me", "", true, bed.AdminUser, bed.Logger)
+	rr := testPkg.ExecuteHandler(t, handler, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+
+	req = testPkg.CreateTestRequest(t, http.MethodGet, "/api/me", "", false, &authModels.User{}, bed.Logger)
+	rr = testPkg.ExecuteHandler(t, handler, req)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+}
@@ -0,0 +1,88 @@
+package resourcemanager
+
+import (
+	"net/http"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	dbQueries "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/queries"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
+	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
+)
+
+// DefaultReferencesHandler lists the records of other resources pointing to a record.
+// Resources the user cannot read are left out, and user binding applies to the referencing records.
+var DefaultReferencesHandler rmTypes.ApiFunction = func(a *rmTypes.Resource, db *dbTypes.DatabaseConnection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestCtx := svrUtils.GetRequestContext(r)
+		log := requestCtx.Logger
+		user := requestCtx.User
+		isAdmin := user.HasRole(authConstants.AdminRole)
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodGet)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		// 2. Check Permissions
+		if !authUtils.UserIsAllowed(a.Permissions, user.GetRoles(), authConstants.OperationRead, a.ResourceNames.Singular, log) {
+			svrUtils.SendJsonResponse(w, http.StatusForbidden, nil, "User is not allowed to access this resource")
+			return
+		}
+
+		// 3. Find Instance (User Binding)
+		filters := map[string]interface{}{
+			"id": svrUtils.GetUrlParam("id", r),
+		}
+
+		if !(a.SkipUserBinding || isAdmin) {
+			filters["created_by_id"] = user.ID
+		}
+
+		instance := a.GetOne()
+		err = dbQueries.FindOne(r.Context(), log, db, &instance, filters, []string{})
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusNotFound, nil, "Instance not found")
+			return
+		}
+
+		// 4. Find Referencing Records
+		output := []ReferencingRecords{}
+		for _, ref := range a.ReferencedBy {
+			if !authUtils.UserIsAllowed(ref.Resource.Permissions, user.GetRoles(), authConstants.OperationRead, ref.Resource.ResourceNames.Singular, log) {
+				continue
+			}
+
+			query := db.DB.WithContext(r.Context()).Model(ref.Resource.Model).
+				Where(map[string]interface{}{ref.Column: instanceId(instance)})
+
+			if !(ref.Resource.SkipUserBinding || isAdmin) {
+				query = query.Where("created_by_id = ?", user.ID)
+			}
+
+			ids := []uint{}
+			err = query.Order("id").Pluck("id", &ids).Error
+			if err != nil {
+				log.Error().Err(err).Msgf("Error finding %s references", ref.Resource.ResourceNames.Singular)
+				svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error finding references")
+				return
+			}
+
+			if len(ids) == 0 {
+				continue
+			}
+
+			output = append(output, ReferencingRecords{
+				Resource: ref.Resource.ResourceNames.Singular,
+				Field:    ref.Resource.FieldNames[ref.Field],
+				IDs:      ids,
+			})
+		}
+
+		// 5. Send Response
+		svrUtils.SendJsonResponse(w, http.StatusOK, output, a.ResourceNames.Singular+" References")
+	}
+}
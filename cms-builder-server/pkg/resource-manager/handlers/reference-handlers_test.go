@@ -0,0 +1,124 @@
+package resourcemanager_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	rmHandlers "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/handlers"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
+	testPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/testing"
+)
+
+type MediaAsset struct {
+	authModels.SystemData
+	Url string `json:"url"`
+}
+
+type GalleryPage struct {
+	authModels.SystemData
+	Title   string      `json:"title"`
+	CoverID *uint       `json:"coverId"`
+	Cover   *MediaAsset `json:"cover"`
+}
+
+type AssetNote struct {
+	authModels.SystemData
+	AssetID uint   `json:"assetId"`
+	Text    string `json:"text"`
+}
+
+func TestReferencesHandler(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+	for _, model := range []interface{}{&MediaAsset{}, &GalleryPage{}, &AssetNote{}} {
+		bed.Db.DB.Unscoped().Where("1 = 1").Delete(model)
+	}
+
+	readable := authTypes.RolePermissionMap{
+		authConstants.AdminRole:   authConstants.AllAllowedAccess,
+		authConstants.VisitorRole: []authTypes.CrudOperation{authConstants.OperationRead},
+	}
+
+	// Pages are registered before the assets they point to
+	_, err := bed.Mgr.AddResource(&rmTypes.ResourceConfig{Model: GalleryPage{}, Permissions: readable})
+	assert.NoError(t, err)
+
+	assets, err := bed.Mgr.AddResource(&rmTypes.ResourceConfig{Model: MediaAsset{}, Permissions: readable, SkipUserBinding: true})
+	assert.NoError(t, err)
+
+	_, err = bed.Mgr.AddResource(&rmTypes.ResourceConfig{
+		Model: AssetNote{},
+		Permissions: authTypes.RolePermissionMap{
+			authConstants.AdminRole: authConstants.AllAllowedAccess,
+		},
+		Relations: []rmTypes.RelationConfig{
+			{Field: "AssetID", Resource: "MediaAsset", OnDelete: rmTypes.CascadeOnDelete},
+		},
+	})
+	assert.NoError(t, err)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/media-assets/{id}/references", assets.Api.References(assets, bed.Db))
+
+	send := func(path string, user *authModels.User) (*httptest.ResponseRecorder, []rmHandlers.ReferencingRecords) {
+		req := testPkg.CreateTestRequest(t, http.MethodGet, path, "", true, user, bed.Logger)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		response := struct {
+			Data []rmHandlers.ReferencingRecords `json:"data"`
+		}{}
+		if rr.Code == http.StatusOK {
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		}
+		return rr, response.Data
+	}
+
+	asset := MediaAsset{Url: "/cat.png"}
+	asset.CreatedByID = bed.AdminUser.ID
+	assert.NoError(t, bed.Db.DB.Create(&asset).Error)
+
+	adminPage := GalleryPage{Title: "Admin", CoverID: &asset.ID}
+	adminPage.CreatedByID = bed.AdminUser.ID
+	assert.NoError(t, bed.Db.DB.Create(&adminPage).Error)
+
+	visitorPage := GalleryPage{Title: "Visitor", CoverID: &asset.ID}
+	visitorPage.CreatedByID = bed.VisitorUser.ID
+	assert.NoError(t, bed.Db.DB.Create(&visitorPage).Error)
+
+	note := AssetNote{AssetID: asset.ID, Text: "Licensed"}
+	note.CreatedByID = bed.AdminUser.ID
+	assert.NoError(t, bed.Db.DB.Create(&note).Error)
+
+	path := fmt.Sprintf("/media-assets/%d/references", asset.ID)
+
+	t.Run("Lists found and declared references", func(t *testing.T) {
+		rr, data := send(path, bed.AdminUser)
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.ElementsMatch(t, []rmHandlers.ReferencingRecords{
+			{Resource: "GalleryPage", Field: "coverId", IDs: []uint{adminPage.ID, visitorPage.ID}},
+			{Resource: "AssetNote", Field: "assetId", IDs: []uint{note.ID}},
+		}, data)
+	})
+
+	t.Run("Visitors see their own records of readable resources", func(t *testing.T) {
+		rr, data := send(path, bed.VisitorUser)
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.Equal(t, []rmHandlers.ReferencingRecords{
+			{Resource: "GalleryPage", Field: "coverId", IDs: []uint{visitorPage.ID}},
+		}, data)
+	})
+
+	t.Run("Unknown record", func(t *testing.T) {
+		rr, _ := send("/media-assets/999999/references", bed.AdminUser)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
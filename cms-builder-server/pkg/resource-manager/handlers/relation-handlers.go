@@ -0,0 +1,242 @@
+package resourcemanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	dbQueries "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/queries"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	loggerTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/logger/types"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
+	"github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/utils"
+)
+
+var ErrDeleteRestricted = errors.New("record is referenced by other records")
+
+// ReferencingRecords lists the records of a resource referencing another record through one of their fields.
+type ReferencingRecords struct {
+	Resource string `json:"resource"`
+	Field    string `json:"field"`
+	IDs      []uint `json:"ids"`
+}
+
+// RelationColumn returns the column holding the given relation field of a resource.
+func RelationColumn(db *dbTypes.DatabaseConnection, a *rmTypes.Resource, fieldName string) (string, error) {
+	s, err := getModelSchema(a, db)
+	if err != nil {
+		return "", err
+	}
+
+	field, ok := s.FieldsByName[fieldName]
+	if !ok || field.DBName == "" {
+		return "", fmt.Errorf("relation field %s not found in model", fieldName)
+	}
+
+	return field.DBName, nil
+}
+
+// FindRestrictingRecords returns the records preventing the delete of the given record.
+// Handlers deleting records on their own must check it before DeleteWithReferences.
+func FindRestrictingRecords(ctx context.Context, db *dbTypes.DatabaseConnection, a *rmTypes.Resource, instance interface{}) ([]ReferencingRecords, error) {
+	id := instanceId(instance)
+	return findRestrictingRecords(ctx, db, a, []uint{id}, map[string]bool{visitKey(a, id): true})
+}
+
+// DeleteWithReferences deletes the given record and applies the delete policies of the records referencing it,
+// all in one transaction. ErrDeleteRestricted is returned if a restricting record showed up in the meantime.
+func DeleteWithReferences(ctx context.Context, log *loggerTypes.Logger, db *dbTypes.DatabaseConnection, a *rmTypes.Resource, instance interface{}, user *authModels.User, requestId string) error {
+	id := instanceId(instance)
+	return db.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		txDb := &dbTypes.DatabaseConnection{DB: tx}
+
+		err := deleteReferences(ctx, log, txDb, a, []uint{id}, user, requestId, map[string]bool{visitKey(a, id): true})
+		if err != nil {
+			return err
+		}
+
+		return dbQueries.Delete(ctx, log, txDb, instance, user, requestId)
+	})
+}
+
+// findRestrictingRecords returns the records that prevent deleting the records with the given ids,
+// including those referencing the records the delete would cascade to.
+func findRestrictingRecords(ctx context.Context, db *dbTypes.DatabaseConnection, a *rmTypes.Resource, ids []uint, visited map[string]bool) ([]ReferencingRecords, error) {
+	output := []ReferencingRecords{}
+
+	for _, ref := range a.ReferencedBy {
+		if ref.OnDelete == "" {
+			continue
+		}
+
+		instances, err := findReferencingInstances(ctx, db, ref, ids)
+		if err != nil {
+			return nil, err
+		}
+
+		switch ref.OnDelete {
+		case rmTypes.RestrictOnDelete:
+			refIds := remainingIds(ref.Resource, instances, visited)
+			if len(refIds) == 0 {
+				continue
+			}
+
+			output = append(output, ReferencingRecords{
+				Resource: ref.Resource.ResourceNames.Singular,
+				Field:    ref.Resource.FieldNames[ref.Field],
+				IDs:      refIds,
+			})
+		case rmTypes.CascadeOnDelete:
+			refIds := visit(ref.Resource, instances, visited)
+			if len(refIds) == 0 {
+				continue
+			}
+
+			restricting, err := findRestrictingRecords(ctx, db, ref.Resource, refIds, visited)
+			if err != nil {
+				return nil, err
+			}
+			output = append(output, restricting...)
+		}
+	}
+
+	return output, nil
+}
+
+// deleteReferences applies the delete policies of the records referencing the records with the given ids.
+// Cascaded records are soft deleted and cleared references are saved, each with its audit entry.
+func deleteReferences(ctx context.Context, log *loggerTypes.Logger, db *dbTypes.DatabaseConnection, a *rmTypes.Resource, ids []uint, user *authModels.User, requestId string, visited map[string]bool) error {
+	for _, ref := range a.ReferencedBy {
+		if ref.OnDelete == "" {
+			continue
+		}
+
+		instances, err := findReferencingInstances(ctx, db, ref, ids)
+		if err != nil {
+			return err
+		}
+
+		switch ref.OnDelete {
+		case rmTypes.RestrictOnDelete:
+			if len(remainingIds(ref.Resource, instances, visited)) > 0 {
+				return ErrDeleteRestricted
+			}
+
+		case rmTypes.CascadeOnDelete:
+			refIds := visit(ref.Resource, instances, visited)
+			if len(refIds) == 0 {
+				continue
+			}
+
+			err = deleteReferences(ctx, log, db, ref.Resource, refIds, user, requestId, visited)
+			if err != nil {
+				return err
+			}
+
+			for _, instance := range referencingInstances(instances, refIds) {
+				err = dbQueries.Delete(ctx, log, db, instance, user, requestId)
+				if err != nil {
+					return err
+				}
+			}
+
+		case rmTypes.SetNullOnDelete:
+			for _, instance := range referencingInstances(instances, remainingIds(ref.Resource, instances, visited)) {
+				previousState := ref.Resource.GetOne()
+				reflect.ValueOf(previousState).Elem().Set(reflect.ValueOf(instance).Elem())
+
+				value := reflect.ValueOf(instance).Elem()
+				value.FieldByName(ref.Field).SetZero()
+				if updatedBy := value.FieldByName("UpdatedByID"); updatedBy.IsValid() {
+					updatedBy.SetUint(uint64(user.ID))
+				}
+
+				differences := utils.CompareInterfaces(previousState, instance)
+				err = dbQueries.Update(ctx, log, db, instance, user, differences, requestId)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// findReferencingInstances returns pointers to the records of the given reference holding one of the given ids.
+func findReferencingInstances(ctx context.Context, db *dbTypes.DatabaseConnection, ref rmTypes.Reference, ids []uint) ([]interface{}, error) {
+	if len(ids) == 0 {
+		return []interface{}{}, nil
+	}
+
+	instances, err := ref.Resource.GetSlice()
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.DB.WithContext(ctx).
+		Where(map[string]interface{}{ref.Column: ids}).
+		Order("id").
+		Find(instances).Error
+	if err != nil {
+		return nil, err
+	}
+
+	slice := reflect.ValueOf(instances).Elem()
+	output := make([]interface{}, slice.Len())
+	for i := range output {
+		output[i] = slice.Index(i).Addr().Interface()
+	}
+
+	return output, nil
+}
+
+// visit returns the ids of the given records the delete has not reached yet and marks them as deleted,
+// so records referencing each other are handled once.
+func visit(a *rmTypes.Resource, instances []interface{}, visited map[string]bool) []uint {
+	ids := remainingIds(a, instances, visited)
+	for _, id := range ids {
+		visited[visitKey(a, id)] = true
+	}
+	return ids
+}
+
+// remainingIds returns the ids of the given records the delete has not reached.
+func remainingIds(a *rmTypes.Resource, instances []interface{}, visited map[string]bool) []uint {
+	ids := []uint{}
+	for _, instance := range instances {
+		id := instanceId(instance)
+		if !visited[visitKey(a, id)] {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func visitKey(a *rmTypes.Resource, id uint) string {
+	return fmt.Sprintf("%s:%d", a.ResourceNames.Singular, id)
+}
+
+// referencingInstances keeps the given records whose id is one of the given ids.
+func referencingInstances(instances []interface{}, ids []uint) []interface{} {
+	keep := map[uint]bool{}
+	for _, id := range ids {
+		keep[id] = true
+	}
+
+	output := []interface{}{}
+	for _, instance := range instances {
+		if keep[instanceId(instance)] {
+			output = append(output, instance)
+		}
+	}
+	return output
+}
+
+func instanceId(instance interface{}) uint {
+	return uint(reflect.ValueOf(instance).Elem().FieldByName("ID").Uint())
+}
@@ -0,0 +1,161 @@
+package resourcemanager_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	dbModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/models"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	rmHandlers "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/handlers"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
+	testPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/testing"
+)
+
+type Magazine struct {
+	authModels.SystemData
+	Name string `json:"name"`
+}
+
+type MagazineIssue struct {
+	authModels.SystemData
+	MagazineID uint   `json:"magazineId"`
+	Title      string `json:"title"`
+}
+
+type MagazineAd struct {
+	authModels.SystemData
+	MagazineID *uint  `json:"magazineId"`
+	Brand      string `json:"brand"`
+}
+
+type IssueReview struct {
+	authModels.SystemData
+	MagazineIssueID uint   `json:"magazineIssueId"`
+	Text            string `json:"text"`
+}
+
+func TestRelationDeletePolicies(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+	for _, model := range []interface{}{&Magazine{}, &MagazineIssue{}, &MagazineAd{}, &IssueReview{}} {
+		bed.Db.DB.Unscoped().Where("1 = 1").Delete(model)
+	}
+	bed.Db.DB.Where("resource_name IN ?", []string{"MagazineIssue", "MagazineAd"}).Delete(&dbModels.DatabaseLog{})
+
+	permissions := authTypes.RolePermissionMap{
+		authConstants.AdminRole: authConstants.AllAllowedAccess,
+	}
+
+	magazines, err := bed.Mgr.AddResource(&rmTypes.ResourceConfig{Model: Magazine{}, Permissions: permissions})
+	assert.NoError(t, err)
+
+	issues, err := bed.Mgr.AddResource(&rmTypes.ResourceConfig{
+		Model:       MagazineIssue{},
+		Permissions: permissions,
+		Relations: []rmTypes.RelationConfig{
+			{Field: "MagazineID", Resource: "Magazine", OnDelete: rmTypes.CascadeOnDelete},
+		},
+	})
+	assert.NoError(t, err)
+
+	_, err = bed.Mgr.AddResource(&rmTypes.ResourceConfig{
+		Model:       MagazineAd{},
+		Permissions: permissions,
+		Relations: []rmTypes.RelationConfig{
+			{Field: "MagazineID", Resource: "Magazine", OnDelete: rmTypes.SetNullOnDelete},
+		},
+	})
+	assert.NoError(t, err)
+
+	_, err = bed.Mgr.AddResource(&rmTypes.ResourceConfig{
+		Model:       IssueReview{},
+		Permissions: permissions,
+		Relations: []rmTypes.RelationConfig{
+			{Field: "MagazineIssueID", Resource: "MagazineIssue"},
+		},
+	})
+	assert.NoError(t, err)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/magazines/{id}/delete", magazines.Api.Delete(magazines, bed.Db))
+	router.HandleFunc("/magazine-issues/{id}/delete", issues.Api.Delete(issues, bed.Db))
+
+	send := func(path string) *httptest.ResponseRecorder {
+		req := testPkg.CreateTestRequest(t, http.MethodDelete, path, "", true, bed.AdminUser, bed.Logger)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	magazine := Magazine{Name: "Monthly"}
+	magazine.CreatedByID = bed.AdminUser.ID
+	assert.NoError(t, bed.Db.DB.Create(&magazine).Error)
+
+	issue := MagazineIssue{MagazineID: magazine.ID, Title: "January"}
+	issue.CreatedByID = bed.AdminUser.ID
+	assert.NoError(t, bed.Db.DB.Create(&issue).Error)
+
+	ad := MagazineAd{MagazineID: &magazine.ID, Brand: "Acme"}
+	ad.CreatedByID = bed.AdminUser.ID
+	assert.NoError(t, bed.Db.DB.Create(&ad).Error)
+
+	review := IssueReview{MagazineIssueID: issue.ID, Text: "Great"}
+	review.CreatedByID = bed.AdminUser.ID
+	assert.NoError(t, bed.Db.DB.Create(&review).Error)
+
+	t.Run("Restricted records refuse the delete, also through cascades", func(t *testing.T) {
+		rr := send(fmt.Sprintf("/magazines/%d/delete", magazine.ID))
+		assert.Equal(t, http.StatusConflict, rr.Code, rr.Body.String())
+
+		response := struct {
+			Data []rmHandlers.ReferencingRecords `json:"data"`
+		}{}
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, []rmHandlers.ReferencingRecords{
+			{Resource: "IssueReview", Field: "magazineIssueId", IDs: []uint{review.ID}},
+		}, response.Data)
+
+		var count int64
+		bed.Db.DB.Model(&MagazineIssue{}).Where("id = ?", issue.ID).Count(&count)
+		assert.Equal(t, int64(1), count, "Nothing should be deleted")
+
+		rr = send(fmt.Sprintf("/magazine-issues/%d/delete", issue.ID))
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("Cascade and set null", func(t *testing.T) {
+		assert.NoError(t, bed.Db.DB.Delete(&review).Error)
+
+		rr := send(fmt.Sprintf("/magazines/%d/delete", magazine.ID))
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		var count int64
+		bed.Db.DB.Model(&MagazineIssue{}).Where("id = ?", issue.ID).Count(&count)
+		assert.Equal(t, int64(0), count, "Issues should be deleted with their magazine")
+
+		bed.Db.DB.Unscoped().Model(&MagazineIssue{}).Where("id = ?", issue.ID).Count(&count)
+		assert.Equal(t, int64(1), count, "Cascaded deletes should be soft deletes")
+
+		updated := MagazineAd{}
+		assert.NoError(t, bed.Db.DB.First(&updated, ad.ID).Error)
+		assert.Nil(t, updated.MagazineID)
+
+		logs := []dbModels.DatabaseLog{}
+		bed.Db.DB.Where("resource_name = ? AND resource_id = ?", "MagazineIssue", fmt.Sprint(issue.ID)).Find(&logs)
+		assert.Len(t, logs, 1)
+		assert.Equal(t, dbTypes.DeleteCRUDAction, logs[0].Action)
+
+		logs = []dbModels.DatabaseLog{}
+		bed.Db.DB.Where("resource_name = ? AND resource_id = ?", "MagazineAd", fmt.Sprint(ad.ID)).Find(&logs)
+		assert.Len(t, logs, 1)
+		assert.Equal(t, dbTypes.UpdateCRUDAction, logs[0].Action)
+	})
+}
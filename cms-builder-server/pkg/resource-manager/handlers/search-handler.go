@@ -0,0 +1,242 @@
+package resourcemanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm/clause"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
+	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
+)
+
+const (
+	defaultSearchLimit = 5
+	maxSearchLimit     = 20
+)
+
+type SearchHit struct {
+	ID    interface{} `json:"id"`
+	Label string      `json:"label"`
+	Score int         `json:"score"`
+	Url   string      `json:"url"`
+}
+
+type SearchResult struct {
+	Name        string      `json:"name"`
+	Plural      string      `json:"pluralName"`
+	KebabPlural string      `json:"kebabPluralName"`
+	Hits        []SearchHit `json:"hits"`
+}
+
+// SearchHandler looks up the q query parameter in every searchable resource the user can read.
+// Hits are ranked by how well they match (exact, prefix or partial match on each field)
+// and grouped by resource, the best matching resource first.
+func SearchHandler(resources map[string]*rmTypes.Resource, db *dbTypes.DatabaseConnection, apiBaseUrl string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestCtx := svrUtils.GetRequestContext(r)
+		log := requestCtx.Logger
+		user := requestCtx.User
+		isAdmin := user.HasRole(authConstants.AdminRole)
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodGet)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		// 2. Parse Query Parameters
+		term := strings.TrimSpace(r.URL.Query().Get("q"))
+		if term == "" {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, "Missing q parameter")
+			return
+		}
+
+		limit := defaultSearchLimit
+		if value := r.URL.Query().Get("limit"); value != "" {
+			limit, err = strconv.Atoi(value)
+			if err != nil || limit < 1 || limit > maxSearchLimit {
+				svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, fmt.Sprintf("Invalid limit parameter, expected 1 to %d", maxSearchLimit))
+				return
+			}
+		}
+
+		results := []SearchResult{}
+
+		for _, a := range resources {
+			if a.Search == nil {
+				continue
+			}
+
+			// 3. Check Permissions
+			if !authUtils.UserIsAllowed(a.Permissions, user.GetRoles(), authConstants.OperationRead, a.ResourceNames.Singular, log) {
+				continue
+			}
+
+			// 4. Search the resource
+			hits, err := searchResource(r, a, db, term, limit, !(a.SkipUserBinding || isAdmin), user.ID)
+			if err != nil {
+				log.Error().Err(err).Str("resource", a.ResourceNames.Singular).Msg("Error searching resource")
+				svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error searching resources")
+				return
+			}
+
+			if len(hits) == 0 {
+				continue
+			}
+
+			for i := range hits {
+				hits[i].Url = fmt.Sprintf("%s/private/api/%s/%v", apiBaseUrl, a.ResourceNames.KebabPlural, hits[i].ID)
+			}
+
+			results = append(results, SearchResult{
+				Name:        a.ResourceNames.Singular,
+				Plural:      a.ResourceNames.Plural,
+				KebabPlural: a.ResourceNames.KebabPlural,
+				Hits:        hits,
+			})
+		}
+
+		// 5. Rank resources by their best hit
+		sort.Slice(results, func(i, j int) bool {
+			if results[i].Hits[0].Score != results[j].Hits[0].Score {
+				return results[i].Hits[0].Score > results[j].Hits[0].Score
+			}
+			return results[i].Name < results[j].Name
+		})
+
+		svrUtils.SendJsonResponse(w, http.StatusOK, results, "search")
+	}
+}
+
+func searchResource(r *http.Request, a *rmTypes.Resource, db *dbTypes.DatabaseConnection, term string, limit int, bindUser bool, userId uint) ([]SearchHit, error) {
+	s, err := getModelSchema(a, db)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve columns and json keys
+	columns := []string{}
+	keys := []string{}
+	for _, fieldName := range a.Search.Fields {
+		field := s.LookUpField(fieldName)
+		if field == nil || field.DBName == "" {
+			return nil, fmt.Errorf("search field %s is not a column", fieldName)
+		}
+		columns = append(columns, field.DBName)
+		keys = append(keys, jsonKey(field.Name, field.Tag.Get("json")))
+	}
+
+	labelKey := a.Search.LabelField
+	if field := s.LookUpField(a.Search.LabelField); field != nil {
+		labelKey = jsonKey(field.Name, field.Tag.Get("json"))
+	}
+
+	// Build query
+	lowerTerm := strings.ToLower(term)
+	pattern := "%" + escapeLike(lowerTerm) + "%"
+	prefix := escapeLike(lowerTerm) + "%"
+	conditions := []string{}
+	args := []interface{}{}
+	scores := []string{}
+	scoreArgs := []interface{}{}
+	for _, column := range columns {
+		quoted := quoteColumn(db, column)
+		conditions = append(conditions, fmt.Sprintf("LOWER(%s) LIKE ? ESCAPE '\\'", quoted))
+		args = append(args, pattern)
+
+		// Same ranking as matchScore, so the best matches are kept by the limit
+		scores = append(scores, fmt.Sprintf("CASE WHEN LOWER(%[1]s) = ? THEN 3 WHEN LOWER(%[1]s) LIKE ? ESCAPE '\\' THEN 2 WHEN LOWER(%[1]s) LIKE ? ESCAPE '\\' THEN 1 ELSE 0 END", quoted))
+		scoreArgs = append(scoreArgs, lowerTerm, prefix, pattern)
+	}
+
+	query := db.DB.WithContext(r.Context()).Model(a.Model).Where(strings.Join(conditions, " OR "), args...)
+	if bindUser {
+		query = query.Where("created_by_id = ?", userId)
+	}
+
+	instances, err := a.GetSlice()
+	if err != nil {
+		return nil, err
+	}
+
+	score := clause.OrderBy{Expression: clause.Expr{
+		SQL:                "(" + strings.Join(scores, " + ") + ") DESC, id DESC",
+		Vars:               scoreArgs,
+		WithoutParentheses: true,
+	}}
+	err = query.Order(score).Limit(limit).Find(instances).Error
+	if err != nil {
+		return nil, err
+	}
+
+	items := []map[string]interface{}{}
+	jsonData, err := json.Marshal(instances)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(jsonData, &items)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := []SearchHit{}
+	for _, item := range items {
+		score := 0
+		for _, key := range keys {
+			score += matchScore(fmt.Sprintf("%v", item[key]), term)
+		}
+
+		hits = append(hits, SearchHit{
+			ID:    item["ID"],
+			Label: fmt.Sprintf("%v", item[labelKey]),
+			Score: score,
+		})
+	}
+
+	return hits, nil
+}
+
+// matchScore ranks exact matches over prefix matches over partial matches.
+func matchScore(value string, term string) int {
+	value = strings.ToLower(value)
+	term = strings.ToLower(term)
+
+	switch {
+	case value == term:
+		return 3
+	case strings.HasPrefix(value, term):
+		return 2
+	case strings.Contains(value, term):
+		return 1
+	}
+
+	return 0
+}
+
+func jsonKey(fieldName string, tag string) string {
+	name := strings.Split(tag, ",")[0]
+	if name == "" || name == "-" {
+		return fieldName
+	}
+	return name
+}
+
+func escapeLike(value string) string {
+	replacer := strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_")
+	return replacer.Replace(value)
+}
+
+func quoteColumn(db *dbTypes.DatabaseConnection, column string) string {
+	builder := strings.Builder{}
+	db.DB.Dialector.QuoteTo(&builder, column)
+	return builder.String()
+}
@@ -0,0 +1,147 @@
+package resourcemanager_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	rmHandlers "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/handlers"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
+	testPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/testing"
+)
+
+type SearchableArticle struct {
+	authModels.SystemData
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+type searchResponse struct {
+	Data []rmHandlers.SearchResult `json:"data"`
+}
+
+func TestSearchHandler(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+
+	_, err := bed.Mgr.AddResource(&rmTypes.ResourceConfig{
+		Model: SearchableArticle{},
+		Permissions: authTypes.RolePermissionMap{
+			authConstants.AdminRole:   authConstants.AllAllowedAccess,
+			authConstants.VisitorRole: []authTypes.CrudOperation{authConstants.OperationRead},
+		},
+		Search: &rmTypes.SearchConfig{
+			Fields: []string{"Title", "Body"},
+		},
+	})
+	assert.NoError(t, err)
+
+	term := testPkg.RandomString(12)
+	articles := []SearchableArticle{
+		{Title: "About " + term, Body: "partial match"},
+		{Title: term, Body: "exact match"},
+		{Title: "Body match", Body: "contains " + term},
+	}
+	for _, article := range articles {
+		article.CreatedByID = bed.AdminUser.ID
+		bed.Db.DB.Create(&article)
+	}
+
+	visitorArticle := SearchableArticle{Title: term + " visitor", Body: "owned by visitor"}
+	visitorArticle.CreatedByID = bed.VisitorUser.ID
+	bed.Db.DB.Create(&visitorArticle)
+
+	handler := rmHandlers.SearchHandler(bed.Mgr.Resources, bed.Db, "http://localhost")
+
+	search := func(method string, user *authModels.User, query string) (int, []rmHandlers.SearchResult, string) {
+		req := testPkg.CreateTestRequest(t, method, "/api/search?"+query, "", true, user, bed.Logger)
+		rr := testPkg.ExecuteHandler(t, handler, req)
+
+		response := searchResponse{}
+		if rr.Code == http.StatusOK {
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		}
+		return rr.Code, response.Data, rr.Body.String()
+	}
+
+	t.Run("Admin ranks exact matches first", func(t *testing.T) {
+		status, results, _ := search(http.MethodGet, bed.AdminUser, "q="+url.QueryEscape(term))
+		assert.Equal(t, http.StatusOK, status)
+		assert.Len(t, results, 1)
+		assert.Equal(t, "SearchableArticle", results[0].Name)
+		assert.Len(t, results[0].Hits, 4)
+		assert.Equal(t, term, results[0].Hits[0].Label)
+		assert.Contains(t, results[0].Hits[0].Url, "http://localhost/private/api/searchable-articles/")
+	})
+
+	t.Run("Visitor only sees own records", func(t *testing.T) {
+		status, results, _ := search(http.MethodGet, bed.VisitorUser, "q="+url.QueryEscape(term))
+		assert.Equal(t, http.StatusOK, status)
+		assert.Len(t, results, 1)
+		assert.Len(t, results[0].Hits, 1)
+		assert.Equal(t, term+" visitor", results[0].Hits[0].Label)
+	})
+
+	t.Run("Resources without read permission are skipped", func(t *testing.T) {
+		status, results, _ := search(http.MethodGet, bed.NoRoleUser, "q="+url.QueryEscape(term))
+		assert.Equal(t, http.StatusOK, status)
+		assert.Empty(t, results)
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		status, results, _ := search(http.MethodGet, bed.AdminUser, "limit=2&q="+url.QueryEscape(term))
+		assert.Equal(t, http.StatusOK, status)
+		assert.Len(t, results[0].Hits, 2)
+
+		status, _, body := search(http.MethodGet, bed.AdminUser, "limit=100&q="+url.QueryEscape(term))
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Contains(t, body, "Invalid limit parameter")
+	})
+
+	t.Run("Older exact matches are kept by the limit", func(t *testing.T) {
+		for i := 0; i < 25; i++ {
+			article := SearchableArticle{Title: "Newer " + term, Body: "partial match"}
+			article.CreatedByID = bed.AdminUser.ID
+			bed.Db.DB.Create(&article)
+		}
+
+		status, results, _ := search(http.MethodGet, bed.AdminUser, "limit=1&q="+url.QueryEscape(term))
+		assert.Equal(t, http.StatusOK, status)
+		assert.Len(t, results[0].Hits, 1)
+		assert.Equal(t, term, results[0].Hits[0].Label)
+	})
+
+	t.Run("Missing term", func(t *testing.T) {
+		status, _, body := search(http.MethodGet, bed.AdminUser, "")
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Contains(t, body, "Missing q parameter")
+	})
+
+	t.Run("Invalid Method", func(t *testing.T) {
+		status, _, body := search(http.MethodPost, bed.AdminUser, "q=x")
+		assert.Equal(t, http.StatusMethodNotAllowed, status)
+		assert.Contains(t, body, "Method not allowed")
+	})
+}
+
+func TestSearchHandler_Users(t *testing.T) {
+	bed := testPkg.SetupAuthTestBed()
+
+	handler := rmHandlers.SearchHandler(bed.Mgr.Resources, bed.Db, "http://localhost")
+
+	req := testPkg.CreateTestRequest(t, http.MethodGet, "/api/search?q="+url.QueryEscape(bed.AdminUser.Email), "", true, bed.VisitorUser, bed.Logger)
+	rr := testPkg.ExecuteHandler(t, handler, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	response := searchResponse{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+
+	// Visitors can read users, but other users' emails must not show up in their search
+	assert.NotContains(t, rr.Body.String(), bed.AdminUser.Email)
+	assert.Empty(t, response.Data)
+}
@@ -0,0 +1,179 @@
+package resourcemanager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"gorm.io/gorm"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	dbQueries "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/queries"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	loggerTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/logger/types"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
+	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
+)
+
+// DefaultSingletonDetailHandler returns the single record of a singleton resource.
+var DefaultSingletonDetailHandler rmTypes.ApiFunction = func(a *rmTypes.Resource, db *dbTypes.DatabaseConnection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestCtx := svrUtils.GetRequestContext(r)
+		log := requestCtx.Logger
+		user := requestCtx.User
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodGet)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		// 2. Check Permissions
+		if !authUtils.UserIsAllowed(a.Permissions, user.GetRoles(), authConstants.OperationRead, a.ResourceNames.Singular, log) {
+			svrUtils.SendJsonResponse(w, http.StatusForbidden, nil, "User is not allowed to read this resource")
+			return
+		}
+
+		// 3. Find Instance
+		instance, err := FindSingleton(r.Context(), log, db, a)
+		if err != nil {
+			sendSingletonError(w, err)
+			return
+		}
+
+		// 4. Localize Translatable Fields
+		locale, err := ResolveLocale(r, a)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, err.Error())
+			return
+		}
+
+		data, err := LocalizeInstances(r.Context(), db, a, instance, locale)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error localizing instance")
+			return
+		}
+		setContentLanguage(w, locale)
+
+		svrUtils.SendJsonResponse(w, http.StatusOK, data, a.ResourceNames.Singular+" Detail")
+	}
+}
+
+// DefaultSingletonUpdateHandler updates the single record of a singleton resource.
+var DefaultSingletonUpdateHandler rmTypes.ApiFunction = func(a *rmTypes.Resource, db *dbTypes.DatabaseConnection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestCtx := svrUtils.GetRequestContext(r)
+		log := requestCtx.Logger
+		user := requestCtx.User
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodPut)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		// 2. Check Permissions
+		if !authUtils.UserIsAllowed(a.Permissions, user.GetRoles(), authConstants.OperationRead, a.ResourceNames.Singular, log) {
+			svrUtils.SendJsonResponse(w, http.StatusForbidden, nil, "User is not allowed to access this resource")
+			return
+		}
+
+		if !authUtils.UserIsAllowed(a.Permissions, user.GetRoles(), authConstants.OperationUpdate, a.ResourceNames.Singular, log) {
+			svrUtils.SendJsonResponse(w, http.StatusForbidden, nil, "User is not allowed to update this resource")
+			return
+		}
+
+		// 3. Find Instance
+		instance, err := FindSingleton(r.Context(), log, db, a)
+		if err != nil {
+			sendSingletonError(w, err)
+			return
+		}
+
+		previousState, err := FindSingleton(r.Context(), log, db, a)
+		if err != nil {
+			sendSingletonError(w, err)
+			return
+		}
+
+		updateInstance(w, r, a, db, instance, previousState)
+	}
+}
+
+// sendSingletonError responds 404 when the record of the singleton is missing, 500 otherwise.
+func sendSingletonError(w http.ResponseWriter, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		svrUtils.SendJsonResponse(w, http.StatusNotFound, nil, "Instance not found")
+		return
+	}
+
+	svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error finding instance")
+}
+
+// FindSingleton returns the record of a singleton resource, or gorm.ErrRecordNotFound when there is none.
+func FindSingleton(ctx context.Context, log *loggerTypes.Logger, db *dbTypes.DatabaseConnection, a *rmTypes.Resource) (interface{}, error) {
+	instance := a.GetOne()
+	err := db.DB.WithContext(ctx).Model(a.Model).Order("id").First(instance).Error
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Error().Err(err).Str("resource", a.ResourceNames.Singular).Msg("Error finding singleton")
+		}
+		return nil, err
+	}
+
+	return instance, nil
+}
+
+// FindOrCreateSingleton returns the record of a singleton resource.
+// When there is none yet, it is created from the values of the resource model,
+// so the model given to ResourceConfig holds the defaults.
+// It only runs at boot, handlers never create the record so concurrent requests can't duplicate it.
+func FindOrCreateSingleton(ctx context.Context, log *loggerTypes.Logger, db *dbTypes.DatabaseConnection, a *rmTypes.Resource, user *authModels.User, requestId string) (interface{}, error) {
+	instance, err := FindSingleton(ctx, log, db, a)
+	if err == nil {
+		return instance, nil
+	}
+
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	instance = a.GetOne()
+	defaults, err := rmTypes.InterfaceToMap(a.Model)
+	if err != nil {
+		return nil, err
+	}
+
+	for key := range filterKeys {
+		delete(defaults, key)
+	}
+
+	for _, fieldName := range []string{"CreatedByID", "UpdatedByID"} {
+		if key, ok := a.FieldNames[fieldName]; ok {
+			defaults[key] = user.ID
+		}
+	}
+
+	data, err := json.Marshal(defaults)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, instance)
+	if err != nil {
+		return nil, err
+	}
+
+	err = dbQueries.Create(ctx, log, db, instance, user, requestId)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Str("resource", a.ResourceNames.Singular).Msg("Singleton created with defaults")
+	return instance, nil
+}
@@ -0,0 +1,123 @@
+package resourcemanager_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	dbModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/models"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	rmHandlers "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/handlers"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
+	rmValidators "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/validators"
+	testPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/testing"
+)
+
+type SiteSettings struct {
+	authModels.SystemData
+	Title  string `json:"title"`
+	Footer string `json:"footer"`
+}
+
+func TestSingletonHandlers(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+	bed.Db.DB.Unscoped().Where("1 = 1").Delete(&SiteSettings{})
+
+	resource, err := bed.Mgr.AddResource(&rmTypes.ResourceConfig{
+		Model:     SiteSettings{Title: "My Site"},
+		Singleton: true,
+		Permissions: authTypes.RolePermissionMap{
+			authConstants.AdminRole:   authConstants.AllAllowedAccess,
+			authConstants.VisitorRole: []authTypes.CrudOperation{authConstants.OperationRead},
+		},
+		Validators: rmTypes.ValidatorsMap{
+			"Title": rmTypes.ValidatorsList{rmValidators.RequiredValidator},
+		},
+	})
+	assert.NoError(t, err)
+	assert.True(t, resource.SkipUserBinding, "Singletons should be shared by every user")
+
+	detail := rmHandlers.DefaultSingletonDetailHandler(resource, bed.Db)
+	update := rmHandlers.DefaultSingletonUpdateHandler(resource, bed.Db)
+
+	read := func(user *authModels.User) (int, SiteSettings) {
+		req := testPkg.CreateTestRequest(t, http.MethodGet, "/api/site-settings", "", true, user, bed.Logger)
+		rr := testPkg.ExecuteHandler(t, detail, req)
+
+		response := struct {
+			Data SiteSettings `json:"data"`
+		}{}
+		if rr.Code == http.StatusOK {
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		}
+		return rr.Code, response.Data
+	}
+
+	t.Run("Record is only created at boot", func(t *testing.T) {
+		status, _ := read(bed.VisitorUser)
+		assert.Equal(t, http.StatusNotFound, status)
+
+		req := testPkg.CreateTestRequest(t, http.MethodPut, "/api/site-settings", `{"title": "Early"}`, true, bed.AdminUser, bed.Logger)
+		rr := testPkg.ExecuteHandler(t, update, req)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+
+		var count int64
+		bed.Db.DB.Model(&SiteSettings{}).Count(&count)
+		assert.Equal(t, int64(0), count)
+
+		assert.NoError(t, bed.Mgr.EnsureSingletons(context.Background(), bed.AdminUser, "test"))
+	})
+
+	t.Run("Record is created with defaults", func(t *testing.T) {
+		status, settings := read(bed.VisitorUser)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "My Site", settings.Title)
+
+		_, again := read(bed.AdminUser)
+		assert.Equal(t, settings.ID, again.ID, "There should be a single record")
+
+		var count int64
+		bed.Db.DB.Model(&SiteSettings{}).Count(&count)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("Update is validated and logged", func(t *testing.T) {
+		req := testPkg.CreateTestRequest(t, http.MethodPut, "/api/site-settings", `{"title": ""}`, true, bed.AdminUser, bed.Logger)
+		rr := testPkg.ExecuteHandler(t, update, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Validation failed")
+
+		req = testPkg.CreateTestRequest(t, http.MethodPut, "/api/site-settings", `{"title": "Renamed", "footer": "All rights reserved"}`, true, bed.AdminUser, bed.Logger)
+		rr = testPkg.ExecuteHandler(t, update, req)
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		_, settings := read(bed.AdminUser)
+		assert.Equal(t, "Renamed", settings.Title)
+		assert.Equal(t, "All rights reserved", settings.Footer)
+
+		log := dbModels.DatabaseLog{}
+		bed.Db.DB.Where("resource_name = ?", "SiteSettings").Order("id desc").First(&log)
+		assert.Equal(t, dbTypes.UpdateCRUDAction, log.Action)
+	})
+
+	t.Run("Permissions", func(t *testing.T) {
+		req := testPkg.CreateTestRequest(t, http.MethodPut, "/api/site-settings", `{"title": "Visitor"}`, true, bed.VisitorUser, bed.Logger)
+		rr := testPkg.ExecuteHandler(t, update, req)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+
+		status, _ := read(bed.NoRoleUser)
+		assert.Equal(t, http.StatusForbidden, status)
+	})
+
+	t.Run("Invalid Method", func(t *testing.T) {
+		req := testPkg.CreateTestRequest(t, http.MethodPost, "/api/site-settings", "", true, bed.AdminUser, bed.Logger)
+		rr := testPkg.ExecuteHandler(t, update, req)
+		assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+	})
+}
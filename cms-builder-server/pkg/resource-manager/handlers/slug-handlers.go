@@ -0,0 +1,293 @@
+package resourcemanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	dbQueries "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/queries"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	loggerTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/logger/types"
+	rmModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/models"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
+	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
+	"github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/utils"
+)
+
+var ErrSlugTaken = errors.New("slug is already taken")
+
+// slugAttempts bounds how many times a create is retried when a concurrent request takes the same slug.
+const slugAttempts = 5
+
+// DefaultBySlugHandler returns the record with the given slug.
+// Old slugs answer with a permanent redirect to the current one.
+var DefaultBySlugHandler rmTypes.ApiFunction = func(a *rmTypes.Resource, db *dbTypes.DatabaseConnection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestCtx := svrUtils.GetRequestContext(r)
+		log := requestCtx.Logger
+		user := requestCtx.User
+		isAdmin := user.HasRole(authConstants.AdminRole)
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodGet)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		// 2. Check Permissions
+		if !authUtils.UserIsAllowed(a.Permissions, user.GetRoles(), authConstants.OperationRead, a.ResourceNames.Singular, log) {
+			svrUtils.SendJsonResponse(w, http.StatusForbidden, nil, "User is not allowed to access this resource")
+			return
+		}
+
+		column, err := slugColumn(a, db)
+		if err != nil {
+			log.Error().Err(err).Msg("Error finding slug column")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, err.Error())
+			return
+		}
+
+		// 3. Construct Query (User Binding)
+		slug := svrUtils.GetUrlParam("slug", r)
+		filters := map[string]interface{}{
+			column: slug,
+		}
+
+		if !(a.SkipUserBinding || isAdmin) {
+			filters["created_by_id"] = user.ID
+		}
+
+		instance := a.GetOne()
+		err = dbQueries.FindOne(r.Context(), log, db, &instance, filters, []string{})
+		if err == nil {
+			svrUtils.SendJsonResponse(w, http.StatusOK, instance, a.ResourceNames.Singular+" Detail")
+			return
+		}
+
+		// 4. Follow Old Slugs
+		redirect := rmModels.SlugRedirect{}
+		err = db.DB.WithContext(r.Context()).
+			Where("resource_name = ? AND slug = ?", a.ResourceNames.Singular, slug).
+			First(&redirect).Error
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusNotFound, nil, "Instance not found")
+			return
+		}
+
+		delete(filters, column)
+		filters["id"] = redirect.ResourceId
+
+		instance = a.GetOne()
+		err = dbQueries.FindOne(r.Context(), log, db, &instance, filters, []string{})
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusNotFound, nil, "Instance not found")
+			return
+		}
+
+		current := slugValue(a, instance).String()
+		w.Header().Set("Location", strings.TrimSuffix(r.URL.Path, slug)+current)
+		svrUtils.SendJsonResponse(w, http.StatusMovedPermanently, map[string]string{"slug": current}, a.ResourceNames.Singular+" has moved")
+	}
+}
+
+// EnsureSlugIndex adds a unique index on the slug column of the given resource,
+// so concurrent requests cannot store the same slug twice.
+func EnsureSlugIndex(db *dbTypes.DatabaseConnection, a *rmTypes.Resource) error {
+	s, err := getModelSchema(a, db)
+	if err != nil {
+		return err
+	}
+
+	column, err := slugColumn(a, db)
+	if err != nil {
+		return err
+	}
+
+	return db.DB.Exec(
+		"CREATE UNIQUE INDEX IF NOT EXISTS ? ON ? (?)",
+		clause.Table{Name: fmt.Sprintf("idx_%s_%s", s.Table, column)},
+		clause.Table{Name: s.Table},
+		clause.Column{Name: column},
+	).Error
+}
+
+// createWithSlug assigns a unique slug to the given instance before saving it with create.
+// When a concurrent request took the same slug first, a new slug is generated and create runs again.
+func createWithSlug(ctx context.Context, db *dbTypes.DatabaseConnection, a *rmTypes.Resource, instance interface{}, create func() error) error {
+	requested := slugValue(a, instance).String()
+
+	for attempt := 1; ; attempt++ {
+		slugValue(a, instance).SetString(requested)
+
+		err := assignSlug(ctx, db, a, instance, "")
+		if err != nil {
+			return err
+		}
+
+		err = create()
+		if !isUniqueViolation(err) || attempt == slugAttempts {
+			return err
+		}
+	}
+}
+
+// updateSlug assigns the slug of an updated instance.
+// An explicit slug in the request wins, otherwise the slug follows the source field when
+// the resource regenerates slugs on update. It returns the slug the record used before.
+func updateSlug(ctx context.Context, db *dbTypes.DatabaseConnection, a *rmTypes.Resource, instance interface{}, previousState interface{}) (string, error) {
+	previousSlug := slugValue(a, previousState).String()
+	requested := slugValue(a, instance).String()
+
+	sourceChanged := sourceValue(a, instance).String() != sourceValue(a, previousState).String()
+	if requested == previousSlug && !(a.Slug.RegenerateOnUpdate && sourceChanged) {
+		return previousSlug, nil
+	}
+
+	if requested == previousSlug {
+		slugValue(a, instance).SetString("")
+	}
+
+	id := fmt.Sprint(reflect.ValueOf(instance).Elem().FieldByName("ID").Interface())
+	return previousSlug, assignSlug(ctx, db, a, instance, id)
+}
+
+// saveSlugRedirect keeps the previous slug of an updated record as a redirect to it.
+// A redirect the record left behind earlier is dropped when the record takes that slug back.
+func saveSlugRedirect(ctx context.Context, log *loggerTypes.Logger, db *dbTypes.DatabaseConnection, a *rmTypes.Resource, instance interface{}, previousSlug string, user *authModels.User, requestId string) error {
+	currentSlug := slugValue(a, instance).String()
+	if previousSlug == "" || previousSlug == currentSlug {
+		return nil
+	}
+
+	id := fmt.Sprint(reflect.ValueOf(instance).Elem().FieldByName("ID").Interface())
+
+	err := db.DB.WithContext(ctx).Unscoped().
+		Where("resource_name = ? AND slug = ? AND resource_id = ?", a.ResourceNames.Singular, currentSlug, id).
+		Delete(&rmModels.SlugRedirect{}).Error
+	if err != nil {
+		return err
+	}
+
+	var count int64
+	err = db.DB.WithContext(ctx).Unscoped().Model(&rmModels.SlugRedirect{}).
+		Where("resource_name = ? AND slug = ?", a.ResourceNames.Singular, previousSlug).
+		Count(&count).Error
+	if err != nil || count > 0 {
+		return err
+	}
+
+	redirect := rmModels.SlugRedirect{
+		ResourceName: a.ResourceNames.Singular,
+		Slug:         previousSlug,
+		ResourceId:   id,
+	}
+	redirect.CreatedByID = user.ID
+	redirect.UpdatedByID = user.ID
+
+	return dbQueries.Create(ctx, log, db, &redirect, user, requestId)
+}
+
+// assignSlug sets the slug of the given instance to a unique slug based on the requested slug,
+// or on the source field when none was requested. The record with the given id is ignored
+// when checking uniqueness.
+func assignSlug(ctx context.Context, db *dbTypes.DatabaseConnection, a *rmTypes.Resource, instance interface{}, id string) error {
+	base := utils.Slugify(slugValue(a, instance).String())
+	if base == "" {
+		base = utils.Slugify(sourceValue(a, instance).String())
+	}
+	if base == "" {
+		base = a.ResourceNames.KebabSingular
+	}
+
+	column, err := slugColumn(a, db)
+	if err != nil {
+		return err
+	}
+
+	for n := 1; ; n++ {
+		candidate := base
+		if n > 1 {
+			candidate = fmt.Sprintf("%s-%d", base, n)
+		}
+
+		taken, err := slugTaken(ctx, db, a, column, candidate, id)
+		if err != nil {
+			return err
+		}
+
+		if !taken {
+			slugValue(a, instance).SetString(candidate)
+			return nil
+		}
+	}
+}
+
+// slugTaken reports whether another record uses the given slug, or used it and left a redirect behind.
+// Soft deleted records keep their slug.
+func slugTaken(ctx context.Context, db *dbTypes.DatabaseConnection, a *rmTypes.Resource, column string, slug string, id string) (bool, error) {
+	query := db.DB.WithContext(ctx).Unscoped().Model(a.Model).Where(clause.Eq{Column: clause.Column{Name: column}, Value: slug})
+	if id != "" {
+		query = query.Where("id <> ?", id)
+	}
+
+	var count int64
+	err := query.Count(&count).Error
+	if err != nil || count > 0 {
+		return count > 0, err
+	}
+
+	query = db.DB.WithContext(ctx).Unscoped().Model(&rmModels.SlugRedirect{}).
+		Where("resource_name = ? AND slug = ?", a.ResourceNames.Singular, slug)
+	if id != "" {
+		query = query.Where("resource_id <> ?", id)
+	}
+
+	err = query.Count(&count).Error
+	return count > 0, err
+}
+
+func slugColumn(a *rmTypes.Resource, db *dbTypes.DatabaseConnection) (string, error) {
+	s, err := getModelSchema(a, db)
+	if err != nil {
+		return "", err
+	}
+
+	field, ok := s.FieldsByName[a.Slug.Field]
+	if !ok || field.DBName == "" {
+		return "", fmt.Errorf("slug field %s not found in model", a.Slug.Field)
+	}
+
+	return field.DBName, nil
+}
+
+func slugValue(a *rmTypes.Resource, instance interface{}) reflect.Value {
+	return reflect.ValueOf(instance).Elem().FieldByName(a.Slug.Field)
+}
+
+func sourceValue(a *rmTypes.Resource, instance interface{}) reflect.Value {
+	return reflect.ValueOf(instance).Elem().FieldByName(a.Slug.Source)
+}
+
+// isUniqueViolation reports whether the given error comes from a unique index, as reported by sqlite or postgres.
+func isUniqueViolation(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "unique constraint") || strings.Contains(msg, "duplicate key")
+}
@@ -0,0 +1,120 @@
+package resourcemanager_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	rmModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/models"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
+	testPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/testing"
+)
+
+type BlogPost struct {
+	authModels.SystemData
+	Title string `json:"title"`
+	Slug  string `json:"slug"`
+}
+
+func TestSlugHandlers(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+	bed.Db.DB.Unscoped().Where("1 = 1").Delete(&BlogPost{})
+	bed.Db.DB.Unscoped().Where("resource_name = ?", "BlogPost").Delete(&rmModels.SlugRedirect{})
+
+	resource, err := bed.Mgr.AddResource(&rmTypes.ResourceConfig{
+		Model: BlogPost{},
+		Slug: &rmTypes.SlugConfig{
+			Field:              "Slug",
+			Source:             "Title",
+			RegenerateOnUpdate: true,
+		},
+		Permissions: authTypes.RolePermissionMap{
+			authConstants.AdminRole: authConstants.AllAllowedAccess,
+		},
+	})
+	assert.NoError(t, err)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/blog-posts/new", resource.Api.Create(resource, bed.Db))
+	router.HandleFunc("/blog-posts/by-slug/{slug}", resource.Api.BySlug(resource, bed.Db))
+	router.HandleFunc("/blog-posts/{id}/update", resource.Api.Update(resource, bed.Db))
+
+	send := func(method string, path string, body string) *httptest.ResponseRecorder {
+		req := testPkg.CreateTestRequest(t, method, path, body, true, bed.AdminUser, bed.Logger)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	decode := func(rr *httptest.ResponseRecorder) BlogPost {
+		response := struct {
+			Data BlogPost `json:"data"`
+		}{}
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		return response.Data
+	}
+
+	create := func(body string) BlogPost {
+		rr := send(http.MethodPost, "/blog-posts/new", body)
+		assert.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+		return decode(rr)
+	}
+
+	first := create(`{"title": "Hello World!"}`)
+	second := create(`{"title": "Hello, world"}`)
+	custom := create(`{"title": "Anything", "slug": "My Custom Slug"}`)
+
+	t.Run("Create generates unique slugs", func(t *testing.T) {
+		assert.Equal(t, "hello-world", first.Slug)
+		assert.Equal(t, "hello-world-2", second.Slug)
+		assert.Equal(t, "my-custom-slug", custom.Slug)
+
+		duplicate := BlogPost{Title: "Duplicate", Slug: "hello-world"}
+		assert.Error(t, bed.Db.DB.Create(&duplicate).Error, "The slug column should have a unique index")
+	})
+
+	t.Run("Lookup by slug", func(t *testing.T) {
+		rr := send(http.MethodGet, "/blog-posts/by-slug/hello-world-2", "")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, second.ID, decode(rr).ID)
+
+		rr = send(http.MethodGet, "/blog-posts/by-slug/nothing-here", "")
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Update regenerates the slug and redirects the old one", func(t *testing.T) {
+		rr := send(http.MethodPut, fmt.Sprintf("/blog-posts/%d/update", first.ID), `{"title": "Goodbye World"}`)
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.Equal(t, "goodbye-world", decode(rr).Slug)
+
+		rr = send(http.MethodGet, "/blog-posts/by-slug/hello-world", "")
+		assert.Equal(t, http.StatusMovedPermanently, rr.Code)
+		assert.Equal(t, "/blog-posts/by-slug/goodbye-world", rr.Header().Get("Location"))
+
+		rr = send(http.MethodPost, "/blog-posts/new", `{"title": "Hello World"}`)
+		assert.Equal(t, "hello-world-3", decode(rr).Slug, "Old slugs stay reserved for their redirect")
+	})
+
+	t.Run("Explicit slug changes win and can take back an old slug", func(t *testing.T) {
+		rr := send(http.MethodPut, fmt.Sprintf("/blog-posts/%d/update", first.ID), `{"slug": "hello-world"}`)
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.Equal(t, "hello-world", decode(rr).Slug)
+
+		rr = send(http.MethodGet, "/blog-posts/by-slug/hello-world", "")
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		rr = send(http.MethodGet, "/blog-posts/by-slug/goodbye-world", "")
+		assert.Equal(t, http.StatusMovedPermanently, rr.Code)
+
+		rr = send(http.MethodPut, fmt.Sprintf("/blog-posts/%d/update", second.ID), `{"slug": "my-custom-slug"}`)
+		assert.Equal(t, "my-custom-slug-2", decode(rr).Slug, "Taken slugs get a suffix")
+	})
+}
@@ -0,0 +1,447 @@
+package resourcemanager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	dbQueries "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/queries"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	rmModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/models"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
+	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
+	"github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/utils"
+)
+
+var ErrUnsupportedLocale = errors.New("unsupported locale")
+
+type MissingTranslation struct {
+	ID     interface{} `json:"id"`
+	Fields []string    `json:"fields"`
+}
+
+// DefaultTranslationsHandler returns the translations of a record grouped by locale and field.
+var DefaultTranslationsHandler rmTypes.ApiFunction = func(a *rmTypes.Resource, db *dbTypes.DatabaseConnection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestCtx := svrUtils.GetRequestContext(r)
+		log := requestCtx.Logger
+		user := requestCtx.User
+		isAdmin := user.HasRole(authConstants.AdminRole)
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodGet)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		// 2. Check Permissions
+		if !authUtils.UserIsAllowed(a.Permissions, user.GetRoles(), authConstants.OperationRead, a.ResourceNames.Singular, log) {
+			svrUtils.SendJsonResponse(w, http.StatusForbidden, nil, "User is not allowed to access this resource")
+			return
+		}
+
+		// 3. Find Instance (User Binding)
+		filters := map[string]interface{}{
+			"id": svrUtils.GetUrlParam("id", r),
+		}
+
+		if !(a.SkipUserBinding || isAdmin) {
+			filters["created_by_id"] = user.ID
+		}
+
+		instance := a.GetOne()
+		err = dbQueries.FindOne(r.Context(), log, db, &instance, filters, []string{})
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusNotFound, nil, "Instance not found")
+			return
+		}
+
+		// 4. Group Translations
+		translations := []rmModels.Translation{}
+		err = db.DB.WithContext(r.Context()).
+			Where("resource_name = ? AND resource_id = ?", a.ResourceNames.Singular, svrUtils.GetUrlParam("id", r)).
+			Find(&translations).Error
+		if err != nil {
+			log.Error().Err(err).Msg("Error finding translations")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error finding translations")
+			return
+		}
+
+		output := map[string]map[string]string{}
+		for _, translation := range translations {
+			if output[translation.Locale] == nil {
+				output[translation.Locale] = map[string]string{}
+			}
+			output[translation.Locale][translation.Field] = translation.Value
+		}
+
+		svrUtils.SendJsonResponse(w, http.StatusOK, output, a.ResourceNames.Singular+" Translations")
+	}
+}
+
+// DefaultTranslateHandler stores the translatable fields of the request body in the locale query parameter.
+// Every created or changed translation is recorded in the database log.
+var DefaultTranslateHandler rmTypes.ApiFunction = func(a *rmTypes.Resource, db *dbTypes.DatabaseConnection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestCtx := svrUtils.GetRequestContext(r)
+		log := requestCtx.Logger
+		user := requestCtx.User
+		requestId := requestCtx.RequestId
+		isAdmin := user.HasRole(authConstants.AdminRole)
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodPut)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		// 2. Check Permissions
+		if !authUtils.UserIsAllowed(a.Permissions, user.GetRoles(), authConstants.OperationUpdate, a.ResourceNames.Singular, log) {
+			svrUtils.SendJsonResponse(w, http.StatusForbidden, nil, "User is not allowed to update this resource")
+			return
+		}
+
+		// 3. Validate Locale
+		locale := svrUtils.GetQueryParam(r, "locale")
+		if !a.Locales.IsSupported(locale) || locale == a.Locales.Default {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, fmt.Sprintf("Invalid locale, expected one of %s other than %s", strings.Join(a.Locales.Supported, ", "), a.Locales.Default))
+			return
+		}
+
+		// 4. Find Instance (User Binding)
+		id := svrUtils.GetUrlParam("id", r)
+		filters := map[string]interface{}{
+			"id": id,
+		}
+
+		if !(a.SkipUserBinding || isAdmin) {
+			filters["created_by_id"] = user.ID
+		}
+
+		instance := a.GetOne()
+		err = dbQueries.FindOne(r.Context(), log, db, &instance, filters, []string{})
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusNotFound, nil, "Instance not found")
+			return
+		}
+
+		// 5. Reject Translations While Another User Holds The Lock
+		if !checkLock(w, r, a, db, instance) {
+			return
+		}
+
+		// 6. Parse Request Body
+		body := map[string]string{}
+		data, err := svrUtils.ReadRequestBody(r)
+		if err == nil {
+			err = json.Unmarshal(data, &body)
+		}
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, "Invalid request body, expected an object of strings")
+			return
+		}
+
+		keys := translatableKeys(a)
+		for key := range body {
+			if !slices.Contains(keys, key) {
+				svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, fmt.Sprintf("Field %s is not translatable", key))
+				return
+			}
+		}
+
+		// 7. Save Translations
+		err = db.DB.WithContext(r.Context()).Transaction(func(tx *gorm.DB) error {
+			txDb := &dbTypes.DatabaseConnection{DB: tx}
+
+			for key, value := range body {
+				translation := rmModels.Translation{}
+				err := tx.Where("resource_name = ? AND resource_id = ? AND field = ? AND locale = ?", a.ResourceNames.Singular, id, key, locale).
+					First(&translation).Error
+
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					translation = rmModels.Translation{
+						ResourceName: a.ResourceNames.Singular,
+						ResourceId:   id,
+						Field:        key,
+						Locale:       locale,
+						Value:        value,
+					}
+					translation.CreatedByID = user.ID
+					translation.UpdatedByID = user.ID
+
+					err = dbQueries.Create(r.Context(), log, txDb, &translation, user, requestId)
+					if err != nil {
+						return err
+					}
+					continue
+				}
+				if err != nil {
+					return err
+				}
+
+				if translation.Value == value {
+					continue
+				}
+
+				previousState := translation
+				translation.Value = value
+				translation.UpdatedByID = user.ID
+
+				differences := utils.CompareInterfaces(previousState, translation)
+				err = dbQueries.Update(r.Context(), log, txDb, &translation, user, differences, requestId)
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			log.Error().Err(err).Msg("Error saving translations")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error saving translations")
+			return
+		}
+
+		svrUtils.SendJsonResponse(w, http.StatusOK, body, a.ResourceNames.Singular+" has been translated")
+	}
+}
+
+// DefaultMissingTranslationsHandler lists the records that miss a translation in the locale query parameter,
+// along with the json keys of the missing fields. Fields that are empty in the default locale are not reported.
+var DefaultMissingTranslationsHandler rmTypes.ApiFunction = func(a *rmTypes.Resource, db *dbTypes.DatabaseConnection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestCtx := svrUtils.GetRequestContext(r)
+		log := requestCtx.Logger
+		user := requestCtx.User
+		isAdmin := user.HasRole(authConstants.AdminRole)
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodGet)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		// 2. Check Permissions
+		if !authUtils.UserIsAllowed(a.Permissions, user.GetRoles(), authConstants.OperationRead, a.ResourceNames.Singular, log) {
+			svrUtils.SendJsonResponse(w, http.StatusForbidden, nil, "User is not allowed to read this resource")
+			return
+		}
+
+		// 3. Validate Locale
+		locale := svrUtils.GetQueryParam(r, "locale")
+		if !a.Locales.IsSupported(locale) || locale == a.Locales.Default {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, fmt.Sprintf("Invalid locale, expected one of %s other than %s", strings.Join(a.Locales.Supported, ", "), a.Locales.Default))
+			return
+		}
+
+		// 4. Find Instances (User Binding)
+		instances, err := a.GetSlice()
+		if err != nil {
+			log.Error().Err(err).Msgf("Error creating slice for model")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, err.Error())
+			return
+		}
+
+		query := db.DB.WithContext(r.Context()).Model(a.Model)
+		if !(a.SkipUserBinding || isAdmin) {
+			query = query.Where("created_by_id = ?", user.ID)
+		}
+
+		err = query.Order("id").Find(instances).Error
+		if err != nil {
+			log.Error().Err(err).Msg("Error finding instances")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error finding instances")
+			return
+		}
+
+		items, err := toItems(instances)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, err.Error())
+			return
+		}
+
+		// 5. Compare with existing translations
+		translations, err := findTranslations(r.Context(), db, a, items, []string{locale})
+		if err != nil {
+			log.Error().Err(err).Msg("Error finding translations")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error finding translations")
+			return
+		}
+
+		missing := []MissingTranslation{}
+		for _, item := range items {
+			id := fmt.Sprint(item["ID"])
+
+			fields := []string{}
+			for _, key := range translatableKeys(a) {
+				if value, ok := item[key].(string); !ok || value == "" {
+					continue
+				}
+				if _, ok := translations[id][key][locale]; !ok {
+					fields = append(fields, key)
+				}
+			}
+
+			if len(fields) > 0 {
+				missing = append(missing, MissingTranslation{ID: item["ID"], Fields: fields})
+			}
+		}
+
+		svrUtils.SendJsonResponse(w, http.StatusOK, missing, a.ResourceNames.Plural+" missing "+locale+" translations")
+	}
+}
+
+// ResolveLocale returns the locale requested with the locale query parameter or, when it is missing,
+// the first supported language of the Accept-Language header. It defaults to the default locale.
+func ResolveLocale(r *http.Request, a *rmTypes.Resource) (string, error) {
+	if a.Locales == nil {
+		return "", nil
+	}
+
+	if locale := svrUtils.GetQueryParam(r, "locale"); locale != "" {
+		if !a.Locales.IsSupported(locale) {
+			return "", fmt.Errorf("%w %s, expected one of %s", ErrUnsupportedLocale, locale, strings.Join(a.Locales.Supported, ", "))
+		}
+		return locale, nil
+	}
+
+	for _, language := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		language = strings.TrimSpace(strings.Split(language, ";")[0])
+		if language == "" {
+			continue
+		}
+
+		if a.Locales.IsSupported(language) {
+			return language, nil
+		}
+
+		base := strings.Split(language, "-")[0]
+		if a.Locales.IsSupported(base) {
+			return base, nil
+		}
+	}
+
+	return a.Locales.Default, nil
+}
+
+// LocalizeInstances replaces the translatable fields of the given instance or slice of instances
+// with their value in the given locale, following the fallback chain down to the default locale.
+// The result is a map or a slice of maps ready to be sent.
+func LocalizeInstances(ctx context.Context, db *dbTypes.DatabaseConnection, a *rmTypes.Resource, instances interface{}, locale string) (interface{}, error) {
+	if len(a.Translatable) == 0 || a.Locales == nil {
+		return instances, nil
+	}
+
+	chain := a.Locales.Chain(locale)
+	if len(chain) == 0 {
+		return instances, nil
+	}
+
+	single, err := rmTypes.InterfaceToMap(instances)
+	isSingle := err == nil
+
+	items := []map[string]interface{}{single}
+	if !isSingle {
+		items, err = toItems(instances)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	translations, err := findTranslations(ctx, db, a, items, chain)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		fields := translations[fmt.Sprint(item["ID"])]
+		for _, key := range translatableKeys(a) {
+			for _, candidate := range chain {
+				if value, ok := fields[key][candidate]; ok {
+					item[key] = value
+					break
+				}
+			}
+		}
+	}
+
+	if isSingle {
+		return single, nil
+	}
+
+	return items, nil
+}
+
+// setContentLanguage tells the client which locale the response was localized in.
+func setContentLanguage(w http.ResponseWriter, locale string) {
+	if locale != "" {
+		w.Header().Set("Content-Language", locale)
+	}
+}
+
+// findTranslations returns the translations of the given items in the given locales, indexed by id, field and locale.
+func findTranslations(ctx context.Context, db *dbTypes.DatabaseConnection, a *rmTypes.Resource, items []map[string]interface{}, locales []string) (map[string]map[string]map[string]string, error) {
+	output := map[string]map[string]map[string]string{}
+	if len(items) == 0 {
+		return output, nil
+	}
+
+	ids := []string{}
+	for _, item := range items {
+		ids = append(ids, fmt.Sprint(item["ID"]))
+	}
+
+	translations := []rmModels.Translation{}
+	err := db.DB.WithContext(ctx).
+		Where("resource_name = ? AND resource_id IN ? AND locale IN ?", a.ResourceNames.Singular, ids, locales).
+		Find(&translations).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, translation := range translations {
+		if output[translation.ResourceId] == nil {
+			output[translation.ResourceId] = map[string]map[string]string{}
+		}
+		if output[translation.ResourceId][translation.Field] == nil {
+			output[translation.ResourceId][translation.Field] = map[string]string{}
+		}
+		output[translation.ResourceId][translation.Field][translation.Locale] = translation.Value
+	}
+
+	return output, nil
+}
+
+func translatableKeys(a *rmTypes.Resource) []string {
+	keys := []string{}
+	for _, fieldName := range a.Translatable {
+		keys = append(keys, a.FieldNames[fieldName])
+	}
+	return keys
+}
+
+func toItems(instances interface{}) ([]map[string]interface{}, error) {
+	jsonData, err := json.Marshal(instances)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []map[string]interface{}{}
+	err = json.Unmarshal(jsonData, &items)
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
@@ -0,0 +1,194 @@
+package resourcemanager_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	dbModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/models"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	rmHandlers "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/handlers"
+	rmModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/models"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
+	testPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/testing"
+)
+
+type Recipe struct {
+	authModels.SystemData
+	Title    string `json:"title"`
+	Summary  string `json:"summary"`
+	Servings int    `json:"servings"`
+}
+
+func TestTranslationHandlers(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+
+	err := bed.Mgr.SetLocales(&rmTypes.LocaleConfig{
+		Default:   "en",
+		Supported: []string{"en", "es", "es-AR", "fr"},
+		Fallbacks: map[string]string{"es-AR": "es"},
+	})
+	assert.NoError(t, err)
+
+	resource, err := bed.Mgr.AddResource(&rmTypes.ResourceConfig{
+		Model:        Recipe{},
+		Translatable: []string{"Title", "Summary"},
+		Permissions: authTypes.RolePermissionMap{
+			authConstants.AdminRole: authConstants.AllAllowedAccess,
+		},
+	})
+	assert.NoError(t, err)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/recipes", resource.Api.List(resource, bed.Db))
+	router.HandleFunc("/recipes/translations/missing", resource.Api.MissingTranslations(resource, bed.Db))
+	router.HandleFunc("/recipes/{id}/translations", resource.Api.Translations(resource, bed.Db)).Methods(http.MethodGet)
+	router.HandleFunc("/recipes/{id}/translations", resource.Api.Translate(resource, bed.Db)).Methods(http.MethodPut)
+	router.HandleFunc("/recipes/{id}", resource.Api.Detail(resource, bed.Db))
+
+	send := func(method string, path string, body string, headers map[string]string) *httptest.ResponseRecorder {
+		req := testPkg.CreateTestRequest(t, method, path, body, true, bed.AdminUser, bed.Logger)
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	detail := func(query string, headers map[string]string) (*httptest.ResponseRecorder, Recipe) {
+		response := struct {
+			Data Recipe `json:"data"`
+		}{}
+
+		rr := send(http.MethodGet, "/recipes/"+query, "", headers)
+		if rr.Code == http.StatusOK {
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		}
+		return rr, response.Data
+	}
+
+	recipe := Recipe{Title: "Bread", Summary: "Flour and water", Servings: 4}
+	recipe.CreatedByID = bed.AdminUser.ID
+	assert.NoError(t, bed.Db.DB.Create(&recipe).Error)
+	id := fmt.Sprint(recipe.ID)
+
+	t.Run("Translate stores the values and logs them", func(t *testing.T) {
+		rr := send(http.MethodPut, "/recipes/"+id+"/translations?locale=es", `{"title": "Pan", "summary": "Harina y agua"}`, nil)
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		rr = send(http.MethodPut, "/recipes/"+id+"/translations?locale=es", `{"title": "Pan casero"}`, nil)
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		rr = send(http.MethodGet, "/recipes/"+id+"/translations", "", nil)
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		response := struct {
+			Data map[string]map[string]string `json:"data"`
+		}{}
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, map[string]string{"title": "Pan casero", "summary": "Harina y agua"}, response.Data["es"])
+
+		logs := []dbModels.DatabaseLog{}
+		bed.Db.DB.Where("resource_name = ?", "Translation").Order("id desc").Limit(1).Find(&logs)
+		assert.Len(t, logs, 1)
+		assert.Equal(t, dbTypes.UpdateCRUDAction, logs[0].Action)
+	})
+
+	t.Run("Translate rejects invalid input", func(t *testing.T) {
+		rr := send(http.MethodPut, "/recipes/"+id+"/translations?locale=en", `{"title": "Bread"}`, nil)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, "The default locale lives in the model")
+
+		rr = send(http.MethodPut, "/recipes/"+id+"/translations?locale=de", `{"title": "Brot"}`, nil)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+		rr = send(http.MethodPut, "/recipes/"+id+"/translations?locale=fr", `{"servings": "2"}`, nil)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Body.String(), "not translatable")
+
+		rr = send(http.MethodPut, "/recipes/99999/translations?locale=fr", `{"title": "Pain"}`, nil)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Detail is localized", func(t *testing.T) {
+		rr, data := detail(id+"?locale=es", nil)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "Pan casero", data.Title)
+		assert.Equal(t, 4, data.Servings)
+		assert.Equal(t, "es", rr.Header().Get("Content-Language"))
+
+		_, data = detail(id+"?locale=es-AR", nil)
+		assert.Equal(t, "Pan casero", data.Title, "es-AR should fall back to es")
+
+		_, data = detail(id+"?locale=fr", nil)
+		assert.Equal(t, "Bread", data.Title, "Missing translations should fall back to the default locale")
+
+		rr, data = detail(id, map[string]string{"Accept-Language": "de-DE, es-MX;q=0.8"})
+		assert.Equal(t, "Pan casero", data.Title, "es-MX should match the es base language")
+		assert.Equal(t, "es", rr.Header().Get("Content-Language"))
+
+		rr, _ = detail(id+"?locale=de", nil)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("List is localized", func(t *testing.T) {
+		rr := send(http.MethodGet, "/recipes?limit=100&locale=es", "", nil)
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		response := struct {
+			Data []Recipe `json:"data"`
+		}{}
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+
+		titles := map[uint]string{}
+		for _, item := range response.Data {
+			titles[item.ID] = item.Title
+		}
+		assert.Equal(t, "Pan casero", titles[recipe.ID])
+	})
+
+	t.Run("Missing translations", func(t *testing.T) {
+		missing := func(locale string) map[uint][]string {
+			rr := send(http.MethodGet, "/recipes/translations/missing?locale="+locale, "", nil)
+			assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+			response := struct {
+				Data []rmHandlers.MissingTranslation `json:"data"`
+			}{}
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+
+			output := map[uint][]string{}
+			for _, item := range response.Data {
+				output[uint(item.ID.(float64))] = item.Fields
+			}
+			return output
+		}
+
+		assert.NotContains(t, missing("es"), recipe.ID)
+		assert.Equal(t, []string{"title", "summary"}, missing("fr")[recipe.ID])
+
+		rr := send(http.MethodGet, "/recipes/translations/missing?locale=en", "", nil)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Translate respects locks of other users", func(t *testing.T) {
+		lock := rmModels.RecordLock{ResourceName: "Recipe", ResourceId: id, UserID: bed.VisitorUser.ID, UserEmail: bed.VisitorUser.Email, ExpiresAt: time.Now().Add(time.Minute)}
+		assert.NoError(t, bed.Db.DB.Create(&lock).Error)
+		defer bed.Db.DB.Unscoped().Delete(&lock)
+
+		rr := send(http.MethodPut, "/recipes/"+id+"/translations?locale=fr", `{"title": "Pain"}`, nil)
+		assert.Equal(t, http.StatusConflict, rr.Code)
+
+		rr = send(http.MethodPut, "/recipes/"+id+"/translations?locale=fr&force=true", `{"title": "Pain"}`, nil)
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	})
+}
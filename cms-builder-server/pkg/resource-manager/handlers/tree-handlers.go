@@ -0,0 +1,579 @@
+package resourcemanager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"gorm.io/gorm"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/utils"
+	dbQueries "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/queries"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	loggerTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/logger/types"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
+	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
+	"github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/utils"
+)
+
+var (
+	ErrTreeNodeNotFound   = errors.New("node not found")
+	ErrTreeParentNotFound = errors.New("parent not found")
+	ErrTreeCycle          = errors.New("a node cannot be moved below itself or its descendants")
+	ErrTreeInvalidOrder   = errors.New("ids must contain every sibling exactly once")
+	ErrTreeHasChildren    = errors.New("node has children, move or delete them first")
+)
+
+// treeKeys are managed by the tree endpoints and cannot be written through the update handler.
+var treeKeys = []string{"parentId", "position", "path"}
+
+// treeRow holds the tree columns of a node.
+type treeRow struct {
+	ID       uint
+	ParentID *uint
+	Position int
+	Path     string
+}
+
+type TreeMoveRequest struct {
+	ParentID *uint `json:"parentId"`
+	Position *int  `json:"position"` // Appends the node to its new siblings when nil
+}
+
+type TreeReorderRequest struct {
+	ParentID *uint  `json:"parentId"`
+	IDs      []uint `json:"ids"`
+}
+
+// DefaultTreeHandler returns the nodes of the resource nested under a children key.
+// The root query parameter limits the response to the subtree of the given node.
+var DefaultTreeHandler rmTypes.ApiFunction = func(a *rmTypes.Resource, db *dbTypes.DatabaseConnection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestCtx := svrUtils.GetRequestContext(r)
+		log := requestCtx.Logger
+		user := requestCtx.User
+		isAdmin := user.HasRole(authConstants.AdminRole)
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodGet)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		// 2. Check Permissions
+		if !authUtils.UserIsAllowed(a.Permissions, user.GetRoles(), authConstants.OperationRead, a.ResourceNames.Singular, log) {
+			svrUtils.SendJsonResponse(w, http.StatusForbidden, nil, "User is not allowed to read this resource")
+			return
+		}
+
+		// 3. Construct Query
+		tx := db.DB.WithContext(r.Context())
+		query := treeScope(tx, a, user, isAdmin)
+
+		if root := svrUtils.GetQueryParam(r, "root"); root != "" {
+			rootRow := treeRow{}
+			err = treeScope(tx, a, user, isAdmin).Where("id = ?", root).First(&rootRow).Error
+			if err != nil {
+				svrUtils.SendJsonResponse(w, http.StatusNotFound, nil, "Root node not found")
+				return
+			}
+			query = query.Where("path LIKE ?", rootRow.Path+"%")
+		}
+
+		// 4. Execute Query
+		instances, err := a.GetSlice()
+		if err != nil {
+			log.Error().Err(err).Msgf("Error creating slice for model")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, err.Error())
+			return
+		}
+
+		err = query.Order("position, id").Find(instances).Error
+		if err != nil {
+			log.Error().Err(err).Msg("Error finding tree nodes")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error finding tree nodes")
+			return
+		}
+
+		// 5. Nest Nodes
+		nodes, err := nestTreeNodes(instances)
+		if err != nil {
+			log.Error().Err(err).Msg("Error nesting tree nodes")
+			svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error nesting tree nodes")
+			return
+		}
+
+		svrUtils.SendJsonResponse(w, http.StatusOK, nodes, a.ResourceNames.Singular+" Tree")
+	}
+}
+
+// DefaultMoveHandler reparents and reorders a node in a single transaction.
+// Siblings are renumbered, descendant paths are rewritten and the move is logged as an update.
+var DefaultMoveHandler rmTypes.ApiFunction = func(a *rmTypes.Resource, db *dbTypes.DatabaseConnection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestCtx := svrUtils.GetRequestContext(r)
+		log := requestCtx.Logger
+		user := requestCtx.User
+		requestId := requestCtx.RequestId
+		isAdmin := user.HasRole(authConstants.AdminRole)
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodPut)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		// 2. Check Permissions
+		if !authUtils.UserIsAllowed(a.Permissions, user.GetRoles(), authConstants.OperationUpdate, a.ResourceNames.Singular, log) {
+			svrUtils.SendJsonResponse(w, http.StatusForbidden, nil, "User is not allowed to update this resource")
+			return
+		}
+
+		// 3. Parse Request Body
+		input := TreeMoveRequest{}
+		body, err := svrUtils.ReadRequestBody(r)
+		if err == nil {
+			err = json.Unmarshal(body, &input)
+		}
+		if err != nil || (input.Position != nil && *input.Position < 0) {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, "Invalid request body")
+			return
+		}
+
+		// 4. Reject Moves While Another User Holds The Lock
+		if !checkTreeLocks(w, r, a, db, user, isAdmin, []string{svrUtils.GetUrlParam("id", r)}) {
+			return
+		}
+
+		// 5. Move Node
+		var node interface{}
+		err = db.DB.WithContext(r.Context()).Transaction(func(tx *gorm.DB) error {
+			node, err = moveTreeNode(r.Context(), log, tx, a, svrUtils.GetUrlParam("id", r), input, user, isAdmin, requestId)
+			return err
+		})
+		if err != nil {
+			sendTreeError(w, log, err)
+			return
+		}
+
+		svrUtils.SendJsonResponse(w, http.StatusOK, node, a.ResourceNames.Singular+" has been moved")
+	}
+}
+
+// DefaultReorderHandler sets the position of every child of a parent from the given list of ids.
+var DefaultReorderHandler rmTypes.ApiFunction = func(a *rmTypes.Resource, db *dbTypes.DatabaseConnection) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestCtx := svrUtils.GetRequestContext(r)
+		log := requestCtx.Logger
+		user := requestCtx.User
+		requestId := requestCtx.RequestId
+		isAdmin := user.HasRole(authConstants.AdminRole)
+
+		// 1. Validate Request Method
+		err := svrUtils.ValidateRequestMethod(r, http.MethodPut)
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusMethodNotAllowed, nil, err.Error())
+			return
+		}
+
+		// 2. Check Permissions
+		if !authUtils.UserIsAllowed(a.Permissions, user.GetRoles(), authConstants.OperationUpdate, a.ResourceNames.Singular, log) {
+			svrUtils.SendJsonResponse(w, http.StatusForbidden, nil, "User is not allowed to update this resource")
+			return
+		}
+
+		// 3. Parse Request Body
+		input := TreeReorderRequest{}
+		body, err := svrUtils.ReadRequestBody(r)
+		if err == nil {
+			err = json.Unmarshal(body, &input)
+		}
+		if err != nil {
+			svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, "Invalid request body")
+			return
+		}
+
+		// 4. Reject Reorders While Another User Holds The Lock of a Sibling
+		if !checkTreeLocks(w, r, a, db, user, isAdmin, input.IDs) {
+			return
+		}
+
+		// 5. Reorder Siblings
+		err = db.DB.WithContext(r.Context()).Transaction(func(tx *gorm.DB) error {
+			return reorderTreeNodes(r.Context(), log, tx, a, input, user, isAdmin, requestId)
+		})
+		if err != nil {
+			sendTreeError(w, log, err)
+			return
+		}
+
+		svrUtils.SendJsonResponse(w, http.StatusOK, input.IDs, a.ResourceNames.Plural+" have been reordered")
+	}
+}
+
+// createTreeNode appends the node to the children of its parent and stores its materialized path.
+func createTreeNode(ctx context.Context, log *loggerTypes.Logger, db *dbTypes.DatabaseConnection, a *rmTypes.Resource, instance interface{}, user *authModels.User, isAdmin bool, requestId string) error {
+	node, ok := instance.(rmTypes.TreeNode)
+	if !ok {
+		return fmt.Errorf("%s does not embed TreeData", a.ResourceNames.Singular)
+	}
+	treeData := node.GetTreeData()
+
+	return db.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		parentPath, err := treeParentPath(tx, a, treeData.ParentID, user, isAdmin)
+		if err != nil {
+			return err
+		}
+
+		// Siblings of every user share the positions under the parent
+		siblings, err := treeSiblings(tx.Model(a.Model), treeData.ParentID)
+		if err != nil {
+			return err
+		}
+
+		treeData.Position = 0
+		if len(siblings) > 0 {
+			treeData.Position = siblings[len(siblings)-1].Position + 1
+		}
+		treeData.Path = ""
+
+		err = dbQueries.Create(ctx, log, &dbTypes.DatabaseConnection{DB: tx}, instance, user, requestId)
+		if err != nil {
+			return err
+		}
+
+		id, err := treeNodeId(instance)
+		if err != nil {
+			return err
+		}
+
+		treeData.Path = fmt.Sprintf("%s%d/", parentPath, id)
+		return tx.Model(a.Model).Where("id = ?", id).UpdateColumn("path", treeData.Path).Error
+	})
+}
+
+// ensureTreeLeaf fails when the node with the given id has children.
+func ensureTreeLeaf(ctx context.Context, db *dbTypes.DatabaseConnection, a *rmTypes.Resource, id interface{}) error {
+	var count int64
+	err := db.DB.WithContext(ctx).Model(a.Model).Where("parent_id = ?", id).Count(&count).Error
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return ErrTreeHasChildren
+	}
+
+	return nil
+}
+
+func moveTreeNode(ctx context.Context, log *loggerTypes.Logger, tx *gorm.DB, a *rmTypes.Resource, id string, input TreeMoveRequest, user *authModels.User, isAdmin bool, requestId string) (interface{}, error) {
+	instance := a.GetOne()
+	err := treeScope(tx, a, user, isAdmin).Where("id = ?", id).First(instance).Error
+	if err != nil {
+		return nil, ErrTreeNodeNotFound
+	}
+
+	previousState := a.GetOne()
+	_ = treeScope(tx, a, user, isAdmin).Where("id = ?", id).First(previousState).Error
+
+	treeData := instance.(rmTypes.TreeNode).GetTreeData()
+	nodeId, err := treeNodeId(instance)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reject moving the node below itself
+	parentPath, err := treeParentPath(tx, a, input.ParentID, user, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	if treeData.Path != "" && strings.HasPrefix(parentPath, treeData.Path) {
+		return nil, ErrTreeCycle
+	}
+
+	// Insert the node among its new siblings
+	siblings, err := treeSiblings(tx.Model(a.Model), input.ParentID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []uint{}
+	for _, sibling := range siblings {
+		if sibling.ID != nodeId {
+			ids = append(ids, sibling.ID)
+		}
+	}
+
+	position := len(ids)
+	if input.Position != nil && *input.Position < position {
+		position = *input.Position
+	}
+
+	ids = append(ids[:position], append([]uint{nodeId}, ids[position:]...)...)
+	err = renumberTreeNodes(tx, a, siblings, ids, nodeId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Close the gap left among the previous siblings
+	if !sameTreeParent(treeData.ParentID, input.ParentID) {
+		previousSiblings, err := treeSiblings(tx.Model(a.Model), treeData.ParentID)
+		if err != nil {
+			return nil, err
+		}
+
+		previousIds := []uint{}
+		for _, sibling := range previousSiblings {
+			if sibling.ID != nodeId {
+				previousIds = append(previousIds, sibling.ID)
+			}
+		}
+
+		err = renumberTreeNodes(tx, a, previousSiblings, previousIds, nodeId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Rewrite the paths of the descendants
+	oldPath := treeData.Path
+	newPath := fmt.Sprintf("%s%d/", parentPath, nodeId)
+	if oldPath != newPath {
+		err = tx.Model(a.Model).
+			Where("path LIKE ? AND id <> ?", oldPath+"%", nodeId).
+			UpdateColumn("path", gorm.Expr("? || SUBSTR(path, ?)", newPath, len(oldPath)+1)).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	treeData.ParentID = input.ParentID
+	treeData.Position = position
+	treeData.Path = newPath
+
+	differences := utils.CompareInterfaces(previousState, instance)
+	if diffMap, ok := differences.(map[string]interface{}); ok && len(diffMap) == 0 {
+		return instance, nil
+	}
+
+	err = dbQueries.Update(ctx, log, &dbTypes.DatabaseConnection{DB: tx}, instance, user, differences, requestId)
+	if err != nil {
+		return nil, err
+	}
+
+	return instance, nil
+}
+
+// reorderTreeNodes orders the siblings the user can see. When siblings of other users share the parent,
+// they keep their positions and the ones of the user are shuffled among their own.
+func reorderTreeNodes(ctx context.Context, log *loggerTypes.Logger, tx *gorm.DB, a *rmTypes.Resource, input TreeReorderRequest, user *authModels.User, isAdmin bool, requestId string) error {
+	siblings, err := treeSiblings(treeScope(tx, a, user, isAdmin), input.ParentID)
+	if err != nil {
+		return err
+	}
+
+	if len(siblings) != len(input.IDs) {
+		return ErrTreeInvalidOrder
+	}
+
+	allSiblings, err := treeSiblings(tx.Model(a.Model), input.ParentID)
+	if err != nil {
+		return err
+	}
+
+	slots := make([]int, len(siblings))
+	for i, sibling := range siblings {
+		slots[i] = sibling.Position
+		if len(allSiblings) == len(siblings) {
+			slots[i] = i
+		}
+	}
+
+	positions := map[uint]int{}
+	for i, id := range input.IDs {
+		if _, exists := positions[id]; exists {
+			return ErrTreeInvalidOrder
+		}
+		positions[id] = slots[i]
+	}
+
+	for _, sibling := range siblings {
+		position, ok := positions[sibling.ID]
+		if !ok {
+			return ErrTreeInvalidOrder
+		}
+
+		if sibling.Position == position {
+			continue
+		}
+
+		instance := a.GetOne()
+		err = tx.Model(a.Model).Where("id = ?", sibling.ID).First(instance).Error
+		if err != nil {
+			return err
+		}
+
+		previousState := a.GetOne()
+		_ = tx.Model(a.Model).Where("id = ?", sibling.ID).First(previousState).Error
+
+		instance.(rmTypes.TreeNode).GetTreeData().Position = position
+
+		differences := utils.CompareInterfaces(previousState, instance)
+		err = dbQueries.Update(ctx, log, &dbTypes.DatabaseConnection{DB: tx}, instance, user, differences, requestId)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// renumberTreeNodes stores the index of each id as its position, skipping the moved node.
+func renumberTreeNodes(tx *gorm.DB, a *rmTypes.Resource, siblings []treeRow, ids []uint, nodeId uint) error {
+	current := map[uint]int{}
+	for _, sibling := range siblings {
+		current[sibling.ID] = sibling.Position
+	}
+
+	for position, id := range ids {
+		if id == nodeId {
+			continue
+		}
+
+		if previous, ok := current[id]; ok && previous == position {
+			continue
+		}
+
+		err := tx.Model(a.Model).Where("id = ?", id).UpdateColumn("position", position).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkTreeLocks runs checkLocks on the nodes with the given ids the user can see.
+func checkTreeLocks(w http.ResponseWriter, r *http.Request, a *rmTypes.Resource, db *dbTypes.DatabaseConnection, user *authModels.User, isAdmin bool, ids interface{}) bool {
+	visible := []uint{}
+	err := treeScope(db.DB.WithContext(r.Context()), a, user, isAdmin).Where("id IN ?", ids).Pluck("id", &visible).Error
+	if err != nil {
+		svrUtils.GetRequestLogger(r).Error().Err(err).Msg("Error finding nodes")
+		svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error finding lock")
+		return false
+	}
+
+	return checkLocks(w, r, a, db, visible...)
+}
+
+// treeScope applies the user binding to a query on the resource table.
+func treeScope(tx *gorm.DB, a *rmTypes.Resource, user *authModels.User, isAdmin bool) *gorm.DB {
+	query := tx.Model(a.Model)
+	if !(a.SkipUserBinding || isAdmin) {
+		query = query.Where("created_by_id = ?", user.ID)
+	}
+	return query
+}
+
+// treeSiblings returns the children of the given parent matching the query, ordered by position.
+func treeSiblings(query *gorm.DB, parentId *uint) ([]treeRow, error) {
+	if parentId == nil {
+		query = query.Where("parent_id IS NULL")
+	} else {
+		query = query.Where("parent_id = ?", *parentId)
+	}
+
+	siblings := []treeRow{}
+	err := query.Order("position, id").Find(&siblings).Error
+	return siblings, err
+}
+
+// treeParentPath returns the materialized path of the given parent, or the root path when there is no parent.
+func treeParentPath(tx *gorm.DB, a *rmTypes.Resource, parentId *uint, user *authModels.User, isAdmin bool) (string, error) {
+	if parentId == nil {
+		return "/", nil
+	}
+
+	parent := treeRow{}
+	err := treeScope(tx, a, user, isAdmin).Where("id = ?", *parentId).First(&parent).Error
+	if err != nil {
+		return "", ErrTreeParentNotFound
+	}
+
+	return parent.Path, nil
+}
+
+func treeNodeId(instance interface{}) (uint, error) {
+	data, err := rmTypes.InterfaceToMap(instance)
+	if err != nil {
+		return 0, err
+	}
+
+	id, ok := data["ID"].(float64)
+	if !ok {
+		return 0, fmt.Errorf("node has no ID")
+	}
+
+	return uint(id), nil
+}
+
+func sameTreeParent(a *uint, b *uint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// nestTreeNodes converts the given nodes to maps and moves every node under the children key of its parent.
+// Nodes whose parent is not part of the list are returned as roots.
+func nestTreeNodes(instances interface{}) ([]map[string]interface{}, error) {
+	jsonData, err := json.Marshal(instances)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []map[string]interface{}{}
+	err = json.Unmarshal(jsonData, &items)
+	if err != nil {
+		return nil, err
+	}
+
+	byId := map[interface{}]map[string]interface{}{}
+	for _, item := range items {
+		item["children"] = []map[string]interface{}{}
+		byId[item["ID"]] = item
+	}
+
+	roots := []map[string]interface{}{}
+	for _, item := range items {
+		parent, ok := byId[item["parentId"]]
+		if !ok {
+			roots = append(roots, item)
+			continue
+		}
+		parent["children"] = append(parent["children"].([]map[string]interface{}), item)
+	}
+
+	return roots, nil
+}
+
+func sendTreeError(w http.ResponseWriter, log *loggerTypes.Logger, err error) {
+	switch {
+	case errors.Is(err, ErrTreeNodeNotFound):
+		svrUtils.SendJsonResponse(w, http.StatusNotFound, nil, "Instance not found")
+	case errors.Is(err, ErrTreeParentNotFound), errors.Is(err, ErrTreeCycle), errors.Is(err, ErrTreeInvalidOrder):
+		svrUtils.SendJsonResponse(w, http.StatusBadRequest, nil, err.Error())
+	default:
+		log.Error().Err(err).Msg("Error updating tree")
+		svrUtils.SendJsonResponse(w, http.StatusInternalServerError, nil, "Error updating tree")
+	}
+}
@@ -0,0 +1,265 @@
+package resourcemanager_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+	dbModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/models"
+	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
+	rmModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/models"
+	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
+	testPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/testing"
+)
+
+type MenuItem struct {
+	authModels.SystemData
+	rmTypes.TreeData
+	Title string `json:"title"`
+}
+
+type treeNodeResponse struct {
+	ID       uint               `json:"ID"`
+	Title    string             `json:"title"`
+	Path     string             `json:"path"`
+	Position int                `json:"position"`
+	Children []treeNodeResponse `json:"children"`
+}
+
+func TestTreeHandlers(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+
+	resource, err := bed.Mgr.AddResource(&rmTypes.ResourceConfig{
+		Model: MenuItem{},
+		Tree:  true,
+		Permissions: authTypes.RolePermissionMap{
+			authConstants.AdminRole: authConstants.AllAllowedAccess,
+		},
+	})
+	assert.NoError(t, err)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/menu-items/new", resource.Api.Create(resource, bed.Db))
+	router.HandleFunc("/menu-items/tree", resource.Api.Tree(resource, bed.Db))
+	router.HandleFunc("/menu-items/reorder", resource.Api.Reorder(resource, bed.Db))
+	router.HandleFunc("/menu-items/{id}/move", resource.Api.Move(resource, bed.Db))
+	router.HandleFunc("/menu-items/{id}/update", resource.Api.Update(resource, bed.Db))
+	router.HandleFunc("/menu-items/{id}/delete", resource.Api.Delete(resource, bed.Db))
+
+	send := func(method string, path string, body string) *httptest.ResponseRecorder {
+		req := testPkg.CreateTestRequest(t, method, path, body, true, bed.AdminUser, bed.Logger)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	create := func(title string, parentId *uint) MenuItem {
+		body := fmt.Sprintf(`{"title": %q}`, title)
+		if parentId != nil {
+			body = fmt.Sprintf(`{"title": %q, "parentId": %d}`, title, *parentId)
+		}
+
+		rr := send(http.MethodPost, "/menu-items/new", body)
+		assert.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+		response := struct {
+			Data MenuItem `json:"data"`
+		}{}
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		return response.Data
+	}
+
+	load := func(id uint) MenuItem {
+		item := MenuItem{}
+		assert.NoError(t, bed.Db.DB.First(&item, id).Error)
+		return item
+	}
+
+	home := create("Home", nil)
+	about := create("About", nil)
+	team := create("Team", &home.ID)
+
+	t.Run("Create sets path and position", func(t *testing.T) {
+		assert.Equal(t, fmt.Sprintf("/%d/", home.ID), home.Path)
+		assert.Equal(t, home.Position+1, about.Position)
+		assert.Equal(t, 0, team.Position)
+		assert.Equal(t, fmt.Sprintf("/%d/%d/", home.ID, team.ID), team.Path)
+
+		missing := uint(99999)
+		rr := send(http.MethodPost, "/menu-items/new", fmt.Sprintf(`{"title": "Orphan", "parentId": %d}`, missing))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Tree nests children", func(t *testing.T) {
+		tree := func(query string) []treeNodeResponse {
+			rr := send(http.MethodGet, "/menu-items/tree"+query, "")
+			assert.Equal(t, http.StatusOK, rr.Code)
+
+			response := struct {
+				Data []treeNodeResponse `json:"data"`
+			}{}
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+			return response.Data
+		}
+
+		roots := map[uint]treeNodeResponse{}
+		for _, node := range tree("") {
+			roots[node.ID] = node
+		}
+		assert.Contains(t, roots, home.ID)
+		assert.Contains(t, roots, about.ID)
+		assert.NotContains(t, roots, team.ID)
+
+		subtree := tree(fmt.Sprintf("?root=%d", home.ID))
+		assert.Len(t, subtree, 1)
+		assert.Equal(t, "Home", subtree[0].Title)
+		assert.Len(t, subtree[0].Children, 1)
+		assert.Equal(t, "Team", subtree[0].Children[0].Title)
+
+		rr := send(http.MethodGet, "/menu-items/tree?root=99999", "")
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Move reparents the subtree and logs the update", func(t *testing.T) {
+		rr := send(http.MethodPut, fmt.Sprintf("/menu-items/%d/move", home.ID), fmt.Sprintf(`{"parentId": %d, "position": 0}`, about.ID))
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		movedHome := load(home.ID)
+		assert.Equal(t, about.ID, *movedHome.ParentID)
+		assert.Equal(t, fmt.Sprintf("/%d/%d/", about.ID, home.ID), movedHome.Path)
+		assert.Equal(t, fmt.Sprintf("/%d/%d/%d/", about.ID, home.ID, team.ID), load(team.ID).Path)
+		assert.Equal(t, 0, movedHome.Position)
+		assert.Equal(t, about.Position-1, load(about.ID).Position, "Previous siblings should be renumbered")
+
+		logs := []dbModels.DatabaseLog{}
+		bed.Db.DB.Where("resource_name = ? AND resource_id = ?", "MenuItem", fmt.Sprint(home.ID)).Find(&logs)
+		assert.Equal(t, dbTypes.UpdateCRUDAction, logs[len(logs)-1].Action)
+	})
+
+	t.Run("Move rejects cycles", func(t *testing.T) {
+		rr := send(http.MethodPut, fmt.Sprintf("/menu-items/%d/move", about.ID), fmt.Sprintf(`{"parentId": %d}`, team.ID))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Body.String(), "cannot be moved below itself")
+
+		rr = send(http.MethodPut, fmt.Sprintf("/menu-items/%d/move", about.ID), fmt.Sprintf(`{"parentId": %d}`, about.ID))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Reorder siblings", func(t *testing.T) {
+		contact := create("Contact", &about.ID)
+
+		rr := send(http.MethodPut, "/menu-items/reorder", fmt.Sprintf(`{"parentId": %d, "ids": [%d, %d]}`, about.ID, contact.ID, home.ID))
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.Equal(t, 0, load(contact.ID).Position)
+		assert.Equal(t, 1, load(home.ID).Position)
+
+		rr = send(http.MethodPut, "/menu-items/reorder", fmt.Sprintf(`{"parentId": %d, "ids": [%d]}`, about.ID, contact.ID))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Body.String(), "every sibling")
+	})
+
+	t.Run("Move and reorder respect locks of other users", func(t *testing.T) {
+		lock := rmModels.RecordLock{ResourceName: "MenuItem", ResourceId: fmt.Sprint(home.ID), UserID: bed.VisitorUser.ID, UserEmail: bed.VisitorUser.Email, ExpiresAt: time.Now().Add(time.Minute)}
+		assert.NoError(t, bed.Db.DB.Create(&lock).Error)
+		defer bed.Db.DB.Unscoped().Delete(&lock)
+
+		siblings := []MenuItem{}
+		bed.Db.DB.Where("parent_id = ?", about.ID).Order("position").Find(&siblings)
+		assert.Len(t, siblings, 2)
+
+		rr := send(http.MethodPut, fmt.Sprintf("/menu-items/%d/move", home.ID), fmt.Sprintf(`{"parentId": %d, "position": 1}`, about.ID))
+		assert.Equal(t, http.StatusConflict, rr.Code)
+
+		reorder := fmt.Sprintf(`{"parentId": %d, "ids": [%d, %d]}`, about.ID, siblings[1].ID, siblings[0].ID)
+		rr = send(http.MethodPut, "/menu-items/reorder", reorder)
+		assert.Equal(t, http.StatusConflict, rr.Code)
+
+		rr = send(http.MethodPut, "/menu-items/reorder?force=true", reorder)
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	})
+
+	t.Run("Update cannot change tree fields", func(t *testing.T) {
+		rr := send(http.MethodPut, fmt.Sprintf("/menu-items/%d/update", team.ID), `{"title": "People", "parentId": null, "path": "/"}`)
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		updated := load(team.ID)
+		assert.Equal(t, "People", updated.Title)
+		assert.Equal(t, home.ID, *updated.ParentID)
+	})
+
+	t.Run("Delete rejects nodes with children", func(t *testing.T) {
+		rr := send(http.MethodDelete, fmt.Sprintf("/menu-items/%d/delete", about.ID), "")
+		assert.Equal(t, http.StatusConflict, rr.Code)
+
+		rr = send(http.MethodDelete, fmt.Sprintf("/menu-items/%d/delete", team.ID), "")
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+}
+
+func TestTreeHandlers_UserBinding(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+
+	resource, err := bed.Mgr.AddResource(&rmTypes.ResourceConfig{
+		Model: MenuItem{},
+		Tree:  true,
+		Permissions: authTypes.RolePermissionMap{
+			authConstants.AdminRole:   authConstants.AllAllowedAccess,
+			authConstants.VisitorRole: authConstants.AllAllowedAccess,
+		},
+	})
+	assert.NoError(t, err)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/menu-items/new", resource.Api.Create(resource, bed.Db))
+	router.HandleFunc("/menu-items/reorder", resource.Api.Reorder(resource, bed.Db))
+
+	send := func(user *authModels.User, path string, method string, body string) *httptest.ResponseRecorder {
+		req := testPkg.CreateTestRequest(t, method, path, body, true, user, bed.Logger)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	create := func(user *authModels.User, body string) MenuItem {
+		rr := send(user, "/menu-items/new", http.MethodPost, body)
+		assert.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+		response := struct {
+			Data MenuItem `json:"data"`
+		}{}
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		return response.Data
+	}
+
+	load := func(id uint) MenuItem {
+		item := MenuItem{}
+		assert.NoError(t, bed.Db.DB.First(&item, id).Error)
+		return item
+	}
+
+	// Children of different users under the same parent don't share positions
+	parent := create(bed.VisitorUser, `{"title": "Visitor menu"}`)
+	adminChild := create(bed.AdminUser, fmt.Sprintf(`{"title": "Admin item", "parentId": %d}`, parent.ID))
+	first := create(bed.VisitorUser, fmt.Sprintf(`{"title": "First", "parentId": %d}`, parent.ID))
+	second := create(bed.VisitorUser, fmt.Sprintf(`{"title": "Second", "parentId": %d}`, parent.ID))
+
+	assert.Equal(t, 0, adminChild.Position)
+	assert.Equal(t, 1, first.Position)
+	assert.Equal(t, 2, second.Position)
+
+	// The visitor only reorders their own children, among the positions they hold
+	rr := send(bed.VisitorUser, "/menu-items/reorder", http.MethodPut, fmt.Sprintf(`{"parentId": %d, "ids": [%d, %d]}`, parent.ID, second.ID, first.ID))
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, 0, load(adminChild.ID).Position)
+	assert.Equal(t, 1, load(second.ID).Position)
+	assert.Equal(t, 2, load(first.ID).Position)
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+)
+
+// RecordLock marks a record as being edited by a user until it expires.
+// Locks are advisory, holders keep them alive with heartbeats and they are removed once released.
+type RecordLock struct {
+	authModels.SystemData
+	ResourceName string    `gorm:"uniqueIndex:idx_record_lock" json:"resourceName"`
+	ResourceId   string    `gorm:"uniqueIndex:idx_record_lock" json:"resourceId"`
+	UserID       uint      `json:"userId"`
+	UserEmail    string    `json:"userEmail"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+}
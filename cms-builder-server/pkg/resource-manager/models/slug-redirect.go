@@ -0,0 +1,13 @@
+package models
+
+import (
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+)
+
+// SlugRedirect keeps a slug a record no longer uses, so links to it keep working.
+type SlugRedirect struct {
+	authModels.SystemData
+	ResourceName string `gorm:"uniqueIndex:idx_slug_redirect" json:"resourceName"`
+	Slug         string `gorm:"uniqueIndex:idx_slug_redirect" json:"slug"`
+	ResourceId   string `gorm:"index" json:"resourceId"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import (
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
+)
+
+// Translation stores the value of a translatable field in a locale other than the default one.
+type Translation struct {
+	authModels.SystemData
+	ResourceName string `gorm:"uniqueIndex:idx_translation" json:"resourceName"`
+	ResourceId   string `gorm:"uniqueIndex:idx_translation" json:"resourceId"`
+	Field        string `gorm:"uniqueIndex:idx_translation" json:"field"` // Json key of the translated field
+	Locale       string `gorm:"uniqueIndex:idx_translation" json:"locale"`
+	Value        string `json:"value"`
+}
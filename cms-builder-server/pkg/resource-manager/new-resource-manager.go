@@ -4,12 +4,15 @@ import (
 	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
 	loggerTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/logger/types"
 	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
+	svrTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/types"
 )
 
 func NewResourceManager(db *dbTypes.DatabaseConnection, log *loggerTypes.Logger) *ResourceManager {
 	return &ResourceManager{
 		Resources: make(map[string]*rmTypes.Resource),
+		Routes:    []svrTypes.Route{},
 		DB:        db,
 		Logger:    log,
+		Locales:   rmTypes.NewLocaleConfig("en"),
 	}
 }
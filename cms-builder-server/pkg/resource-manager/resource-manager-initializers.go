@@ -1,11 +1,19 @@
 package resourcemanager
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/invopop/jsonschema"
 
 	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
 	rmHandlers "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/handlers"
+	rmModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/models"
 	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
 	svrTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/types"
 	"github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/utils"
@@ -36,6 +44,10 @@ func InitializeResourceNames(r *rmTypes.Resource) (rmTypes.ResourceNames, error)
 }
 
 func InitializeRoutes(r *rmTypes.Resource, input []svrTypes.Route, db *dbTypes.DatabaseConnection) error {
+	if r.Singleton {
+		return initializeSingletonRoutes(r, input, db)
+	}
+
 	baseRoute := "/api/" + r.ResourceNames.KebabPlural
 
 	routes := []svrTypes.Route{
@@ -53,6 +65,13 @@ func InitializeRoutes(r *rmTypes.Resource, input []svrTypes.Route, db *dbTypes.D
 			RequiresAuth: false,
 			Methods:      []string{http.MethodGet},
 		},
+		{
+			Path:         baseRoute + "/aggregate",
+			Handler:      r.Api.Aggregate(r, db),
+			Name:         fmt.Sprintf("%s:aggregate", r.ResourceNames.Singular),
+			RequiresAuth: true,
+			Methods:      []string{http.MethodGet},
+		},
 		{
 			Path:         baseRoute + "/new",
 			Handler:      r.Api.Create(r, db),
@@ -74,6 +93,27 @@ func InitializeRoutes(r *rmTypes.Resource, input []svrTypes.Route, db *dbTypes.D
 			RequiresAuth: true,
 			Methods:      []string{http.MethodPut},
 		},
+		{
+			Path:         baseRoute + "/{id}/references",
+			Handler:      r.Api.References(r, db),
+			Name:         fmt.Sprintf("%s:references", r.ResourceNames.Singular),
+			RequiresAuth: true,
+			Methods:      []string{http.MethodGet},
+		},
+		{
+			Path:         baseRoute + "/{id}/lock",
+			Handler:      r.Api.Lock(r, db),
+			Name:         fmt.Sprintf("%s:lock", r.ResourceNames.Singular),
+			RequiresAuth: true,
+			Methods:      []string{http.MethodPost},
+		},
+		{
+			Path:         baseRoute + "/{id}/lock",
+			Handler:      r.Api.Unlock(r, db),
+			Name:         fmt.Sprintf("%s:unlock", r.ResourceNames.Singular),
+			RequiresAuth: true,
+			Methods:      []string{http.MethodDelete},
+		},
 		{
 			Path:         baseRoute + "/{id}",
 			Handler:      r.Api.Detail(r, db),
@@ -83,14 +123,168 @@ func InitializeRoutes(r *rmTypes.Resource, input []svrTypes.Route, db *dbTypes.D
 		},
 	}
 
-	for _, route := range routes {
-		err := r.AddRoute(route)
-		if err != nil {
-			return err
+	if r.Tree {
+		routes = append(routes, []svrTypes.Route{
+			{
+				Path:         baseRoute + "/tree",
+				Handler:      r.Api.Tree(r, db),
+				Name:         fmt.Sprintf("%s:tree", r.ResourceNames.Singular),
+				RequiresAuth: true,
+				Methods:      []string{http.MethodGet},
+			},
+			{
+				Path:         baseRoute + "/reorder",
+				Handler:      r.Api.Reorder(r, db),
+				Name:         fmt.Sprintf("%s:reorder", r.ResourceNames.Singular),
+				RequiresAuth: true,
+				Methods:      []string{http.MethodPut},
+			},
+			{
+				Path:         baseRoute + "/{id}/move",
+				Handler:      r.Api.Move(r, db),
+				Name:         fmt.Sprintf("%s:move", r.ResourceNames.Singular),
+				RequiresAuth: true,
+				Methods:      []string{http.MethodPut},
+			},
+		}...)
+	}
+
+	if len(r.Translatable) > 0 {
+		routes = append(routes, translationRoutes(r, baseRoute, db)...)
+	}
+
+	if r.Delivery != nil {
+		routes = append(routes, deliveryRoutes(r, db)...)
+	}
+
+	if r.Slug != nil {
+		routes = append(routes, svrTypes.Route{
+			Path:         baseRoute + "/by-slug/{slug}",
+			Handler:      r.Api.BySlug(r, db),
+			Name:         fmt.Sprintf("%s:by-slug", r.ResourceNames.Singular),
+			RequiresAuth: true,
+			Methods:      []string{http.MethodGet},
+		})
+	}
+
+	return addRoutes(r, append(routes, input...))
+}
+
+// initializeSingletonRoutes replaces the list, create and {id} routes with a
+// single path that reads and updates the only record of the resource.
+func initializeSingletonRoutes(r *rmTypes.Resource, input []svrTypes.Route, db *dbTypes.DatabaseConnection) error {
+	baseRoute := "/api/" + r.ResourceNames.KebabSingular
+
+	routes := []svrTypes.Route{
+		{
+			Path:         baseRoute,
+			Handler:      r.Api.Detail(r, db),
+			Name:         fmt.Sprintf("%s Detail", r.ResourceNames.Singular),
+			RequiresAuth: true,
+			Methods:      []string{http.MethodGet},
+		},
+		{
+			Path:         baseRoute,
+			Handler:      r.Api.Update(r, db),
+			Name:         fmt.Sprintf("Update %s", r.ResourceNames.Singular),
+			RequiresAuth: true,
+			Methods:      []string{http.MethodPut},
+		},
+		{
+			Path:         baseRoute + "/schema",
+			Handler:      r.Api.Schema(r),
+			Name:         fmt.Sprintf("%s Schema", r.ResourceNames.Singular),
+			RequiresAuth: false,
+			Methods:      []string{http.MethodGet},
+		},
+	}
+
+	if len(r.Translatable) > 0 {
+		routes = append(routes, translationRoutes(r, baseRoute, db)...)
+	}
+
+	if r.Delivery != nil {
+		routes = append(routes, deliveryRoutes(r, db)...)
+	}
+
+	return addRoutes(r, append(routes, input...))
+}
+
+// deliveryRoutes returns the public, read-only routes of the content delivery API.
+func deliveryRoutes(r *rmTypes.Resource, db *dbTypes.DatabaseConnection) []svrTypes.Route {
+	if r.Singleton {
+		return []svrTypes.Route{
+			{
+				Path:         "/content/" + r.ResourceNames.KebabSingular,
+				Handler:      r.Api.DeliveryDetail(r, db),
+				Name:         fmt.Sprintf("%s:delivery-detail", r.ResourceNames.Singular),
+				RequiresAuth: false,
+				Methods:      []string{http.MethodGet},
+			},
 		}
 	}
 
-	for _, route := range input {
+	baseRoute := "/content/" + r.ResourceNames.KebabPlural
+
+	routes := []svrTypes.Route{
+		{
+			Path:         baseRoute,
+			Handler:      r.Api.DeliveryList(r, db),
+			Name:         fmt.Sprintf("%s:delivery-list", r.ResourceNames.Singular),
+			RequiresAuth: false,
+			Methods:      []string{http.MethodGet},
+		},
+		{
+			Path:         baseRoute + "/{id}",
+			Handler:      r.Api.DeliveryDetail(r, db),
+			Name:         fmt.Sprintf("%s:delivery-detail", r.ResourceNames.Singular),
+			RequiresAuth: false,
+			Methods:      []string{http.MethodGet},
+		},
+	}
+
+	if r.Slug != nil {
+		routes = append(routes, svrTypes.Route{
+			Path:         baseRoute + "/by-slug/{slug}",
+			Handler:      r.Api.DeliveryDetail(r, db),
+			Name:         fmt.Sprintf("%s:delivery-by-slug", r.ResourceNames.Singular),
+			RequiresAuth: false,
+			Methods:      []string{http.MethodGet},
+		})
+	}
+
+	return routes
+}
+
+// translationRoutes returns the routes that read and write the translations of a resource.
+func translationRoutes(r *rmTypes.Resource, baseRoute string, db *dbTypes.DatabaseConnection) []svrTypes.Route {
+	return []svrTypes.Route{
+		{
+			Path:         baseRoute + "/translations/missing",
+			Handler:      r.Api.MissingTranslations(r, db),
+			Name:         fmt.Sprintf("%s:missing-translations", r.ResourceNames.Singular),
+			RequiresAuth: true,
+			Methods:      []string{http.MethodGet},
+		},
+		{
+			Path:         baseRoute + "/{id}/translations",
+			Handler:      r.Api.Translations(r, db),
+			Name:         fmt.Sprintf("%s:translations", r.ResourceNames.Singular),
+			RequiresAuth: true,
+			Methods:      []string{http.MethodGet},
+		},
+		{
+			Path:         baseRoute + "/{id}/translations",
+			Handler:      r.Api.Translate(r, db),
+			Name:         fmt.Sprintf("%s:translate", r.ResourceNames.Singular),
+			RequiresAuth: true,
+			Methods:      []string{http.MethodPut},
+		},
+	}
+}
+
+func addRoutes(r *rmTypes.Resource, routes []svrTypes.Route) error {
+	for _, route := range routes {
 		err := r.AddRoute(route)
 		if err != nil {
 			return err
@@ -115,16 +309,470 @@ func InitializeValidators(r *rmTypes.Resource, input *rmTypes.ValidatorsMap) err
 	return nil
 }
 
+// InitializeSearch validates the search settings of the given resource.
+// Fields must exist in the model, the label defaults to the first search field.
+func InitializeSearch(r *rmTypes.Resource, input *rmTypes.SearchConfig) error {
+	if input == nil {
+		return nil
+	}
+
+	if len(input.Fields) == 0 {
+		return fmt.Errorf("search config for %s requires at least one field", r.ResourceNames.Singular)
+	}
+
+	search := &rmTypes.SearchConfig{
+		Fields:     slices.Clone(input.Fields),
+		LabelField: input.LabelField,
+	}
+
+	if search.LabelField == "" {
+		search.LabelField = search.Fields[0]
+	}
+
+	// Copied, so appending the label doesn't write into the fields of the config
+	fields := append(slices.Clone(search.Fields), search.LabelField)
+	for _, fieldName := range fields {
+		if !r.HasField(fieldName) {
+			return fmt.Errorf("search field %s not found in model", fieldName)
+		}
+	}
+
+	r.Search = search
+	return nil
+}
+
+// InitializeSlug validates the slug settings of the given resource.
+// Both the slug and the source field must be strings of the model.
+func InitializeSlug(r *rmTypes.Resource, input *rmTypes.SlugConfig, db *dbTypes.DatabaseConnection) error {
+	if input == nil {
+		return nil
+	}
+
+	if input.Field == "" || input.Source == "" {
+		return fmt.Errorf("slug config for %s requires a field and a source", r.ResourceNames.Singular)
+	}
+
+	modelType := reflect.TypeOf(r.GetOne()).Elem()
+	for _, fieldName := range []string{input.Field, input.Source} {
+		field, ok := modelType.FieldByName(fieldName)
+		if !ok {
+			return fmt.Errorf("slug field %s not found in model", fieldName)
+		}
+
+		if field.Type.Kind() != reflect.String {
+			return fmt.Errorf("slug field %s must be a string", fieldName)
+		}
+	}
+
+	err := db.DB.AutoMigrate(&rmModels.SlugRedirect{})
+	if err != nil {
+		return err
+	}
+
+	r.Slug = &rmTypes.SlugConfig{
+		Field:              input.Field,
+		Source:             input.Source,
+		RegenerateOnUpdate: input.RegenerateOnUpdate,
+	}
+	return nil
+}
+
+// InitializeDelivery validates the public delivery settings of the given resource.
+// Fields are translated to json keys and the ID is always delivered.
+// The published field must be a bool or a time.
+func InitializeDelivery(r *rmTypes.Resource, input *rmTypes.DeliveryConfig) error {
+	if input == nil {
+		return nil
+	}
+
+	delivery := &rmTypes.DeliveryConfig{
+		Fields:    []string{},
+		Published: input.Published,
+		MaxAge:    input.MaxAge,
+		CacheSize: input.CacheSize,
+	}
+
+	for _, fieldName := range append([]string{"ID"}, input.Fields...) {
+		key, ok := r.FieldNames[fieldName]
+		if !ok {
+			return fmt.Errorf("delivery field %s not found in model", fieldName)
+		}
+
+		if !slices.Contains(delivery.Fields, key) {
+			delivery.Fields = append(delivery.Fields, key)
+		}
+	}
+
+	if input.Published != "" {
+		field, ok := reflect.TypeOf(r.GetOne()).Elem().FieldByName(input.Published)
+		if !ok {
+			return fmt.Errorf("published field %s not found in model", input.Published)
+		}
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		if fieldType.Kind() != reflect.Bool && fieldType != reflect.TypeOf(time.Time{}) {
+			return fmt.Errorf("published field %s must be a bool or a time", input.Published)
+		}
+	}
+
+	if delivery.MaxAge <= 0 {
+		delivery.MaxAge = rmTypes.DefaultDeliveryMaxAge
+	}
+
+	if delivery.CacheSize <= 0 {
+		delivery.CacheSize = rmTypes.DefaultDeliveryCacheSize
+	}
+
+	r.Delivery = delivery
+	r.DeliveryCache = rmTypes.NewDeliveryCache(delivery.CacheSize)
+	return nil
+}
+
+// InitializeSingleton restricts the given resource to a single record shared by every user.
+func InitializeSingleton(r *rmTypes.Resource, enabled bool) error {
+	if !enabled {
+		return nil
+	}
+
+	if r.Tree {
+		return fmt.Errorf("singleton resource %s cannot be a tree", r.ResourceNames.Singular)
+	}
+
+	if r.Search != nil {
+		return fmt.Errorf("singleton resource %s cannot be searchable", r.ResourceNames.Singular)
+	}
+
+	if r.Slug != nil {
+		return fmt.Errorf("singleton resource %s cannot have a slug", r.ResourceNames.Singular)
+	}
+
+	r.Singleton = true
+	r.SkipUserBinding = true
+	return nil
+}
+
+// InitializeTree enables the tree endpoints on the given resource.
+// The model must embed TreeData.
+func InitializeTree(r *rmTypes.Resource, enabled bool) error {
+	if !enabled {
+		return nil
+	}
+
+	if _, ok := r.GetOne().(rmTypes.TreeNode); !ok {
+		return fmt.Errorf("tree resource %s must embed TreeData", r.ResourceNames.Singular)
+	}
+
+	r.Tree = true
+	return nil
+}
+
+// InitializeTranslatable enables per locale values on the given string fields of the resource.
+func InitializeTranslatable(r *rmTypes.Resource, input []string, db *dbTypes.DatabaseConnection) error {
+	if len(input) == 0 {
+		return nil
+	}
+
+	if r.Locales == nil {
+		return fmt.Errorf("translatable resource %s requires locales", r.ResourceNames.Singular)
+	}
+
+	modelType := reflect.TypeOf(r.GetOne()).Elem()
+	for _, fieldName := range input {
+		field, ok := modelType.FieldByName(fieldName)
+		if !ok {
+			return fmt.Errorf("translatable field %s not found in model", fieldName)
+		}
+
+		if field.Type.Kind() != reflect.String {
+			return fmt.Errorf("translatable field %s must be a string", fieldName)
+		}
+	}
+
+	err := db.DB.AutoMigrate(&rmModels.Translation{})
+	if err != nil {
+		return err
+	}
+
+	r.Translatable = input
+	return nil
+}
+
+// InitializeRelations validates the relations of the given resource.
+// Referenced resources must be registered before, unless the resource references itself,
+// and the set-null policy requires a nullable field.
+func InitializeRelations(r *rmTypes.Resource, input []rmTypes.RelationConfig, resources map[string]*rmTypes.Resource) error {
+	if len(input) == 0 {
+		return nil
+	}
+
+	modelType := reflect.TypeOf(r.GetOne()).Elem()
+	relations := []rmTypes.RelationConfig{}
+	for _, relation := range input {
+		field, ok := modelType.FieldByName(relation.Field)
+		if !ok {
+			return fmt.Errorf("relation field %s not found in model", relation.Field)
+		}
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		if !slices.Contains([]reflect.Kind{reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64}, fieldType.Kind()) {
+			return fmt.Errorf("relation field %s must be an unsigned integer", relation.Field)
+		}
+
+		if _, ok := resources[relation.Resource]; !ok && relation.Resource != r.ResourceNames.Singular {
+			return fmt.Errorf("relation field %s references unknown resource %s", relation.Field, relation.Resource)
+		}
+
+		if relation.OnDelete == "" {
+			relation.OnDelete = rmTypes.RestrictOnDelete
+		}
+
+		switch relation.OnDelete {
+		case rmTypes.RestrictOnDelete, rmTypes.CascadeOnDelete:
+		case rmTypes.SetNullOnDelete:
+			if field.Type.Kind() != reflect.Ptr {
+				return fmt.Errorf("relation field %s must be a pointer to be set to null", relation.Field)
+			}
+		default:
+			return fmt.Errorf("invalid delete policy %s for relation field %s", relation.OnDelete, relation.Field)
+		}
+
+		relations = append(relations, relation)
+	}
+
+	r.Relations = relations
+	return nil
+}
+
+// systemFields are managed by the server and displayed as read only by default.
+var systemFields = map[string]bool{
+	"ID":          true,
+	"CreatedAt":   true,
+	"UpdatedAt":   true,
+	"DeletedAt":   true,
+	"CreatedByID": true,
+	"CreatedBy":   true,
+	"UpdatedByID": true,
+	"UpdatedBy":   true,
+}
+
+// treeFields are managed by the tree endpoints and displayed as read only by default.
+var treeFields = map[string]bool{
+	"ParentID": true,
+	"Position": true,
+	"Path":     true,
+}
+
+// InitializeFieldNames maps the model field names of the given resource to their json keys.
+// Fields of embedded structs are promoted the same way encoding/json does.
+func InitializeFieldNames(r *rmTypes.Resource) map[string]string {
+	fieldNames := map[string]string{}
+
+	modelType := reflect.TypeOf(r.Model)
+	if modelType == nil {
+		return fieldNames
+	}
+
+	collectFieldNames(modelType, fieldNames)
+	return fieldNames
+}
+
+func collectFieldNames(modelType reflect.Type, fieldNames map[string]string) {
+	if modelType.Kind() == reflect.Ptr {
+		modelType = modelType.Elem()
+	}
+	if modelType.Kind() != reflect.Struct {
+		return
+	}
+
+	embedded := []reflect.Type{}
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+		tag := strings.Split(field.Tag.Get("json"), ",")[0]
+
+		if field.Anonymous && tag == "" {
+			embedded = append(embedded, field.Type)
+			continue
+		}
+
+		if !field.IsExported() || tag == "-" {
+			continue
+		}
+
+		if tag == "" {
+			tag = field.Name
+		}
+		fieldNames[field.Name] = tag
+	}
+
+	// Outer fields take precedence over promoted ones
+	for _, embeddedType := range embedded {
+		promoted := map[string]string{}
+		collectFieldNames(embeddedType, promoted)
+		for name, key := range promoted {
+			if _, ok := fieldNames[name]; !ok {
+				fieldNames[name] = key
+			}
+		}
+	}
+}
+
+// InitializeDisplay builds the admin UI metadata of the given resource.
+// Every field gets a default label, the given input overrides labels and adds
+// widgets, help texts, groups, list columns and the default sort.
+// Field names are translated to json keys, so FieldNames must be initialized first.
+func InitializeDisplay(r *rmTypes.Resource, input *rmTypes.DisplayConfig) error {
+	display := &rmTypes.DisplayConfig{
+		Label:       utils.Humanize(r.ResourceNames.Singular),
+		PluralLabel: utils.Humanize(r.ResourceNames.Plural),
+		ListColumns: []string{},
+		Fields:      map[string]rmTypes.FieldDisplay{},
+	}
+
+	for fieldName, key := range r.FieldNames {
+		display.Fields[key] = rmTypes.FieldDisplay{
+			Label:        utils.Humanize(fieldName),
+			ReadOnly:     systemFields[fieldName] || (r.Tree && treeFields[fieldName]),
+			Translatable: slices.Contains(r.Translatable, fieldName),
+		}
+	}
+
+	if input == nil {
+		r.Display = display
+		return nil
+	}
+
+	if input.Label != "" {
+		display.Label = input.Label
+	}
+
+	if input.PluralLabel != "" {
+		display.PluralLabel = input.PluralLabel
+	}
+
+	for fieldName, field := range input.Fields {
+		key, ok := r.FieldNames[fieldName]
+		if !ok {
+			return fmt.Errorf("display field %s not found in model", fieldName)
+		}
+
+		err := validateFieldDisplay(fieldName, field)
+		if err != nil {
+			return err
+		}
+
+		if field.Label == "" {
+			field.Label = display.Fields[key].Label
+		}
+		field.Translatable = display.Fields[key].Translatable
+
+		display.Fields[key] = field
+	}
+
+	for _, fieldName := range input.ListColumns {
+		key, ok := r.FieldNames[fieldName]
+		if !ok {
+			return fmt.Errorf("list column %s not found in model", fieldName)
+		}
+		display.ListColumns = append(display.ListColumns, key)
+	}
+
+	if input.DefaultSort != "" {
+		sort := []string{}
+		for _, fieldName := range strings.Split(input.DefaultSort, ",") {
+			prefix := ""
+			if strings.HasPrefix(fieldName, "-") {
+				prefix = "-"
+				fieldName = strings.TrimPrefix(fieldName, "-")
+			}
+
+			key, ok := r.FieldNames[fieldName]
+			if !ok {
+				return fmt.Errorf("sort field %s not found in model", fieldName)
+			}
+			sort = append(sort, prefix+key)
+		}
+		display.DefaultSort = strings.Join(sort, ",")
+	}
+
+	r.Display = display
+	return nil
+}
+
+func validateFieldDisplay(fieldName string, field rmTypes.FieldDisplay) error {
+	if field.Widget == "" {
+		return nil
+	}
+
+	if !slices.Contains(rmTypes.Widgets, field.Widget) {
+		return fmt.Errorf("unknown widget %s for field %s", field.Widget, fieldName)
+	}
+
+	if field.Widget == rmTypes.WidgetSelect && len(field.Options) == 0 {
+		return fmt.Errorf("select widget for field %s requires options", fieldName)
+	}
+
+	if field.Widget == rmTypes.WidgetRelation && field.Relation == "" {
+		return fmt.Errorf("relation widget for field %s requires a relation", fieldName)
+	}
+
+	return nil
+}
+
+// InitializeJsonSchema reflects the JSON schema of the given resource model and
+// stores it with the display metadata under the x-display key.
+func InitializeJsonSchema(r *rmTypes.Resource) error {
+	schema := jsonschema.Reflect(r.Model)
+	schema.Extras = map[string]any{
+		"x-display": r.Display,
+	}
+
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return err
+	}
+
+	r.JsonSchema = data
+	return nil
+}
+
 // InitializeHandlers returns a new ApiHandlers struct with default handlers.
+// Singleton resources default to the singleton detail and update handlers.
 // If the given input is not nil, it overwrites the default handlers with the given functions.
-func InitializeHandlers(input *rmTypes.ApiHandlers) *rmTypes.ApiHandlers {
+func InitializeHandlers(input *rmTypes.ApiHandlers, singleton bool) *rmTypes.ApiHandlers {
 	handlers := &rmTypes.ApiHandlers{
-		List:   rmHandlers.DefaultListHandler,
-		Detail: rmHandlers.DefaultDetailHandler,
-		Create: rmHandlers.DefaultCreateHandler,
-		Update: rmHandlers.DefaultUpdateHandler,
-		Delete: rmHandlers.DefaultDeleteHandler,
-		Schema: rmHandlers.DefaultSchemaHandler,
+		List:      rmHandlers.DefaultListHandler,
+		Detail:    rmHandlers.DefaultDetailHandler,
+		Create:    rmHandlers.DefaultCreateHandler,
+		Update:    rmHandlers.DefaultUpdateHandler,
+		Delete:    rmHandlers.DefaultDeleteHandler,
+		Aggregate: rmHandlers.DefaultAggregateHandler,
+		Tree:      rmHandlers.DefaultTreeHandler,
+		Move:      rmHandlers.DefaultMoveHandler,
+		Reorder:   rmHandlers.DefaultReorderHandler,
+		Schema:    rmHandlers.DefaultSchemaHandler,
+
+		Translations:        rmHandlers.DefaultTranslationsHandler,
+		Translate:           rmHandlers.DefaultTranslateHandler,
+		MissingTranslations: rmHandlers.DefaultMissingTranslationsHandler,
+		BySlug:              rmHandlers.DefaultBySlugHandler,
+		DeliveryList:        rmHandlers.DefaultDeliveryListHandler,
+		DeliveryDetail:      rmHandlers.DefaultDeliveryDetailHandler,
+		References:          rmHandlers.DefaultReferencesHandler,
+		Lock:                rmHandlers.DefaultLockHandler,
+		Unlock:              rmHandlers.DefaultUnlockHandler,
+	}
+
+	if singleton {
+		handlers.Detail = rmHandlers.DefaultSingletonDetailHandler
+		handlers.Update = rmHandlers.DefaultSingletonUpdateHandler
 	}
 
 	if input != nil {
@@ -148,6 +796,58 @@ func InitializeHandlers(input *rmTypes.ApiHandlers) *rmTypes.ApiHandlers {
 			handlers.Delete = input.Delete
 		}
 
+		if input.Aggregate != nil {
+			handlers.Aggregate = input.Aggregate
+		}
+
+		if input.Tree != nil {
+			handlers.Tree = input.Tree
+		}
+
+		if input.Move != nil {
+			handlers.Move = input.Move
+		}
+
+		if input.Reorder != nil {
+			handlers.Reorder = input.Reorder
+		}
+
+		if input.Translations != nil {
+			handlers.Translations = input.Translations
+		}
+
+		if input.Translate != nil {
+			handlers.Translate = input.Translate
+		}
+
+		if input.MissingTranslations != nil {
+			handlers.MissingTranslations = input.MissingTranslations
+		}
+
+		if input.BySlug != nil {
+			handlers.BySlug = input.BySlug
+		}
+
+		if input.DeliveryList != nil {
+			handlers.DeliveryList = input.DeliveryList
+		}
+
+		if input.DeliveryDetail != nil {
+			handlers.DeliveryDetail = input.DeliveryDetail
+		}
+
+		if input.References != nil {
+			handlers.References = input.References
+		}
+
+		if input.Lock != nil {
+			handlers.Lock = input.Lock
+		}
+
+		if input.Unlock != nil {
+			handlers.Unlock = input.Unlock
+		}
+
 		if input.Schema != nil {
 			handlers.Schema = input.Schema
 		}
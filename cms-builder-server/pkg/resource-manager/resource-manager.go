@@ -1,22 +1,36 @@
 package resourcemanager
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"sort"
+	"sync"
 
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
 	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
 	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
 	loggerTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/logger/types"
 	rmHandlers "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/handlers"
+	rmModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/models"
 	rmTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager/types"
 	svrTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/types"
 	utilsPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/utils"
 )
 
+var schemaCache = &sync.Map{}
+
 type ResourceManager struct {
 	Resources map[string]*rmTypes.Resource
+	Routes    []svrTypes.Route // Routes that are not bound to a single resource
 	DB        *dbTypes.DatabaseConnection
 	Logger    *loggerTypes.Logger
+	Locales   *rmTypes.LocaleConfig // Locales content can be delivered in, shared by every resource
+
+	locksMigrated bool // Whether the record locks table was migrated
 }
 
 func (r *ResourceManager) GetResourceByName(name string) (*rmTypes.Resource, error) {
@@ -50,26 +64,230 @@ func (r *ResourceManager) GetRoutes(apiBaseUrl string) []svrTypes.Route {
 			RequiresAuth: false,
 			Methods:      []string{http.MethodGet},
 		},
+		{
+			Path:         "/api/search",
+			Handler:      rmHandlers.SearchHandler(r.Resources, r.DB, apiBaseUrl),
+			Name:         "search",
+			RequiresAuth: true,
+			Methods:      []string{http.MethodGet},
+		},
+		{
+			Path:         "/api/me",
+			Handler:      rmHandlers.MeHandler(r.Resources),
+			Name:         "me",
+			RequiresAuth: true,
+			Methods:      []string{http.MethodGet},
+		},
 	}
 
+	routes = append(routes, r.Routes...)
+
+	resourceRoutes := []svrTypes.Route{}
 	for _, resource := range r.Resources {
 		for _, route := range resource.Routes {
-			routes = append(routes, route)
+			resourceRoutes = append(resourceRoutes, route)
+		}
+	}
+
+	// Routes are matched in registration order. Sorting by path registers
+	// static segments such as /aggregate before path variables such as /{id}.
+	sort.SliceStable(resourceRoutes, func(i, j int) bool {
+		return resourceRoutes[i].Path < resourceRoutes[j].Path
+	})
+
+	return append(routes, resourceRoutes...)
+}
+
+// AddRoute registers a route that spans several resources, such as the dashboard.
+func (r *ResourceManager) AddRoute(route svrTypes.Route) error {
+	for _, existing := range r.Routes {
+		if existing.Name == route.Name {
+			return fmt.Errorf("route with name %s already exists", route.Name)
 		}
 	}
 
-	return routes
+	r.Routes = append(r.Routes, route)
+	return nil
+}
+
+// SetLocales replaces the locales content can be delivered in, for every resource.
+func (r *ResourceManager) SetLocales(locales *rmTypes.LocaleConfig) error {
+	if locales == nil {
+		return fmt.Errorf("locales are required")
+	}
+
+	err := locales.Validate()
+	if err != nil {
+		return err
+	}
+
+	r.Locales = locales
+	for _, resource := range r.Resources {
+		resource.Locales = locales
+	}
+
+	return nil
+}
+
+// deliveryCallback is the name of the gorm callbacks that purge the delivery caches.
+const deliveryCallback = "resource-manager:purge-delivery-cache"
+
+// registerDeliveryCallbacks purges the delivery cache of a resource once every create,
+// update or delete of its records is committed, whichever handler or job writes them.
+// Translations purge every delivery cache, since they are shared by all resources.
+func (r *ResourceManager) registerDeliveryCallbacks() error {
+	translationName, err := utilsPkg.GetInterfaceName(rmModels.Translation{})
+	if err != nil {
+		return err
+	}
+
+	purge := func(tx *gorm.DB) {
+		if tx.Error != nil || tx.Statement.Schema == nil {
+			return
+		}
+
+		name := tx.Statement.Schema.Name
+		for _, resource := range r.Resources {
+			if resource.DeliveryCache == nil {
+				continue
+			}
+
+			if name == resource.ResourceNames.Singular || name == translationName {
+				resource.DeliveryCache.Purge()
+			}
+		}
+	}
+
+	callbacks := r.DB.DB.Callback()
+	processors := []struct {
+		get      func(name string) func(*gorm.DB)
+		replace  func(name string, fn func(*gorm.DB)) error
+		register func(name string, fn func(*gorm.DB)) error
+	}{
+		{callbacks.Create().Get, callbacks.Create().Replace, callbacks.Create().After("gorm:commit_or_rollback_transaction").Register},
+		{callbacks.Update().Get, callbacks.Update().Replace, callbacks.Update().After("gorm:commit_or_rollback_transaction").Register},
+		{callbacks.Delete().Get, callbacks.Delete().Replace, callbacks.Delete().After("gorm:commit_or_rollback_transaction").Register},
+	}
+
+	for _, processor := range processors {
+		var err error
+		if processor.get(deliveryCallback) != nil {
+			err = processor.replace(deliveryCallback, purge)
+		} else {
+			err = processor.register(deliveryCallback, purge)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// linkRelations adds the relations of the given resource to the resources they reference,
+// so deleting a referenced record can enforce their delete policies.
+func (r *ResourceManager) linkRelations(resource *rmTypes.Resource) error {
+	for _, relation := range resource.Relations {
+		column, err := rmHandlers.RelationColumn(r.DB, resource, relation.Field)
+		if err != nil {
+			return err
+		}
+
+		target := r.Resources[relation.Resource]
+		target.ReferencedBy = append(target.ReferencedBy, rmTypes.Reference{
+			Resource: resource,
+			Field:    relation.Field,
+			Column:   column,
+			OnDelete: relation.OnDelete,
+		})
+	}
+
+	return nil
+}
+
+// indexReferences adds the foreign keys gorm finds between registered models to the resources they
+// point to, so the references of a record can be listed. It runs after every registration since
+// either side of a relation may be registered last. Audit fields are left out.
+func (r *ResourceManager) indexReferences() error {
+	for _, resource := range r.Resources {
+		s, err := schema.Parse(resource.Model, schemaCache, r.DB.DB.NamingStrategy)
+		if err != nil {
+			return err
+		}
+
+		for _, relationship := range s.Relationships.Relations {
+			other, ok := r.Resources[relationship.FieldSchema.Name]
+			if !ok {
+				continue
+			}
+
+			for _, reference := range relationship.References {
+				if reference.PrimaryKey == nil || reference.ForeignKey == nil || auditFields[reference.ForeignKey.Name] {
+					continue
+				}
+
+				switch relationship.Type {
+				case schema.BelongsTo:
+					addReference(other, resource, reference.ForeignKey)
+				case schema.HasOne, schema.HasMany:
+					addReference(resource, other, reference.ForeignKey)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// auditFields hold the users who created and updated a record, they reference every record.
+var auditFields = map[string]bool{
+	"CreatedByID": true,
+	"UpdatedByID": true,
+}
+
+// addReference adds the given foreign key of the referencing resource to the target, unless it is known.
+func addReference(target *rmTypes.Resource, referencing *rmTypes.Resource, foreignKey *schema.Field) {
+	for _, ref := range target.ReferencedBy {
+		if ref.Resource == referencing && ref.Column == foreignKey.DBName {
+			return
+		}
+	}
+
+	target.ReferencedBy = append(target.ReferencedBy, rmTypes.Reference{
+		Resource: referencing,
+		Field:    foreignKey.Name,
+		Column:   foreignKey.DBName,
+	})
+}
+
+// EnsureSingletons creates the record of every singleton resource that has none yet.
+func (r *ResourceManager) EnsureSingletons(ctx context.Context, user *authModels.User, requestId string) error {
+	for _, resource := range r.Resources {
+		if !resource.Singleton {
+			continue
+		}
+
+		_, err := rmHandlers.FindOrCreateSingleton(ctx, r.Logger, r.DB, resource, user, requestId)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func (r *ResourceManager) AddResource(input *rmTypes.ResourceConfig) (*rmTypes.Resource, error) {
 
 	resource := &rmTypes.Resource{
 		Model:           input.Model,
-		Api:             InitializeHandlers(input.Handlers),
+		Api:             InitializeHandlers(input.Handlers, input.Singleton),
 		SkipUserBinding: input.SkipUserBinding,
+		PrivateActivity: input.PrivateActivity,
+		Locales:         r.Locales,
 		Permissions:     make(authTypes.RolePermissionMap),
 		Validators:      make(rmTypes.ValidatorsMap),
 		Routes:          map[string]svrTypes.Route{},
+		CustomRoutes:    input.Routes,
 		ResourceNames:   rmTypes.ResourceNames{},
 		JsonSchema:      nil,
 	}
@@ -89,9 +307,7 @@ func (r *ResourceManager) AddResource(input *rmTypes.ResourceConfig) (*rmTypes.R
 		return nil, fmt.Errorf("resource with name %s already exists", name)
 	}
 
-	// TODO: Initialize JsonSchema
-
-	// TODO: Maybe I can initialize FieldNames, so that validations are easier
+	resource.FieldNames = InitializeFieldNames(resource)
 
 	// Validate Permissions
 	if input.Permissions != nil {
@@ -104,6 +320,59 @@ func (r *ResourceManager) AddResource(input *rmTypes.ResourceConfig) (*rmTypes.R
 		return nil, err
 	}
 
+	// Validate Search
+	err = InitializeSearch(resource, input.Search)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate Tree
+	err = InitializeTree(resource, input.Tree)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate Slug
+	err = InitializeSlug(resource, input.Slug, r.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate Delivery
+	err = InitializeDelivery(resource, input.Delivery)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate Singleton
+	err = InitializeSingleton(resource, input.Singleton)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate Translatable
+	err = InitializeTranslatable(resource, input.Translatable, r.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate Relations
+	err = InitializeRelations(resource, input.Relations, r.Resources)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate Display
+	err = InitializeDisplay(resource, input.Display)
+	if err != nil {
+		return nil, err
+	}
+
+	err = InitializeJsonSchema(resource)
+	if err != nil {
+		return nil, err
+	}
+
 	// Validate Routes
 	err = InitializeRoutes(resource, input.Routes, r.DB)
 	if err != nil {
@@ -118,5 +387,38 @@ func (r *ResourceManager) AddResource(input *rmTypes.ResourceConfig) (*rmTypes.R
 		return nil, err
 	}
 
+	// Records of non singleton resources can be locked while edited
+	if !resource.Singleton && !r.locksMigrated {
+		err = r.DB.DB.AutoMigrate(&rmModels.RecordLock{})
+		if err != nil {
+			return nil, err
+		}
+		r.locksMigrated = true
+	}
+
+	if resource.Slug != nil {
+		err = rmHandlers.EnsureSlugIndex(r.DB, resource)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if resource.Delivery != nil {
+		err = r.registerDeliveryCallbacks()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = r.linkRelations(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.indexReferences()
+	if err != nil {
+		return nil, err
+	}
+
 	return resource, nil
 }
@@ -1,12 +1,16 @@
 package resourcemanager_test
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
 	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
+	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
 	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
 	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
 	rmPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager"
@@ -101,3 +105,377 @@ func TestResourceManager_AddResource(t *testing.T) {
 	assert.NotNil(t, resource.Validators, "AddResource() validators should be initialized to empty map if nil")
 	assert.NotNil(t, resource.Permissions, "AddResource() permissions should be initialized to empty map if nil")
 }
+
+func TestResourceManager_GetRoutes(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+
+	routes := bed.Mgr.GetRoutes("http://localhost")
+	assert.Equal(t, "/api", routes[0].Path, "GetRoutes() should start with the api route")
+
+	positions := map[string]int{}
+	for i, route := range routes {
+		positions[route.Path] = i
+	}
+
+	aggregate, ok := positions["/api/mock-structs/aggregate"]
+	assert.True(t, ok, "GetRoutes() missing aggregate route")
+
+	detail, ok := positions["/api/mock-structs/{id}"]
+	assert.True(t, ok, "GetRoutes() missing detail route")
+
+	assert.Less(t, aggregate, detail, "Static routes should be registered before path variables")
+}
+
+func TestResourceManager_AddResource_Search(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+	rm := rmPkg.NewResourceManager(bed.Db, bed.Logger)
+
+	type SearchResource struct {
+		Title string `json:"title"`
+		Body  string `json:"body"`
+	}
+
+	resource, err := rm.AddResource(&rmTypes.ResourceConfig{
+		Model:  SearchResource{},
+		Search: &rmTypes.SearchConfig{Fields: []string{"Title", "Body"}},
+	})
+	assert.NoError(t, err, "AddResource() returned error")
+	assert.Equal(t, "Title", resource.Search.LabelField, "Search label should default to the first field")
+
+	type InvalidSearchResource struct {
+		Title string `json:"title"`
+	}
+
+	_, err = rm.AddResource(&rmTypes.ResourceConfig{
+		Model:  InvalidSearchResource{},
+		Search: &rmTypes.SearchConfig{Fields: []string{"Missing"}},
+	})
+	assert.Error(t, err, "AddResource() should fail with unknown search fields")
+}
+
+func TestResourceManager_AddResource_Display(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+	rm := rmPkg.NewResourceManager(bed.Db, bed.Logger)
+
+	type DisplayResource struct {
+		authModels.SystemData
+		Title    string `json:"title"`
+		Body     string `json:"body"`
+		Status   string `json:"status"`
+		Internal string `json:"-"`
+	}
+
+	resource, err := rm.AddResource(&rmTypes.ResourceConfig{
+		Model: DisplayResource{},
+		Display: &rmTypes.DisplayConfig{
+			ListColumns: []string{"Title", "Status"},
+			DefaultSort: "-CreatedAt,Title",
+			Fields: map[string]rmTypes.FieldDisplay{
+				"Body":   {Widget: rmTypes.WidgetRichText, HelpText: "Main content", Group: "Content"},
+				"Status": {Label: "State", Widget: rmTypes.WidgetSelect, Options: []string{"draft", "published"}},
+			},
+		},
+	})
+	assert.NoError(t, err, "AddResource() returned error")
+
+	assert.Equal(t, "title", resource.FieldNames["Title"])
+	assert.Equal(t, "createdById", resource.FieldNames["CreatedByID"])
+	assert.Equal(t, "ID", resource.FieldNames["ID"])
+	assert.NotContains(t, resource.FieldNames, "Internal")
+
+	display := resource.Display
+	assert.Equal(t, "Display Resource", display.Label)
+	assert.Equal(t, []string{"title", "status"}, display.ListColumns)
+	assert.Equal(t, "-CreatedAt,title", display.DefaultSort)
+	assert.Equal(t, "Body", display.Fields["body"].Label, "Label should default to the field name")
+	assert.Equal(t, rmTypes.WidgetRichText, display.Fields["body"].Widget)
+	assert.Equal(t, "State", display.Fields["status"].Label)
+	assert.Equal(t, "Created By ID", display.Fields["createdById"].Label)
+	assert.True(t, display.Fields["createdById"].ReadOnly, "System fields should be read only")
+	assert.False(t, display.Fields["title"].ReadOnly)
+
+	schema := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(resource.JsonSchema, &schema))
+	assert.Contains(t, schema, "$defs")
+	assert.Contains(t, schema, "x-display")
+
+	invalid := []*rmTypes.DisplayConfig{
+		{ListColumns: []string{"Missing"}},
+		{DefaultSort: "-Missing"},
+		{Fields: map[string]rmTypes.FieldDisplay{"Missing": {}}},
+		{Fields: map[string]rmTypes.FieldDisplay{"Title": {Widget: "unknown"}}},
+		{Fields: map[string]rmTypes.FieldDisplay{"Status": {Widget: rmTypes.WidgetSelect}}},
+		{Fields: map[string]rmTypes.FieldDisplay{"Title": {Widget: rmTypes.WidgetRelation}}},
+	}
+
+	for _, config := range invalid {
+		rm := rmPkg.NewResourceManager(bed.Db, bed.Logger)
+		_, err := rm.AddResource(&rmTypes.ResourceConfig{
+			Model:   DisplayResource{},
+			Display: config,
+		})
+		assert.Error(t, err, "AddResource() should fail with invalid display config")
+	}
+}
+
+func TestResourceManager_AddResource_Singleton(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+	rm := rmPkg.NewResourceManager(bed.Db, bed.Logger)
+
+	type HomepageConfig struct {
+		authModels.SystemData
+		Headline string `json:"headline"`
+	}
+	bed.Db.DB.Unscoped().Where("1 = 1").Delete(&HomepageConfig{})
+
+	resource, err := rm.AddResource(&rmTypes.ResourceConfig{
+		Model:     HomepageConfig{Headline: "Welcome"},
+		Singleton: true,
+	})
+	assert.NoError(t, err, "AddResource() returned error")
+
+	paths := map[string]bool{}
+	for _, route := range resource.Routes {
+		paths[route.Path] = true
+	}
+	assert.True(t, paths["/api/homepage-config"], "Singleton should expose its name route")
+	assert.True(t, paths["/api/homepage-config/schema"], "Singleton should expose its schema route")
+	assert.False(t, paths["/api/homepage-configs/new"], "Singleton should not expose the create route")
+	assert.False(t, paths["/api/homepage-configs/{id}"], "Singleton should not expose the detail route")
+
+	err = rm.EnsureSingletons(context.Background(), bed.AdminUser, "test")
+	assert.NoError(t, err, "EnsureSingletons() returned error")
+
+	config := HomepageConfig{}
+	assert.NoError(t, bed.Db.DB.First(&config).Error)
+	assert.Equal(t, "Welcome", config.Headline)
+	assert.Equal(t, bed.AdminUser.ID, config.CreatedByID)
+
+	type TreeSingleton struct {
+		rmTypes.TreeData
+	}
+	_, err = rm.AddResource(&rmTypes.ResourceConfig{
+		Model:     TreeSingleton{},
+		Tree:      true,
+		Singleton: true,
+	})
+	assert.Error(t, err, "AddResource() should reject tree singletons")
+}
+
+func TestResourceManager_AddResource_Translatable(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+	rm := rmPkg.NewResourceManager(bed.Db, bed.Logger)
+
+	err := rm.SetLocales(&rmTypes.LocaleConfig{Default: "en", Supported: []string{"es"}})
+	assert.Error(t, err, "Default locale must be supported")
+
+	err = rm.SetLocales(&rmTypes.LocaleConfig{
+		Default:   "en",
+		Supported: []string{"en", "es", "es-AR"},
+		Fallbacks: map[string]string{"es-AR": "es"},
+	})
+	assert.NoError(t, err, "SetLocales() returned error")
+	assert.Equal(t, []string{"es-AR", "es"}, rm.Locales.Chain("es-AR"))
+	assert.Empty(t, rm.Locales.Chain("en"))
+
+	type Headline struct {
+		authModels.SystemData
+		Title string `json:"title"`
+		Votes int    `json:"votes"`
+	}
+
+	resource, err := rm.AddResource(&rmTypes.ResourceConfig{
+		Model:        Headline{},
+		Translatable: []string{"Title"},
+	})
+	assert.NoError(t, err, "AddResource() returned error")
+	assert.Equal(t, rm.Locales, resource.Locales)
+	assert.True(t, resource.Display.Fields["title"].Translatable)
+	assert.False(t, resource.Display.Fields["votes"].Translatable)
+
+	paths := map[string]bool{}
+	for _, route := range resource.Routes {
+		paths[route.Path] = true
+	}
+	assert.True(t, paths["/api/headlines/{id}/translations"])
+	assert.True(t, paths["/api/headlines/translations/missing"])
+
+	type NumberHeadline struct {
+		authModels.SystemData
+		Votes int `json:"votes"`
+	}
+	_, err = rm.AddResource(&rmTypes.ResourceConfig{
+		Model:        NumberHeadline{},
+		Translatable: []string{"Votes"},
+	})
+	assert.Error(t, err, "Translatable fields must be strings")
+
+	type MissingHeadline struct {
+		authModels.SystemData
+	}
+	_, err = rm.AddResource(&rmTypes.ResourceConfig{
+		Model:        MissingHeadline{},
+		Translatable: []string{"Title"},
+	})
+	assert.Error(t, err, "Translatable fields must exist")
+
+	type UnlocalizedHeadline struct {
+		authModels.SystemData
+		Title string `json:"title"`
+	}
+	rm.Locales = nil
+	_, err = rm.AddResource(&rmTypes.ResourceConfig{
+		Model:        UnlocalizedHeadline{},
+		Translatable: []string{"Title"},
+	})
+	assert.Error(t, err, "Translatable resources require locales")
+}
+
+func TestResourceManager_AddResource_Slug(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+	rm := rmPkg.NewResourceManager(bed.Db, bed.Logger)
+
+	type Landing struct {
+		authModels.SystemData
+		Name  string `json:"name"`
+		Slug  string `json:"slug"`
+		Order int    `json:"order"`
+	}
+
+	resource, err := rm.AddResource(&rmTypes.ResourceConfig{
+		Model: Landing{},
+		Slug:  &rmTypes.SlugConfig{Field: "Slug", Source: "Name"},
+	})
+	assert.NoError(t, err, "AddResource() returned error")
+	assert.Contains(t, resource.Routes, "Landing:by-slug")
+	assert.Equal(t, "/api/landings/by-slug/{slug}", resource.Routes["Landing:by-slug"].Path)
+
+	type NumberSlug struct {
+		authModels.SystemData
+		Name  string `json:"name"`
+		Order int    `json:"order"`
+	}
+	_, err = rm.AddResource(&rmTypes.ResourceConfig{
+		Model: NumberSlug{},
+		Slug:  &rmTypes.SlugConfig{Field: "Order", Source: "Name"},
+	})
+	assert.Error(t, err, "Slug fields must be strings")
+
+	type SlugSingleton struct {
+		authModels.SystemData
+		Name string `json:"name"`
+		Slug string `json:"slug"`
+	}
+	_, err = rm.AddResource(&rmTypes.ResourceConfig{
+		Model:     SlugSingleton{},
+		Singleton: true,
+		Slug:      &rmTypes.SlugConfig{Field: "Slug", Source: "Name"},
+	})
+	assert.Error(t, err, "Singletons cannot have a slug")
+}
+
+func TestResourceManager_AddResource_Delivery(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+	rm := rmPkg.NewResourceManager(bed.Db, bed.Logger)
+
+	type Promo struct {
+		authModels.SystemData
+		Title       string     `json:"title"`
+		PublishedAt *time.Time `json:"publishedAt"`
+	}
+
+	resource, err := rm.AddResource(&rmTypes.ResourceConfig{
+		Model: Promo{},
+		Delivery: &rmTypes.DeliveryConfig{
+			Fields:    []string{"Title"},
+			Published: "PublishedAt",
+		},
+	})
+	assert.NoError(t, err, "AddResource() returned error")
+	assert.Equal(t, []string{"ID", "title"}, resource.Delivery.Fields)
+	assert.Equal(t, rmTypes.DefaultDeliveryMaxAge, resource.Delivery.MaxAge)
+	assert.False(t, resource.Routes["Promo:delivery-list"].RequiresAuth, "Delivery routes should be public")
+	assert.Equal(t, "/content/promos/{id}", resource.Routes["Promo:delivery-detail"].Path)
+
+	type TextPublished struct {
+		authModels.SystemData
+		Status string `json:"status"`
+	}
+	_, err = rm.AddResource(&rmTypes.ResourceConfig{
+		Model:    TextPublished{},
+		Delivery: &rmTypes.DeliveryConfig{Published: "Status"},
+	})
+	assert.Error(t, err, "Published field must be a bool or a time")
+
+	type UnknownDelivered struct {
+		authModels.SystemData
+	}
+	_, err = rm.AddResource(&rmTypes.ResourceConfig{
+		Model:    UnknownDelivered{},
+		Delivery: &rmTypes.DeliveryConfig{Fields: []string{"Missing"}},
+	})
+	assert.Error(t, err, "Delivered fields must exist")
+}
+
+func TestResourceManager_AddResource_Relations(t *testing.T) {
+	bed := testPkg.SetupHandlerTestBed()
+	rm := rmPkg.NewResourceManager(bed.Db, bed.Logger)
+
+	type Shelf struct {
+		authModels.SystemData
+		Name string `json:"name"`
+	}
+
+	type Box struct {
+		authModels.SystemData
+		ShelfID  uint   `json:"shelfId"`
+		LeftOnID *uint  `json:"leftOnId"`
+		ParentID *uint  `json:"parentId"`
+		Label    string `json:"label"`
+	}
+
+	shelf, err := rm.AddResource(&rmTypes.ResourceConfig{Model: Shelf{}})
+	assert.NoError(t, err, "AddResource() returned error")
+
+	box, err := rm.AddResource(&rmTypes.ResourceConfig{
+		Model: Box{},
+		Relations: []rmTypes.RelationConfig{
+			{Field: "ShelfID", Resource: "Shelf"},
+			{Field: "LeftOnID", Resource: "Shelf", OnDelete: rmTypes.SetNullOnDelete},
+			{Field: "ParentID", Resource: "Box", OnDelete: rmTypes.CascadeOnDelete},
+		},
+	})
+	assert.NoError(t, err, "AddResource() returned error")
+	assert.Equal(t, rmTypes.RestrictOnDelete, box.Relations[0].OnDelete, "Relations restrict by default")
+
+	assert.Len(t, shelf.ReferencedBy, 2)
+	assert.Equal(t, "shelf_id", shelf.ReferencedBy[0].Column)
+	assert.Equal(t, box, shelf.ReferencedBy[0].Resource)
+	assert.Len(t, box.ReferencedBy, 1, "Resources can reference themselves")
+
+	invalid := []struct {
+		name     string
+		relation rmTypes.RelationConfig
+	}{
+		{"Unknown field", rmTypes.RelationConfig{Field: "Missing", Resource: "Shelf"}},
+		{"Not an id", rmTypes.RelationConfig{Field: "Label", Resource: "Shelf"}},
+		{"Unknown resource", rmTypes.RelationConfig{Field: "ShelfID", Resource: "Missing"}},
+		{"Set null on a required field", rmTypes.RelationConfig{Field: "ShelfID", Resource: "Shelf", OnDelete: rmTypes.SetNullOnDelete}},
+		{"Unknown policy", rmTypes.RelationConfig{Field: "ShelfID", Resource: "Shelf", OnDelete: "ignore"}},
+	}
+
+	for _, tc := range invalid {
+		type Crate struct {
+			authModels.SystemData
+			ShelfID uint   `json:"shelfId"`
+			Label   string `json:"label"`
+		}
+
+		_, err := rm.AddResource(&rmTypes.ResourceConfig{
+			Model:     Crate{},
+			Relations: []rmTypes.RelationConfig{tc.relation},
+		})
+		assert.Error(t, err, tc.name)
+	}
+	assert.Len(t, shelf.ReferencedBy, 2, "Invalid relations should not be linked")
+}
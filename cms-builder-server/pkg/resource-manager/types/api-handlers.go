@@ -4,10 +4,23 @@ import "net/http"
 
 // ApiHandlers holds the handlers for various API operations.
 type ApiHandlers struct {
-	List   ApiFunction
-	Detail ApiFunction
-	Create ApiFunction
-	Update ApiFunction
-	Delete ApiFunction
-	Schema func(resource *Resource) http.HandlerFunc
+	List                ApiFunction
+	Detail              ApiFunction
+	Create              ApiFunction
+	Update              ApiFunction
+	Delete              ApiFunction
+	Aggregate           ApiFunction
+	Tree                ApiFunction
+	Move                ApiFunction
+	Reorder             ApiFunction
+	Translations        ApiFunction
+	Translate           ApiFunction
+	MissingTranslations ApiFunction
+	BySlug              ApiFunction
+	DeliveryList        ApiFunction
+	DeliveryDetail      ApiFunction
+	References          ApiFunction
+	Lock                ApiFunction
+	Unlock              ApiFunction
+	Schema              func(resource *Resource) http.HandlerFunc
 }
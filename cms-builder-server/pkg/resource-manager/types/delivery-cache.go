@@ -0,0 +1,92 @@
+package types
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// DeliveryCacheEntry is a response of the public content delivery API.
+type DeliveryCacheEntry struct {
+	Key       string
+	Status    int
+	Body      []byte
+	ETag      string
+	Locale    string
+	ExpiresAt time.Time
+}
+
+// DeliveryCache is a least recently used cache of delivery responses.
+// It is purged whenever the records of its resource are written.
+type DeliveryCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	order   *list.List // Most recently used entries first
+}
+
+func NewDeliveryCache(size int) *DeliveryCache {
+	return &DeliveryCache{
+		size:    size,
+		entries: map[string]*list.Element{},
+		order:   list.New(),
+	}
+}
+
+// Get returns the entry stored under the given key, unless it expired.
+func (c *DeliveryCache) Get(key string) (*DeliveryCacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*DeliveryCacheEntry)
+	if time.Now().After(entry.ExpiresAt) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	return entry, true
+}
+
+// Set stores the given entry, evicting the least recently used one when the cache is full.
+func (c *DeliveryCache) Set(entry *DeliveryCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[entry.Key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[entry.Key] = c.order.PushFront(entry)
+
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*DeliveryCacheEntry).Key)
+	}
+}
+
+// Purge drops every entry.
+func (c *DeliveryCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = map[string]*list.Element{}
+	c.order.Init()
+}
+
+// Len returns the number of stored entries.
+func (c *DeliveryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
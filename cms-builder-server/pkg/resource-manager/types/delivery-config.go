@@ -0,0 +1,17 @@
+package types
+
+import "time"
+
+// DeliveryConfig exposes a resource read-only on the public content delivery API.
+// Delivered records are never bound to a user, so only whitelisted fields of published records are sent.
+type DeliveryConfig struct {
+	Fields    []string      // Model field names sent to the public, the ID is always sent
+	Published string        // Model field name of a bool, or a time that must be in the past, empty delivers every record
+	MaxAge    time.Duration // Time clients and the in-process cache may reuse a response, defaults to DefaultDeliveryMaxAge
+	CacheSize int           // Responses kept in the in-process cache, defaults to DefaultDeliveryCacheSize
+}
+
+const (
+	DefaultDeliveryMaxAge    = 5 * time.Minute
+	DefaultDeliveryCacheSize = 256
+)
@@ -0,0 +1,53 @@
+package types
+
+// FieldWidget tells the admin which input component renders a field.
+type FieldWidget string
+
+const (
+	WidgetText     FieldWidget = "text"
+	WidgetTextarea FieldWidget = "textarea"
+	WidgetRichText FieldWidget = "richtext"
+	WidgetNumber   FieldWidget = "number"
+	WidgetCheckbox FieldWidget = "checkbox"
+	WidgetDate     FieldWidget = "date"
+	WidgetImage    FieldWidget = "image"
+	WidgetRelation FieldWidget = "relation"
+	WidgetSelect   FieldWidget = "select"
+)
+
+// Widgets lists every widget the admin knows how to render.
+var Widgets = []FieldWidget{
+	WidgetText,
+	WidgetTextarea,
+	WidgetRichText,
+	WidgetNumber,
+	WidgetCheckbox,
+	WidgetDate,
+	WidgetImage,
+	WidgetRelation,
+	WidgetSelect,
+}
+
+// FieldDisplay describes how a single field is presented in the admin.
+type FieldDisplay struct {
+	Label    string      `json:"label"`              // Human label, defaults to the field name split in words
+	HelpText string      `json:"helpText,omitempty"` // Hint shown below the input
+	Widget   FieldWidget `json:"widget,omitempty"`   // Input component, empty lets the admin decide from the schema type
+	Group    string      `json:"group,omitempty"`    // Form section the field belongs to
+	ReadOnly bool        `json:"readOnly"`           // Whether the field is displayed but not editable
+	Options  []string    `json:"options,omitempty"`  // Allowed values for the select widget
+	Relation string      `json:"relation,omitempty"` // Resource name picked by the relation widget
+
+	Translatable bool `json:"translatable,omitempty"` // Whether the field has a value per locale, set from the resource config
+}
+
+// DisplayConfig declares the admin UI metadata of a resource.
+// Field names, list columns and the default sort use model field names,
+// once initialized they are translated to the json keys the admin works with.
+type DisplayConfig struct {
+	Label       string                  `json:"label"`       // Human singular label, defaults to the resource name
+	PluralLabel string                  `json:"pluralLabel"` // Human plural label, defaults to the plural resource name
+	ListColumns []string                `json:"listColumns"` // Columns shown in the list view
+	DefaultSort string                  `json:"defaultSort"` // Sort applied to the list when none is requested, prefix with - for descending
+	Fields      map[string]FieldDisplay `json:"fields"`      // Field metadata
+}
@@ -0,0 +1,58 @@
+package types
+
+import (
+	"fmt"
+	"slices"
+)
+
+// LocaleConfig defines the locales content can be delivered in.
+// Model fields hold the value in the default locale, other locales are stored as translations.
+type LocaleConfig struct {
+	Default   string            `json:"default"`   // Locale stored in the model fields
+	Supported []string          `json:"supported"` // Locales accepted by the locale parameter, including the default one
+	Fallbacks map[string]string `json:"fallbacks"` // Locale tried next when a translation is missing, e.g. es-AR falls back to es
+}
+
+// NewLocaleConfig returns a config that only supports the given default locale.
+func NewLocaleConfig(defaultLocale string) *LocaleConfig {
+	return &LocaleConfig{
+		Default:   defaultLocale,
+		Supported: []string{defaultLocale},
+		Fallbacks: map[string]string{},
+	}
+}
+
+// Validate checks that the default locale and every fallback are supported.
+func (c *LocaleConfig) Validate() error {
+	if c.Default == "" {
+		return fmt.Errorf("default locale is required")
+	}
+
+	if !c.IsSupported(c.Default) {
+		return fmt.Errorf("default locale %s must be supported", c.Default)
+	}
+
+	for locale, fallback := range c.Fallbacks {
+		if !c.IsSupported(locale) || !c.IsSupported(fallback) {
+			return fmt.Errorf("fallback %s -> %s uses unsupported locales", locale, fallback)
+		}
+	}
+
+	return nil
+}
+
+// IsSupported reports whether content can be requested in the given locale.
+func (c *LocaleConfig) IsSupported(locale string) bool {
+	return slices.Contains(c.Supported, locale)
+}
+
+// Chain returns the translated locales to look up for the given locale, in order.
+// The default locale is left out since its values live in the model.
+func (c *LocaleConfig) Chain(locale string) []string {
+	chain := []string{}
+	for locale != "" && locale != c.Default && !slices.Contains(chain, locale) {
+		chain = append(chain, locale)
+		locale = c.Fallbacks[locale]
+	}
+	return chain
+}
@@ -0,0 +1,25 @@
+package types
+
+// DeletePolicy tells what happens to the records referencing a record when it is deleted.
+type DeletePolicy string
+
+const (
+	RestrictOnDelete DeletePolicy = "restrict" // The delete is refused while referencing records exist
+	CascadeOnDelete  DeletePolicy = "cascade"  // Referencing records are deleted as well
+	SetNullOnDelete  DeletePolicy = "set-null" // The reference of referencing records is cleared
+)
+
+// RelationConfig declares that a field of a resource holds the id of a record of another resource.
+type RelationConfig struct {
+	Field    string       // Model field name holding the referenced id, it must be an unsigned integer
+	Resource string       // Name of the referenced resource, it must be registered before
+	OnDelete DeletePolicy // What happens to this record when the referenced record is deleted, restrict by default
+}
+
+// Reference is a relation as seen from the referenced resource.
+type Reference struct {
+	Resource *Resource    // The referencing resource
+	Field    string       // Model field name of the referencing resource holding the id
+	Column   string       // Column of the referencing resource holding the id
+	OnDelete DeletePolicy // Empty for foreign keys found in the models, which are listed but not enforced
+}
@@ -9,7 +9,16 @@ type ResourceConfig struct {
 	Model           interface{}
 	Handlers        *ApiHandlers
 	SkipUserBinding bool
+	PrivateActivity bool // Log entries are only listed to admins, for records readable per record rather than per resource
+	Tree            bool
+	Singleton       bool // The model values are used as defaults for the record created on first boot
 	Validators      ValidatorsMap
 	Permissions     authTypes.RolePermissionMap
 	Routes          []svrTypes.Route
+	Search          *SearchConfig
+	Slug            *SlugConfig
+	Delivery        *DeliveryConfig // Exposes the resource on the public content delivery API
+	Display         *DisplayConfig
+	Translatable    []string         // Model field names stored per locale, they must be strings
+	Relations       []RelationConfig // Delete policies of the fields referencing other resources
 }
@@ -14,13 +14,26 @@ import (
 type Resource struct {
 	Model           interface{}                 // The model struct
 	SkipUserBinding bool                        // Whether to skip user binding for this resource
+	PrivateActivity bool                        // Whether log entries are left out of the activity feed of non-admins
+	Tree            bool                        // Whether the model embeds TreeData and exposes the tree endpoints
+	Singleton       bool                        // Whether the resource holds exactly one record
+	Translatable    []string                    // Model field names stored per locale
+	Locales         *LocaleConfig               // Locales shared by every resource of the manager
+	Slug            *SlugConfig                 // Slug generation settings, nil when the resource has no slug
+	Delivery        *DeliveryConfig             // Public delivery settings, fields hold json keys once initialized
+	DeliveryCache   *DeliveryCache              // Cached public delivery responses
+	Relations       []RelationConfig            // Fields of this resource referencing other resources
+	ReferencedBy    []Reference                 `json:"-"` // Fields of other resources referencing this one
 	Validators      ValidatorsMap               // Map of field validators
 	Permissions     authTypes.RolePermissionMap // Role-based permissions
 	Api             *ApiHandlers                // API handlers
 	Routes          map[string]svrTypes.Route   // Custom routes for this resource
+	CustomRoutes    []svrTypes.Route            `json:"-"` // Routes given in the config, on top of the generated ones
+	Search          *SearchConfig               // Global search settings, nil when the resource is not searchable
+	Display         *DisplayConfig              `json:"display"`       // Admin UI metadata
 	ResourceNames   ResourceNames               `json:"resourceNames"` // Resource names
 	JsonSchema      json.RawMessage             `json:"jsonSchema"`    // JSON schema
-	FieldNames      map[string]string           `json:"fieldNames"`    // TODO: Map of field names
+	FieldNames      map[string]string           `json:"fieldNames"`    // Map of model field names to json keys
 }
 
 // GetSlice returns a new slice of the resource's model type.
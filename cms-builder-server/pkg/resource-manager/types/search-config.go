@@ -0,0 +1,7 @@
+package types
+
+// SearchConfig marks a resource as searchable from the global search endpoint.
+type SearchConfig struct {
+	Fields     []string // Model field names matched against the search term
+	LabelField string   // Model field name used as the hit label, defaults to the first search field
+}
@@ -0,0 +1,8 @@
+package types
+
+// SlugConfig generates a unique, URL-safe slug for every record of a resource.
+type SlugConfig struct {
+	Field              string // Model field name holding the slug, it must be a string
+	Source             string // Model field name the slug is generated from, it must be a string
+	RegenerateOnUpdate bool   // Whether the slug follows the source field when it changes, old slugs redirect to the new one
+}
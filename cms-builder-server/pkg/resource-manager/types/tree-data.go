@@ -0,0 +1,19 @@
+package types
+
+// TreeData adds parent/child relations and manual ordering to a model.
+// Models embed it and enable the tree endpoints with ResourceConfig.Tree.
+type TreeData struct {
+	ParentID *uint  `gorm:"index" json:"parentId" jsonschema:"title=Parent Id,description=Id of the parent node"`
+	Position int    `gorm:"not null;default:0" json:"position" jsonschema:"title=Position,description=Order of the node among its siblings"`
+	Path     string `gorm:"index" json:"path" jsonschema:"title=Path,description=Materialized path of ancestor ids including the node e.g. /1/4/9/"`
+}
+
+// GetTreeData returns the tree data of the node, it is promoted to the models embedding TreeData.
+func (t *TreeData) GetTreeData() *TreeData {
+	return t
+}
+
+// TreeNode is implemented by every model embedding TreeData.
+type TreeNode interface {
+	GetTreeData() *TreeData
+}
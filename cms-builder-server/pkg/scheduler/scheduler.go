@@ -7,10 +7,13 @@ import (
 	"github.com/go-co-op/gocron/v2"
 	"github.com/google/uuid"
 
+	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
 	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
 	dbQueries "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/queries"
 	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
 	loggerTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/logger/types"
+	notificationConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/notification/constants"
+	notificationTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/notification/types"
 	schConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/scheduler/constants"
 	schInterfaces "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/scheduler/interfaces"
 	schModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/scheduler/models"
@@ -27,6 +30,7 @@ type Scheduler struct {
 	TaskManager  schTypes.TaskManager          // Thread-safe map for storing job results.
 	JobRegistry  schTypes.JobRegistry          // Registry of task functions and their parameters.
 	RunScheduler bool                          // Flag to determine if the scheduler should run.
+	Notifier     notificationTypes.Notifier    // Notifies admins of failed jobs, optional.
 }
 
 // RegisterTask registers a task function with the scheduler.
@@ -208,11 +212,40 @@ func (s *Scheduler) WithErrors(jobDefinition *schModels.SchedulerJobDefinition)
 			s.Logger.Error().Err(err).Msg("Error updating task status")
 		}
 
+		s.notifyFailure(jobDefinition, jobError)
+
 		// Reset the task
 		s.TaskManager.Delete(jobDefinition.Name)
 	}
 }
 
+// notifyFailure tells the admins a job failed, when the scheduler has a notifier.
+// Parameters:
+//   - jobDefinition: Job definition.
+//   - jobError: Error the job failed with.
+func (s *Scheduler) notifyFailure(jobDefinition *schModels.SchedulerJobDefinition, jobError error) {
+	if s.Notifier == nil {
+		return
+	}
+
+	input := notificationTypes.NotificationInput{
+		Role:  authConstants.AdminRole,
+		Kind:  notificationConstants.KindJobFailed,
+		Title: fmt.Sprintf("Job %s failed", jobDefinition.Name),
+		Body:  jobError.Error(),
+	}
+
+	if jobDefinition.SystemData != nil {
+		input.ResourceName = "SchedulerJobDefinition"
+		input.ResourceId = jobDefinition.StringID()
+	}
+
+	err := s.Notifier.Notify(context.Background(), input)
+	if err != nil {
+		s.Logger.Error().Err(err).Msg("Error notifying failed job")
+	}
+}
+
 // After returns a function that is executed after a job completes successfully.
 // Parameters:
 //   - jobDefinition: Job definition.
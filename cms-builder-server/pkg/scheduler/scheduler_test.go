@@ -1,6 +1,7 @@
 package scheduler_test
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -10,6 +11,8 @@ import (
 	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
 	dbTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/types"
 	loggerTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/logger/types"
+	notificationConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/notification/constants"
+	notificationTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/notification/types"
 	schPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/scheduler"
 	schConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/scheduler/constants"
 	schModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/scheduler/models"
@@ -183,3 +186,35 @@ func TestRunJob(t *testing.T) {
 		})
 	}
 }
+
+type mockNotifier struct {
+	Sent []notificationTypes.NotificationInput
+}
+
+func (n *mockNotifier) Notify(ctx context.Context, input notificationTypes.NotificationInput) error {
+	n.Sent = append(n.Sent, input)
+	return nil
+}
+
+// TestWithErrors_NotifiesAdmins tests failed jobs are reported to the admins.
+func TestWithErrors_NotifiesAdmins(t *testing.T) {
+	bed := testPkg.SetupSchedulerTestBed()
+	bed.Scheduler.Cron = MockCron{}
+
+	notifier := &mockNotifier{}
+	bed.Scheduler.Notifier = notifier
+
+	jd, _ := RegisterTestTask(bed.Scheduler, bed.Logger, bed.Store, bed.Db, bed.SchedulerUser)
+
+	jobId := uuid.New()
+	bed.Scheduler.Before(&jd)(jobId, jd.Name)
+	bed.Scheduler.WithErrors(&jd)(jobId, jd.Name, fmt.Errorf("Test func has failed"))
+
+	assert.Len(t, notifier.Sent, 1)
+	assert.Equal(t, notificationConstants.KindJobFailed, notifier.Sent[0].Kind)
+	assert.Equal(t, "Job test-job failed", notifier.Sent[0].Title)
+	assert.Equal(t, "Test func has failed", notifier.Sent[0].Body)
+
+	bed.Scheduler.After(&jd)(jobId, jd.Name)
+	assert.Len(t, notifier.Sent, 1, "Successful jobs are not notified")
+}
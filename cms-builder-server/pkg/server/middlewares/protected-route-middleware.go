@@ -2,10 +2,30 @@ package server
 
 import (
 	"net/http"
+	"sync"
 
 	svrUtils "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/utils"
 )
 
+var (
+	verifiedEmailMu      sync.RWMutex
+	requireVerifiedEmail bool
+)
+
+// SetRequireVerifiedEmail makes protected routes reject users who haven't verified their email.
+// Admins impersonating a user are let through, and so are api keys, as service users can't verify their email.
+func SetRequireVerifiedEmail(require bool) {
+	verifiedEmailMu.Lock()
+	defer verifiedEmailMu.Unlock()
+	requireVerifiedEmail = require
+}
+
+func getRequireVerifiedEmail() bool {
+	verifiedEmailMu.RLock()
+	defer verifiedEmailMu.RUnlock()
+	return requireVerifiedEmail
+}
+
 func ProtectedRouteMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
@@ -18,6 +38,13 @@ func ProtectedRouteMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
+		if getRequireVerifiedEmail() && !ctx.User.IsEmailVerified() && ctx.User.ImpersonatedBy == nil && ctx.User.ApiKeyID == 0 {
+			ctx.Logger.Warn().Uint("user", ctx.User.ID).Msg("Email not verified, not allowed to enter protected route")
+
+			svrUtils.SendJsonResponse(w, http.StatusForbidden, nil, "Email not verified")
+			return
+		}
+
 		ctx.Logger.Debug().Bool("authenticated", ctx.IsAuthenticated).Msg("Entering protected route")
 		next.ServeHTTP(w, r)
 	})
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -84,3 +85,57 @@ func TestProtectedRouteMiddleware(t *testing.T) {
 		})
 	}
 }
+
+// TestProtectedRouteMiddleware_RequireVerifiedEmail tests that unverified users are blocked once required.
+func TestProtectedRouteMiddleware_RequireVerifiedEmail(t *testing.T) {
+	svrMiddlewares.SetRequireVerifiedEmail(true)
+	defer svrMiddlewares.SetRequireVerifiedEmail(false)
+
+	verifiedAt := time.Now()
+	admin := &authModels.User{ID: 1, FirstName: "Admin", EmailVerifiedAt: &verifiedAt}
+
+	tests := []struct {
+		name           string
+		user           *authModels.User
+		expectedStatus int
+	}{
+		{
+			name:           "verified user",
+			user:           &authModels.User{ID: 2, FirstName: "John Doe", EmailVerifiedAt: &verifiedAt},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "unverified user",
+			user:           &authModels.User{ID: 3, FirstName: "John Doe"},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "unverified user impersonated by an admin",
+			user:           &authModels.User{ID: 4, FirstName: "John Doe", ImpersonatedBy: admin},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "unverified service user of an api key",
+			user:           &authModels.User{ID: 5, FirstName: "Ci", ApiKeyID: 1},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			ctx := context.WithValue(req.Context(), authConstants.CtxRequestIsAuth, true)
+			ctx = context.WithValue(ctx, authConstants.CtxRequestUser, tt.user)
+			req = req.WithContext(ctx)
+
+			w := httptest.NewRecorder()
+			svrMiddlewares.ProtectedRouteMiddleware(handler).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code, "Unexpected status code")
+		})
+	}
+}
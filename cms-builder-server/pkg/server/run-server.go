@@ -1,6 +1,7 @@
 package server
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/NYTimes/gziphandler"
@@ -30,7 +31,7 @@ func RunServer(s *svrTypes.Server, getRoutes svrTypes.GetRoutesFunc, certificate
 	publicRouter := s.Root
 	publicRouter.Use(
 		svrMiddlewares.RecoveryMiddleware,
-		authMiddlewares.AuthMiddleware(s.GodToken, s.GodUser, s.Firebase, s.DB, s.SystemUser),
+		authMiddlewares.AuthMiddleware(s.GodToken, s.GodUser, s.AuthProvider, s.DB, s.SystemUser),
 		authMiddlewares.UserCookieMiddleware,
 		rlMiddlewares.RequestLoggerMiddleware(s.DB),
 		svrMiddlewares.LoggingMiddleware(s.LoggerConfig),
@@ -40,16 +41,13 @@ func RunServer(s *svrTypes.Server, getRoutes svrTypes.GetRoutesFunc, certificate
 		gziphandler.GzipHandler,
 	)
 
-	routesSeen := map[string]bool{}
+	// Check for duplicates - We shouldn't have two routes with the same path and method
+	err := ValidateRoutes(routes)
+	if err != nil {
+		panic(err.Error())
+	}
 
 	for _, route := range routes {
-
-		// Check for duplicates - We shouldn't have two routes with the same path
-		if routesSeen[route.Path] {
-			panic("Duplicate route: " + route.Path + " - " + route.Name)
-		}
-		routesSeen[route.Path] = true
-
 		if route.RequiresAuth {
 			continue
 		}
@@ -84,3 +82,35 @@ func RunServer(s *svrTypes.Server, getRoutes svrTypes.GetRoutesFunc, certificate
 
 	return s.ListenAndServeTLS(certificates.CertFile, certificates.KeyFile)
 }
+
+// ValidateRoutes fails when two routes share a path and a method. Routes without methods match
+// every method, so they collide with any other route on their path.
+func ValidateRoutes(routes []svrTypes.Route) error {
+	anyMethod := "*"
+	routesSeen := map[string]map[string]bool{}
+
+	for _, route := range routes {
+		methodsSeen, ok := routesSeen[route.Path]
+		if !ok {
+			methodsSeen = map[string]bool{}
+			routesSeen[route.Path] = methodsSeen
+		}
+
+		if len(route.Methods) == 0 {
+			if len(methodsSeen) > 0 {
+				return fmt.Errorf("duplicate route: %s %s - %s", anyMethod, route.Path, route.Name)
+			}
+			methodsSeen[anyMethod] = true
+			continue
+		}
+
+		for _, method := range route.Methods {
+			if methodsSeen[method] || methodsSeen[anyMethod] {
+				return fmt.Errorf("duplicate route: %s %s - %s", method, route.Path, route.Name)
+			}
+			methodsSeen[method] = true
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package server_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	svrPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server"
+	svrTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/server/types"
+)
+
+func TestValidateRoutes(t *testing.T) {
+	get := []string{http.MethodGet}
+	post := []string{http.MethodPost}
+
+	tests := []struct {
+		name        string
+		routes      []svrTypes.Route
+		expectedErr string
+	}{
+		{
+			name: "Same path with different methods",
+			routes: []svrTypes.Route{
+				{Path: "/posts", Name: "list", Methods: get},
+				{Path: "/posts", Name: "create", Methods: post},
+			},
+		},
+		{
+			name: "Same path and method",
+			routes: []svrTypes.Route{
+				{Path: "/posts", Name: "list", Methods: get},
+				{Path: "/posts", Name: "other", Methods: []string{http.MethodPut, http.MethodGet}},
+			},
+			expectedErr: "duplicate route: GET /posts - other",
+		},
+		{
+			name: "Routes without methods on the same path",
+			routes: []svrTypes.Route{
+				{Path: "/posts", Name: "any"},
+				{Path: "/posts", Name: "other"},
+			},
+			expectedErr: "duplicate route: * /posts - other",
+		},
+		{
+			name: "Route without methods after a route with methods",
+			routes: []svrTypes.Route{
+				{Path: "/posts", Name: "list", Methods: get},
+				{Path: "/posts", Name: "any"},
+			},
+			expectedErr: "duplicate route: * /posts - any",
+		},
+		{
+			name: "Route with methods after a route without methods",
+			routes: []svrTypes.Route{
+				{Path: "/posts", Name: "any"},
+				{Path: "/posts", Name: "create", Methods: post},
+			},
+			expectedErr: "duplicate route: POST /posts - create",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := svrPkg.ValidateRoutes(tt.routes)
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.expectedErr)
+		})
+	}
+}
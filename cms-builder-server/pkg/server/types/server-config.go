@@ -2,7 +2,7 @@ package server
 
 import (
 	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
-	cliPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/clients"
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
 	loggerTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/logger/types"
 )
 
@@ -16,5 +16,5 @@ type ServerConfig struct {
 	GodToken       string
 	GodUser        *authModels.User
 	SystemUser     *authModels.User
-	Firebase       *cliPkg.FirebaseManager
+	AuthProvider   authTypes.AuthProvider
 }
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -37,6 +38,39 @@ func GetRequestAccessToken(r *http.Request) string {
 	return ""
 }
 
+// GetRequestApiKey returns the key of an "Authorization: ApiKey <key>" header.
+func GetRequestApiKey(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if header != "" {
+		tokenArray := strings.Split(header, " ")
+		if len(tokenArray) == 2 && tokenArray[0] == "ApiKey" {
+			return tokenArray[1]
+		}
+	}
+	return ""
+}
+
+// GetRequestImpersonationToken returns the token of an "Authorization: Impersonate <token>" header.
+func GetRequestImpersonationToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if header != "" {
+		tokenArray := strings.Split(header, " ")
+		if len(tokenArray) == 2 && tokenArray[0] == "Impersonate" {
+			return tokenArray[1]
+		}
+	}
+	return ""
+}
+
+// GetRequestIp returns the IP of the client connection, proxy headers are not trusted.
+func GetRequestIp(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
 func GetRequestId(r *http.Request) string {
 	ctx := r.Context()
 	if requestId, ok := ctx.Value(svrConstants.CtxTraceId).(string); ok {
@@ -62,6 +62,16 @@ func SendJsonResponse(w http.ResponseWriter, status int, data interface{}, msg s
 //
 // The function also sets the Content-Type header to "application/json", and writes the response with the given status code.
 func SendJsonResponseWithPagination(w http.ResponseWriter, status int, data interface{}, msg string, pagination *dbTypes.Pagination) {
+	responseBytes := MarshalJsonResponse(status, data, msg, pagination)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(responseBytes)
+}
+
+// MarshalJsonResponse returns the body SendJsonResponseWithPagination writes for the given arguments.
+// It lets handlers that cache or hash responses build the same envelope.
+func MarshalJsonResponse(status int, data interface{}, msg string, pagination *dbTypes.Pagination) []byte {
 	var response svrTypes.Response
 
 	if status >= 200 && status < 300 {
@@ -80,9 +90,7 @@ func SendJsonResponseWithPagination(w http.ResponseWriter, status int, data inte
 		responseBytes = []byte(fmt.Sprintf(`{"error": "%s"}`, err.Error()))
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	w.Write(responseBytes)
+	return responseBytes
 }
 
 // ParseResponse takes a JSON byte slice and an interface to unmarshal the data into.
@@ -0,0 +1,106 @@
+package testing
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	authTypes "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/types"
+)
+
+const MockAuthProviderType authTypes.AuthProviderType = "mock"
+
+// MockAuthProvider keeps provider users in memory, so tests don't need a real auth provider.
+type MockAuthProvider struct {
+	mu        sync.Mutex
+	Users     map[string]authTypes.ProviderUser // Users by email
+	Tokens    map[string]string                 // Emails by access token
+	AuthTimes map[string]time.Time              // Sign in times by access token, each token is a session
+}
+
+func NewMockAuthProvider() *MockAuthProvider {
+	return &MockAuthProvider{
+		Users:     map[string]authTypes.ProviderUser{},
+		Tokens:    map[string]string{},
+		AuthTimes: map[string]time.Time{},
+	}
+}
+
+// IssueToken returns an access token for the provider user with the given email.
+func (p *MockAuthProvider) IssueToken(email string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	token := uuid.New().String()
+	p.Tokens[token] = email
+	p.AuthTimes[token] = time.Now()
+	return token
+}
+
+func (p *MockAuthProvider) Type() authTypes.AuthProviderType {
+	return MockAuthProviderType
+}
+
+func (p *MockAuthProvider) VerifyToken(ctx context.Context, token string) (*authTypes.ProviderUser, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	email, ok := p.Tokens[token]
+	if !ok {
+		return nil, authTypes.ErrProviderInvalidToken
+	}
+
+	user, ok := p.Users[email]
+	if !ok {
+		return nil, authTypes.ErrProviderInvalidToken
+	}
+
+	user.SessionId = token
+	user.AuthTime = p.AuthTimes[token]
+	return &user, nil
+}
+
+func (p *MockAuthProvider) CreateUser(ctx context.Context, input authTypes.ProviderUserInput) (*authTypes.ProviderUser, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.Users[input.Email]; ok {
+		return nil, authTypes.ErrProviderUserExists
+	}
+
+	user := authTypes.ProviderUser{
+		ID:    uuid.New().String(),
+		Email: input.Email,
+		Name:  input.Name,
+	}
+	p.Users[input.Email] = user
+
+	return &user, nil
+}
+
+func (p *MockAuthProvider) DeleteUser(ctx context.Context, id string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for email, user := range p.Users {
+		if user.ID == id {
+			delete(p.Users, email)
+		}
+	}
+
+	return nil
+}
+
+func (p *MockAuthProvider) GetUserByEmail(ctx context.Context, email string) (*authTypes.ProviderUser, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	user, ok := p.Users[email]
+	if !ok {
+		return nil, authTypes.ErrProviderUserNotFound
+	}
+
+	return &user, nil
+}
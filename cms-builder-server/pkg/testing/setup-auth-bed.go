@@ -1,23 +1,14 @@
 package testing
 
 import (
-	"os"
-
-	"github.com/joho/godotenv"
-
 	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
 	authResources "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/resources"
-	cliPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/clients"
 	dbModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/models"
 	rmPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager"
 )
 
 func SetupAuthTestBed() TestUtils {
 
-	if os.Getenv("FIREBASE_SECRET") == "" {
-		godotenv.Load(".test.env")
-	}
-
 	db := NewTestDB()
 	err := db.DB.AutoMigrate(authModels.User{})
 	if err != nil {
@@ -29,23 +20,19 @@ func SetupAuthTestBed() TestUtils {
 		panic(err)
 	}
 
-	log := NewTestLogger()
-	mgr := rmPkg.NewResourceManager(db, log)
-
-	// fb
-	fbCfg := &cliPkg.FirebaseConfig{
-		Secret: os.Getenv("FIREBASE_SECRET"),
-	}
-	fb, err := cliPkg.NewFirebaseAdmin(fbCfg)
+	err = db.DB.AutoMigrate(authModels.UserSession{}, authModels.SessionRevocation{})
 	if err != nil {
 		panic(err)
 	}
 
+	log := NewTestLogger()
+	mgr := rmPkg.NewResourceManager(db, log)
+
 	getSystemUser := func() *authModels.User {
 		return CreateSystemUser()
 	}
 
-	srcConfig := authResources.SetupUserResource(fb, db, log, getSystemUser)
+	srcConfig := authResources.SetupUserResource(NewMockAuthProvider(), db, log, getSystemUser)
 	src, err := mgr.AddResource(srcConfig)
 	if err != nil {
 		panic(err)
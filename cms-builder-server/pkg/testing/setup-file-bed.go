@@ -66,7 +66,7 @@ func SetupFileTestBed() TestUtils {
 
 	manager := rmPkg.NewResourceManager(db, log)
 
-	fileSetup := fileResources.SetupFileResource(manager, db, localStore, log, "http://localhost:8080")
+	fileSetup := fileResources.SetupFileResource(manager, db, localStore, log, "http://localhost:8080", nil)
 	fileResource, err := manager.AddResource(fileSetup)
 	if err != nil {
 		panic(err)
@@ -18,6 +18,11 @@ func SetupHandlerTestBed() TestUtils {
 		panic(err)
 	}
 
+	err = db.DB.AutoMigrate(authModels.UserSession{}, authModels.SessionRevocation{})
+	if err != nil {
+		panic(err)
+	}
+
 	log := NewTestLogger()
 	mgr := rmPkg.NewResourceManager(db, log)
 
@@ -6,7 +6,6 @@ import (
 	"github.com/joho/godotenv"
 
 	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
-	cliPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/clients"
 	dbModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/database/models"
 	rlModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/request-logger/models"
 	rmPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/resource-manager"
@@ -29,26 +28,23 @@ func SetupServerTestBed() TestUtils {
 		panic(err)
 	}
 
-	err = db.DB.AutoMigrate(rlModels.RequestLog{})
+	err = db.DB.AutoMigrate(authModels.UserSession{}, authModels.SessionRevocation{})
 	if err != nil {
 		panic(err)
 	}
 
-	err = db.DB.AutoMigrate(MockStruct{})
+	err = db.DB.AutoMigrate(rlModels.RequestLog{})
 	if err != nil {
 		panic(err)
 	}
 
-	log := NewTestLogger()
-
-	firebaseConfig := &cliPkg.FirebaseConfig{
-		Secret: os.Getenv("FIREBASE_SECRET"),
-	}
-	firebaseClient, err := cliPkg.NewFirebaseAdmin(firebaseConfig)
+	err = db.DB.AutoMigrate(MockStruct{})
 	if err != nil {
 		panic(err)
 	}
 
+	log := NewTestLogger()
+
 	serverConfig := svrTypes.ServerConfig{
 		Host:      os.Getenv("SERVER_HOST"),
 		Port:      os.Getenv("SERVER_PORT"),
@@ -61,7 +57,7 @@ func SetupServerTestBed() TestUtils {
 		SystemUser: &authModels.User{
 			Email: "system",
 		},
-		Firebase: firebaseClient,
+		AuthProvider: NewMockAuthProvider(),
 	}
 
 	server, err := svrPkg.NewServer(&serverConfig, db, log)
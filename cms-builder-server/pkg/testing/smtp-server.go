@@ -0,0 +1,155 @@
+package testing
+
+import (
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+
+	emailPkg "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/email"
+)
+
+// SmtpMessage is an email received by the SmtpServer.
+type SmtpMessage struct {
+	From string
+	To   []string
+	Data string // Headers and body, as sent by the client
+}
+
+// Body returns the message without its headers.
+func (m SmtpMessage) Body() string {
+	_, body, _ := strings.Cut(m.Data, "\r\n\r\n")
+	return body
+}
+
+// SmtpServer is a local SMTP stand-in keeping the emails it receives, so email flows can be
+// tested without a mail provider. Any credentials are accepted.
+type SmtpServer struct {
+	Host     string
+	Port     string
+	listener net.Listener
+	mu       sync.Mutex
+	messages []SmtpMessage
+}
+
+// StartSmtpServer listens on a free local port until Close is called.
+func StartSmtpServer() (*SmtpServer, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	if err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	server := &SmtpServer{
+		Host:     host,
+		Port:     port,
+		listener: listener,
+	}
+
+	go server.serve()
+	return server, nil
+}
+
+// Sender returns an EmailSender delivering to the server.
+func (s *SmtpServer) Sender() *emailPkg.EmailSender {
+	return emailPkg.NewEmailSender(s.Host, s.Port, "test", "test", "cms@test.com")
+}
+
+// Messages returns the emails received so far.
+func (s *SmtpServer) Messages() []SmtpMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]SmtpMessage{}, s.messages...)
+}
+
+// Close stops the server.
+func (s *SmtpServer) Close() error {
+	return s.listener.Close()
+}
+
+func (s *SmtpServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+// handle speaks just enough SMTP for net/smtp: plain authentication without TLS,
+// which the client allows on localhost.
+func (s *SmtpServer) handle(conn net.Conn) {
+	defer conn.Close()
+
+	text := textproto.NewConn(conn)
+	reply := func(line string) bool {
+		return text.PrintfLine("%s", line) == nil
+	}
+
+	if !reply("220 localhost ESMTP") {
+		return
+	}
+
+	message := SmtpMessage{}
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+
+		command, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(command) {
+		case "EHLO", "HELO":
+			if !reply("250-localhost") || !reply("250 AUTH PLAIN") {
+				return
+			}
+		case "AUTH":
+			reply("235 2.7.0 Authentication successful")
+		case "MAIL":
+			message = SmtpMessage{From: addressOf(arg)}
+			reply("250 OK")
+		case "RCPT":
+			message.To = append(message.To, addressOf(arg))
+			reply("250 OK")
+		case "DATA":
+			if !reply("354 End data with <CR><LF>.<CR><LF>") {
+				return
+			}
+			data, err := text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			// Dot reading normalizes line endings to \n, messages keep the ones they were sent with
+			message.Data = strings.ReplaceAll(string(data), "\n", "\r\n")
+
+			s.mu.Lock()
+			s.messages = append(s.messages, message)
+			s.mu.Unlock()
+
+			reply("250 OK")
+		case "RSET", "NOOP":
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+// addressOf returns the address of a MAIL or RCPT argument, e.g. FROM:<user@test.com>.
+func addressOf(arg string) string {
+	_, address, _ := strings.Cut(arg, ":")
+	address = strings.TrimSpace(address)
+	if end := strings.Index(address, ">"); end >= 0 {
+		address = address[:end]
+	}
+	return strings.TrimPrefix(address, "<")
+}
@@ -7,8 +7,10 @@ import (
 	"regexp"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/gertd/go-pluralize"
+	"golang.org/x/text/unicode/norm"
 )
 
 func GetInterfaceName(input interface{}) (string, error) {
@@ -196,3 +198,33 @@ func Deepcopy(a interface{}) interface{} {
 		return a
 	}
 }
+
+// Humanize splits a camel case identifier in words, e.g. "CreatedByID" becomes "Created By ID".
+func Humanize(s string) string {
+	re := regexp.MustCompile("(.)([A-Z][a-z]+)")
+	s = re.ReplaceAllString(s, "${1} ${2}")
+	re = regexp.MustCompile("([a-z0-9])([A-Z])")
+	s = re.ReplaceAllString(s, "${1} ${2}")
+	return s
+}
+
+// Slugify turns the given text into a lowercase, URL-safe slug, e.g. "Café & Bar" becomes "cafe-bar".
+func Slugify(s string) string {
+	var builder strings.Builder
+	dash := false
+
+	for _, r := range norm.NFD.String(strings.ToLower(s)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Drop the accents split from their letters
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			builder.WriteRune(r)
+			dash = false
+		case !dash && builder.Len() > 0:
+			builder.WriteRune('-')
+			dash = true
+		}
+	}
+
+	return strings.TrimSuffix(builder.String(), "-")
+}
@@ -94,6 +94,47 @@ func TestKebabCase(t *testing.T) {
 	}
 }
 
+// TestHumanize tests the Humanize function.
+func TestHumanize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"User", "User"},
+		{"FirstName", "First Name"},
+		{"CreatedByID", "Created By ID"},
+		{"HTTPRequest", "HTTP Request"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result := Humanize(tt.input)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+// TestSlugify tests the Slugify function.
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Hello World", "hello-world"},
+		{"  Café & Bar!  ", "cafe-bar"},
+		{"Año 2024: ¿Qué pasó?", "ano-2024-que-paso"},
+		{"already-a-slug", "already-a-slug"},
+		{"日本語", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result := Slugify(tt.input)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
 func TestCompareInterfaces(t *testing.T) {
 	type TestCase struct {
 		name     string
@@ -2,6 +2,7 @@ package orchestrator
 
 import (
 	"context"
+	"time"
 
 	authConstants "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/constants"
 	authModels "github.com/frangdelsolar/cms-builder/cms-builder-server/pkg/auth/models"
@@ -40,6 +41,7 @@ func (o *Orchestrator) SetupOrchestratorUsers() error {
 			Password:         uuid.New().String(),
 			Roles:            []authTypes.Role{authConstants.AdminRole},
 			RegisterFirebase: false,
+			EmailVerified:    true,
 		},
 		{
 			FirstName:        o.Config.GetString(EnvKeys.AdminName),
@@ -47,6 +49,7 @@ func (o *Orchestrator) SetupOrchestratorUsers() error {
 			Password:         o.Config.GetString(EnvKeys.AdminPassword),
 			Roles:            []authTypes.Role{authConstants.AdminRole},
 			RegisterFirebase: true,
+			EmailVerified:    true,
 		},
 		{
 			FirstName:        "Scheduler",
@@ -54,13 +57,14 @@ func (o *Orchestrator) SetupOrchestratorUsers() error {
 			Password:         uuid.New().String(),
 			Roles:            []authTypes.Role{authConstants.SchedulerRole},
 			RegisterFirebase: false,
+			EmailVerified:    true,
 		},
 	}
 
 	for _, userData := range usersData {
-		user, err := authUtils.CreateUserWithRole(userData, o.FirebaseClient, o.DB, o.Users.System, requestId, o.Logger)
+		user, err := authUtils.CreateUserWithRole(userData, o.AuthProvider, o.DB, o.Users.System, requestId, o.Logger)
 		if err != nil {
-			o.Logger.Error().Err(err).Interface("user", userData).Msg("Error creating user")
+			o.Logger.Error().Err(err).Str("email", userData.Email).Msg("Error creating user")
 			return err
 		}
 
@@ -78,10 +82,12 @@ func (o *Orchestrator) SetupOrchestratorUsers() error {
 
 func (o *Orchestrator) GetOrCreateSystemUser(requestId string) (*authModels.User, error) {
 
+	now := time.Now()
 	systemUser := authModels.User{
-		FirstName: "System",
-		Email:     "system@" + o.Config.GetString(EnvKeys.Domain),
-		Roles:     "admin",
+		FirstName:       "System",
+		Email:           "system@" + o.Config.GetString(EnvKeys.Domain),
+		Roles:           "admin",
+		EmailVerifiedAt: &now,
 	}
 
 	filters := map[string]interface{}{
@@ -95,7 +101,7 @@ func (o *Orchestrator) GetOrCreateSystemUser(requestId string) (*authModels.User
 		o.Logger.Warn().Err(err).Msg("System User not found")
 	}
 
-	if systemUser.ID == 0 || systemUser == (authModels.User{}) {
+	if systemUser.ID == 0 {
 		o.Logger.Debug().Interface("user", systemUser).Msg("Creating system user from config")
 		err := dbQueries.Create(ctx, o.Logger, o.DB, &systemUser, &systemUser, requestId)
 		if err != nil {